@@ -1,43 +1,164 @@
 package main
 
 import (
-	"bufio"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
-	"strings"
+	"os/signal"
+	"syscall"
+
+	"github.com/MatiXxD/url-shortener/pkg/client"
+)
+
+// Exit codes
+const (
+	exitOK        = 0
+	exitError     = 1 // request failed or server error
+	exitUsage     = 2 // wrong command, flags or config
+	exitPartial   = 3 // some items of command failed
+	exitNotFound  = 4 // link doesn't exist or was deleted
+	exitForbidden = 5 // auth is missing, api key lacks scope or link belongs to another user
 )
 
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+// reportedError was already written to stderr
+type reportedError struct {
+	error
+}
+
+func (e *reportedError) Unwrap() error {
+	return e.error
+}
+
+func usageErrorf(format string, args ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// cli is environment of running command
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	globals *globalFlags
+	cfg     *clientConfig
+	api     *client.Client
+	out     *printer
+}
+
 func main() {
-	endpoint := "http://localhost:8080/"
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
 
-	fmt.Println("Введите длинный URL")
-	reader := bufio.NewReader(os.Stdin)
-	long, err := reader.ReadString('\n')
-	if err != nil {
-		panic(err)
+// run executes command from args and returns exit code
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	c := &cli{stdin: stdin, stdout: stdout, stderr: stderr, globals: newGlobalFlags()}
+
+	fs := flag.NewFlagSet("client", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	c.globals.register(fs)
+	fs.Usage = func() { c.usage(fs) }
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
 	}
+	c.globals.visit(fs)
 
-	data := strings.TrimSuffix(long, "\n")
-	client := &http.Client{}
-	request, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(data))
-	if err != nil {
-		panic(err)
+	if fs.NArg() == 0 {
+		c.usage(fs)
+		return exitUsage
+	}
+
+	name := fs.Arg(0)
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+
+		err := cmd.run(ctx, c, fs.Args()[1:])
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		var reported *reportedError
+		if err != nil && !errors.Is(err, errPartial) && !errors.As(err, &reported) {
+			fmt.Fprintf(stderr, "%s: %v\n", name, err)
+		}
+		return exitCode(err)
 	}
 
-	request.Header.Add("Content-Type", "text/plain")
-	response, err := client.Do(request)
+	fmt.Fprintf(stderr, "unknown command %q\n", name)
+	c.usage(fs)
+	return exitUsage
+}
+
+func (c *cli) usage(fs *flag.FlagSet) {
+	fmt.Fprintf(c.stderr, "Usage: client [flags] <command> [command flags] [args]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(c.stderr, "  %-8s %s\n", cmd.name, cmd.usage)
+	}
+	fmt.Fprintf(c.stderr, "\nFlags:\n")
+	fs.PrintDefaults()
+}
+
+// flagSet creates flag set of command, global flags are added on parse
+func (c *cli) flagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: client %s %s\n\nFlags:\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parse parses command flags and prepares config and api client
+func (c *cli) parse(fs *flag.FlagSet, args []string) error {
+	c.globals.register(fs)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		// flag set has already reported the error
+		return &reportedError{&usageError{msg: err.Error()}}
+	}
+	c.globals.visit(fs)
+
+	cfg, err := c.globals.load()
 	if err != nil {
-		panic(err)
+		return &usageError{msg: err.Error()}
 	}
 
-	fmt.Println("Статус-код ", response.Status)
-	defer response.Body.Close()
-	body, err := io.ReadAll(response.Body)
+	ccfg := client.DefaultConfig()
+	ccfg.BaseURL = cfg.Server
+	ccfg.Token = cfg.Token
+	ccfg.APIKey = cfg.APIKey
+	ccfg.CookieName = cfg.CookieName
+	ccfg.Gzip = cfg.Gzip
+	ccfg.Timeout = cfg.Timeout
+
+	api, err := client.New(ccfg)
 	if err != nil {
-		panic(err)
+		return &usageError{msg: err.Error()}
 	}
 
-	fmt.Println(string(body))
+	c.cfg = cfg
+	c.api = api
+	c.out = &printer{format: cfg.Output, w: c.stdout}
+
+	return nil
 }
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/config"
+	mw "github.com/MatiXxD/url-shortener/internal/middleware"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/url/handlers"
+	"github.com/MatiXxD/url-shortener/internal/url/repository"
+	"github.com/MatiXxD/url-shortener/internal/url/usecase"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func runTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	l := &logger.Logger{SugaredLogger: zap.NewNop().Sugar()}
+
+	ts := httptest.NewUnstartedServer(nil)
+	cfg := config.Default()
+	cfg.BaseURL = "http://" + ts.Listener.Addr().String()
+
+	u := usecase.NewUrlUsecase(repository.NewMapRepository(map[string]*models.URL{}, l), cfg, l)
+	h := handlers.NewUrlHandler(u, cfg, l)
+
+	mux := chi.NewRouter()
+	mux.Use(mw.CompressMiddleware)
+	mux.Use(func(next http.Handler) http.Handler {
+		return mw.AuthMiddleware([]byte("secret"), cfg.Auth.CookieName, time.Hour, next)
+	})
+	mux.Get("/{url}", h.GetURL)
+	mux.Post("/api/shorten", h.ShortenURL)
+	mux.Post("/api/shorten/batch", h.BatchReduceURL)
+	mux.Get("/api/urls", h.ListURLs)
+	mux.Delete("/api/urls/{url}", h.DeleteURL)
+
+	ts.Config.Handler = mux
+	ts.Start()
+	t.Cleanup(ts.Close)
+
+	return ts
+}
+
+func runClient(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// userToken returns auth cookie issued by server to new user
+func userToken(t *testing.T, ts *httptest.Server) string {
+	t.Helper()
+
+	resp, err := http.Get(ts.URL + "/api/urls")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	for _, c := range resp.Cookies() {
+		if c.Name == "token" {
+			return c.Value
+		}
+	}
+	t.Fatal("server did not issue token")
+	return ""
+}
+
+func TestClient(t *testing.T) {
+	t.Setenv("SHORTENER_CONFIG", filepath.Join(t.TempDir(), "missing.json"))
+	// missing explicit config is an error
+	code, _, stderr := runClient(t, "", "stats")
+	require.Equal(t, exitUsage, code)
+	require.Contains(t, stderr, "failed to read config file")
+
+	cfgPath := filepath.Join(t.TempDir(), "client.json")
+	require.NoError(t, os.WriteFile(cfgPath, []byte(`{"timeout": "5s", "output": "json"}`), 0o600))
+	t.Setenv("SHORTENER_CONFIG", cfgPath)
+
+	ts := runTestServer(t)
+	token := userToken(t, ts)
+	global := []string{"-server", ts.URL, "-token", token}
+
+	code, stdout, stderr := runClient(t, "", append(global, "shorten", "-gzip", "-tag", "cli", "https://a.com")...)
+	require.Equal(t, exitOK, code, stdout+stderr)
+
+	var shortened []struct {
+		URL      string `json:"url"`
+		ShortURL string `json:"short_url"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &shortened))
+	require.Len(t, shortened, 1)
+	require.True(t, strings.HasPrefix(shortened[0].ShortURL, ts.URL+"/"))
+
+	code, stdout, stderr = runClient(t, "", append(global, "resolve", shortened[0].ShortURL)...)
+	require.Equal(t, exitOK, code, stderr)
+	require.Contains(t, stdout, `"location": "https://a.com"`)
+
+	code, _, _ = runClient(t, "", append(global, "resolve", "missing")...)
+	require.Equal(t, exitNotFound, code)
+
+	var batch strings.Builder
+	batch.WriteString("# comment\n\n")
+	for i := range 25 {
+		fmt.Fprintf(&batch, "https://b.com/%d\n", i)
+	}
+	// longer than limit of server
+	batch.WriteString("https://c.com/" + strings.Repeat("a", 3000) + "\n")
+
+	code, stdout, stderr = runClient(t, batch.String(),
+		append(global, "-chunk-size", "4", "-workers", "3", "-gzip", "batch", "-")...)
+	require.Equal(t, exitPartial, code, stderr)
+
+	var res []*models.UrlDTO
+	require.NoError(t, json.Unmarshal([]byte(stdout), &res))
+	require.Len(t, res, 26)
+	for i, r := range res[:25] {
+		require.Equal(t, fmt.Sprint(i+3), r.CorrelationID)
+		require.Equal(t, fmt.Sprintf("https://b.com/%d", i), r.OriginURL)
+		require.Equal(t, models.BatchStatusCreated, r.Status)
+	}
+	require.Equal(t, models.BatchStatusInvalid, res[25].Status)
+
+	code, stdout, stderr = runClient(t, "", append(global, "-o", "table", "list", "-tag", "cli")...)
+	require.Equal(t, exitOK, code, stderr)
+	require.Contains(t, stdout, "SHORT URL")
+	require.Contains(t, stdout, "https://a.com")
+	require.NotContains(t, stdout, "https://b.com")
+
+	code, stdout, stderr = runClient(t, "", append(global, "list", "-all", "-limit", "10")...)
+	require.Equal(t, exitOK, code, stderr)
+
+	var page models.LinkPage
+	require.NoError(t, json.Unmarshal([]byte(stdout), &page))
+	require.Len(t, page.Items, 26)
+
+	code, _, stderr = runClient(t, "", append(global, "delete", shortened[0].ShortURL)...)
+	require.Equal(t, exitOK, code, stderr)
+
+	code, _, _ = runClient(t, "", append(global, "resolve", shortened[0].ShortURL)...)
+	require.Equal(t, exitNotFound, code)
+
+	code, _, _ = runClient(t, "", append(global, "unknown")...)
+	require.Equal(t, exitUsage, code)
+
+	code, _, _ = runClient(t, "", append(global, "shorten")...)
+	require.Equal(t, exitUsage, code)
+
+	code, _, _ = runClient(t, "", append(global, "-o", "xml", "list")...)
+	require.Equal(t, exitUsage, code)
+}
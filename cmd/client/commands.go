@@ -0,0 +1,422 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	neturl "net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/pkg/client"
+)
+
+// errPartial means some items of command failed, the rest succeeded
+var errPartial = errors.New("some items failed")
+
+type command struct {
+	name  string
+	usage string
+	run   func(ctx context.Context, c *cli, args []string) error
+}
+
+var commands = []command{
+	{"shorten", "Shorten urls: shorten [flags] <url>...", cmdShorten},
+	{"batch", "Shorten urls from file, one per line: batch [flags] [file|-]", cmdBatch},
+	{"resolve", "Show destination of short links: resolve <code|short url>...", cmdResolve},
+	{"list", "List own links: list [flags]", cmdList},
+	{"delete", "Delete own links: delete [flags] <code|short url>...", cmdDelete},
+	{"stats", "Show service stats, allowed from trusted subnet only: stats", cmdStats},
+}
+
+// stringsFlag collects repeated flag values
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringsFlag) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+func cmdShorten(ctx context.Context, c *cli, args []string) error {
+	fs := c.flagSet("shorten", "[flags] <url>...")
+	title := fs.String("title", "", "Title of links")
+	var tags stringsFlag
+	fs.Var(&tags, "tag", "Tag of links, can be repeated")
+	shortDomain := fs.String("short-domain", "", "Short domain of links")
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return usageErrorf("at least one url is required")
+	}
+
+	type result struct {
+		URL      string `json:"url"`
+		ShortURL string `json:"short_url,omitempty"`
+		Error    string `json:"error,omitempty"`
+	}
+
+	var (
+		res    = make([]*result, 0, fs.NArg())
+		rows   = make([][]string, 0, fs.NArg())
+		failed int
+		last   error
+	)
+	for _, u := range fs.Args() {
+		r := &result{URL: u}
+		resp, err := c.api.Shorten(ctx, &client.ShortenRequest{
+			URL:         u,
+			Title:       *title,
+			Tags:        tags,
+			ShortDomain: *shortDomain,
+		})
+		if err != nil {
+			r.Error, last = err.Error(), err
+			failed++
+		} else {
+			r.ShortURL = resp.ShortURL
+		}
+		res = append(res, r)
+		rows = append(rows, []string{r.URL, r.ShortURL, r.Error})
+	}
+
+	if err := c.out.print(res, []string{"URL", "SHORT URL", "ERROR"}, rows); err != nil {
+		return err
+	}
+
+	switch {
+	case failed == len(res):
+		return &reportedError{last}
+	case failed != 0:
+		return errPartial
+	}
+	return nil
+}
+
+func cmdBatch(ctx context.Context, c *cli, args []string) error {
+	fs := c.flagSet("batch", "[flags] [file|-]")
+	title := fs.String("title", "", "Title of links")
+	var tags stringsFlag
+	fs.Var(&tags, "tag", "Tag of links, can be repeated")
+	shortDomain := fs.String("short-domain", "", "Short domain of links")
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return usageErrorf("only one file is accepted")
+	}
+
+	var in io.Reader = c.stdin
+	if name := fs.Arg(0); name != "" && name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return fmt.Errorf("cannot open batch file: %w", err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	items, err := readBatch(in)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return usageErrorf("no urls to shorten")
+	}
+	for _, it := range items {
+		it.Title, it.Tags, it.ShortDomain = *title, tags, *shortDomain
+	}
+
+	res := c.sendBatch(ctx, items)
+
+	rows := make([][]string, 0, len(res))
+	failed := 0
+	for _, r := range res {
+		if r.Status != client.BatchStatusCreated && r.Status != client.BatchStatusExisting {
+			failed++
+		}
+		rows = append(rows, []string{r.CorrelationID, r.Status, r.OriginURL, r.ShortURL, r.Error})
+	}
+	if err := c.out.print(res, []string{"LINE", "STATUS", "URL", "SHORT URL", "ERROR"}, rows); err != nil {
+		return err
+	}
+
+	switch {
+	case failed == len(res):
+		return errors.New("no urls were shortened")
+	case failed != 0:
+		return errPartial
+	}
+	return nil
+}
+
+// readBatch reads one url per line, empty lines and lines starting with # are skipped
+func readBatch(r io.Reader) ([]*client.BatchItem, error) {
+	var items []*client.BatchItem
+
+	sc := bufio.NewScanner(r)
+	line := 0
+	for sc.Scan() {
+		line++
+		u := strings.TrimSpace(sc.Text())
+		if u == "" || strings.HasPrefix(u, "#") {
+			continue
+		}
+		items = append(items, &client.BatchItem{CorrelationID: strconv.Itoa(line), OriginURL: u})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read batch: %w", err)
+	}
+
+	return items, nil
+}
+
+// sendBatch sends items by chunks from several workers, results keep order of items
+func (c *cli) sendBatch(ctx context.Context, items []*client.BatchItem) []*client.BatchItem {
+	res := make([]*client.BatchItem, len(items))
+
+	chunks := make(chan int)
+	var wg sync.WaitGroup
+	for range c.cfg.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for start := range chunks {
+				end := min(start+c.cfg.ChunkSize, len(items))
+				c.sendChunk(ctx, items[start:end], res[start:end])
+			}
+		}()
+	}
+
+	for start := 0; start < len(items); start += c.cfg.ChunkSize {
+		chunks <- start
+	}
+	close(chunks)
+	wg.Wait()
+
+	return res
+}
+
+func (c *cli) sendChunk(ctx context.Context, items, res []*client.BatchItem) {
+	saved, err := c.api.ShortenBatch(ctx, items, false)
+	if err != nil && !errors.Is(err, client.ErrPartialBatch) {
+		for i, it := range items {
+			res[i] = &client.BatchItem{
+				CorrelationID: it.CorrelationID,
+				OriginURL:     it.OriginURL,
+				Status:        client.BatchStatusFailed,
+				Error:         err.Error(),
+			}
+		}
+		return
+	}
+
+	byID := make(map[string]*client.BatchItem, len(saved))
+	for _, s := range saved {
+		byID[s.CorrelationID] = s
+	}
+	for i, it := range items {
+		s, ok := byID[it.CorrelationID]
+		if !ok {
+			s = &client.BatchItem{CorrelationID: it.CorrelationID, Status: client.BatchStatusFailed, Error: "missing in response"}
+		}
+		s.OriginURL = it.OriginURL
+		res[i] = s
+	}
+}
+
+func cmdResolve(ctx context.Context, c *cli, args []string) error {
+	fs := c.flagSet("resolve", "<code|short url>...")
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return usageErrorf("at least one code is required")
+	}
+
+	var (
+		res  = make([]*resolved, 0, fs.NArg())
+		rows = make([][]string, 0, fs.NArg())
+		last error
+	)
+	for _, link := range fs.Args() {
+		r, err := c.api.Resolve(ctx, link, nil)
+		if err != nil {
+			fmt.Fprintf(c.stderr, "%s: %v\n", link, err)
+			last = err
+			continue
+		}
+		res = append(res, &resolved{Link: link, Status: r.StatusCode, Location: r.Location})
+		rows = append(rows, []string{link, strconv.Itoa(r.StatusCode), r.Location})
+	}
+
+	if err := c.out.print(res, []string{"LINK", "STATUS", "LOCATION"}, rows); err != nil {
+		return err
+	}
+
+	if last != nil && len(res) != 0 {
+		return errPartial
+	}
+	if last != nil {
+		return &reportedError{last}
+	}
+	return nil
+}
+
+func cmdList(ctx context.Context, c *cli, args []string) error {
+	fs := c.flagSet("list", "[flags]")
+	tag := fs.String("tag", "", "Only links with tag")
+	domain := fs.String("domain", "", "Only links to destination host")
+	shortDomain := fs.String("short-domain", "", "Only links of short domain")
+	deleted := fs.String("deleted", "", "Deleted links: false, true or all")
+	sort := fs.String("sort", "", "Sort field, - prefix for descending order")
+	limit := fs.Int("limit", 0, "Page size")
+	cursor := fs.String("cursor", "", "Cursor of page")
+	all := fs.Bool("all", false, "Fetch all pages")
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	opts := &client.ListOptions{
+		Tag:         *tag,
+		Domain:      *domain,
+		ShortDomain: *shortDomain,
+		Deleted:     *deleted,
+		Sort:        strings.TrimPrefix(*sort, "-"),
+		Desc:        strings.HasPrefix(*sort, "-"),
+		Limit:       *limit,
+		Cursor:      *cursor,
+	}
+
+	page := &client.LinkPage{}
+	for {
+		p, err := c.api.ListURLs(ctx, opts)
+		if err != nil {
+			return err
+		}
+		page.Items = append(page.Items, p.Items...)
+		page.NextCursor = p.NextCursor
+		if !*all || p.NextCursor == "" {
+			break
+		}
+		opts.Cursor = p.NextCursor
+	}
+
+	rows := make([][]string, 0, len(page.Items))
+	for _, l := range page.Items {
+		rows = append(rows, []string{
+			l.ShortURL, l.OriginalURL, l.Title, strings.Join(l.Tags, ","),
+			l.CreatedAt.Format(time.RFC3339), strconv.FormatBool(l.Deleted),
+		})
+	}
+	if err := c.out.print(page, []string{"SHORT URL", "URL", "TITLE", "TAGS", "CREATED", "DELETED"}, rows); err != nil {
+		return err
+	}
+	if page.NextCursor != "" && c.cfg.Output == outputTable {
+		fmt.Fprintf(c.stderr, "next cursor: %s\n", page.NextCursor)
+	}
+
+	return nil
+}
+
+func cmdDelete(ctx context.Context, c *cli, args []string) error {
+	fs := c.flagSet("delete", "[flags] <code|short url>...")
+	shortDomain := fs.String("short-domain", "", "Short domain of links")
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return usageErrorf("at least one code is required")
+	}
+
+	var last error
+	deleted := 0
+	for _, link := range fs.Args() {
+		if err := c.api.DeleteURL(ctx, shortCode(link), &client.LinkOptions{ShortDomain: *shortDomain}); err != nil {
+			fmt.Fprintf(c.stderr, "%s: %v\n", link, err)
+			last = err
+			continue
+		}
+		deleted++
+	}
+
+	if c.cfg.Output == outputJSON {
+		if err := c.out.print(map[string]int{"deleted": deleted}, nil, nil); err != nil {
+			return err
+		}
+	}
+
+	if last != nil && deleted != 0 {
+		return errPartial
+	}
+	if last != nil {
+		return &reportedError{last}
+	}
+	return nil
+}
+
+func cmdStats(ctx context.Context, c *cli, args []string) error {
+	fs := c.flagSet("stats", "")
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	stats, err := c.api.Stats(ctx)
+	if err != nil {
+		return err
+	}
+
+	rows := [][]string{
+		{"urls", strconv.Itoa(stats.URLs)},
+		{"users", strconv.Itoa(stats.Users)},
+		{"deleted", strconv.Itoa(stats.Deleted)},
+	}
+	for _, n := range stats.DBNodes {
+		rows = append(rows, []string{"db " + n.Name, fmt.Sprintf("healthy=%t reads=%d lag=%dms conns=%d/%d",
+			n.Healthy, n.Reads, n.LagMs, n.AcquiredConns, n.MaxConns)})
+	}
+
+	return c.out.print(stats, nil, rows)
+}
+
+// exitCode maps command error to process exit code
+func exitCode(err error) int {
+	var usageErr *usageError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &usageErr):
+		return exitUsage
+	case errors.Is(err, errPartial):
+		return exitPartial
+	case errors.Is(err, client.ErrNotFound), errors.Is(err, client.ErrGone):
+		return exitNotFound
+	case errors.Is(err, client.ErrUnauthorized), errors.Is(err, client.ErrForbidden):
+		return exitForbidden
+	}
+	return exitError
+}
+
+type resolved struct {
+	Link     string `json:"link"`
+	Status   int    `json:"status"`
+	Location string `json:"location,omitempty"`
+}
+
+// shortCode accepts code or full short url
+func shortCode(s string) string {
+	if u, err := neturl.Parse(s); err == nil && u.Host != "" {
+		return path.Base(strings.TrimSuffix(u.Path, "/"))
+	}
+	return strings.Trim(s, "/")
+}
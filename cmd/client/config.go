@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// clientConfig is built in layers: defaults < config file < env < flags.
+type clientConfig struct {
+	Server     string        `json:"server"`
+	Token      string        `json:"token"`
+	APIKey     string        `json:"api_key"`
+	CookieName string        `json:"cookie_name"`
+	Output     string        `json:"output"`
+	Gzip       bool          `json:"gzip"`
+	Timeout    time.Duration `json:"-"`
+	// Workers batch requests are sent concurrently
+	Workers int `json:"workers"`
+	// ChunkSize urls are sent in one batch request
+	ChunkSize int `json:"chunk_size"`
+
+	// ConfigPath only comes from flags/env
+	ConfigPath string `json:"-"`
+}
+
+// Output formats
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+const (
+	defaultServer     = "http://localhost:8080"
+	defaultCookieName = "token"
+	defaultTimeout    = 30 * time.Second
+	defaultWorkers    = 4
+	defaultChunkSize  = 100
+
+	configFileName = "url-shortener/client.json"
+)
+
+func defaultConfig() *clientConfig {
+	return &clientConfig{
+		Server:     defaultServer,
+		CookieName: defaultCookieName,
+		Output:     outputTable,
+		Timeout:    defaultTimeout,
+		Workers:    defaultWorkers,
+		ChunkSize:  defaultChunkSize,
+	}
+}
+
+// UnmarshalJSON reads timeout as "30s"
+func (c *clientConfig) UnmarshalJSON(data []byte) error {
+	type plain clientConfig
+	aux := struct {
+		*plain
+		Timeout string `json:"timeout"`
+	}{plain: (*plain)(c)}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&aux); err != nil {
+		return err
+	}
+
+	if aux.Timeout != "" {
+		v, err := time.ParseDuration(aux.Timeout)
+		if err != nil {
+			return fmt.Errorf("timeout: %w", err)
+		}
+		c.Timeout = v
+	}
+
+	return nil
+}
+
+// globalFlags are accepted before and after command name
+type globalFlags struct {
+	cfg *clientConfig
+	set map[string]func(dst, src *clientConfig)
+}
+
+var flagSetters = map[string]func(dst, src *clientConfig){
+	"server":      func(dst, src *clientConfig) { dst.Server = src.Server },
+	"token":       func(dst, src *clientConfig) { dst.Token = src.Token },
+	"api-key":     func(dst, src *clientConfig) { dst.APIKey = src.APIKey },
+	"cookie-name": func(dst, src *clientConfig) { dst.CookieName = src.CookieName },
+	"o":           func(dst, src *clientConfig) { dst.Output = src.Output },
+	"gzip":        func(dst, src *clientConfig) { dst.Gzip = src.Gzip },
+	"timeout":     func(dst, src *clientConfig) { dst.Timeout = src.Timeout },
+	"workers":     func(dst, src *clientConfig) { dst.Workers = src.Workers },
+	"chunk-size":  func(dst, src *clientConfig) { dst.ChunkSize = src.ChunkSize },
+}
+
+func newGlobalFlags() *globalFlags {
+	return &globalFlags{
+		cfg: defaultConfig(),
+		set: make(map[string]func(dst, src *clientConfig)),
+	}
+}
+
+// register adds global flags to flag set of command, values parsed before command name are kept
+func (g *globalFlags) register(fs *flag.FlagSet) {
+	c := g.cfg
+	fs.StringVar(&c.Server, "server", c.Server, "Server base url")
+	fs.StringVar(&c.Token, "token", c.Token, "Auth token, value of auth cookie")
+	fs.StringVar(&c.APIKey, "api-key", c.APIKey, "API key, sent instead of auth cookie")
+	fs.StringVar(&c.CookieName, "cookie-name", c.CookieName, "Name of auth cookie")
+	fs.StringVar(&c.Output, "o", c.Output, "Output format: table or json")
+	fs.BoolVar(&c.Gzip, "gzip", c.Gzip, "Compress request bodies")
+	fs.DurationVar(&c.Timeout, "timeout", c.Timeout, "Request timeout")
+	fs.IntVar(&c.Workers, "workers", c.Workers, "Concurrent batch requests")
+	fs.IntVar(&c.ChunkSize, "chunk-size", c.ChunkSize, "Urls in one batch request")
+	fs.StringVar(&c.ConfigPath, "config", c.ConfigPath, "Path to json config file")
+}
+
+// visit remembers flags which were set explicitly
+func (g *globalFlags) visit(fs *flag.FlagSet) {
+	fs.Visit(func(f *flag.Flag) {
+		if apply, ok := flagSetters[f.Name]; ok {
+			g.set[f.Name] = apply
+		}
+	})
+}
+
+// envVars maps environment variables to config fields
+var envVars = []struct {
+	name string
+	set  func(cfg *clientConfig, value string) error
+}{
+	{"SHORTENER_SERVER", func(c *clientConfig, v string) error { c.Server = v; return nil }},
+	{"SHORTENER_TOKEN", func(c *clientConfig, v string) error { c.Token = v; return nil }},
+	{"SHORTENER_API_KEY", func(c *clientConfig, v string) error { c.APIKey = v; return nil }},
+	{"SHORTENER_COOKIE_NAME", func(c *clientConfig, v string) error { c.CookieName = v; return nil }},
+	{"SHORTENER_OUTPUT", func(c *clientConfig, v string) error { c.Output = v; return nil }},
+	{"SHORTENER_GZIP", func(c *clientConfig, v string) (err error) { c.Gzip, err = strconv.ParseBool(v); return }},
+	{"SHORTENER_TIMEOUT", func(c *clientConfig, v string) (err error) { c.Timeout, err = time.ParseDuration(v); return }},
+	{"SHORTENER_WORKERS", func(c *clientConfig, v string) (err error) { c.Workers, err = strconv.Atoi(v); return }},
+	{"SHORTENER_CHUNK_SIZE", func(c *clientConfig, v string) (err error) { c.ChunkSize, err = strconv.Atoi(v); return }},
+}
+
+// load applies config layers in order of precedence and validates result
+func (g *globalFlags) load() (*clientConfig, error) {
+	cfg := defaultConfig()
+
+	cfg.ConfigPath = os.Getenv("SHORTENER_CONFIG")
+	if g.cfg.ConfigPath != "" {
+		cfg.ConfigPath = g.cfg.ConfigPath
+	}
+
+	path, required := cfg.ConfigPath, true
+	if path == "" {
+		// default config file is optional
+		if dir, err := os.UserConfigDir(); err == nil {
+			path, required = filepath.Join(dir, configFileName), false
+		}
+	}
+	if path != "" {
+		if err := parseFile(cfg, path, required); err != nil {
+			return nil, err
+		}
+	}
+
+	var errs []error
+	for _, v := range envVars {
+		value := os.Getenv(v.name)
+		if value == "" {
+			continue
+		}
+		if err := v.set(cfg, value); err != nil {
+			errs = append(errs, fmt.Errorf("env %s: %w", v.name, err))
+		}
+	}
+
+	for _, apply := range g.set {
+		apply(cfg, g.cfg)
+	}
+
+	if err := cfg.validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) != 0 {
+		return nil, fmt.Errorf("invalid config:\n%w", errors.Join(errs...))
+	}
+
+	return cfg, nil
+}
+
+func parseFile(cfg *clientConfig, path string, required bool) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !required {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (c *clientConfig) validate() error {
+	var errs []error
+
+	if c.Server == "" {
+		errs = append(errs, errors.New("server: must not be empty"))
+	}
+	if c.Output != outputTable && c.Output != outputJSON {
+		errs = append(errs, fmt.Errorf("output: must be %s or %s, got %q", outputTable, outputJSON, c.Output))
+	}
+	if c.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("timeout: must not be negative, got %s", c.Timeout))
+	}
+	if c.Workers < 1 {
+		errs = append(errs, fmt.Errorf("workers: must be positive, got %d", c.Workers))
+	}
+	if c.ChunkSize < 1 {
+		errs = append(errs, fmt.Errorf("chunk_size: must be positive, got %d", c.ChunkSize))
+	}
+
+	return errors.Join(errs...)
+}
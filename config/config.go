@@ -1,56 +1,412 @@
 package config
 
 import (
+	"errors"
 	"flag"
+	"fmt"
+	"net/http"
 	"os"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/pkg/tokengen"
 )
 
+// ServiceConfig is built in layers: defaults < config file < env < flags.
 type ServiceConfig struct {
-	Addr        string
-	BaseURL     string
-	LoggerLevel string
-	FilePath    string
-	DSN         string
+	Addr          string   `json:"addr" yaml:"addr" toml:"addr"`
+	BaseURL       string   `json:"base_url" yaml:"base_url" toml:"base_url"`
+	Domains       []string `json:"domains" yaml:"domains" toml:"domains"`
+	LoggerLevel   string   `json:"log_level" yaml:"log_level" toml:"log_level"`
+	TrustedSubnet string   `json:"trusted_subnet" yaml:"trusted_subnet" toml:"trusted_subnet"`
+	// TrustedProxies are subnets of proxies X-Real-IP header is taken from
+	TrustedProxies []string       `json:"trusted_proxies" yaml:"trusted_proxies" toml:"trusted_proxies"`
+	Storage        StorageConfig  `json:"storage" yaml:"storage" toml:"storage"`
+	Cache          CacheConfig    `json:"cache" yaml:"cache" toml:"cache"`
+	Auth           AuthConfig     `json:"auth" yaml:"auth" toml:"auth"`
+	Limits         LimitsConfig   `json:"limits" yaml:"limits" toml:"limits"`
+	TLS            TLSConfig      `json:"tls" yaml:"tls" toml:"tls"`
+	Preview        PreviewConfig  `json:"preview" yaml:"preview" toml:"preview"`
+	Redirect       RedirectConfig `json:"redirect" yaml:"redirect" toml:"redirect"`
+	ShortCode      CodeConfig     `json:"short_code" yaml:"short_code" toml:"short_code"`
+	Webhooks       WebhookConfig  `json:"webhooks" yaml:"webhooks" toml:"webhooks"`
+	Outbox         OutboxConfig   `json:"outbox" yaml:"outbox" toml:"outbox"`
+	APIKeys        APIKeyConfig   `json:"api_keys" yaml:"api_keys" toml:"api_keys"`
+	Quotas         QuotaConfig    `json:"quotas" yaml:"quotas" toml:"quotas"`
+
+	// ConfigPath and PrintConfig only come from flags/env
+	ConfigPath  string `json:"-" yaml:"-" toml:"-"`
+	PrintConfig bool   `json:"-" yaml:"-" toml:"-"`
+}
+
+type StorageConfig struct {
+	FilePath string `json:"file_path" yaml:"file_path" toml:"file_path"`
+	DSN      string `json:"dsn" yaml:"dsn" toml:"dsn"`
+	// BatchChunkSize urls of batch and stream are saved by one storage call
+	BatchChunkSize int `json:"batch_chunk_size" yaml:"batch_chunk_size" toml:"batch_chunk_size"`
+	// CopyThreshold is minimal chunk which postgres saves with COPY, zero disables it
+	CopyThreshold int `json:"copy_threshold" yaml:"copy_threshold" toml:"copy_threshold"`
+	// ReplicaDSNs serve url reads, writes always go to DSN
+	ReplicaDSNs []string `json:"replica_dsns" yaml:"replica_dsns" toml:"replica_dsns"`
+	// ReplicaCheckInterval is how often replicas are pinged
+	ReplicaCheckInterval Duration `json:"replica_check_interval" yaml:"replica_check_interval" toml:"replica_check_interval"`
+	// ReplicaMaxLag marks lagging replica unhealthy, zero disables lag check
+	ReplicaMaxLag Duration `json:"replica_max_lag" yaml:"replica_max_lag" toml:"replica_max_lag"`
+}
+
+type CacheConfig struct {
+	Size int      `json:"size" yaml:"size" toml:"size"`
+	TTL  Duration `json:"ttl" yaml:"ttl" toml:"ttl"`
+}
+
+type AuthConfig struct {
+	Secret     string   `json:"secret" yaml:"secret" toml:"secret"`
+	CookieName string   `json:"cookie_name" yaml:"cookie_name" toml:"cookie_name"`
+	TokenTTL   Duration `json:"token_ttl" yaml:"token_ttl" toml:"token_ttl"`
+	// AdminToken is required by management endpoints, empty token closes them
+	AdminToken string `json:"admin_token" yaml:"admin_token" toml:"admin_token"`
+}
+
+type LimitsConfig struct {
+	MaxBodySize  int64 `json:"max_body_size" yaml:"max_body_size" toml:"max_body_size"`
+	MaxBatchSize int   `json:"max_batch_size" yaml:"max_batch_size" toml:"max_batch_size"`
+	MaxURLLength int   `json:"max_url_length" yaml:"max_url_length" toml:"max_url_length"`
+	// MaxStreamItems caps ndjson batch, its body is limited by MaxBodySize per line
+	MaxStreamItems int `json:"max_stream_items" yaml:"max_stream_items" toml:"max_stream_items"`
+	// PasswordAttempts failed attempts per code lock it for PasswordLockout
+	PasswordAttempts int      `json:"password_attempts" yaml:"password_attempts" toml:"password_attempts"`
+	PasswordLockout  Duration `json:"password_lockout" yaml:"password_lockout" toml:"password_lockout"`
+}
+
+type TLSConfig struct {
+	Enabled        bool     `json:"enabled" yaml:"enabled" toml:"enabled"`
+	CertFile       string   `json:"cert_file" yaml:"cert_file" toml:"cert_file"`
+	KeyFile        string   `json:"key_file" yaml:"key_file" toml:"key_file"`
+	MinVersion     string   `json:"min_version" yaml:"min_version" toml:"min_version"`
+	CipherPolicy   string   `json:"cipher_policy" yaml:"cipher_policy" toml:"cipher_policy"`
+	ClientCAFile   string   `json:"client_ca_file" yaml:"client_ca_file" toml:"client_ca_file"`
+	ClientAuth     string   `json:"client_auth" yaml:"client_auth" toml:"client_auth"`
+	SelfSigned     bool     `json:"self_signed" yaml:"self_signed" toml:"self_signed"`
+	ReloadInterval Duration `json:"reload_interval" yaml:"reload_interval" toml:"reload_interval"`
+	HTTP2          bool     `json:"http2" yaml:"http2" toml:"http2"`
+	RedirectAddr   string   `json:"redirect_addr" yaml:"redirect_addr" toml:"redirect_addr"`
+}
+
+type PreviewConfig struct {
+	// Untrusted enables interstitial for links created by users not listed in TrustedUsers
+	Untrusted    bool     `json:"untrusted" yaml:"untrusted" toml:"untrusted"`
+	TrustedUsers []string `json:"trusted_users" yaml:"trusted_users" toml:"trusted_users"`
+}
+
+type RedirectConfig struct {
+	// Code is used for links created without own redirect code
+	Code int `json:"code" yaml:"code" toml:"code"`
+	// QueryPrecedence decides whose value wins when passthrough query has the same key as destination
+	QueryPrecedence string `json:"query_precedence" yaml:"query_precedence" toml:"query_precedence"`
+}
+
+type CodeConfig struct {
+	Strategy string `json:"strategy" yaml:"strategy" toml:"strategy"`
+	// Alphabet is preset name or symbols
+	Alphabet string `json:"alphabet" yaml:"alphabet" toml:"alphabet"`
+	// Length is exact for random and hash codes and minimal for sequence ones, check symbol is not counted
+	Length int `json:"length" yaml:"length" toml:"length"`
+	// Salt changes hash and hashids codes
+	Salt string `json:"salt" yaml:"salt" toml:"salt"`
+	// Denylist words must not appear in generated codes
+	Denylist []string `json:"denylist" yaml:"denylist" toml:"denylist"`
+	// CheckSymbol appends symbol which catches mistyped codes, codes issued without it keep working
+	CheckSymbol bool `json:"check_symbol" yaml:"check_symbol" toml:"check_symbol"`
+	// CaseInsensitive resolves codes in any case, alphabet must be lower case
+	CaseInsensitive bool `json:"case_insensitive" yaml:"case_insensitive" toml:"case_insensitive"`
 }
 
+type WebhookConfig struct {
+	// Workers send deliveries concurrently
+	Workers int `json:"workers" yaml:"workers" toml:"workers"`
+	// MaxAttempts failed attempts make delivery dead
+	MaxAttempts int `json:"max_attempts" yaml:"max_attempts" toml:"max_attempts"`
+	// MinBackoff doubles after every failed attempt up to MaxBackoff
+	MinBackoff Duration `json:"min_backoff" yaml:"min_backoff" toml:"min_backoff"`
+	MaxBackoff Duration `json:"max_backoff" yaml:"max_backoff" toml:"max_backoff"`
+	// Timeout of one delivery request
+	Timeout Duration `json:"timeout" yaml:"timeout" toml:"timeout"`
+	// PollInterval is how often queue is checked for due deliveries
+	PollInterval Duration `json:"poll_interval" yaml:"poll_interval" toml:"poll_interval"`
+	// Retention is how long delivered and dead deliveries are kept, zero keeps them forever
+	Retention Duration `json:"retention" yaml:"retention" toml:"retention"`
+	// ClickQueue is how many clicks wait to be published, redirects drop clicks over it
+	ClickQueue int `json:"click_queue" yaml:"click_queue" toml:"click_queue"`
+}
+
+type OutboxConfig struct {
+	// Sinks receive link events from outbox, every sink has its own offset
+	Sinks []string `json:"sinks" yaml:"sinks" toml:"sinks"`
+	// FilePath is where file sink appends events
+	FilePath string `json:"file_path" yaml:"file_path" toml:"file_path"`
+	// HTTPURL receives events of http sink
+	HTTPURL string `json:"http_url" yaml:"http_url" toml:"http_url"`
+	// Timeout of http sink request
+	Timeout Duration `json:"timeout" yaml:"timeout" toml:"timeout"`
+	// BatchSize events are sent to sink at once
+	BatchSize int `json:"batch_size" yaml:"batch_size" toml:"batch_size"`
+	// PollInterval is how often outbox is checked for new events
+	PollInterval Duration `json:"poll_interval" yaml:"poll_interval" toml:"poll_interval"`
+}
+
+type APIKeyConfig struct {
+	// RateLimit is requests per minute of key created without its own limit
+	RateLimit int `json:"rate_limit" yaml:"rate_limit" toml:"rate_limit"`
+	// TouchInterval is how often last use of key is saved
+	TouchInterval Duration `json:"touch_interval" yaml:"touch_interval" toml:"touch_interval"`
+}
+
+// QuotaConfig limits links of every user, api key and workspace
+type QuotaConfig struct {
+	User   QuotaLimits `json:"user" yaml:"user" toml:"user"`
+	APIKey QuotaLimits `json:"api_key" yaml:"api_key" toml:"api_key"`
+	// Workspace quotas are shared by its members, default workspace has none
+	Workspace QuotaLimits `json:"workspace" yaml:"workspace" toml:"workspace"`
+}
+
+// QuotaLimits are turned off by zero
+type QuotaLimits struct {
+	// LinksPerDay counts links created since midnight UTC
+	LinksPerDay int `json:"links_per_day" yaml:"links_per_day" toml:"links_per_day"`
+	// ActiveLinks counts created links which are not deleted, links of api key belong to its user
+	ActiveLinks int `json:"active_links" yaml:"active_links" toml:"active_links"`
+	// BatchSize limits urls in one batch request
+	BatchSize int `json:"batch_size" yaml:"batch_size" toml:"batch_size"`
+}
+
+// RedirectCodes are allowed redirect status codes
+var RedirectCodes = []int{
+	http.StatusMovedPermanently,
+	http.StatusFound,
+	http.StatusTemporaryRedirect,
+	http.StatusPermanentRedirect,
+}
+
+// Passthrough query precedence values
+const (
+	QueryPrecedenceDestination = "destination"
+	QueryPrecedenceRequest     = "request"
+)
+
+// Short code strategies
+const (
+	CodeStrategyRandom   = "random"   // secure random symbols
+	CodeStrategySequence = "sequence" // counter from storage
+	CodeStrategyHash     = "hash"     // deterministic code of original url
+	CodeStrategyHashids  = "hashids"  // obfuscated counter
+)
+
+// Outbox sinks
+const (
+	OutboxSinkStdout = "stdout"
+	OutboxSinkFile   = "file"
+	OutboxSinkHTTP   = "http"
+)
+
+// TLS options values
+const (
+	TLSVersion12 = "1.2"
+	TLSVersion13 = "1.3"
+
+	CipherPolicyDefault    = "default"    // go defaults
+	CipherPolicyModern     = "modern"     // only ECDHE with AEAD ciphers
+	CipherPolicyCompatible = "compatible" // modern plus ECDHE with CBC for old clients
+
+	ClientAuthNone    = "none"
+	ClientAuthRequest = "request" // verify client cert if it was sent
+	ClientAuthRequire = "require" // reject clients without valid cert
+)
+
 const (
 	defaultAddr        = ":8080"
 	defaultBaseURL     = "http://localhost:8080"
 	defaultLoggerLevel = "info"
 	defaultFilePath    = "/tmp/short-url-db.json"
 	defaultDSN         = ""
-)
 
-func New() *ServiceConfig {
-	cfg := &ServiceConfig{}
+	defaultBatchChunkSize = 1000
+	defaultCopyThreshold  = 1000
 
-	flag.StringVar(&cfg.Addr, "a", defaultAddr, "Addres and port for server")
-	flag.StringVar(&cfg.BaseURL, "b", defaultBaseURL, "BaseURL for short ulrs")
-	flag.StringVar(&cfg.LoggerLevel, "l", defaultLoggerLevel, "Loger level")
-	flag.StringVar(&cfg.FilePath, "f", defaultFilePath, "File path to store URL")
-	flag.StringVar(&cfg.DSN, "d", defaultDSN, "DSN for postgres database")
+	defaultReplicaCheckInterval = 5 * time.Second
+	defaultReplicaMaxLag        = 10 * time.Second
 
-	flag.Parse()
+	defaultCacheSize = 1024
+	defaultCacheTTL  = 10 * time.Minute
 
-	parseEnv(cfg)
+	defaultCookieName = "token"
+	defaultTokenTTL   = 30 * 24 * time.Hour
+
+	defaultMaxBodySize  = 1 << 20
+	defaultMaxBatchSize = 1000
+	defaultMaxURLLength = 2048
+
+	defaultMaxStreamItems = 1_000_000
+
+	defaultPasswordAttempts = 5
+	defaultPasswordLockout  = 15 * time.Minute
+
+	defaultRedirectCode = http.StatusTemporaryRedirect
+
+	defaultTLSMinVersion     = TLSVersion12
+	defaultTLSReloadInterval = time.Minute
+
+	defaultWebhookWorkers      = 4
+	defaultWebhookMaxAttempts  = 8
+	defaultWebhookMinBackoff   = 10 * time.Second
+	defaultWebhookMaxBackoff   = time.Hour
+	defaultWebhookTimeout      = 10 * time.Second
+	defaultWebhookPollInterval = time.Second
+	defaultWebhookRetention    = 7 * 24 * time.Hour
+	defaultWebhookClickQueue   = 1024
+
+	defaultOutboxTimeout      = 10 * time.Second
+	defaultOutboxBatchSize    = 100
+	defaultOutboxPollInterval = time.Second
+
+	defaultAPIKeyRateLimit     = 600
+	defaultAPIKeyTouchInterval = time.Minute
+)
 
-	return cfg
+// New builds config from command line arguments and environment.
+func New() (*ServiceConfig, error) {
+	return Load(os.Args[1:])
 }
 
-func parseEnv(cfg *ServiceConfig) {
-	if addr := os.Getenv("SERVER_ADDRESS"); addr != "" {
-		cfg.Addr = addr
+// Default returns config with default values only.
+func Default() *ServiceConfig {
+	return &ServiceConfig{
+		Addr:        defaultAddr,
+		BaseURL:     defaultBaseURL,
+		LoggerLevel: defaultLoggerLevel,
+		Storage: StorageConfig{
+			FilePath:       defaultFilePath,
+			DSN:            defaultDSN,
+			BatchChunkSize: defaultBatchChunkSize,
+			CopyThreshold:  defaultCopyThreshold,
+
+			ReplicaCheckInterval: Duration(defaultReplicaCheckInterval),
+			ReplicaMaxLag:        Duration(defaultReplicaMaxLag),
+		},
+		Cache: CacheConfig{
+			Size: defaultCacheSize,
+			TTL:  Duration(defaultCacheTTL),
+		},
+		Auth: AuthConfig{
+			CookieName: defaultCookieName,
+			TokenTTL:   Duration(defaultTokenTTL),
+		},
+		Limits: LimitsConfig{
+			MaxBodySize:      defaultMaxBodySize,
+			MaxBatchSize:     defaultMaxBatchSize,
+			MaxURLLength:     defaultMaxURLLength,
+			MaxStreamItems:   defaultMaxStreamItems,
+			PasswordAttempts: defaultPasswordAttempts,
+			PasswordLockout:  Duration(defaultPasswordLockout),
+		},
+		TLS: TLSConfig{
+			MinVersion:     defaultTLSMinVersion,
+			CipherPolicy:   CipherPolicyDefault,
+			ClientAuth:     ClientAuthNone,
+			ReloadInterval: Duration(defaultTLSReloadInterval),
+			HTTP2:          true,
+		},
+		Redirect: RedirectConfig{
+			Code:            defaultRedirectCode,
+			QueryPrecedence: QueryPrecedenceDestination,
+		},
+		ShortCode: CodeConfig{
+			Strategy: CodeStrategyRandom,
+			Alphabet: tokengen.DefaultAlphabet,
+			Length:   tokengen.DefaultLength,
+		},
+		Webhooks: WebhookConfig{
+			Workers:      defaultWebhookWorkers,
+			MaxAttempts:  defaultWebhookMaxAttempts,
+			MinBackoff:   Duration(defaultWebhookMinBackoff),
+			MaxBackoff:   Duration(defaultWebhookMaxBackoff),
+			Timeout:      Duration(defaultWebhookTimeout),
+			PollInterval: Duration(defaultWebhookPollInterval),
+			Retention:    Duration(defaultWebhookRetention),
+			ClickQueue:   defaultWebhookClickQueue,
+		},
+		Outbox: OutboxConfig{
+			Timeout:      Duration(defaultOutboxTimeout),
+			BatchSize:    defaultOutboxBatchSize,
+			PollInterval: Duration(defaultOutboxPollInterval),
+		},
+		APIKeys: APIKeyConfig{
+			RateLimit:     defaultAPIKeyRateLimit,
+			TouchInterval: Duration(defaultAPIKeyTouchInterval),
+		},
 	}
-	if baseURL := os.Getenv("BASE_URL"); baseURL != "" {
-		cfg.BaseURL = baseURL
+}
+
+// Load applies config layers in order of precedence and validates result.
+func Load(args []string) (*ServiceConfig, error) {
+	flagCfg := Default()
+
+	fs := flag.NewFlagSet("url-shortener", flag.ContinueOnError)
+	fs.StringVar(&flagCfg.Addr, "a", defaultAddr, "Addres and port for server")
+	fs.StringVar(&flagCfg.BaseURL, "b", defaultBaseURL, "BaseURL for short ulrs")
+	fs.StringVar(&flagCfg.LoggerLevel, "l", defaultLoggerLevel, "Loger level")
+	fs.StringVar(&flagCfg.Storage.FilePath, "f", defaultFilePath, "File path to store URL")
+	fs.StringVar(&flagCfg.Storage.DSN, "d", defaultDSN, "DSN for postgres database")
+	fs.StringVar(&flagCfg.TrustedSubnet, "t", "", "Trusted subnet (CIDR) for internal endpoints")
+	fs.BoolVar(&flagCfg.TLS.Enabled, "s", false, "Enable HTTPS")
+	fs.StringVar(&flagCfg.ConfigPath, "c", "", "Path to config file (yaml, json or toml)")
+	fs.BoolVar(&flagCfg.PrintConfig, "print-config", false, "Print effective config and exit")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
 	}
-	if logLvl := os.Getenv("LOG_LVL"); logLvl != "" {
-		cfg.LoggerLevel = logLvl
+
+	cfg := Default()
+
+	cfg.ConfigPath = os.Getenv("CONFIG")
+	if flagCfg.ConfigPath != "" {
+		cfg.ConfigPath = flagCfg.ConfigPath
 	}
-	if filePath := os.Getenv("FILE_STORAGE_PATH"); filePath != "" {
-		cfg.FilePath = filePath
+	cfg.PrintConfig = flagCfg.PrintConfig
+
+	if cfg.ConfigPath != "" {
+		if err := parseFile(cfg, cfg.ConfigPath); err != nil {
+			return nil, err
+		}
 	}
-	if dsn := os.Getenv("DATABASE_DSN"); dsn != "" {
-		cfg.DSN = dsn
+
+	var errs []error
+	if err := parseEnv(cfg); err != nil {
+		errs = append(errs, err)
 	}
+
+	// only flags which were set explicitly override other layers
+	fs.Visit(func(f *flag.Flag) {
+		if apply, ok := flagSetters[f.Name]; ok {
+			apply(cfg, flagCfg)
+		}
+	})
+
+	if err := cfg.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) != 0 {
+		return nil, fmt.Errorf("invalid config:\n%w", errors.Join(errs...))
+	}
+
+	return cfg, nil
+}
+
+var flagSetters = map[string]func(dst, src *ServiceConfig){
+	"a": func(dst, src *ServiceConfig) { dst.Addr = src.Addr },
+	"b": func(dst, src *ServiceConfig) { dst.BaseURL = src.BaseURL },
+	"l": func(dst, src *ServiceConfig) { dst.LoggerLevel = src.LoggerLevel },
+	"f": func(dst, src *ServiceConfig) { dst.Storage.FilePath = src.Storage.FilePath },
+	"d": func(dst, src *ServiceConfig) { dst.Storage.DSN = src.Storage.DSN },
+	"t": func(dst, src *ServiceConfig) { dst.TrustedSubnet = src.TrustedSubnet },
+	"s": func(dst, src *ServiceConfig) { dst.TLS.Enabled = src.TLS.Enabled },
 }
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type envSetter func(cfg *ServiceConfig, value string) error
+
+// envVars maps environment variables to config fields
+var envVars = []struct {
+	name string
+	set  envSetter
+}{
+	{"SERVER_ADDRESS", setString(func(c *ServiceConfig) *string { return &c.Addr })},
+	{"BASE_URL", setString(func(c *ServiceConfig) *string { return &c.BaseURL })},
+	{"SHORT_DOMAINS", setStrings(func(c *ServiceConfig) *[]string { return &c.Domains })},
+	{"LOG_LVL", setString(func(c *ServiceConfig) *string { return &c.LoggerLevel })},
+	{"TRUSTED_SUBNET", setString(func(c *ServiceConfig) *string { return &c.TrustedSubnet })},
+	{"TRUSTED_PROXIES", setStrings(func(c *ServiceConfig) *[]string { return &c.TrustedProxies })},
+	{"FILE_STORAGE_PATH", setString(func(c *ServiceConfig) *string { return &c.Storage.FilePath })},
+	{"DATABASE_DSN", setString(func(c *ServiceConfig) *string { return &c.Storage.DSN })},
+	{"BATCH_CHUNK_SIZE", setInt(func(c *ServiceConfig) *int { return &c.Storage.BatchChunkSize })},
+	{"COPY_THRESHOLD", setInt(func(c *ServiceConfig) *int { return &c.Storage.CopyThreshold })},
+	{"DATABASE_REPLICA_DSNS", setStrings(func(c *ServiceConfig) *[]string { return &c.Storage.ReplicaDSNs })},
+	{"REPLICA_CHECK_INTERVAL", setDuration(func(c *ServiceConfig) *Duration { return &c.Storage.ReplicaCheckInterval })},
+	{"REPLICA_MAX_LAG", setDuration(func(c *ServiceConfig) *Duration { return &c.Storage.ReplicaMaxLag })},
+	{"CACHE_SIZE", setInt(func(c *ServiceConfig) *int { return &c.Cache.Size })},
+	{"CACHE_TTL", setDuration(func(c *ServiceConfig) *Duration { return &c.Cache.TTL })},
+	{"AUTH_SECRET", setString(func(c *ServiceConfig) *string { return &c.Auth.Secret })},
+	{"AUTH_COOKIE_NAME", setString(func(c *ServiceConfig) *string { return &c.Auth.CookieName })},
+	{"AUTH_TOKEN_TTL", setDuration(func(c *ServiceConfig) *Duration { return &c.Auth.TokenTTL })},
+	{"AUTH_ADMIN_TOKEN", setString(func(c *ServiceConfig) *string { return &c.Auth.AdminToken })},
+	{"MAX_BODY_SIZE", setInt64(func(c *ServiceConfig) *int64 { return &c.Limits.MaxBodySize })},
+	{"MAX_BATCH_SIZE", setInt(func(c *ServiceConfig) *int { return &c.Limits.MaxBatchSize })},
+	{"MAX_URL_LENGTH", setInt(func(c *ServiceConfig) *int { return &c.Limits.MaxURLLength })},
+	{"MAX_STREAM_ITEMS", setInt(func(c *ServiceConfig) *int { return &c.Limits.MaxStreamItems })},
+	{"PASSWORD_ATTEMPTS", setInt(func(c *ServiceConfig) *int { return &c.Limits.PasswordAttempts })},
+	{"PASSWORD_LOCKOUT", setDuration(func(c *ServiceConfig) *Duration { return &c.Limits.PasswordLockout })},
+	{"ENABLE_HTTPS", setBool(func(c *ServiceConfig) *bool { return &c.TLS.Enabled })},
+	{"TLS_CERT_FILE", setString(func(c *ServiceConfig) *string { return &c.TLS.CertFile })},
+	{"TLS_KEY_FILE", setString(func(c *ServiceConfig) *string { return &c.TLS.KeyFile })},
+	{"TLS_MIN_VERSION", setString(func(c *ServiceConfig) *string { return &c.TLS.MinVersion })},
+	{"TLS_CIPHER_POLICY", setString(func(c *ServiceConfig) *string { return &c.TLS.CipherPolicy })},
+	{"TLS_CLIENT_CA_FILE", setString(func(c *ServiceConfig) *string { return &c.TLS.ClientCAFile })},
+	{"TLS_CLIENT_AUTH", setString(func(c *ServiceConfig) *string { return &c.TLS.ClientAuth })},
+	{"TLS_SELF_SIGNED", setBool(func(c *ServiceConfig) *bool { return &c.TLS.SelfSigned })},
+	{"TLS_RELOAD_INTERVAL", setDuration(func(c *ServiceConfig) *Duration { return &c.TLS.ReloadInterval })},
+	{"TLS_HTTP2", setBool(func(c *ServiceConfig) *bool { return &c.TLS.HTTP2 })},
+	{"TLS_REDIRECT_ADDR", setString(func(c *ServiceConfig) *string { return &c.TLS.RedirectAddr })},
+	{"PREVIEW_UNTRUSTED", setBool(func(c *ServiceConfig) *bool { return &c.Preview.Untrusted })},
+	{"PREVIEW_TRUSTED_USERS", setStrings(func(c *ServiceConfig) *[]string { return &c.Preview.TrustedUsers })},
+	{"REDIRECT_CODE", setInt(func(c *ServiceConfig) *int { return &c.Redirect.Code })},
+	{"REDIRECT_QUERY_PRECEDENCE", setString(func(c *ServiceConfig) *string { return &c.Redirect.QueryPrecedence })},
+	{"SHORT_CODE_STRATEGY", setString(func(c *ServiceConfig) *string { return &c.ShortCode.Strategy })},
+	{"SHORT_CODE_ALPHABET", setString(func(c *ServiceConfig) *string { return &c.ShortCode.Alphabet })},
+	{"SHORT_CODE_LENGTH", setInt(func(c *ServiceConfig) *int { return &c.ShortCode.Length })},
+	{"SHORT_CODE_SALT", setString(func(c *ServiceConfig) *string { return &c.ShortCode.Salt })},
+	{"SHORT_CODE_DENYLIST", setStrings(func(c *ServiceConfig) *[]string { return &c.ShortCode.Denylist })},
+	{"SHORT_CODE_CHECK_SYMBOL", setBool(func(c *ServiceConfig) *bool { return &c.ShortCode.CheckSymbol })},
+	{"SHORT_CODE_CASE_INSENSITIVE", setBool(func(c *ServiceConfig) *bool { return &c.ShortCode.CaseInsensitive })},
+	{"WEBHOOK_WORKERS", setInt(func(c *ServiceConfig) *int { return &c.Webhooks.Workers })},
+	{"WEBHOOK_MAX_ATTEMPTS", setInt(func(c *ServiceConfig) *int { return &c.Webhooks.MaxAttempts })},
+	{"WEBHOOK_MIN_BACKOFF", setDuration(func(c *ServiceConfig) *Duration { return &c.Webhooks.MinBackoff })},
+	{"WEBHOOK_MAX_BACKOFF", setDuration(func(c *ServiceConfig) *Duration { return &c.Webhooks.MaxBackoff })},
+	{"WEBHOOK_TIMEOUT", setDuration(func(c *ServiceConfig) *Duration { return &c.Webhooks.Timeout })},
+	{"WEBHOOK_POLL_INTERVAL", setDuration(func(c *ServiceConfig) *Duration { return &c.Webhooks.PollInterval })},
+	{"WEBHOOK_RETENTION", setDuration(func(c *ServiceConfig) *Duration { return &c.Webhooks.Retention })},
+	{"WEBHOOK_CLICK_QUEUE", setInt(func(c *ServiceConfig) *int { return &c.Webhooks.ClickQueue })},
+	{"OUTBOX_SINKS", setStrings(func(c *ServiceConfig) *[]string { return &c.Outbox.Sinks })},
+	{"OUTBOX_FILE_PATH", setString(func(c *ServiceConfig) *string { return &c.Outbox.FilePath })},
+	{"OUTBOX_HTTP_URL", setString(func(c *ServiceConfig) *string { return &c.Outbox.HTTPURL })},
+	{"OUTBOX_TIMEOUT", setDuration(func(c *ServiceConfig) *Duration { return &c.Outbox.Timeout })},
+	{"OUTBOX_BATCH_SIZE", setInt(func(c *ServiceConfig) *int { return &c.Outbox.BatchSize })},
+	{"OUTBOX_POLL_INTERVAL", setDuration(func(c *ServiceConfig) *Duration { return &c.Outbox.PollInterval })},
+	{"API_KEY_RATE_LIMIT", setInt(func(c *ServiceConfig) *int { return &c.APIKeys.RateLimit })},
+	{"API_KEY_TOUCH_INTERVAL", setDuration(func(c *ServiceConfig) *Duration { return &c.APIKeys.TouchInterval })},
+	{"QUOTA_USER_LINKS_PER_DAY", setInt(func(c *ServiceConfig) *int { return &c.Quotas.User.LinksPerDay })},
+	{"QUOTA_USER_ACTIVE_LINKS", setInt(func(c *ServiceConfig) *int { return &c.Quotas.User.ActiveLinks })},
+	{"QUOTA_USER_BATCH_SIZE", setInt(func(c *ServiceConfig) *int { return &c.Quotas.User.BatchSize })},
+	{"QUOTA_API_KEY_LINKS_PER_DAY", setInt(func(c *ServiceConfig) *int { return &c.Quotas.APIKey.LinksPerDay })},
+	{"QUOTA_API_KEY_BATCH_SIZE", setInt(func(c *ServiceConfig) *int { return &c.Quotas.APIKey.BatchSize })},
+	{"QUOTA_WORKSPACE_LINKS_PER_DAY", setInt(func(c *ServiceConfig) *int { return &c.Quotas.Workspace.LinksPerDay })},
+	{"QUOTA_WORKSPACE_ACTIVE_LINKS", setInt(func(c *ServiceConfig) *int { return &c.Quotas.Workspace.ActiveLinks })},
+	{"QUOTA_WORKSPACE_BATCH_SIZE", setInt(func(c *ServiceConfig) *int { return &c.Quotas.Workspace.BatchSize })},
+}
+
+func parseEnv(cfg *ServiceConfig) error {
+	var errs []error
+
+	for _, v := range envVars {
+		value := os.Getenv(v.name)
+		if value == "" {
+			continue
+		}
+		if err := v.set(cfg, value); err != nil {
+			errs = append(errs, fmt.Errorf("env %s: %w", v.name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func setString(field func(*ServiceConfig) *string) envSetter {
+	return func(cfg *ServiceConfig, value string) error {
+		*field(cfg) = value
+		return nil
+	}
+}
+
+// setStrings reads comma separated list
+func setStrings(field func(*ServiceConfig) *[]string) envSetter {
+	return func(cfg *ServiceConfig, value string) error {
+		var list []string
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				list = append(list, v)
+			}
+		}
+		*field(cfg) = list
+		return nil
+	}
+}
+
+func setInt(field func(*ServiceConfig) *int) envSetter {
+	return func(cfg *ServiceConfig, value string) error {
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("not an integer: %q", value)
+		}
+		*field(cfg) = v
+		return nil
+	}
+}
+
+func setInt64(field func(*ServiceConfig) *int64) envSetter {
+	return func(cfg *ServiceConfig, value string) error {
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("not an integer: %q", value)
+		}
+		*field(cfg) = v
+		return nil
+	}
+}
+
+func setBool(field func(*ServiceConfig) *bool) envSetter {
+	return func(cfg *ServiceConfig, value string) error {
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("not a boolean: %q", value)
+		}
+		*field(cfg) = v
+		return nil
+	}
+}
+
+func setDuration(field func(*ServiceConfig) *Duration) envSetter {
+	return func(cfg *ServiceConfig, value string) error {
+		v, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("not a duration: %q", value)
+		}
+		*field(cfg) = Duration(v)
+		return nil
+	}
+}
@@ -0,0 +1,369 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/MatiXxD/url-shortener/pkg/tokengen"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	minSecretLength = 16
+
+	minAlphabetLength = 16
+)
+
+// MaxCodeLength limits length of short codes
+const MaxCodeLength = 64
+
+// Validate checks all fields and reports every problem at once
+func (cfg *ServiceConfig) Validate() error {
+	var errs []error
+	check := func(field string, err error) {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", field, err))
+		}
+	}
+
+	check("addr", validateAddr(cfg.Addr))
+	check("base_url", ValidateBaseURL(cfg.BaseURL))
+	for i, domain := range cfg.Domains {
+		check(fmt.Sprintf("domains[%d]", i), ValidateBaseURL(domain))
+	}
+	check("log_level", validateLoggerLevel(cfg.LoggerLevel))
+
+	if cfg.TrustedSubnet != "" {
+		if _, _, err := net.ParseCIDR(cfg.TrustedSubnet); err != nil {
+			check("trusted_subnet", fmt.Errorf("expected CIDR, got %q", cfg.TrustedSubnet))
+		}
+	}
+	for i, proxy := range cfg.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil {
+			check(fmt.Sprintf("trusted_proxies[%d]", i), fmt.Errorf("expected CIDR, got %q", proxy))
+		}
+	}
+
+	if cfg.Storage.DSN != "" {
+		if _, err := pgconn.ParseConfig(cfg.Storage.DSN); err != nil {
+			check("storage.dsn", errors.New("malformed dsn"))
+		}
+	}
+
+	for i, dsn := range cfg.Storage.ReplicaDSNs {
+		if _, err := pgconn.ParseConfig(dsn); err != nil {
+			check(fmt.Sprintf("storage.replica_dsns[%d]", i), errors.New("malformed dsn"))
+		}
+	}
+	if len(cfg.Storage.ReplicaDSNs) > 0 && cfg.Storage.DSN == "" {
+		check("storage.replica_dsns", errors.New("requires storage.dsn"))
+	}
+	if cfg.Storage.ReplicaCheckInterval <= 0 {
+		check("storage.replica_check_interval", errors.New("must be positive"))
+	}
+	if cfg.Storage.ReplicaMaxLag < 0 {
+		check("storage.replica_max_lag", errors.New("must not be negative"))
+	}
+
+	if cfg.Storage.BatchChunkSize < 1 {
+		check("storage.batch_chunk_size", errors.New("must be positive"))
+	}
+	if cfg.Storage.CopyThreshold < 0 {
+		check("storage.copy_threshold", errors.New("must not be negative"))
+	}
+
+	if cfg.Cache.Size < 0 {
+		check("cache.size", errors.New("must not be negative"))
+	}
+	if cfg.Cache.TTL < 0 {
+		check("cache.ttl", errors.New("must not be negative"))
+	}
+
+	if cfg.Auth.Secret != "" && len(cfg.Auth.Secret) < minSecretLength {
+		check("auth.secret", fmt.Errorf("must be at least %d characters", minSecretLength))
+	}
+	if cfg.Auth.CookieName == "" {
+		check("auth.cookie_name", errors.New("must not be empty"))
+	}
+	if cfg.Auth.TokenTTL <= 0 {
+		check("auth.token_ttl", errors.New("must be positive"))
+	}
+
+	if cfg.Limits.MaxBodySize < 0 {
+		check("limits.max_body_size", errors.New("must not be negative"))
+	}
+	if cfg.Limits.MaxBatchSize < 0 {
+		check("limits.max_batch_size", errors.New("must not be negative"))
+	}
+	if cfg.Limits.MaxURLLength < 0 {
+		check("limits.max_url_length", errors.New("must not be negative"))
+	}
+	if cfg.Limits.MaxStreamItems < 0 {
+		check("limits.max_stream_items", errors.New("must not be negative"))
+	}
+
+	if cfg.Limits.PasswordAttempts < 0 {
+		check("limits.password_attempts", errors.New("must not be negative"))
+	}
+	if cfg.Limits.PasswordLockout < 0 {
+		check("limits.password_lockout", errors.New("must not be negative"))
+	}
+
+	if !slices.Contains(RedirectCodes, cfg.Redirect.Code) {
+		check("redirect.code", fmt.Errorf("expected one of %v, got %d", RedirectCodes, cfg.Redirect.Code))
+	}
+	switch cfg.Redirect.QueryPrecedence {
+	case QueryPrecedenceDestination, QueryPrecedenceRequest:
+	default:
+		check("redirect.query_precedence", fmt.Errorf("unknown value %q", cfg.Redirect.QueryPrecedence))
+	}
+
+	for _, err := range validateShortCode(&cfg.ShortCode) {
+		check("short_code", err)
+	}
+
+	for _, err := range validateWebhooks(&cfg.Webhooks) {
+		check("webhooks", err)
+	}
+
+	for _, err := range validateOutbox(&cfg.Outbox) {
+		check("outbox", err)
+	}
+
+	if cfg.APIKeys.RateLimit < 1 {
+		check("api_keys.rate_limit", errors.New("must be positive"))
+	}
+	if cfg.APIKeys.TouchInterval < 0 {
+		check("api_keys.touch_interval", errors.New("must not be negative"))
+	}
+
+	for _, err := range validateQuotaLimits(&cfg.Quotas.User) {
+		check("quotas.user", err)
+	}
+	for _, err := range validateQuotaLimits(&cfg.Quotas.APIKey) {
+		check("quotas.api_key", err)
+	}
+	for _, err := range validateQuotaLimits(&cfg.Quotas.Workspace) {
+		check("quotas.workspace", err)
+	}
+	// links are not linked to api key, so its active links can't be counted
+	if cfg.Quotas.APIKey.ActiveLinks != 0 {
+		check("quotas.api_key.active_links", errors.New("is not supported, links of key are counted by its user"))
+	}
+
+	if cfg.TLS.Enabled {
+		for _, err := range validateTLS(&cfg.TLS) {
+			check("tls", err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateShortCode(cfg *CodeConfig) []error {
+	var errs []error
+
+	switch cfg.Strategy {
+	case CodeStrategyRandom, CodeStrategySequence, CodeStrategyHash, CodeStrategyHashids:
+	default:
+		errs = append(errs, fmt.Errorf("unknown strategy %q", cfg.Strategy))
+	}
+
+	alphabet := tokengen.Alphabet(cfg.Alphabet)
+	if len(alphabet) < minAlphabetLength {
+		errs = append(errs, fmt.Errorf("alphabet must be preset or have at least %d symbols", minAlphabetLength))
+	}
+	seen := make(map[rune]bool, len(alphabet))
+	for _, r := range alphabet {
+		// '+' is reserved for preview links, other symbols must not need escaping
+		if !isUnreserved(r) || r == '+' {
+			errs = append(errs, fmt.Errorf("alphabet symbol %q is not url safe", r))
+			continue
+		}
+		if seen[r] {
+			errs = append(errs, fmt.Errorf("alphabet symbol %q is repeated", r))
+		}
+		seen[r] = true
+	}
+	if cfg.CaseInsensitive && strings.ToLower(alphabet) != alphabet {
+		errs = append(errs, errors.New("case insensitive codes need lower case alphabet"))
+	}
+
+	if cfg.Length < 1 || cfg.Length > MaxCodeLength {
+		errs = append(errs, fmt.Errorf("length must be between 1 and %d", MaxCodeLength))
+	}
+
+	return errs
+}
+
+func validateWebhooks(cfg *WebhookConfig) []error {
+	var errs []error
+
+	if cfg.Workers < 1 {
+		errs = append(errs, errors.New("workers must be positive"))
+	}
+	if cfg.MaxAttempts < 1 {
+		errs = append(errs, errors.New("max_attempts must be positive"))
+	}
+	if cfg.MinBackoff <= 0 {
+		errs = append(errs, errors.New("min_backoff must be positive"))
+	}
+	if cfg.MaxBackoff < cfg.MinBackoff {
+		errs = append(errs, errors.New("max_backoff must not be less than min_backoff"))
+	}
+	if cfg.Timeout <= 0 {
+		errs = append(errs, errors.New("timeout must be positive"))
+	}
+	if cfg.PollInterval <= 0 {
+		errs = append(errs, errors.New("poll_interval must be positive"))
+	}
+	if cfg.Retention < 0 {
+		errs = append(errs, errors.New("retention must not be negative"))
+	}
+	if cfg.ClickQueue < 1 {
+		errs = append(errs, errors.New("click_queue must be positive"))
+	}
+
+	return errs
+}
+
+func isUnreserved(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+		r == '-' || r == '.' || r == '_' || r == '~'
+}
+
+func validateTLS(cfg *TLSConfig) []error {
+	var errs []error
+
+	if !cfg.SelfSigned && (cfg.CertFile == "" || cfg.KeyFile == "") {
+		errs = append(errs, errors.New("cert_file and key_file are required unless self_signed is set"))
+	}
+	if cfg.SelfSigned && (cfg.CertFile != "" || cfg.KeyFile != "") {
+		errs = append(errs, errors.New("self_signed can't be used with cert_file and key_file"))
+	}
+
+	switch cfg.MinVersion {
+	case TLSVersion12, TLSVersion13:
+	default:
+		errs = append(errs, fmt.Errorf("unsupported min_version %q", cfg.MinVersion))
+	}
+
+	switch cfg.CipherPolicy {
+	case CipherPolicyDefault, CipherPolicyModern, CipherPolicyCompatible:
+	default:
+		errs = append(errs, fmt.Errorf("unknown cipher_policy %q", cfg.CipherPolicy))
+	}
+
+	switch cfg.ClientAuth {
+	case ClientAuthNone:
+	case ClientAuthRequest, ClientAuthRequire:
+		if cfg.ClientCAFile == "" {
+			errs = append(errs, fmt.Errorf("client_ca_file is required for client_auth %q", cfg.ClientAuth))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown client_auth %q", cfg.ClientAuth))
+	}
+
+	if cfg.ReloadInterval < 0 {
+		errs = append(errs, errors.New("reload_interval must not be negative"))
+	}
+
+	if cfg.RedirectAddr != "" {
+		if err := validateAddr(cfg.RedirectAddr); err != nil {
+			errs = append(errs, fmt.Errorf("redirect_addr: %w", err))
+		}
+	}
+
+	return errs
+}
+
+func validateAddr(addr string) error {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return fmt.Errorf("expected host:port, got %q", addr)
+	}
+	return nil
+}
+
+// ValidateBaseURL checks url short urls are built from
+func ValidateBaseURL(baseURL string) error {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("malformed url %q", baseURL)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("scheme must be http or https, got %q", baseURL)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("host is missing in %q", baseURL)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("query and fragment are not allowed in %q", baseURL)
+	}
+	if len(u.Path) != 0 && u.Path[len(u.Path)-1] == '/' {
+		return fmt.Errorf("trailing slash is not allowed in %q", baseURL)
+	}
+	return nil
+}
+
+func validateLoggerLevel(lvl string) error {
+	if _, err := zapcore.ParseLevel(lvl); err != nil {
+		return fmt.Errorf("unknown level %q", lvl)
+	}
+	return nil
+}
+
+func validateQuotaLimits(cfg *QuotaLimits) []error {
+	var errs []error
+	if cfg.LinksPerDay < 0 {
+		errs = append(errs, errors.New("links_per_day must not be negative"))
+	}
+	if cfg.ActiveLinks < 0 {
+		errs = append(errs, errors.New("active_links must not be negative"))
+	}
+	if cfg.BatchSize < 0 {
+		errs = append(errs, errors.New("batch_size must not be negative"))
+	}
+	return errs
+}
+
+func validateOutbox(cfg *OutboxConfig) []error {
+	var errs []error
+
+	seen := make(map[string]bool, len(cfg.Sinks))
+	for _, sink := range cfg.Sinks {
+		switch sink {
+		case OutboxSinkStdout, OutboxSinkFile, OutboxSinkHTTP:
+		default:
+			errs = append(errs, fmt.Errorf("unknown sink %q", sink))
+		}
+		if seen[sink] {
+			errs = append(errs, fmt.Errorf("sink %q is repeated", sink))
+		}
+		seen[sink] = true
+	}
+	if seen[OutboxSinkFile] && cfg.FilePath == "" {
+		errs = append(errs, errors.New("file_path is required by file sink"))
+	}
+	if seen[OutboxSinkHTTP] {
+		if u, err := url.Parse(cfg.HTTPURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, errors.New("http_url must be absolute http or https url"))
+		}
+	}
+
+	if cfg.Timeout <= 0 {
+		errs = append(errs, errors.New("timeout must be positive"))
+	}
+	if cfg.BatchSize < 1 {
+		errs = append(errs, errors.New("batch_size must be positive"))
+	}
+	if cfg.PollInterval <= 0 {
+		errs = append(errs, errors.New("poll_interval must be positive"))
+	}
+
+	return errs
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/MatiXxD/url-shortener/internal/apikey"
+	"github.com/MatiXxD/url-shortener/internal/apikey/usecase"
+	mw "github.com/MatiXxD/url-shortener/internal/middleware"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/go-chi/chi/v5"
+	"github.com/mailru/easyjson"
+)
+
+type APIKeyHandler struct {
+	apiKeyUsecase apikey.Usecase
+	logger        *logger.Logger
+}
+
+func NewAPIKeyHandler(u apikey.Usecase, l *logger.Logger) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyUsecase: u,
+		logger:        l,
+	}
+}
+
+// Authenticate makes request with bearer api key act as user of key, it goes before cookie auth.
+// Requests without key are passed as is.
+func (kh *APIKeyHandler) Authenticate(next http.Handler) http.Handler {
+	af := func(w http.ResponseWriter, r *http.Request) {
+		token, ok := mw.BearerToken(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		logger := kh.logger
+		reqID := mw.GetRequestID(r.Context())
+		if reqID != "" {
+			logger = kh.logger.With("request_id", reqID)
+		}
+
+		k, err := kh.apiKeyUsecase.Authenticate(r.Context(), token)
+		var retryErr *usecase.RetryError
+		switch {
+		case errors.As(err, &retryErr):
+			logger.Errorf("api key rate limit: %v", err)
+			w.Header().Set("Retry-After", strconv.Itoa(int(retryErr.RetryAfter.Seconds())+1))
+			http.Error(w, "Too many requests", http.StatusTooManyRequests)
+			return
+		case errors.Is(err, usecase.ErrUnauthorized):
+			logger.Errorf("can't authenticate: %v", err)
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Invalid api key", http.StatusUnauthorized)
+			return
+		case err != nil:
+			logger.Errorf("can't authenticate: %v", err)
+			http.Error(w, "Can't check api key", http.StatusInternalServerError)
+			return
+		}
+
+		ctx := mw.WithAPIKey(mw.WithUserID(r.Context(), k.UserID), k)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+	return http.HandlerFunc(af)
+}
+
+func (kh *APIKeyHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	logger := kh.logger
+	reqID := mw.GetRequestID(r.Context())
+	if reqID != "" {
+		logger = kh.logger.With("request_id", reqID)
+	}
+
+	keys, err := kh.apiKeyUsecase.ListAPIKeys(r.Context())
+	if err != nil {
+		logger.Errorf("can't list api keys: %v", err)
+		http.Error(w, "Can't list api keys", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := easyjson.MarshalToWriter(models.APIKeyList(keys), w); err != nil {
+		logger.Error("can't marshal response body")
+		http.Error(w, "Can't marshal response body", http.StatusInternalServerError)
+		return
+	}
+}
+
+// AddAPIKey responds with created key, its secret is not shown again
+func (kh *APIKeyHandler) AddAPIKey(w http.ResponseWriter, r *http.Request) {
+	logger := kh.logger
+	reqID := mw.GetRequestID(r.Context())
+	if reqID != "" {
+		logger = kh.logger.With("request_id", reqID)
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if !strings.Contains(contentType, "application/json") {
+		logger.Error("request contains wrong content type")
+		http.Error(w, "Wrong content type", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	var req models.AddAPIKeyReqBody
+	err := easyjson.UnmarshalFromReader(r.Body, &req)
+	if isTooLarge(err) {
+		logger.Error("request body is too large")
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		logger.Errorf("can't unmarshal request body: %v", err)
+		http.Error(w, "Can't read body", http.StatusBadRequest)
+		return
+	}
+
+	k, err := kh.apiKeyUsecase.AddAPIKey(r.Context(), &req)
+	if errors.Is(err, usecase.ErrInvalidAPIKey) {
+		logger.Errorf("invalid api key: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		logger.Errorf("can't add api key: %v", err)
+		http.Error(w, "Can't add api key", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if _, err := easyjson.MarshalToWriter(k, w); err != nil {
+		logger.Error("can't marshal response body")
+	}
+}
+
+func (kh *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	logger := kh.logger
+	reqID := mw.GetRequestID(r.Context())
+	if reqID != "" {
+		logger = kh.logger.With("request_id", reqID)
+	}
+
+	id := chi.URLParam(r, "id")
+	err := kh.apiKeyUsecase.RevokeAPIKey(r.Context(), id)
+	if errors.Is(err, usecase.ErrAPIKeyNotFound) {
+		logger.Errorf("can't find api key %s", id)
+		http.Error(w, "Can't find api key", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		logger.Errorf("can't revoke api key %s: %v", id, err)
+		http.Error(w, "Can't revoke api key", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func isTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
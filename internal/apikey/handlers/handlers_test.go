@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/config"
+	"github.com/MatiXxD/url-shortener/internal/apikey/repository"
+	"github.com/MatiXxD/url-shortener/internal/apikey/usecase"
+	mw "github.com/MatiXxD/url-shortener/internal/middleware"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func doRequest(t *testing.T, ts *httptest.Server, method, path, token, body string) (*http.Response, string) {
+	req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+	require.NoError(t, err)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := ts.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp, string(data)
+}
+
+func TestAPIKeyHandler(t *testing.T) {
+	zl, err := zap.NewDevelopment()
+	require.NoError(t, err)
+	l := &logger.Logger{SugaredLogger: zl.Sugar()}
+
+	r, err := repository.NewFileRepository("", l)
+	require.NoError(t, err)
+	cfg := config.Default()
+	cfg.APIKeys.RateLimit = 5
+	h := NewAPIKeyHandler(usecase.NewAPIKeyUsecase(r, cfg, l), l)
+
+	mux := chi.NewRouter()
+	mux.Use(h.Authenticate)
+	mux.Use(func(next http.Handler) http.Handler {
+		return mw.AuthMiddleware([]byte("secret"), "token", time.Hour, next)
+	})
+	mux.Get("/api/keys", h.ListAPIKeys)
+	mux.Post("/api/keys", h.AddAPIKey)
+	mux.Delete("/api/keys/{id}", h.RevokeAPIKey)
+	mux.With(func(next http.Handler) http.Handler {
+		return mw.ScopeMiddleware(models.ScopeLinksRead, next)
+	}).Get("/api/urls", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, mw.GetUserID(r.Context()))
+	})
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	resp, body := doRequest(t, ts, http.MethodPost, "/api/keys", "", `{"name":"reader","user_id":"owner","scopes":["links:read"],"rate_limit":2}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode, body)
+	var reader models.APIKey
+	require.NoError(t, json.Unmarshal([]byte(body), &reader))
+	require.NotEmpty(t, reader.Key)
+	require.NotContains(t, body, `"hash"`)
+
+	resp, body = doRequest(t, ts, http.MethodPost, "/api/keys", "", `{"name":"writer","scopes":["links:write"]}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode, body)
+	var writer models.APIKey
+	require.NoError(t, json.Unmarshal([]byte(body), &writer))
+
+	resp, body = doRequest(t, ts, http.MethodGet, "/api/urls", reader.Key, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "owner", body)
+	require.Empty(t, resp.Cookies())
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		token    string
+		body     string
+		wantCode int
+	}{
+		{
+			name:     "key without scope",
+			method:   http.MethodGet,
+			path:     "/api/urls",
+			token:    writer.Key,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "unknown key",
+			method:   http.MethodGet,
+			path:     "/api/urls",
+			token:    "usk_0_0",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "request with cookie is not checked",
+			method:   http.MethodGet,
+			path:     "/api/urls",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "invalid key request",
+			method:   http.MethodPost,
+			path:     "/api/keys",
+			body:     `{"name":"job","scopes":["admin"]}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "revoke unknown key",
+			method:   http.MethodDelete,
+			path:     "/api/keys/missing",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "rate limit",
+			method:   http.MethodGet,
+			path:     "/api/urls",
+			token:    reader.Key,
+			wantCode: http.StatusTooManyRequests,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantCode == http.StatusTooManyRequests {
+				resp, _ := doRequest(t, ts, tt.method, tt.path, tt.token, tt.body)
+				require.Equal(t, http.StatusOK, resp.StatusCode)
+			}
+
+			resp, body := doRequest(t, ts, tt.method, tt.path, tt.token, tt.body)
+			require.Equal(t, tt.wantCode, resp.StatusCode, body)
+			if tt.wantCode == http.StatusTooManyRequests {
+				require.NotEmpty(t, resp.Header.Get("Retry-After"))
+			}
+		})
+	}
+
+	resp, _ = doRequest(t, ts, http.MethodDelete, "/api/keys/"+writer.ID, "", "")
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	resp, _ = doRequest(t, ts, http.MethodGet, "/api/urls", writer.Key, "")
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp, body = doRequest(t, ts, http.MethodGet, "/api/keys", "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var keys []*models.APIKey
+	require.NoError(t, json.Unmarshal([]byte(body), &keys))
+	require.Len(t, keys, 2)
+	require.NotNil(t, keys[1].RevokedAt)
+	require.NotNil(t, keys[0].LastUsedAt)
+	require.NotContains(t, body, `"key"`)
+}
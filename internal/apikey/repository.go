@@ -0,0 +1,22 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+)
+
+var ErrNotFound = errors.New("api key was not found")
+
+// Repository keeps api keys with hashes of their secrets, revoked keys are kept for audit
+type Repository interface {
+	AddAPIKey(context.Context, *models.APIKey) error
+	GetAPIKey(ctx context.Context, id string) (*models.APIKey, error)
+	ListAPIKeys(context.Context) ([]*models.APIKey, error)
+	// RevokeAPIKey sets revocation time of key unless it is already revoked
+	RevokeAPIKey(ctx context.Context, id string, at time.Time) error
+	// TouchAPIKey saves last use of key, earlier time doesn't replace later one
+	TouchAPIKey(ctx context.Context, id string, at time.Time) error
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/apikey"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+)
+
+// apiKeysSuffix is appended to storage filename for api keys
+const apiKeysSuffix = ".apikeys"
+
+// FileRepository keeps api keys in memory, they are rewritten as a whole next to storage file
+// unless filename is empty
+type FileRepository struct {
+	filename string
+	keys     map[string]*models.APIKey
+	logger   *logger.Logger
+	mu       sync.RWMutex
+}
+
+func NewFileRepository(filename string, logger *logger.Logger) (*FileRepository, error) {
+	fr := &FileRepository{
+		filename: filename,
+		keys:     make(map[string]*models.APIKey),
+		logger:   logger,
+	}
+	if filename == "" {
+		return fr, nil
+	}
+
+	if err := fr.init(); err != nil {
+		logger.Errorf("failed to init api keys %v: %v", filename, err)
+		return nil, fmt.Errorf("failed to init api keys: %w", err)
+	}
+
+	return fr, nil
+}
+
+func (fr *FileRepository) AddAPIKey(ctx context.Context, k *models.APIKey) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	stored := *k
+	fr.keys[k.ID] = &stored
+
+	if err := fr.save(); err != nil {
+		delete(fr.keys, k.ID)
+		fr.logger.Errorf("failed to save api keys: %v", err)
+		return fmt.Errorf("failed to save api keys: %w", err)
+	}
+
+	return nil
+}
+
+func (fr *FileRepository) GetAPIKey(ctx context.Context, id string) (*models.APIKey, error) {
+	fr.mu.RLock()
+	defer fr.mu.RUnlock()
+
+	k, ok := fr.keys[id]
+	if !ok {
+		return nil, apikey.ErrNotFound
+	}
+	copied := *k
+	return &copied, nil
+}
+
+func (fr *FileRepository) ListAPIKeys(ctx context.Context) ([]*models.APIKey, error) {
+	fr.mu.RLock()
+	defer fr.mu.RUnlock()
+
+	return listKeys(fr.keys), nil
+}
+
+func (fr *FileRepository) RevokeAPIKey(ctx context.Context, id string, at time.Time) error {
+	return fr.update(id, func(k *models.APIKey) {
+		if k.RevokedAt == nil {
+			k.RevokedAt = &at
+		}
+	})
+}
+
+func (fr *FileRepository) TouchAPIKey(ctx context.Context, id string, at time.Time) error {
+	return fr.update(id, func(k *models.APIKey) {
+		if k.LastUsedAt == nil || k.LastUsedAt.Before(at) {
+			k.LastUsedAt = &at
+		}
+	})
+}
+
+// update changes copy of key and keeps it only if keys are saved
+func (fr *FileRepository) update(id string, change func(*models.APIKey)) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	k, ok := fr.keys[id]
+	if !ok {
+		return apikey.ErrNotFound
+	}
+	updated := *k
+	change(&updated)
+	fr.keys[id] = &updated
+
+	if err := fr.save(); err != nil {
+		fr.keys[id] = k
+		fr.logger.Errorf("failed to save api keys: %v", err)
+		return fmt.Errorf("failed to save api keys: %w", err)
+	}
+
+	return nil
+}
+
+func (fr *FileRepository) init() error {
+	data, err := os.ReadFile(fr.filename + apiKeysSuffix)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var keys []*models.APIKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return fmt.Errorf("malformed api keys: %w", err)
+	}
+	for _, k := range keys {
+		fr.keys[k.ID] = k
+	}
+
+	return nil
+}
+
+func (fr *FileRepository) save() error {
+	if fr.filename == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(listKeys(fr.keys))
+	if err != nil {
+		return err
+	}
+
+	name := fr.filename + apiKeysSuffix
+	tmp := name + ".tmp"
+	// file has hashes of secrets, it is readable by owner only
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, name)
+}
+
+// listKeys returns copies of keys, the oldest first
+func listKeys(keys map[string]*models.APIKey) []*models.APIKey {
+	res := make([]*models.APIKey, 0, len(keys))
+	for _, k := range keys {
+		copied := *k
+		res = append(res, &copied)
+	}
+	slices.SortFunc(res, func(a, b *models.APIKey) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return res
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/apikey"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileRepository_APIKeys(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "db.json")
+	now := time.Now().UTC().Truncate(time.Second)
+
+	fr, err := NewFileRepository(filename, l)
+	require.NoError(t, err)
+
+	for i, id := range []string{"b", "a"} {
+		err := fr.AddAPIKey(ctx, &models.APIKey{
+			ID:        id,
+			Name:      "job " + id,
+			UserID:    "u-" + id,
+			Scopes:    []string{models.ScopeLinksWrite},
+			RateLimit: 60,
+			Hash:      "hash-" + id,
+			CreatedAt: now.Add(time.Duration(i) * time.Second),
+		})
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, fr.TouchAPIKey(ctx, "a", now.Add(time.Minute)))
+	// earlier use doesn't replace later one
+	require.NoError(t, fr.TouchAPIKey(ctx, "a", now))
+	require.NoError(t, fr.RevokeAPIKey(ctx, "b", now.Add(time.Hour)))
+	require.NoError(t, fr.RevokeAPIKey(ctx, "b", now.Add(2*time.Hour)))
+	require.ErrorIs(t, fr.RevokeAPIKey(ctx, "missing", now), apikey.ErrNotFound)
+	require.ErrorIs(t, fr.TouchAPIKey(ctx, "missing", now), apikey.ErrNotFound)
+
+	info, err := os.Stat(filename + apiKeysSuffix)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	reopened, err := NewFileRepository(filename, l)
+	require.NoError(t, err)
+
+	keys, err := reopened.ListAPIKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	require.Equal(t, "b", keys[0].ID)
+	require.Equal(t, now.Add(time.Hour), *keys[0].RevokedAt)
+	require.Nil(t, keys[0].LastUsedAt)
+
+	k, err := reopened.GetAPIKey(ctx, "a")
+	require.NoError(t, err)
+	require.Equal(t, "hash-a", k.Hash)
+	require.Equal(t, now.Add(time.Minute), *k.LastUsedAt)
+	require.Nil(t, k.RevokedAt)
+
+	_, err = reopened.GetAPIKey(ctx, "missing")
+	require.ErrorIs(t, err, apikey.ErrNotFound)
+}
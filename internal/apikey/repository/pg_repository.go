@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/apikey"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/MatiXxD/url-shortener/pkg/postgres"
+	"github.com/jackc/pgx/v5"
+)
+
+// keyColumns are selected by scanKeys
+const keyColumns = `id, name, user_id, scopes, rate_limit, hash, created_at, last_used_at, revoked_at`
+
+// PostgresRepository reads keys from primary, so revoked key stops working right away
+type PostgresRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewPostgresRepository(db *postgres.DB, logger *logger.Logger) *PostgresRepository {
+	return &PostgresRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (pr *PostgresRepository) AddAPIKey(ctx context.Context, k *models.APIKey) error {
+	_, err := pr.db.Pool.Exec(ctx, `
+		INSERT INTO api_key (id, name, user_id, scopes, rate_limit, hash, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, k.ID, k.Name, k.UserID, k.Scopes, k.RateLimit, k.Hash, k.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to add api key: %w", err)
+	}
+
+	return nil
+}
+
+func (pr *PostgresRepository) GetAPIKey(ctx context.Context, id string) (*models.APIKey, error) {
+	rows, err := pr.db.Pool.Query(ctx, `SELECT `+keyColumns+` FROM api_key WHERE id = $1`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	keys, err := scanKeys(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, apikey.ErrNotFound
+	}
+
+	return keys[0], nil
+}
+
+func (pr *PostgresRepository) ListAPIKeys(ctx context.Context) ([]*models.APIKey, error) {
+	rows, err := pr.db.Pool.Query(ctx, `SELECT `+keyColumns+` FROM api_key ORDER BY created_at, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+
+	return scanKeys(rows)
+}
+
+func (pr *PostgresRepository) RevokeAPIKey(ctx context.Context, id string, at time.Time) error {
+	var revokedAt time.Time
+	err := pr.db.Pool.QueryRow(ctx, `
+		UPDATE api_key SET revoked_at = COALESCE(revoked_at, $2) WHERE id = $1 RETURNING revoked_at
+	`, id, at).Scan(&revokedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return apikey.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	return nil
+}
+
+func (pr *PostgresRepository) TouchAPIKey(ctx context.Context, id string, at time.Time) error {
+	tag, err := pr.db.Pool.Exec(ctx, `UPDATE api_key SET last_used_at = GREATEST(last_used_at, $2) WHERE id = $1`, id, at)
+	if err != nil {
+		return fmt.Errorf("failed to touch api key: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return apikey.ErrNotFound
+	}
+
+	return nil
+}
+
+func scanKeys(rows pgx.Rows) ([]*models.APIKey, error) {
+	defer rows.Close()
+
+	res := make([]*models.APIKey, 0)
+	for rows.Next() {
+		var k models.APIKey
+		err := rows.Scan(&k.ID, &k.Name, &k.UserID, &k.Scopes, &k.RateLimit, &k.Hash, &k.CreatedAt,
+			&k.LastUsedAt, &k.RevokedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		res = append(res, &k)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read api keys: %w", err)
+	}
+
+	return res, nil
+}
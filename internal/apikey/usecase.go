@@ -0,0 +1,17 @@
+package apikey
+
+import (
+	"context"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+)
+
+type Usecase interface {
+	// AddAPIKey creates key, the returned key is the only place its secret is shown
+	AddAPIKey(context.Context, *models.AddAPIKeyReqBody) (*models.APIKey, error)
+	// ListAPIKeys returns keys without secrets and hashes
+	ListAPIKeys(context.Context) ([]*models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string) error
+	// Authenticate returns active key of bearer token and counts request against its rate limit
+	Authenticate(ctx context.Context, token string) (*models.APIKey, error)
+}
@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrInvalidAPIKey  = errors.New("invalid api key")
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	ErrUnauthorized   = errors.New("api key is not valid")
+	ErrRateLimited    = errors.New("rate limit of api key is exceeded")
+)
+
+// RetryError tells client when the request may be retried
+type RetryError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("%v, retry after %s", e.Err, e.RetryAfter)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
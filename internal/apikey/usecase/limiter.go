@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter is token bucket per key, bucket holds requests of one minute and refills evenly
+type rateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	at     time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// allow takes token of key, when there is none it returns time until the next one
+func (rl *rateLimiter) allow(key string, perMinute int) (time.Duration, bool) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.now()
+	limit := float64(perMinute)
+	b, ok := rl.buckets[key]
+	if !ok {
+		b = &bucket{tokens: limit, at: now}
+		rl.buckets[key] = b
+	}
+
+	b.tokens = min(limit, b.tokens+now.Sub(b.at).Minutes()*limit)
+	b.at = now
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / limit * float64(time.Minute)), false
+	}
+
+	b.tokens--
+	return 0, true
+}
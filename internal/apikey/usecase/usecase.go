@@ -0,0 +1,202 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/config"
+	"github.com/MatiXxD/url-shortener/internal/apikey"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/google/uuid"
+)
+
+const (
+	// keyPrefix starts every key, so leaked keys are easy to find
+	keyPrefix = "usk_"
+	// idSize and secretSize are lengths of key parts in bytes
+	idSize     = 8
+	secretSize = 32
+
+	maxNameLength = 100
+)
+
+type APIKeyUsecase struct {
+	repo    apikey.Repository
+	cfg     config.APIKeyConfig
+	logger  *logger.Logger
+	limiter *rateLimiter
+	now     func() time.Time
+
+	mu sync.Mutex
+	// touched is when last use of key was saved
+	touched map[string]time.Time
+}
+
+func NewAPIKeyUsecase(r apikey.Repository, cfg *config.ServiceConfig, l *logger.Logger) *APIKeyUsecase {
+	return &APIKeyUsecase{
+		repo:    r,
+		cfg:     cfg.APIKeys,
+		logger:  l,
+		limiter: newRateLimiter(),
+		now:     time.Now,
+		touched: make(map[string]time.Time),
+	}
+}
+
+// AddAPIKey creates key of user from request or of new user, key is <prefix><id>_<secret>
+func (ku *APIKeyUsecase) AddAPIKey(ctx context.Context, req *models.AddAPIKeyReqBody) (*models.APIKey, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > maxNameLength {
+		return nil, fmt.Errorf("%w: name must have 1 to %d symbols", ErrInvalidAPIKey, maxNameLength)
+	}
+	if len(req.Scopes) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidAPIKey)
+	}
+	scopes := make([]string, 0, len(req.Scopes))
+	for _, s := range req.Scopes {
+		if !slices.Contains(models.Scopes, s) {
+			return nil, fmt.Errorf("%w: unknown scope %q", ErrInvalidAPIKey, s)
+		}
+		if !slices.Contains(scopes, s) {
+			scopes = append(scopes, s)
+		}
+	}
+	if req.RateLimit < 0 {
+		return nil, fmt.Errorf("%w: negative rate limit", ErrInvalidAPIKey)
+	}
+
+	id, err := randomHex(idSize)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomHex(secretSize)
+	if err != nil {
+		return nil, err
+	}
+
+	k := &models.APIKey{
+		ID:        id,
+		Name:      name,
+		UserID:    req.UserID,
+		Scopes:    scopes,
+		RateLimit: req.RateLimit,
+		Hash:      hashSecret(secret),
+		CreatedAt: ku.now().UTC(),
+	}
+	if k.UserID == "" {
+		k.UserID = uuid.New().String()
+	}
+	if k.RateLimit == 0 {
+		k.RateLimit = ku.cfg.RateLimit
+	}
+
+	if err := ku.repo.AddAPIKey(ctx, k); err != nil {
+		ku.logger.Errorf("cannot add api key: %v", err)
+		return nil, fmt.Errorf("cannot add api key: %w", err)
+	}
+
+	k.Hash = ""
+	k.Key = keyPrefix + id + "_" + secret
+	return k, nil
+}
+
+func (ku *APIKeyUsecase) ListAPIKeys(ctx context.Context) ([]*models.APIKey, error) {
+	keys, err := ku.repo.ListAPIKeys(ctx)
+	if err != nil {
+		ku.logger.Errorf("cannot list api keys: %v", err)
+		return nil, fmt.Errorf("cannot list api keys: %w", err)
+	}
+
+	for _, k := range keys {
+		k.Hash = ""
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey stops key, it is kept in list with time of revocation
+func (ku *APIKeyUsecase) RevokeAPIKey(ctx context.Context, id string) error {
+	err := ku.repo.RevokeAPIKey(ctx, id, ku.now().UTC())
+	if errors.Is(err, apikey.ErrNotFound) {
+		return ErrAPIKeyNotFound
+	}
+	if err != nil {
+		ku.logger.Errorf("cannot revoke api key=%s: %v", id, err)
+		return fmt.Errorf("cannot revoke api key: %w", err)
+	}
+
+	return nil
+}
+
+// Authenticate checks token, use of key is saved at most once per touch interval
+func (ku *APIKeyUsecase) Authenticate(ctx context.Context, token string) (*models.APIKey, error) {
+	id, secret, ok := strings.Cut(strings.TrimPrefix(token, keyPrefix), "_")
+	if !ok || !strings.HasPrefix(token, keyPrefix) {
+		return nil, fmt.Errorf("%w: malformed key", ErrUnauthorized)
+	}
+
+	k, err := ku.repo.GetAPIKey(ctx, id)
+	if errors.Is(err, apikey.ErrNotFound) {
+		return nil, fmt.Errorf("%w: unknown key %s", ErrUnauthorized, id)
+	}
+	if err != nil {
+		ku.logger.Errorf("cannot get api key=%s: %v", id, err)
+		return nil, fmt.Errorf("cannot get api key: %w", err)
+	}
+	if subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(k.Hash)) != 1 {
+		return nil, fmt.Errorf("%w: wrong secret of key %s", ErrUnauthorized, id)
+	}
+	if k.RevokedAt != nil {
+		return nil, fmt.Errorf("%w: key %s is revoked", ErrUnauthorized, id)
+	}
+
+	if wait, ok := ku.limiter.allow(k.ID, k.RateLimit); !ok {
+		return nil, &RetryError{Err: ErrRateLimited, RetryAfter: wait}
+	}
+
+	ku.touch(ctx, k.ID)
+
+	k.Hash = ""
+	return k, nil
+}
+
+// touch saves last use of key, failure is only logged as key is already checked
+func (ku *APIKeyUsecase) touch(ctx context.Context, id string) {
+	now := ku.now().UTC()
+
+	ku.mu.Lock()
+	due := now.Sub(ku.touched[id]) >= ku.cfg.TouchInterval.Std()
+	if due {
+		ku.touched[id] = now
+	}
+	ku.mu.Unlock()
+
+	if !due {
+		return
+	}
+	if err := ku.repo.TouchAPIKey(context.WithoutCancel(ctx), id, now); err != nil {
+		ku.logger.Errorf("cannot save last use of api key=%s: %v", id, err)
+	}
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("cannot generate api key: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/config"
+	"github.com/MatiXxD/url-shortener/internal/apikey/repository"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var l *logger.Logger
+
+func TestMain(m *testing.M) {
+	zl, err := zap.NewDevelopment()
+	if err != nil {
+		panic(err)
+	}
+
+	l = &logger.Logger{SugaredLogger: zl.Sugar()}
+
+	os.Exit(m.Run())
+}
+
+// clock is time of test, it moves only when told
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func newTestUsecase(t *testing.T) (*APIKeyUsecase, *clock) {
+	r, err := repository.NewFileRepository("", l)
+	require.NoError(t, err)
+
+	cfg := config.Default()
+	cfg.APIKeys.RateLimit = 3
+	cfg.APIKeys.TouchInterval = config.Duration(time.Minute)
+
+	c := &clock{now: time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)}
+	ku := NewAPIKeyUsecase(r, cfg, l)
+	ku.now = c.Now
+	ku.limiter.now = c.Now
+	return ku, c
+}
+
+func TestAPIKeyUsecase_AddAPIKey(t *testing.T) {
+	ku, _ := newTestUsecase(t)
+	ctx := context.Background()
+
+	k, err := ku.AddAPIKey(ctx, &models.AddAPIKeyReqBody{
+		Name:   " batch job ",
+		Scopes: []string{models.ScopeLinksWrite, models.ScopeLinksRead, models.ScopeLinksWrite},
+	})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(k.Key, keyPrefix+k.ID+"_"))
+	require.Empty(t, k.Hash)
+	require.Equal(t, "batch job", k.Name)
+	require.NotEmpty(t, k.UserID)
+	require.Equal(t, []string{models.ScopeLinksWrite, models.ScopeLinksRead}, k.Scopes)
+	require.Equal(t, 3, k.RateLimit)
+
+	keys, err := ku.ListAPIKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.Empty(t, keys[0].Key)
+	require.Empty(t, keys[0].Hash)
+
+	tests := []struct {
+		name string
+		req  models.AddAPIKeyReqBody
+	}{
+		{
+			name: "no name",
+			req:  models.AddAPIKeyReqBody{Scopes: []string{models.ScopeLinksRead}},
+		},
+		{
+			name: "no scopes",
+			req:  models.AddAPIKeyReqBody{Name: "job"},
+		},
+		{
+			name: "unknown scope",
+			req:  models.AddAPIKeyReqBody{Name: "job", Scopes: []string{"admin"}},
+		},
+		{
+			name: "negative rate limit",
+			req:  models.AddAPIKeyReqBody{Name: "job", Scopes: []string{models.ScopeLinksRead}, RateLimit: -1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ku.AddAPIKey(ctx, &tt.req)
+			require.ErrorIs(t, err, ErrInvalidAPIKey)
+		})
+	}
+}
+
+func TestAPIKeyUsecase_Authenticate(t *testing.T) {
+	ku, c := newTestUsecase(t)
+	ctx := context.Background()
+
+	k, err := ku.AddAPIKey(ctx, &models.AddAPIKeyReqBody{Name: "job", UserID: "owner", Scopes: []string{models.ScopeLinksRead}, RateLimit: 2})
+	require.NoError(t, err)
+
+	got, err := ku.Authenticate(ctx, k.Key)
+	require.NoError(t, err)
+	require.Equal(t, "owner", got.UserID)
+	require.Empty(t, got.Hash)
+
+	t.Run("rate limit", func(t *testing.T) {
+		_, err := ku.Authenticate(ctx, k.Key)
+		require.NoError(t, err)
+
+		_, err = ku.Authenticate(ctx, k.Key)
+		var retryErr *RetryError
+		require.ErrorAs(t, err, &retryErr)
+		require.ErrorIs(t, err, ErrRateLimited)
+		require.Equal(t, 30*time.Second, retryErr.RetryAfter)
+
+		c.now = c.now.Add(30 * time.Second)
+		_, err = ku.Authenticate(ctx, k.Key)
+		require.NoError(t, err)
+	})
+
+	t.Run("last use is saved once per interval", func(t *testing.T) {
+		keys, err := ku.ListAPIKeys(ctx)
+		require.NoError(t, err)
+		require.Equal(t, time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC), *keys[0].LastUsedAt)
+
+		c.now = c.now.Add(time.Minute)
+		_, err = ku.Authenticate(ctx, k.Key)
+		require.NoError(t, err)
+
+		keys, err = ku.ListAPIKeys(ctx)
+		require.NoError(t, err)
+		require.Equal(t, c.now, *keys[0].LastUsedAt)
+	})
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{
+			name:  "malformed",
+			token: "token",
+		},
+		{
+			name:  "unknown key",
+			token: keyPrefix + "0000000000000000_" + strings.Repeat("0", 64),
+		},
+		{
+			name:  "wrong secret",
+			token: keyPrefix + k.ID + "_" + strings.Repeat("0", 64),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ku.Authenticate(ctx, tt.token)
+			require.ErrorIs(t, err, ErrUnauthorized)
+		})
+	}
+
+	t.Run("revoked key", func(t *testing.T) {
+		require.NoError(t, ku.RevokeAPIKey(ctx, k.ID))
+		_, err := ku.Authenticate(ctx, k.Key)
+		require.ErrorIs(t, err, ErrUnauthorized)
+
+		require.ErrorIs(t, ku.RevokeAPIKey(ctx, "missing"), ErrAPIKeyNotFound)
+	})
+}
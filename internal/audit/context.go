@@ -0,0 +1,26 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+)
+
+type ctxKeyOrigin struct{}
+
+// origin is who makes request and id of the request
+type origin struct {
+	actor     models.AuditActor
+	requestID string
+}
+
+// WithActor returns context of request made by actor, changes made with it are recorded under actor
+func WithActor(ctx context.Context, actor models.AuditActor, requestID string) context.Context {
+	return context.WithValue(ctx, ctxKeyOrigin{}, origin{actor: actor, requestID: requestID})
+}
+
+// ActorFrom returns actor and request id of context, they are empty for changes made outside of requests
+func ActorFrom(ctx context.Context) (models.AuditActor, string) {
+	o, _ := ctx.Value(ctxKeyOrigin{}).(origin)
+	return o.actor, o.requestID
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/audit"
+	"github.com/MatiXxD/url-shortener/internal/audit/usecase"
+	mw "github.com/MatiXxD/url-shortener/internal/middleware"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/mailru/easyjson"
+)
+
+type AuditHandler struct {
+	auditUsecase audit.Usecase
+	logger       *logger.Logger
+}
+
+func NewAuditHandler(u audit.Usecase, l *logger.Logger) *AuditHandler {
+	return &AuditHandler{
+		auditUsecase: u,
+		logger:       l,
+	}
+}
+
+// ListEntries serves audit log, newest entries first
+func (ah *AuditHandler) ListEntries(w http.ResponseWriter, r *http.Request) {
+	logger := ah.logger
+	reqID := mw.GetRequestID(r.Context())
+	if reqID != "" {
+		logger = ah.logger.With("request_id", reqID)
+	}
+
+	query := r.URL.Query()
+	filter, err := parseAuditFilter(query)
+	if err != nil {
+		logger.Errorf("invalid filter: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	entries, next, err := ah.auditUsecase.ListEntries(r.Context(), filter, query.Get("cursor"))
+	if errors.Is(err, usecase.ErrInvalidFilter) || errors.Is(err, usecase.ErrInvalidCursor) {
+		logger.Errorf("invalid filter: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		logger.Errorf("can't list audit entries: %v", err)
+		http.Error(w, "Can't list audit entries", http.StatusInternalServerError)
+		return
+	}
+
+	page := &models.AuditPage{
+		Items:      entries,
+		NextCursor: next,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := easyjson.MarshalToWriter(page, w); err != nil {
+		logger.Error("can't marshal response body")
+		http.Error(w, "Can't marshal response body", http.StatusInternalServerError)
+		return
+	}
+}
+
+// parseAuditFilter reads log query: user_id, api_key_id, ip, action, short_domain (empty for default one),
+// code, request_id, from, to (RFC 3339) and limit
+func parseAuditFilter(query neturl.Values) (*models.AuditFilter, error) {
+	filter := &models.AuditFilter{
+		UserID:    query.Get("user_id"),
+		APIKeyID:  query.Get("api_key_id"),
+		IP:        query.Get("ip"),
+		Action:    query.Get("action"),
+		Code:      query.Get("code"),
+		RequestID: query.Get("request_id"),
+	}
+	if query.Has("short_domain") {
+		domain := query.Get("short_domain")
+		filter.ShortDomain = &domain
+	}
+
+	for name, dst := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		v := query.Get(name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: expected RFC 3339 time", name)
+		}
+		*dst = t.UTC()
+	}
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("limit: not an integer")
+		}
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/audit"
+	"github.com/MatiXxD/url-shortener/internal/audit/repository"
+	"github.com/MatiXxD/url-shortener/internal/audit/usecase"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAuditHandler_ListEntries(t *testing.T) {
+	zl, err := zap.NewDevelopment()
+	require.NoError(t, err)
+	l := &logger.Logger{SugaredLogger: zl.Sugar()}
+
+	r, err := repository.NewFileRepository("", l)
+	require.NoError(t, err)
+	u := usecase.NewAuditUsecase(r, l)
+	h := NewAuditHandler(u, l)
+
+	ctx := audit.WithActor(context.Background(), models.AuditActor{UserID: "u1", IP: "10.0.0.1"}, "req-1")
+	require.NoError(t, u.Record(ctx,
+		&models.AuditEntry{Action: models.AuditCreate, Code: "a", After: &models.AuditState{OriginalURL: "https://example.com"}},
+		&models.AuditEntry{Action: models.AuditCreate, Code: "b", ShortDomain: "go.example.com"},
+	))
+	require.NoError(t, u.Record(context.Background(), &models.AuditEntry{Action: models.AuditDelete, Code: "a"}))
+
+	mux := chi.NewRouter()
+	mux.Get("/api/audit", h.ListEntries)
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	from := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	tests := []struct {
+		name      string
+		query     string
+		wantCode  int
+		wantCodes []string
+		wantNext  string
+	}{
+		{
+			name:      "all",
+			wantCode:  http.StatusOK,
+			wantCodes: []string{"a", "b", "a"},
+		},
+		{
+			name:      "by user and action",
+			query:     "?user_id=u1&action=create",
+			wantCode:  http.StatusOK,
+			wantCodes: []string{"b", "a"},
+		},
+		{
+			name:      "by code of default domain",
+			query:     "?code=a&short_domain=&request_id=req-1&from=" + from,
+			wantCode:  http.StatusOK,
+			wantCodes: []string{"a"},
+		},
+		{
+			name:      "page",
+			query:     "?limit=1",
+			wantCode:  http.StatusOK,
+			wantCodes: []string{"a"},
+			wantNext:  "3",
+		},
+		{
+			name:     "unknown action",
+			query:    "?action=purge",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "malformed time",
+			query:    "?to=yesterday",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "malformed cursor",
+			query:    "?cursor=x",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := ts.Client().Get(ts.URL + "/api/audit" + tt.query)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			require.Equal(t, tt.wantCode, resp.StatusCode, string(body))
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			var page models.AuditPage
+			require.NoError(t, json.Unmarshal(body, &page))
+			codes := make([]string, 0, len(page.Items))
+			for _, e := range page.Items {
+				codes = append(codes, e.Code)
+			}
+			require.Equal(t, tt.wantCodes, codes)
+			require.Equal(t, tt.wantNext, page.NextCursor)
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+)
+
+// Repository keeps audit entries, they are only appended and never changed
+type Repository interface {
+	// AddEntries saves entries with one write and sets their ids
+	AddEntries(context.Context, []*models.AuditEntry) error
+	ListEntries(context.Context, *models.AuditFilter) ([]*models.AuditEntry, error)
+}
@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/mailru/easyjson"
+)
+
+// auditSuffix is appended to storage filename for audit journal
+const auditSuffix = ".audit"
+
+// FileRepository keeps audit entries in memory, they are appended to journal next to storage file
+// unless filename is empty
+type FileRepository struct {
+	filename string
+	// entries are ordered by id
+	entries []*models.AuditEntry
+	// starts are journal offsets of entries
+	starts []int64
+	logger *logger.Logger
+	mu     sync.RWMutex
+}
+
+func NewFileRepository(filename string, logger *logger.Logger) (*FileRepository, error) {
+	fr := &FileRepository{
+		filename: filename,
+		entries:  make([]*models.AuditEntry, 0),
+		logger:   logger,
+	}
+	if filename == "" {
+		return fr, nil
+	}
+
+	if err := fr.init(); err != nil {
+		logger.Errorf("failed to init audit log %v: %v", filename, err)
+		return nil, fmt.Errorf("failed to init audit log: %w", err)
+	}
+
+	return fr, nil
+}
+
+// AddEntries appends entries to journal with one write, they are kept only when it succeeds
+func (fr *FileRepository) AddEntries(ctx context.Context, entries []*models.AuditEntry) error {
+	return fr.AddEntriesWith(entries, func() error { return nil })
+}
+
+// AddEntriesWith appends entries to journal before save writes the change they describe, so storage
+// records change and its entries together. Entries are cut off from journal when save fails.
+func (fr *FileRepository) AddEntriesWith(entries []*models.AuditEntry, save func() error) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	if len(entries) == 0 {
+		return save()
+	}
+
+	var seq int64
+	if len(fr.entries) != 0 {
+		seq = fr.entries[len(fr.entries)-1].ID
+	}
+
+	added := make([]*models.AuditEntry, 0, len(entries))
+	for i, e := range entries {
+		stored := *e
+		stored.ID = seq + int64(i) + 1
+		added = append(added, &stored)
+	}
+
+	starts, err := fr.save(added)
+	if err != nil {
+		fr.logger.Errorf("failed to save audit entries: %v", err)
+		return fmt.Errorf("failed to save audit entries: %w", err)
+	}
+	if err := save(); err != nil {
+		if len(starts) != 0 {
+			if err := fr.truncate(starts[0]); err != nil {
+				fr.logger.Errorf("failed to cut off audit entries of unsaved change: %v", err)
+			}
+		}
+		return err
+	}
+
+	fr.entries = append(fr.entries, added...)
+	fr.starts = append(fr.starts, starts...)
+	for i, e := range entries {
+		e.ID = added[i].ID
+	}
+
+	return nil
+}
+
+func (fr *FileRepository) ListEntries(ctx context.Context, filter *models.AuditFilter) ([]*models.AuditEntry, error) {
+	fr.mu.RLock()
+	defer fr.mu.RUnlock()
+
+	res := make([]*models.AuditEntry, 0)
+	for _, e := range slices.Backward(fr.entries) {
+		if filter.Limit > 0 && len(res) == filter.Limit {
+			break
+		}
+		if matchEntry(e, filter) {
+			copied := *e
+			res = append(res, &copied)
+		}
+	}
+
+	return res, nil
+}
+
+// CutUncommitted drops entries at the end of journal whose change is not saved, saved tells it.
+// Such entries are left by crash between writes of entries and their change.
+func (fr *FileRepository) CutUncommitted(saved func(*models.AuditEntry) bool) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	n := len(fr.entries)
+	for n > 0 && !saved(fr.entries[n-1]) {
+		fr.logger.Warnf("dropping audit entry %d, its change was not saved", fr.entries[n-1].ID)
+		n--
+	}
+	if n == len(fr.entries) {
+		return nil
+	}
+
+	if fr.filename != "" {
+		if err := fr.truncate(fr.starts[n]); err != nil {
+			return fmt.Errorf("failed to cut off audit entries: %w", err)
+		}
+		fr.starts = fr.starts[:n]
+	}
+	fr.entries = fr.entries[:n]
+
+	return nil
+}
+
+// init replays journal, line torn by crash is cut off
+func (fr *FileRepository) init() error {
+	file, err := os.OpenFile(fr.filename+auditSuffix, os.O_RDWR, 0)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var size int64
+	r := bufio.NewReader(file)
+	for {
+		line, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(line) != 0 {
+				return file.Truncate(size)
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var e models.AuditEntry
+		if err := easyjson.Unmarshal(line, &e); err != nil {
+			return fmt.Errorf("malformed audit entry at byte %d: %w", size, err)
+		}
+		fr.entries = append(fr.entries, &e)
+		fr.starts = append(fr.starts, size)
+		size += int64(len(line))
+	}
+}
+
+// save appends entries to journal with one write and returns their offsets
+func (fr *FileRepository) save(entries []*models.AuditEntry) ([]int64, error) {
+	if fr.filename == "" {
+		return nil, nil
+	}
+
+	var data []byte
+	starts := make([]int64, 0, len(entries))
+	for _, e := range entries {
+		line, err := easyjson.Marshal(e)
+		if err != nil {
+			return nil, err
+		}
+		starts = append(starts, int64(len(data)))
+		data = append(append(data, line...), '\n')
+	}
+
+	file, err := os.OpenFile(fr.filename+auditSuffix, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := file.Write(data); err != nil {
+		// torn line would break journal on load
+		return nil, errors.Join(err, file.Truncate(info.Size()))
+	}
+
+	for i := range starts {
+		starts[i] += info.Size()
+	}
+	return starts, nil
+}
+
+func (fr *FileRepository) truncate(size int64) error {
+	return os.Truncate(fr.filename+auditSuffix, size)
+}
+
+func matchEntry(e *models.AuditEntry, filter *models.AuditFilter) bool {
+	switch {
+	case filter.Before != 0 && e.ID >= filter.Before:
+		return false
+	case filter.UserID != "" && e.Actor.UserID != filter.UserID:
+		return false
+	case filter.APIKeyID != "" && e.Actor.APIKeyID != filter.APIKeyID:
+		return false
+	case filter.IP != "" && e.Actor.IP != filter.IP:
+		return false
+	case filter.Action != "" && e.Action != filter.Action:
+		return false
+	case filter.ShortDomain != nil && e.ShortDomain != *filter.ShortDomain:
+		return false
+	case filter.Code != "" && e.Code != filter.Code:
+		return false
+	case filter.RequestID != "" && e.RequestID != filter.RequestID:
+		return false
+	case !filter.From.IsZero() && e.At.Before(filter.From):
+		return false
+	case !filter.To.IsZero() && !e.At.Before(filter.To):
+		return false
+	}
+	return true
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func newEntry(action, userID, code string, at time.Time) *models.AuditEntry {
+	return &models.AuditEntry{
+		At:     at,
+		Actor:  models.AuditActor{UserID: userID, IP: "10.0.0.1"},
+		Action: action,
+		Code:   code,
+		After:  &models.AuditState{OriginalURL: "https://example.com/" + code, Tags: []string{"go"}},
+	}
+}
+
+func TestFileRepository_Entries(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "db.json")
+	now := time.Now().UTC().Truncate(time.Second)
+
+	fr, err := NewFileRepository(filename, l)
+	require.NoError(t, err)
+
+	entries := []*models.AuditEntry{
+		newEntry(models.AuditCreate, "u1", "a", now),
+		newEntry(models.AuditCreate, "u2", "b", now.Add(time.Second)),
+	}
+	require.NoError(t, fr.AddEntries(ctx, entries))
+	require.Equal(t, int64(1), entries[0].ID)
+	require.Equal(t, int64(2), entries[1].ID)
+
+	deleted := newEntry(models.AuditDelete, "u1", "a", now.Add(2*time.Second))
+	deleted.Before, deleted.After = deleted.After, &models.AuditState{OriginalURL: deleted.After.OriginalURL, Deleted: true}
+	require.NoError(t, fr.AddEntries(ctx, []*models.AuditEntry{deleted}))
+
+	// torn line of crashed write is cut off on load
+	f, err := os.OpenFile(filename+auditSuffix, os.O_WRONLY|os.O_APPEND, 0666)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"id":4,"act`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	reopened, err := NewFileRepository(filename, l)
+	require.NoError(t, err)
+
+	next := newEntry(models.AuditUpdate, "u2", "b", now.Add(3*time.Second))
+	require.NoError(t, reopened.AddEntries(ctx, []*models.AuditEntry{next}))
+	require.Equal(t, int64(4), next.ID)
+
+	domain := ""
+	tests := []struct {
+		name   string
+		filter models.AuditFilter
+		want   []int64
+	}{
+		{
+			name: "all newest first",
+			want: []int64{4, 3, 2, 1},
+		},
+		{
+			name:   "by user",
+			filter: models.AuditFilter{UserID: "u1"},
+			want:   []int64{3, 1},
+		},
+		{
+			name:   "by action",
+			filter: models.AuditFilter{Action: models.AuditDelete},
+			want:   []int64{3},
+		},
+		{
+			name:   "by code of default domain",
+			filter: models.AuditFilter{Code: "b", ShortDomain: &domain},
+			want:   []int64{4, 2},
+		},
+		{
+			name:   "by time range",
+			filter: models.AuditFilter{From: now.Add(time.Second), To: now.Add(3 * time.Second)},
+			want:   []int64{3, 2},
+		},
+		{
+			name:   "page",
+			filter: models.AuditFilter{Before: 4, Limit: 2},
+			want:   []int64{3, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := reopened.ListEntries(ctx, &tt.filter)
+			require.NoError(t, err)
+
+			ids := make([]int64, 0, len(got))
+			for _, e := range got {
+				ids = append(ids, e.ID)
+			}
+			require.Equal(t, tt.want, ids)
+		})
+	}
+
+	got, err := reopened.ListEntries(ctx, &models.AuditFilter{Action: models.AuditDelete})
+	require.NoError(t, err)
+	require.Equal(t, deleted.Before, got[0].Before)
+	require.True(t, got[0].After.Deleted)
+	require.Equal(t, "10.0.0.1", got[0].Actor.IP)
+}
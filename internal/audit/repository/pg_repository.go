@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/MatiXxD/url-shortener/pkg/postgres"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mailru/easyjson"
+)
+
+// entryColumns are selected by scanEntries
+const entryColumns = `id, at, user_id, api_key_id, ip, action, short_domain, code, before, after, request_id`
+
+// PostgresRepository writes audit log to primary, table refuses updates and deletes.
+// Log is read from replicas, entries of the last moments may be missing there.
+type PostgresRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewPostgresRepository(db *postgres.DB, logger *logger.Logger) *PostgresRepository {
+	return &PostgresRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// AddEntries inserts entries in one transaction
+func (pr *PostgresRepository) AddEntries(ctx context.Context, entries []*models.AuditEntry) error {
+	tx, err := pr.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to save audit entries: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := InsertEntries(ctx, tx, entries); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to save audit entries: %w", err)
+	}
+
+	return nil
+}
+
+func (pr *PostgresRepository) ListEntries(ctx context.Context, filter *models.AuditFilter) ([]*models.AuditEntry, error) {
+	var conds []string
+	var args []any
+	where := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, strings.ReplaceAll(cond, "?", "$"+strconv.Itoa(len(args))))
+	}
+
+	if filter.UserID != "" {
+		where("user_id = ?", filter.UserID)
+	}
+	if filter.APIKeyID != "" {
+		where("api_key_id = ?", filter.APIKeyID)
+	}
+	if filter.IP != "" {
+		where("ip = ?", filter.IP)
+	}
+	if filter.Action != "" {
+		where("action = ?", filter.Action)
+	}
+	if filter.ShortDomain != nil {
+		where("short_domain = ?", *filter.ShortDomain)
+	}
+	if filter.Code != "" {
+		where("code = ?", filter.Code)
+	}
+	if filter.RequestID != "" {
+		where("request_id = ?", filter.RequestID)
+	}
+	if !filter.From.IsZero() {
+		where("at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		where("at < ?", filter.To)
+	}
+	if filter.Before != 0 {
+		where("id < ?", filter.Before)
+	}
+
+	query := `SELECT ` + entryColumns + ` FROM audit_log`
+	if len(conds) != 0 {
+		query += ` WHERE ` + strings.Join(conds, " AND ")
+	}
+	query += ` ORDER BY id DESC`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += ` LIMIT $` + strconv.Itoa(len(args))
+	}
+
+	var res []*models.AuditEntry
+	err := pr.db.Read(ctx, func(pool *pgxpool.Pool) error {
+		rows, err := pool.Query(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		res, err = scanEntries(rows)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+
+	return res, nil
+}
+
+func scanEntries(rows pgx.Rows) ([]*models.AuditEntry, error) {
+	defer rows.Close()
+
+	res := make([]*models.AuditEntry, 0)
+	for rows.Next() {
+		var e models.AuditEntry
+		var before, after []byte
+		err := rows.Scan(&e.ID, &e.At, &e.Actor.UserID, &e.Actor.APIKeyID, &e.Actor.IP, &e.Action, &e.ShortDomain,
+			&e.Code, &before, &after, &e.RequestID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		if e.Before, err = unmarshalState(before); err != nil {
+			return nil, err
+		}
+		if e.After, err = unmarshalState(after); err != nil {
+			return nil, err
+		}
+		res = append(res, &e)
+	}
+
+	return res, rows.Err()
+}
+
+// marshalState returns nil for missing state, so column is null
+// InsertEntries inserts entries in transaction tx, storages record changes of links with their entries this way
+func InsertEntries(ctx context.Context, tx pgx.Tx, entries []*models.AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO audit_log (at, user_id, api_key_id, ip, action, short_domain, code, before, after, request_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`
+
+	batch := &pgx.Batch{}
+	for _, e := range entries {
+		before, err := marshalState(e.Before)
+		if err != nil {
+			return err
+		}
+		after, err := marshalState(e.After)
+		if err != nil {
+			return err
+		}
+		batch.Queue(query, e.At, e.Actor.UserID, e.Actor.APIKeyID, e.Actor.IP, e.Action, e.ShortDomain, e.Code,
+			before, after, e.RequestID)
+	}
+
+	br := tx.SendBatch(ctx, batch)
+	defer br.Close()
+
+	for _, e := range entries {
+		if err := br.QueryRow().Scan(&e.ID); err != nil {
+			return fmt.Errorf("failed to save audit entry of code=%s: %w", e.Code, err)
+		}
+	}
+
+	if err := br.Close(); err != nil {
+		return fmt.Errorf("failed to save audit entries: %w", err)
+	}
+
+	return nil
+}
+
+func marshalState(s *models.AuditState) ([]byte, error) {
+	if s == nil {
+		return nil, nil
+	}
+	data, err := easyjson.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode audit state: %w", err)
+	}
+	return data, nil
+}
+
+func unmarshalState(data []byte) (*models.AuditState, error) {
+	if data == nil {
+		return nil, nil
+	}
+	var s models.AuditState
+	if err := easyjson.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("malformed audit state: %w", err)
+	}
+	return &s, nil
+}
@@ -0,0 +1,14 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+)
+
+type Usecase interface {
+	// Record saves entries, actor and request id missing in them are taken from context
+	Record(context.Context, ...*models.AuditEntry) error
+	// ListEntries returns page of audit log after cursor and cursor of the next page
+	ListEntries(context.Context, *models.AuditFilter, string) ([]*models.AuditEntry, string, error)
+}
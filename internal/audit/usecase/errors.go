@@ -0,0 +1,10 @@
+package usecase
+
+import (
+	"errors"
+)
+
+var (
+	ErrInvalidFilter = errors.New("invalid filter")
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
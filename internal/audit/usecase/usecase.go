@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/audit"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 1000
+)
+
+type AuditUsecase struct {
+	repo   audit.Repository
+	logger *logger.Logger
+	now    func() time.Time
+}
+
+func NewAuditUsecase(r audit.Repository, l *logger.Logger) *AuditUsecase {
+	return &AuditUsecase{
+		repo:   r,
+		logger: l,
+		now:    time.Now,
+	}
+}
+
+// Record saves entries of one change, they get the same time
+func (au *AuditUsecase) Record(ctx context.Context, entries ...*models.AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	actor, requestID := audit.ActorFrom(ctx)
+	now := au.now().UTC()
+	for _, e := range entries {
+		if e.At.IsZero() {
+			e.At = now
+		}
+		if e.Actor.UserID == "" {
+			e.Actor.UserID = actor.UserID
+		}
+		if e.Actor.APIKeyID == "" {
+			e.Actor.APIKeyID = actor.APIKeyID
+		}
+		if e.Actor.IP == "" {
+			e.Actor.IP = actor.IP
+		}
+		if e.RequestID == "" {
+			e.RequestID = requestID
+		}
+	}
+
+	if err := au.repo.AddEntries(ctx, entries); err != nil {
+		au.logger.Errorf("cannot save %d audit entries: %v", len(entries), err)
+		return fmt.Errorf("cannot save audit entries: %w", err)
+	}
+
+	return nil
+}
+
+// ListEntries returns entries newest first, cursor is id of the last entry of previous page
+func (au *AuditUsecase) ListEntries(ctx context.Context, filter *models.AuditFilter, cursor string) ([]*models.AuditEntry, string, error) {
+	f := *filter
+	if f.Action != "" && !slices.Contains(models.AuditActions, f.Action) {
+		return nil, "", fmt.Errorf("%w: unknown action %q", ErrInvalidFilter, f.Action)
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && !f.From.Before(f.To) {
+		return nil, "", fmt.Errorf("%w: from must be before to", ErrInvalidFilter)
+	}
+	if f.Limit < 0 {
+		return nil, "", fmt.Errorf("%w: negative limit", ErrInvalidFilter)
+	}
+	if f.Limit == 0 {
+		f.Limit = defaultPageSize
+	}
+	f.Limit = min(f.Limit, maxPageSize)
+
+	if cursor != "" {
+		before, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil || before <= 0 {
+			return nil, "", ErrInvalidCursor
+		}
+		f.Before = before
+	}
+
+	// one extra entry tells if there is next page
+	limit := f.Limit
+	f.Limit++
+	entries, err := au.repo.ListEntries(ctx, &f)
+	if err != nil {
+		au.logger.Errorf("cannot list audit entries: %v", err)
+		return nil, "", fmt.Errorf("cannot list audit entries: %w", err)
+	}
+	if len(entries) <= limit {
+		return entries, "", nil
+	}
+
+	entries = entries[:limit]
+	return entries, strconv.FormatInt(entries[limit-1].ID, 10), nil
+}
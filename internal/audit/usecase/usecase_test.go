@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/audit"
+	"github.com/MatiXxD/url-shortener/internal/audit/repository"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var l *logger.Logger
+
+func TestMain(m *testing.M) {
+	zl, err := zap.NewDevelopment()
+	if err != nil {
+		panic(err)
+	}
+
+	l = &logger.Logger{SugaredLogger: zl.Sugar()}
+
+	os.Exit(m.Run())
+}
+
+func newTestUsecase(t *testing.T) *AuditUsecase {
+	r, err := repository.NewFileRepository("", l)
+	require.NoError(t, err)
+	return NewAuditUsecase(r, l)
+}
+
+func TestAuditUsecase_Record(t *testing.T) {
+	au := newTestUsecase(t)
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	au.now = func() time.Time { return now }
+
+	ctx := audit.WithActor(context.Background(), models.AuditActor{UserID: "cookie-user", IP: "10.0.0.1"}, "req-1")
+	entries := []*models.AuditEntry{
+		{Action: models.AuditCreate, Code: "a"},
+		{Action: models.AuditCreate, Code: "b", Actor: models.AuditActor{UserID: "owner"}},
+	}
+	require.NoError(t, au.Record(ctx, entries...))
+
+	got, next, err := au.ListEntries(context.Background(), &models.AuditFilter{RequestID: "req-1"}, "")
+	require.NoError(t, err)
+	require.Empty(t, next)
+	require.Len(t, got, 2)
+
+	require.Equal(t, "b", got[0].Code)
+	require.Equal(t, models.AuditActor{UserID: "owner", IP: "10.0.0.1"}, got[0].Actor)
+	require.Equal(t, models.AuditActor{UserID: "cookie-user", IP: "10.0.0.1"}, got[1].Actor)
+	require.Equal(t, now, got[1].At)
+
+	t.Run("change outside of request", func(t *testing.T) {
+		e := &models.AuditEntry{Action: models.AuditDelete, Code: "a"}
+		require.NoError(t, au.Record(context.Background(), e))
+		require.Empty(t, e.Actor)
+		require.Empty(t, e.RequestID)
+	})
+}
+
+func TestAuditUsecase_ListEntries(t *testing.T) {
+	au := newTestUsecase(t)
+	ctx := context.Background()
+	for _, code := range []string{"a", "b", "c", "d", "e"} {
+		require.NoError(t, au.Record(ctx, &models.AuditEntry{Action: models.AuditCreate, Code: code}))
+	}
+
+	t.Run("pages", func(t *testing.T) {
+		var codes []string
+		cursor := ""
+		for {
+			page, next, err := au.ListEntries(ctx, &models.AuditFilter{Limit: 2}, cursor)
+			require.NoError(t, err)
+			for _, e := range page {
+				codes = append(codes, e.Code)
+			}
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+		require.Equal(t, []string{"e", "d", "c", "b", "a"}, codes)
+	})
+
+	now := time.Now()
+	tests := []struct {
+		name   string
+		filter models.AuditFilter
+		cursor string
+		err    error
+	}{
+		{
+			name:   "unknown action",
+			filter: models.AuditFilter{Action: "purge"},
+			err:    ErrInvalidFilter,
+		},
+		{
+			name:   "empty time range",
+			filter: models.AuditFilter{From: now, To: now},
+			err:    ErrInvalidFilter,
+		},
+		{
+			name:   "negative limit",
+			filter: models.AuditFilter{Limit: -1},
+			err:    ErrInvalidFilter,
+		},
+		{
+			name:   "malformed cursor",
+			cursor: "abc",
+			err:    ErrInvalidCursor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := au.ListEntries(ctx, &tt.filter, tt.cursor)
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/MatiXxD/url-shortener/internal/audit"
+	"github.com/MatiXxD/url-shortener/internal/models"
+)
+
+// ActorMiddleware tells audit who makes request, it goes after request id and auth middlewares
+func ActorMiddleware(h http.Handler) http.Handler {
+	mw := func(w http.ResponseWriter, r *http.Request) {
+		actor := models.AuditActor{UserID: GetUserID(r.Context())}
+		if k := GetAPIKey(r.Context()); k != nil {
+			actor.APIKeyID = k.ID
+		}
+		if ip := RealIP(r); ip != nil {
+			actor.IP = ip.String()
+		}
+
+		ctx := audit.WithActor(r.Context(), actor, GetRequestID(r.Context()))
+		h.ServeHTTP(w, r.WithContext(ctx))
+	}
+	return http.HandlerFunc(mw)
+}
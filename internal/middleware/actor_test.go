@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MatiXxD/url-shortener/internal/audit"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActorMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		remoteAddr string
+		realIP     string
+		wantIP     string
+	}{
+		{
+			name:       "real ip from trusted proxy",
+			remoteAddr: "192.168.0.1:1234",
+			realIP:     "10.1.2.3",
+			wantIP:     "10.1.2.3",
+		},
+		{
+			name:       "spoofed real ip",
+			remoteAddr: "172.16.0.5:1234",
+			realIP:     "10.1.2.3",
+			wantIP:     "172.16.0.5",
+		},
+	}
+
+	_, proxy, err := net.ParseCIDR("192.168.0.0/24")
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var actor models.AuditActor
+			var reqID string
+			h := RealIPMiddleware([]*net.IPNet{proxy}, RequestIdMiddleware(ActorMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				actor, reqID = audit.ActorFrom(r.Context())
+			}))))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			r.Header.Set("X-Real-IP", tt.realIP)
+			r = r.WithContext(WithAPIKey(WithUserID(r.Context(), "u1"), &models.APIKey{ID: "k1"}))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			require.Equal(t, models.AuditActor{UserID: "u1", APIKeyID: "k1", IP: tt.wantIP}, actor)
+			require.Equal(t, w.Header().Get(RequestIDHeader), reqID)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+)
+
+type ctxKeyAPIKey struct{}
+
+// WithAPIKey puts key request is authorized with into context
+func WithAPIKey(ctx context.Context, k *models.APIKey) context.Context {
+	return context.WithValue(ctx, ctxKeyAPIKey{}, k)
+}
+
+// GetAPIKey returns key of request, it is nil for requests with cookie
+func GetAPIKey(ctx context.Context) *models.APIKey {
+	k, _ := ctx.Value(ctxKeyAPIKey{}).(*models.APIKey)
+	return k
+}
+
+// BearerToken returns token of Authorization header
+func BearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// ScopeMiddleware lets request with api key through only if key has scope, requests with cookie are not checked
+func ScopeMiddleware(scope string, h http.Handler) http.HandlerFunc {
+	sf := func(w http.ResponseWriter, r *http.Request) {
+		if k := GetAPIKey(r.Context()); k != nil && !slices.Contains(k.Scopes, scope) {
+			http.Error(w, "Api key has no "+scope+" scope", http.StatusForbidden)
+			return
+		}
+		h.ServeHTTP(w, r)
+	}
+	return sf
+}
+
+// KeyOrTrustedMiddleware allows requests with api key and clients from subnet, scope of key is checked by ScopeMiddleware
+func KeyOrTrustedMiddleware(subnet *net.IPNet, h http.Handler) http.HandlerFunc {
+	trusted := TrustedSubnetMiddleware(subnet, h)
+	kf := func(w http.ResponseWriter, r *http.Request) {
+		if GetAPIKey(r.Context()) != nil {
+			h.ServeHTTP(w, r)
+			return
+		}
+		trusted(w, r)
+	}
+	return kf
+}
+
+// AdminTokenHeader carries admin token of service
+const AdminTokenHeader = "X-Admin-Token"
+
+// AdminMiddleware allows only requests with admin token, empty token denies everyone
+func AdminMiddleware(token string, h http.Handler) http.HandlerFunc {
+	af := func(w http.ResponseWriter, r *http.Request) {
+		got := r.Header.Get(AdminTokenHeader)
+		if token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			http.Error(w, "Admin token required", http.StatusForbidden)
+			return
+		}
+		h.ServeHTTP(w, r)
+	}
+	return af
+}
@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   string
+		wantOk bool
+	}{
+		{
+			name:   "bearer token",
+			header: "Bearer usk_1_2",
+			want:   "usk_1_2",
+			wantOk: true,
+		},
+		{
+			name:   "scheme in any case",
+			header: "bearer usk_1_2",
+			want:   "usk_1_2",
+			wantOk: true,
+		},
+		{
+			name:   "basic auth",
+			header: "Basic dXNlcjpwYXNz",
+		},
+		{
+			name:   "empty token",
+			header: "Bearer ",
+		},
+		{
+			name: "no header",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+
+			got, ok := BearerToken(r)
+			require.Equal(t, tt.wantOk, ok)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestScopeMiddleware(t *testing.T) {
+	_, subnet, err := net.ParseCIDR("10.0.0.0/8")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		key        *models.APIKey
+		remoteAddr string
+		want       int
+	}{
+		{
+			name:       "key with scope",
+			key:        &models.APIKey{Scopes: []string{models.ScopeLinksRead, models.ScopeStatsRead}},
+			remoteAddr: "192.168.0.1:1234",
+			want:       http.StatusOK,
+		},
+		{
+			name:       "key without scope",
+			key:        &models.APIKey{Scopes: []string{models.ScopeLinksWrite}},
+			remoteAddr: "10.1.2.3:1234",
+			want:       http.StatusForbidden,
+		},
+		{
+			name:       "cookie from trusted subnet",
+			remoteAddr: "10.1.2.3:1234",
+			want:       http.StatusOK,
+		},
+		{
+			name:       "cookie from outside",
+			remoteAddr: "192.168.0.1:1234",
+			want:       http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			h := ScopeMiddleware(models.ScopeStatsRead, KeyOrTrustedMiddleware(subnet, ok))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.key != nil {
+				r = r.WithContext(WithAPIKey(r.Context(), tt.key))
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			require.Equal(t, tt.want, w.Code)
+		})
+	}
+}
+
+func TestAdminMiddleware(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  string
+		header string
+		key    *models.APIKey
+		want   int
+	}{
+		{
+			name:   "admin token",
+			token:  "admin-token",
+			header: "admin-token",
+			want:   http.StatusOK,
+		},
+		{
+			name:   "wrong token",
+			token:  "admin-token",
+			header: "admin",
+			want:   http.StatusForbidden,
+		},
+		{
+			name:  "api key without token",
+			token: "admin-token",
+			key:   &models.APIKey{Scopes: models.Scopes},
+			want:  http.StatusForbidden,
+		},
+		{
+			name: "no token configured",
+			want: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := AdminMiddleware(tt.token, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				r.Header.Set(AdminTokenHeader, tt.header)
+			}
+			if tt.key != nil {
+				r = r.WithContext(WithAPIKey(r.Context(), tt.key))
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			require.Equal(t, tt.want, w.Code)
+		})
+	}
+}
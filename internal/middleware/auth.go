@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type (
+	ctxKeyUserID    struct{}
+	ctxKeyNewUserIP struct{}
+)
+
+// AuthMiddleware identifies user by signed cookie, new users get fresh id and cookie and their client ip
+// is kept in context. Requests with user set by api key are passed as is.
+func AuthMiddleware(secret []byte, cookieName string, ttl time.Duration, h http.Handler) http.HandlerFunc {
+	af := func(w http.ResponseWriter, r *http.Request) {
+		if GetAPIKey(r.Context()) != nil {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		var userID string
+
+		if c, err := r.Cookie(cookieName); err == nil {
+			userID, _ = verifyUserToken(secret, c.Value)
+		}
+
+		ctx := r.Context()
+		if userID == "" {
+			if ip := RealIP(r); ip != nil {
+				ctx = context.WithValue(ctx, ctxKeyNewUserIP{}, ip.String())
+			}
+			userID = uuid.New().String()
+			http.SetCookie(w, &http.Cookie{
+				Name:     cookieName,
+				Value:    signUserToken(secret, userID),
+				Path:     "/",
+				Expires:  time.Now().Add(ttl),
+				HttpOnly: true,
+				Secure:   r.TLS != nil,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+
+		h.ServeHTTP(w, r.WithContext(WithUserID(ctx, userID)))
+	}
+	return af
+}
+
+func GetUserID(ctx context.Context) string {
+	userID, _ := ctx.Value(ctxKeyUserID{}).(string)
+	return userID
+}
+
+// NewUserIP returns client ip of user who got id with this request, empty for user with cookie
+func NewUserIP(ctx context.Context) string {
+	ip, _ := ctx.Value(ctxKeyNewUserIP{}).(string)
+	return ip
+}
+
+// WithUserID puts user id into request context
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, ctxKeyUserID{}, userID)
+}
+
+// token format: <user_id>.<base64url(hmac_sha256(user_id))>
+func signUserToken(secret []byte, userID string) string {
+	return userID + "." + base64.RawURLEncoding.EncodeToString(userSignature(secret, userID))
+}
+
+func verifyUserToken(secret []byte, token string) (string, bool) {
+	userID, sign, ok := strings.Cut(token, ".")
+	if !ok || userID == "" {
+		return "", false
+	}
+
+	got, err := base64.RawURLEncoding.DecodeString(sign)
+	if err != nil {
+		return "", false
+	}
+
+	if !hmac.Equal(got, userSignature(secret, userID)) {
+		return "", false
+	}
+
+	return userID, true
+}
+
+func userSignature(secret []byte, userID string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(userID))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserToken(t *testing.T) {
+	secret := []byte("0123456789abcdef")
+	token := signUserToken(secret, "user-1")
+
+	tests := []struct {
+		name   string
+		token  string
+		want   string
+		wantOk bool
+	}{
+		{
+			name:   "valid token",
+			token:  token,
+			want:   "user-1",
+			wantOk: true,
+		},
+		{
+			name:  "tampered user id",
+			token: "user-2" + token[len("user-1"):],
+		},
+		{
+			name:  "no signature",
+			token: "user-1",
+		},
+		{
+			name:  "broken signature",
+			token: "user-1.!!!",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := verifyUserToken(secret, tt.token)
+			require.Equal(t, tt.wantOk, ok)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	secret := []byte("0123456789abcdef")
+
+	var gotUserID, gotIP string
+	h := AuthMiddleware(secret, "token", time.Hour, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUserID = GetUserID(r.Context())
+		gotIP = NewUserIP(r.Context())
+	}))
+
+	t.Run("new user gets cookie", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		cookies := w.Result().Cookies()
+		require.Len(t, cookies, 1)
+		require.NotEmpty(t, gotUserID)
+
+		userID, ok := verifyUserToken(secret, cookies[0].Value)
+		require.True(t, ok)
+		require.Equal(t, gotUserID, userID)
+		require.Equal(t, "192.0.2.1", gotIP)
+	})
+
+	t.Run("known user keeps id", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.AddCookie(&http.Cookie{Name: "token", Value: signUserToken(secret, "user-1")})
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		require.Empty(t, w.Result().Cookies())
+		require.Equal(t, "user-1", gotUserID)
+		require.Empty(t, gotIP)
+	})
+
+	t.Run("forged cookie is replaced", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.AddCookie(&http.Cookie{Name: "token", Value: signUserToken([]byte("another-secret!!"), "user-1")})
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		require.Len(t, w.Result().Cookies(), 1)
+		require.NotEqual(t, "user-1", gotUserID)
+	})
+	t.Run("request with api key gets no cookie", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r = r.WithContext(WithAPIKey(WithUserID(r.Context(), "service"), &models.APIKey{ID: "k1"}))
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		require.Empty(t, w.Result().Cookies())
+		require.Equal(t, "service", gotUserID)
+	})
+}
@@ -3,29 +3,69 @@ package middleware
 import (
 	"compress/gzip"
 	"io"
+	"mime"
 	"net/http"
+	"slices"
 	"strings"
 )
 
-// compressWriter used instead of http.ResponseWriter in compress middleware
+// compressWriter used instead of http.ResponseWriter in compress middleware,
+// response is compressed only if its content type is in encodeList
 type compressWriter struct {
 	http.ResponseWriter
-	gw *gzip.Writer
+	gw          *gzip.Writer
+	wroteHeader bool
 }
 
 func newCompressWriter(w http.ResponseWriter) *compressWriter {
-	gzipWriter, _ := gzip.NewWriterLevel(w, gzip.BestCompression) // ignore error because using fixed lvl
-	return &compressWriter{
-		ResponseWriter: w,
-		gw:             gzipWriter,
+	return &compressWriter{ResponseWriter: w}
+}
+
+func (cw *compressWriter) WriteHeader(code int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+
+	h := cw.Header()
+	if hasBody(code) && h.Get("Content-Encoding") == "" && shouldEncode(h.Get("Content-Type")) {
+		cw.gw, _ = gzip.NewWriterLevel(cw.ResponseWriter, gzip.BestCompression) // ignore error because using fixed lvl
+		h.Set("Content-Encoding", "gzip")
+		h.Add("Vary", "Accept-Encoding")
+		h.Del("Content-Length")
 	}
+
+	cw.ResponseWriter.WriteHeader(code)
 }
 
 func (cw *compressWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.gw == nil {
+		return cw.ResponseWriter.Write(b)
+	}
 	return cw.gw.Write(b)
 }
 
+// Flush sends compressed data written so far, streamed responses rely on it
+func (cw *compressWriter) Flush() {
+	if cw.gw != nil {
+		_ = cw.gw.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
 func (cw *compressWriter) Close() error {
+	if cw.gw == nil {
+		return nil
+	}
 	return cw.gw.Close()
 }
 
@@ -64,8 +104,7 @@ func CompressMiddleware(h http.Handler) http.Handler {
 		cw := w
 
 		// compress response
-		if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") &&
-			shouldEncode(r.Header.Get("Content-Type")) {
+		if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
 			tmp := newCompressWriter(w)
 			defer tmp.Close()
 
@@ -96,11 +135,19 @@ var encodeList = []string{
 	"text/html",
 }
 
+// shouldEncode reports if response of content type is compressed, responses without type are not
 func shouldEncode(contentType string) bool {
-	for _, ct := range encodeList {
-		if strings.Contains(ct, contentType) {
-			return true
-		}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return slices.Contains(encodeList, mediaType)
+}
+
+// hasBody reports if response with code carries content, redirects only have short note for browsers
+func hasBody(code int) bool {
+	if code < http.StatusOK || code == http.StatusNoContent {
+		return false
 	}
-	return false
+	return code < http.StatusMultipleChoices || code >= http.StatusBadRequest
 }
@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		contentType    string
+		status         int
+		wantGzip       bool
+	}{
+		{
+			name:           "json",
+			acceptEncoding: "gzip",
+			contentType:    "application/json",
+			status:         http.StatusOK,
+			wantGzip:       true,
+		},
+		{
+			name:           "html with charset",
+			acceptEncoding: "gzip, deflate",
+			contentType:    "text/html; charset=utf-8",
+			status:         http.StatusOK,
+			wantGzip:       true,
+		},
+		{
+			name:           "client without gzip",
+			acceptEncoding: "",
+			contentType:    "application/json",
+			status:         http.StatusOK,
+		},
+		{
+			name:           "type not listed",
+			acceptEncoding: "gzip",
+			contentType:    "image/png",
+			status:         http.StatusOK,
+		},
+		{
+			name:           "no content type",
+			acceptEncoding: "gzip",
+			status:         http.StatusOK,
+		},
+		{
+			name:           "redirect",
+			acceptEncoding: "gzip",
+			contentType:    "text/html; charset=utf-8",
+			status:         http.StatusTemporaryRedirect,
+		},
+		{
+			name:           "no content",
+			acceptEncoding: "gzip",
+			contentType:    "application/json",
+			status:         http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"result":"ok"}`
+			if tt.status == http.StatusNoContent {
+				body = ""
+			}
+
+			h := CompressMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.contentType != "" {
+					w.Header().Set("Content-Type", tt.contentType)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = io.WriteString(w, body)
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.acceptEncoding != "" {
+				r.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			resp := w.Result()
+			defer resp.Body.Close()
+			require.Equal(t, tt.status, resp.StatusCode)
+
+			if !tt.wantGzip {
+				require.Empty(t, resp.Header.Get("Content-Encoding"))
+				require.Equal(t, body, w.Body.String())
+				return
+			}
+
+			require.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+			gr, err := gzip.NewReader(resp.Body)
+			require.NoError(t, err)
+			data, err := io.ReadAll(gr)
+			require.NoError(t, err)
+			require.Equal(t, body, string(data))
+		})
+	}
+}
+
+func TestCompressMiddleware_request(t *testing.T) {
+	h := CompressMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		_, _ = w.Write(data)
+	}))
+
+	pr, pw := io.Pipe()
+	go func() {
+		gw := gzip.NewWriter(pw)
+		_, _ = io.WriteString(gw, "https://example.com")
+		_ = gw.Close()
+		_ = pw.Close()
+	}()
+
+	r := httptest.NewRequest(http.MethodPost, "/", pr)
+	r.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "https://example.com", w.Body.String())
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ContentTypeNDJSON is streamed by handlers line by line, they limit every line themselves
+const ContentTypeNDJSON = "application/x-ndjson"
+
+// LimitBodyMiddleware caps request body size, zero means no limit
+func LimitBodyMiddleware(maxSize int64, h http.Handler) http.HandlerFunc {
+	lf := func(w http.ResponseWriter, r *http.Request) {
+		if maxSize > 0 && !strings.Contains(r.Header.Get("Content-Type"), ContentTypeNDJSON) {
+			r.Body = http.MaxBytesReader(w, r.Body, maxSize)
+		}
+		h.ServeHTTP(w, r)
+	}
+	return lf
+}
@@ -7,11 +7,20 @@ import (
 	"github.com/google/uuid"
 )
 
+// RequestIDHeader carries request id of client, it is sent back in response
+const RequestIDHeader = "X-Request-ID"
+
 type ctxKeyRequestID struct{}
 
+// RequestIdMiddleware keeps request id sent by client if it is uuid, otherwise generates new one
 func RequestIdMiddleware(h http.Handler) http.Handler {
 	mw := func(w http.ResponseWriter, r *http.Request) {
-		reqID := uuid.New()
+		reqID, err := uuid.Parse(r.Header.Get(RequestIDHeader))
+		if err != nil {
+			reqID = uuid.New()
+		}
+		w.Header().Set(RequestIDHeader, reqID.String())
+
 		ctx := context.WithValue(r.Context(), ctxKeyRequestID{}, reqID)
 		h.ServeHTTP(w, r.WithContext(ctx))
 	}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestIdMiddleware(t *testing.T) {
+	clientID := uuid.New().String()
+
+	tests := []struct {
+		name   string
+		header string
+		keep   bool
+	}{
+		{
+			name:   "client id is kept",
+			header: clientID,
+			keep:   true,
+		},
+		{
+			name:   "invalid id is replaced",
+			header: "not-uuid",
+		},
+		{
+			name: "id is generated",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			h := RequestIdMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = GetRequestID(r.Context())
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				r.Header.Set(RequestIDHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			require.NotEmpty(t, got)
+			require.Equal(t, got, w.Header().Get(RequestIDHeader))
+			if tt.keep {
+				require.Equal(t, tt.header, got)
+			} else {
+				require.NotEqual(t, tt.header, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+)
+
+type ctxKeyRealIP struct{}
+
+// TrustedSubnetMiddleware allows only clients from subnet, nil subnet denies everyone
+func TrustedSubnetMiddleware(subnet *net.IPNet, h http.Handler) http.HandlerFunc {
+	tf := func(w http.ResponseWriter, r *http.Request) {
+		ip := RealIP(r)
+		if subnet == nil || ip == nil || !subnet.Contains(ip) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		h.ServeHTTP(w, r)
+	}
+	return tf
+}
+
+// RealIPMiddleware takes client ip from X-Real-IP header only if request comes from one of proxies,
+// otherwise header can be set by client itself and remote address is used
+func RealIPMiddleware(proxies []*net.IPNet, h http.Handler) http.HandlerFunc {
+	rf := func(w http.ResponseWriter, r *http.Request) {
+		ip := remoteIP(r)
+		if ip != nil && contains(proxies, ip) {
+			if realIP := net.ParseIP(r.Header.Get("X-Real-IP")); realIP != nil {
+				ip = realIP
+			}
+		}
+
+		ctx := context.WithValue(r.Context(), ctxKeyRealIP{}, ip)
+		h.ServeHTTP(w, r.WithContext(ctx))
+	}
+	return rf
+}
+
+// RealIP returns client ip found by RealIPMiddleware, remote address without it
+func RealIP(r *http.Request) net.IP {
+	if ip, ok := r.Context().Value(ctxKeyRealIP{}).(net.IP); ok {
+		return ip
+	}
+	return remoteIP(r)
+}
+
+func remoteIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	return net.ParseIP(host)
+}
+
+func contains(subnets []*net.IPNet, ip net.IP) bool {
+	for _, subnet := range subnets {
+		if subnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
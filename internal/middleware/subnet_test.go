@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrustedSubnetMiddleware(t *testing.T) {
+	_, subnet, err := net.ParseCIDR("10.0.0.0/8")
+	require.NoError(t, err)
+	_, proxy, err := net.ParseCIDR("192.168.0.0/24")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		subnet     *net.IPNet
+		proxies    []*net.IPNet
+		remoteAddr string
+		realIP     string
+		want       int
+	}{
+		{
+			name:       "remote addr in subnet",
+			subnet:     subnet,
+			remoteAddr: "10.1.2.3:1234",
+			want:       http.StatusOK,
+		},
+		{
+			name:       "real ip from trusted proxy",
+			subnet:     subnet,
+			proxies:    []*net.IPNet{proxy},
+			remoteAddr: "192.168.0.1:1234",
+			realIP:     "10.1.2.3",
+			want:       http.StatusOK,
+		},
+		{
+			name:       "spoofed real ip",
+			subnet:     subnet,
+			proxies:    []*net.IPNet{proxy},
+			remoteAddr: "192.168.1.1:1234",
+			realIP:     "10.1.2.3",
+			want:       http.StatusForbidden,
+		},
+		{
+			name:       "real ip without proxies",
+			subnet:     subnet,
+			remoteAddr: "192.168.0.1:1234",
+			realIP:     "10.1.2.3",
+			want:       http.StatusForbidden,
+		},
+		{
+			name:       "proxy itself in subnet",
+			subnet:     subnet,
+			proxies:    []*net.IPNet{subnet},
+			remoteAddr: "10.1.2.3:1234",
+			realIP:     "192.168.0.1",
+			want:       http.StatusForbidden,
+		},
+		{
+			name:       "outside subnet",
+			subnet:     subnet,
+			remoteAddr: "192.168.0.1:1234",
+			want:       http.StatusForbidden,
+		},
+		{
+			name:       "no subnet configured",
+			remoteAddr: "10.1.2.3:1234",
+			want:       http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := RealIPMiddleware(tt.proxies, TrustedSubnetMiddleware(tt.subnet, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			require.Equal(t, tt.want, w.Code)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+)
+
+//go:generate easyjson -all apikey.go
+
+// API key scopes
+const (
+	ScopeLinksWrite = "links:write"
+	ScopeLinksRead  = "links:read"
+	ScopeStatsRead  = "stats:read"
+)
+
+var Scopes = []string{ScopeLinksWrite, ScopeLinksRead, ScopeStatsRead}
+
+// APIKey lets service act as user without cookie, only hash of its secret is kept
+type APIKey struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	UserID string   `json:"user_id"`
+	Scopes []string `json:"scopes"`
+	// RateLimit is requests per minute
+	RateLimit int `json:"rate_limit"`
+	// Key is sent as bearer token, it is returned only when key is created
+	Key string `json:"key,omitempty"`
+	// Hash is sha256 of key secret, it is never returned by api
+	Hash       string     `json:"hash,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+//easyjson:json
+type APIKeyList []*APIKey
+
+type AddAPIKeyReqBody struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	// UserID owns links of key, new user is created when empty
+	UserID string `json:"user_id,omitempty"`
+	// RateLimit is default of service when zero
+	RateLimit int `json:"rate_limit,omitempty"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+)
+
+//go:generate easyjson -all audit.go
+
+// Audit actions
+const (
+	AuditCreate      = "create"
+	AuditBatchCreate = "batch_create"
+	AuditUpdate      = "update"
+	AuditRollback    = "rollback"
+	AuditDelete      = "delete"
+)
+
+var AuditActions = []string{AuditCreate, AuditBatchCreate, AuditUpdate, AuditRollback, AuditDelete}
+
+// AuditActor tells who made change, APIKeyID is set for requests authorized with api key
+type AuditActor struct {
+	UserID   string `json:"user_id,omitempty"`
+	APIKeyID string `json:"api_key_id,omitempty"`
+	IP       string `json:"ip,omitempty"`
+}
+
+// AuditEntry is record of link change, Before is nil for created link
+type AuditEntry struct {
+	ID          int64       `json:"id"`
+	At          time.Time   `json:"at"`
+	Actor       AuditActor  `json:"actor"`
+	Action      string      `json:"action"`
+	ShortDomain string      `json:"short_domain,omitempty"`
+	Code        string      `json:"code"`
+	Before      *AuditState `json:"before,omitempty"`
+	After       *AuditState `json:"after,omitempty"`
+	RequestID   string      `json:"request_id,omitempty"`
+}
+
+// AuditState is link values kept by audit
+type AuditState struct {
+	OriginalURL string   `json:"original_url"`
+	Title       string   `json:"title,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Deleted     bool     `json:"deleted,omitempty"`
+}
+
+type AuditPage struct {
+	Items      []*AuditEntry `json:"items"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
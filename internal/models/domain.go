@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+//go:generate easyjson -all domain.go
+
+// Domain is short domain links can be created on
+type Domain struct {
+	Host    string `json:"host"`
+	BaseURL string `json:"base_url"`
+	// Configured domains come from config and can't be removed by API
+	Configured bool `json:"configured,omitempty"`
+	// Workspace can only use domain, domains without it are shared
+	Workspace string    `json:"workspace,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
+
+//easyjson:json
+type DomainList []*Domain
+
+type AddDomainReqBody struct {
+	BaseURL   string `json:"base_url"`
+	Workspace string `json:"workspace,omitempty"`
+}
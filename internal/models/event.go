@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+)
+
+//go:generate easyjson -all event.go
+
+// Link event types
+const (
+	EventLinkCreated = "link.created"
+	EventLinkUpdated = "link.updated"
+	EventLinkDeleted = "link.deleted"
+	EventLinkClicked = "link.clicked"
+)
+
+// EventTypes are all event types subscriptions can select
+var EventTypes = []string{EventLinkCreated, EventLinkUpdated, EventLinkDeleted, EventLinkClicked}
+
+// Event tells what happened to link, it is payload of webhook delivery
+type Event struct {
+	ID         string     `json:"id"`
+	Type       string     `json:"type"`
+	OccurredAt time.Time  `json:"occurred_at"`
+	Link       *EventLink `json:"link"`
+	// Click is set for link.clicked
+	Click *Click `json:"click,omitempty"`
+}
+
+// EventLink is state of link after event
+type EventLink struct {
+	ShortURL    string `json:"short_url"`
+	Code        string `json:"code"`
+	ShortDomain string `json:"short_domain,omitempty"`
+	OriginalURL string `json:"original_url"`
+	// PreviousURL is destination replaced by link.updated
+	PreviousURL string   `json:"previous_url,omitempty"`
+	Title       string   `json:"title,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	UserID      string   `json:"user_id,omitempty"`
+}
+
+// Click describes request which was redirected
+type Click struct {
+	Referer   string `json:"referer,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+}
+
+// OutboxRecord is event saved together with change which caused it.
+// Offsets grow in order records become visible, consumers keep offset of the last record they handled.
+type OutboxRecord struct {
+	Offset int64  `json:"offset"`
+	Event  *Event `json:"event"`
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+)
+
+// Sort fields of link listing
+const (
+	SortCreatedAt   = "created_at"
+	SortOriginalURL = "original_url"
+	SortShortURL    = "short_url"
+)
+
+// URLFilter selects links of user for listing
+type URLFilter struct {
+	UserID    string
+	Workspace string
+	Tag       string
+	// Domain is host of original url
+	Domain string
+	// ShortDomain nil means links of all short domains
+	ShortDomain *string
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	// Deleted nil means both active and deleted links
+	Deleted *bool
+	Sort    string
+	Desc    bool
+	Limit   int
+	// After is position of the last link of previous page
+	After *URLCursor
+}
+
+// URLCursor is sort key of link, Value holds original url for SortOriginalURL
+type URLCursor struct {
+	CreatedAt   time.Time
+	Value       string
+	ShortURL    string
+	ShortDomain string
+}
+
+// DeliveryFilter selects deliveries for log, newest first
+type DeliveryFilter struct {
+	WebhookID string
+	Status    string
+	EventType string
+	Limit     int
+	// Before is id of the last delivery of previous page
+	Before int64
+}
+
+// AuditFilter selects audit entries, newest first
+type AuditFilter struct {
+	UserID   string
+	APIKeyID string
+	IP       string
+	Action   string
+	// ShortDomain nil means entries of all short domains
+	ShortDomain *string
+	Code        string
+	RequestID   string
+	From        time.Time
+	To          time.Time
+	Limit       int
+	// Before is id of the last entry of previous page
+	Before int64
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+//go:generate easyjson -all history.go
+
+// URLChange is a record of destination change
+type URLChange struct {
+	ID          int       `json:"id"`
+	ShortURL    string    `json:"-"`
+	ShortDomain string    `json:"-"`
+	PreviousURL string    `json:"previous_url"`
+	NewURL      string    `json:"new_url"`
+	ChangedBy   string    `json:"changed_by,omitempty"`
+	ChangedAt   time.Time `json:"changed_at"`
+}
+
+//easyjson:json
+type URLHistory []*URLChange
+
+type UpdateURLReqBody struct {
+	URL string `json:"url"`
+}
+
+type RollbackReqBody struct {
+	// ChangeID is change to undo, zero means the latest one
+	ChangeID int `json:"change_id,omitempty"`
+}
@@ -0,0 +1,326 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package models
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjson40eb0d12DecodeGithubComMatiXxDUrlShortenerInternalModels(in *jlexer.Lexer, out *UpdateURLReqBody) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "url":
+			out.URL = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson40eb0d12EncodeGithubComMatiXxDUrlShortenerInternalModels(out *jwriter.Writer, in UpdateURLReqBody) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"url\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.URL))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v UpdateURLReqBody) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson40eb0d12EncodeGithubComMatiXxDUrlShortenerInternalModels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v UpdateURLReqBody) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson40eb0d12EncodeGithubComMatiXxDUrlShortenerInternalModels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *UpdateURLReqBody) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson40eb0d12DecodeGithubComMatiXxDUrlShortenerInternalModels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *UpdateURLReqBody) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson40eb0d12DecodeGithubComMatiXxDUrlShortenerInternalModels(l, v)
+}
+func easyjson40eb0d12DecodeGithubComMatiXxDUrlShortenerInternalModels1(in *jlexer.Lexer, out *URLHistory) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(URLHistory, 0, 8)
+			} else {
+				*out = URLHistory{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v1 *URLChange
+			if in.IsNull() {
+				in.Skip()
+				v1 = nil
+			} else {
+				if v1 == nil {
+					v1 = new(URLChange)
+				}
+				(*v1).UnmarshalEasyJSON(in)
+			}
+			*out = append(*out, v1)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson40eb0d12EncodeGithubComMatiXxDUrlShortenerInternalModels1(out *jwriter.Writer, in URLHistory) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v2, v3 := range in {
+			if v2 > 0 {
+				out.RawByte(',')
+			}
+			if v3 == nil {
+				out.RawString("null")
+			} else {
+				(*v3).MarshalEasyJSON(out)
+			}
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v URLHistory) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson40eb0d12EncodeGithubComMatiXxDUrlShortenerInternalModels1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v URLHistory) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson40eb0d12EncodeGithubComMatiXxDUrlShortenerInternalModels1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *URLHistory) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson40eb0d12DecodeGithubComMatiXxDUrlShortenerInternalModels1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *URLHistory) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson40eb0d12DecodeGithubComMatiXxDUrlShortenerInternalModels1(l, v)
+}
+func easyjson40eb0d12DecodeGithubComMatiXxDUrlShortenerInternalModels2(in *jlexer.Lexer, out *URLChange) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "id":
+			out.ID = int(in.Int())
+		case "previous_url":
+			out.PreviousURL = string(in.String())
+		case "new_url":
+			out.NewURL = string(in.String())
+		case "changed_by":
+			out.ChangedBy = string(in.String())
+		case "changed_at":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.ChangedAt).UnmarshalJSON(data))
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson40eb0d12EncodeGithubComMatiXxDUrlShortenerInternalModels2(out *jwriter.Writer, in URLChange) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix[1:])
+		out.Int(int(in.ID))
+	}
+	{
+		const prefix string = ",\"previous_url\":"
+		out.RawString(prefix)
+		out.String(string(in.PreviousURL))
+	}
+	{
+		const prefix string = ",\"new_url\":"
+		out.RawString(prefix)
+		out.String(string(in.NewURL))
+	}
+	if in.ChangedBy != "" {
+		const prefix string = ",\"changed_by\":"
+		out.RawString(prefix)
+		out.String(string(in.ChangedBy))
+	}
+	{
+		const prefix string = ",\"changed_at\":"
+		out.RawString(prefix)
+		out.Raw((in.ChangedAt).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v URLChange) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson40eb0d12EncodeGithubComMatiXxDUrlShortenerInternalModels2(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v URLChange) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson40eb0d12EncodeGithubComMatiXxDUrlShortenerInternalModels2(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *URLChange) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson40eb0d12DecodeGithubComMatiXxDUrlShortenerInternalModels2(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *URLChange) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson40eb0d12DecodeGithubComMatiXxDUrlShortenerInternalModels2(l, v)
+}
+func easyjson40eb0d12DecodeGithubComMatiXxDUrlShortenerInternalModels3(in *jlexer.Lexer, out *RollbackReqBody) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "change_id":
+			out.ChangeID = int(in.Int())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson40eb0d12EncodeGithubComMatiXxDUrlShortenerInternalModels3(out *jwriter.Writer, in RollbackReqBody) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if in.ChangeID != 0 {
+		const prefix string = ",\"change_id\":"
+		first = false
+		out.RawString(prefix[1:])
+		out.Int(int(in.ChangeID))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v RollbackReqBody) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson40eb0d12EncodeGithubComMatiXxDUrlShortenerInternalModels3(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v RollbackReqBody) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson40eb0d12EncodeGithubComMatiXxDUrlShortenerInternalModels3(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *RollbackReqBody) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson40eb0d12DecodeGithubComMatiXxDUrlShortenerInternalModels3(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *RollbackReqBody) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson40eb0d12DecodeGithubComMatiXxDUrlShortenerInternalModels3(l, v)
+}
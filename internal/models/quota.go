@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+)
+
+//go:generate easyjson -all quota.go
+
+// Subjects of quotas
+const (
+	QuotaScopeUser      = "user"
+	QuotaScopeAPIKey    = "api_key"
+	QuotaScopeWorkspace = "workspace"
+	// QuotaScopeIP counts users without cookie by client ip, they get new id with every request
+	QuotaScopeIP = "ip"
+)
+
+// Quotas of subject
+const (
+	QuotaLinksPerDay = "links_per_day"
+	QuotaActiveLinks = "active_links"
+	QuotaBatchSize   = "batch_size"
+)
+
+// QuotaCounter is counter of quota, it is dropped after ExpiresAt unless it's zero
+type QuotaCounter struct {
+	Key       string    `json:"key"`
+	Value     int       `json:"value"`
+	Limit     int       `json:"-"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+// QuotaReservation is links taken from counters by request, they are given back to the same counters
+// whenever it happens. Active are keys of active links counters, created link keeps them to free them.
+type QuotaReservation struct {
+	Counters []*QuotaCounter `json:"counters"`
+	Active   []string        `json:"active"`
+}
+
+// QuotaUsage is how much of quota subject has used, quotas without ResetAt are not reset
+type QuotaUsage struct {
+	Scope   string     `json:"scope"`
+	Subject string     `json:"subject"`
+	Quota   string     `json:"quota"`
+	Limit   int        `json:"limit"`
+	Used    int        `json:"used"`
+	ResetAt *time.Time `json:"reset_at,omitempty"`
+}
+
+//easyjson:json
+type QuotaUsageList []*QuotaUsage
+
+// QuotaError is response to request rejected by quota
+type QuotaError struct {
+	Error string      `json:"error"`
+	Quota *QuotaUsage `json:"quota"`
+}
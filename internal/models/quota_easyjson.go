@@ -0,0 +1,525 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package models
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+	time "time"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonD24230d6DecodeGithubComMatiXxDUrlShortenerInternalModels(in *jlexer.Lexer, out *QuotaUsageList) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(QuotaUsageList, 0, 8)
+			} else {
+				*out = QuotaUsageList{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v1 *QuotaUsage
+			if in.IsNull() {
+				in.Skip()
+				v1 = nil
+			} else {
+				if v1 == nil {
+					v1 = new(QuotaUsage)
+				}
+				(*v1).UnmarshalEasyJSON(in)
+			}
+			*out = append(*out, v1)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD24230d6EncodeGithubComMatiXxDUrlShortenerInternalModels(out *jwriter.Writer, in QuotaUsageList) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v2, v3 := range in {
+			if v2 > 0 {
+				out.RawByte(',')
+			}
+			if v3 == nil {
+				out.RawString("null")
+			} else {
+				(*v3).MarshalEasyJSON(out)
+			}
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v QuotaUsageList) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD24230d6EncodeGithubComMatiXxDUrlShortenerInternalModels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v QuotaUsageList) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD24230d6EncodeGithubComMatiXxDUrlShortenerInternalModels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *QuotaUsageList) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD24230d6DecodeGithubComMatiXxDUrlShortenerInternalModels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *QuotaUsageList) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD24230d6DecodeGithubComMatiXxDUrlShortenerInternalModels(l, v)
+}
+func easyjsonD24230d6DecodeGithubComMatiXxDUrlShortenerInternalModels1(in *jlexer.Lexer, out *QuotaUsage) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "scope":
+			out.Scope = string(in.String())
+		case "subject":
+			out.Subject = string(in.String())
+		case "quota":
+			out.Quota = string(in.String())
+		case "limit":
+			out.Limit = int(in.Int())
+		case "used":
+			out.Used = int(in.Int())
+		case "reset_at":
+			if in.IsNull() {
+				in.Skip()
+				out.ResetAt = nil
+			} else {
+				if out.ResetAt == nil {
+					out.ResetAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.ResetAt).UnmarshalJSON(data))
+				}
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD24230d6EncodeGithubComMatiXxDUrlShortenerInternalModels1(out *jwriter.Writer, in QuotaUsage) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"scope\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Scope))
+	}
+	{
+		const prefix string = ",\"subject\":"
+		out.RawString(prefix)
+		out.String(string(in.Subject))
+	}
+	{
+		const prefix string = ",\"quota\":"
+		out.RawString(prefix)
+		out.String(string(in.Quota))
+	}
+	{
+		const prefix string = ",\"limit\":"
+		out.RawString(prefix)
+		out.Int(int(in.Limit))
+	}
+	{
+		const prefix string = ",\"used\":"
+		out.RawString(prefix)
+		out.Int(int(in.Used))
+	}
+	if in.ResetAt != nil {
+		const prefix string = ",\"reset_at\":"
+		out.RawString(prefix)
+		out.Raw((*in.ResetAt).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v QuotaUsage) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD24230d6EncodeGithubComMatiXxDUrlShortenerInternalModels1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v QuotaUsage) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD24230d6EncodeGithubComMatiXxDUrlShortenerInternalModels1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *QuotaUsage) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD24230d6DecodeGithubComMatiXxDUrlShortenerInternalModels1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *QuotaUsage) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD24230d6DecodeGithubComMatiXxDUrlShortenerInternalModels1(l, v)
+}
+func easyjsonD24230d6DecodeGithubComMatiXxDUrlShortenerInternalModels2(in *jlexer.Lexer, out *QuotaReservation) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "counters":
+			if in.IsNull() {
+				in.Skip()
+				out.Counters = nil
+			} else {
+				in.Delim('[')
+				if out.Counters == nil {
+					if !in.IsDelim(']') {
+						out.Counters = make([]*QuotaCounter, 0, 8)
+					} else {
+						out.Counters = []*QuotaCounter{}
+					}
+				} else {
+					out.Counters = (out.Counters)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v4 *QuotaCounter
+					if in.IsNull() {
+						in.Skip()
+						v4 = nil
+					} else {
+						if v4 == nil {
+							v4 = new(QuotaCounter)
+						}
+						(*v4).UnmarshalEasyJSON(in)
+					}
+					out.Counters = append(out.Counters, v4)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "active":
+			if in.IsNull() {
+				in.Skip()
+				out.Active = nil
+			} else {
+				in.Delim('[')
+				if out.Active == nil {
+					if !in.IsDelim(']') {
+						out.Active = make([]string, 0, 4)
+					} else {
+						out.Active = []string{}
+					}
+				} else {
+					out.Active = (out.Active)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v5 string
+					v5 = string(in.String())
+					out.Active = append(out.Active, v5)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD24230d6EncodeGithubComMatiXxDUrlShortenerInternalModels2(out *jwriter.Writer, in QuotaReservation) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"counters\":"
+		out.RawString(prefix[1:])
+		if in.Counters == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v6, v7 := range in.Counters {
+				if v6 > 0 {
+					out.RawByte(',')
+				}
+				if v7 == nil {
+					out.RawString("null")
+				} else {
+					(*v7).MarshalEasyJSON(out)
+				}
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"active\":"
+		out.RawString(prefix)
+		if in.Active == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v8, v9 := range in.Active {
+				if v8 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v9))
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v QuotaReservation) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD24230d6EncodeGithubComMatiXxDUrlShortenerInternalModels2(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v QuotaReservation) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD24230d6EncodeGithubComMatiXxDUrlShortenerInternalModels2(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *QuotaReservation) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD24230d6DecodeGithubComMatiXxDUrlShortenerInternalModels2(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *QuotaReservation) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD24230d6DecodeGithubComMatiXxDUrlShortenerInternalModels2(l, v)
+}
+func easyjsonD24230d6DecodeGithubComMatiXxDUrlShortenerInternalModels3(in *jlexer.Lexer, out *QuotaError) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "error":
+			out.Error = string(in.String())
+		case "quota":
+			if in.IsNull() {
+				in.Skip()
+				out.Quota = nil
+			} else {
+				if out.Quota == nil {
+					out.Quota = new(QuotaUsage)
+				}
+				(*out.Quota).UnmarshalEasyJSON(in)
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD24230d6EncodeGithubComMatiXxDUrlShortenerInternalModels3(out *jwriter.Writer, in QuotaError) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"error\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Error))
+	}
+	{
+		const prefix string = ",\"quota\":"
+		out.RawString(prefix)
+		if in.Quota == nil {
+			out.RawString("null")
+		} else {
+			(*in.Quota).MarshalEasyJSON(out)
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v QuotaError) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD24230d6EncodeGithubComMatiXxDUrlShortenerInternalModels3(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v QuotaError) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD24230d6EncodeGithubComMatiXxDUrlShortenerInternalModels3(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *QuotaError) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD24230d6DecodeGithubComMatiXxDUrlShortenerInternalModels3(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *QuotaError) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD24230d6DecodeGithubComMatiXxDUrlShortenerInternalModels3(l, v)
+}
+func easyjsonD24230d6DecodeGithubComMatiXxDUrlShortenerInternalModels4(in *jlexer.Lexer, out *QuotaCounter) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "key":
+			out.Key = string(in.String())
+		case "value":
+			out.Value = int(in.Int())
+		case "expires_at":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.ExpiresAt).UnmarshalJSON(data))
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD24230d6EncodeGithubComMatiXxDUrlShortenerInternalModels4(out *jwriter.Writer, in QuotaCounter) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"key\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Key))
+	}
+	{
+		const prefix string = ",\"value\":"
+		out.RawString(prefix)
+		out.Int(int(in.Value))
+	}
+	if true {
+		const prefix string = ",\"expires_at\":"
+		out.RawString(prefix)
+		out.Raw((in.ExpiresAt).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v QuotaCounter) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD24230d6EncodeGithubComMatiXxDUrlShortenerInternalModels4(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v QuotaCounter) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD24230d6EncodeGithubComMatiXxDUrlShortenerInternalModels4(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *QuotaCounter) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD24230d6DecodeGithubComMatiXxDUrlShortenerInternalModels4(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *QuotaCounter) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD24230d6DecodeGithubComMatiXxDUrlShortenerInternalModels4(l, v)
+}
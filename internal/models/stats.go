@@ -0,0 +1,24 @@
+package models
+
+//go:generate easyjson -all stats.go
+
+type Stats struct {
+	URLs    int `json:"urls"`
+	Users   int `json:"users"`
+	Deleted int `json:"deleted"`
+	// DBNodes are pool metrics of postgres primary and replicas
+	DBNodes []*DBNodeStats `json:"db_nodes,omitempty"`
+}
+
+type DBNodeStats struct {
+	Name          string `json:"name"`
+	Healthy       bool   `json:"healthy"`
+	Reads         int64  `json:"reads"`
+	LagMs         int64  `json:"lag_ms"`
+	TotalConns    int32  `json:"total_conns"`
+	IdleConns     int32  `json:"idle_conns"`
+	AcquiredConns int32  `json:"acquired_conns"`
+	MaxConns      int32  `json:"max_conns"`
+	AcquireCount  int64  `json:"acquire_count"`
+	AcquireTimeMs int64  `json:"acquire_time_ms"`
+}
@@ -0,0 +1,277 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package models
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonE3ab7953DecodeGithubComMatiXxDUrlShortenerInternalModels(in *jlexer.Lexer, out *Stats) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "urls":
+			out.URLs = int(in.Int())
+		case "users":
+			out.Users = int(in.Int())
+		case "deleted":
+			out.Deleted = int(in.Int())
+		case "db_nodes":
+			if in.IsNull() {
+				in.Skip()
+				out.DBNodes = nil
+			} else {
+				in.Delim('[')
+				if out.DBNodes == nil {
+					if !in.IsDelim(']') {
+						out.DBNodes = make([]*DBNodeStats, 0, 8)
+					} else {
+						out.DBNodes = []*DBNodeStats{}
+					}
+				} else {
+					out.DBNodes = (out.DBNodes)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 *DBNodeStats
+					if in.IsNull() {
+						in.Skip()
+						v1 = nil
+					} else {
+						if v1 == nil {
+							v1 = new(DBNodeStats)
+						}
+						(*v1).UnmarshalEasyJSON(in)
+					}
+					out.DBNodes = append(out.DBNodes, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE3ab7953EncodeGithubComMatiXxDUrlShortenerInternalModels(out *jwriter.Writer, in Stats) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"urls\":"
+		out.RawString(prefix[1:])
+		out.Int(int(in.URLs))
+	}
+	{
+		const prefix string = ",\"users\":"
+		out.RawString(prefix)
+		out.Int(int(in.Users))
+	}
+	{
+		const prefix string = ",\"deleted\":"
+		out.RawString(prefix)
+		out.Int(int(in.Deleted))
+	}
+	if len(in.DBNodes) != 0 {
+		const prefix string = ",\"db_nodes\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v2, v3 := range in.DBNodes {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				if v3 == nil {
+					out.RawString("null")
+				} else {
+					(*v3).MarshalEasyJSON(out)
+				}
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Stats) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE3ab7953EncodeGithubComMatiXxDUrlShortenerInternalModels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Stats) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE3ab7953EncodeGithubComMatiXxDUrlShortenerInternalModels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Stats) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE3ab7953DecodeGithubComMatiXxDUrlShortenerInternalModels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Stats) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE3ab7953DecodeGithubComMatiXxDUrlShortenerInternalModels(l, v)
+}
+func easyjsonE3ab7953DecodeGithubComMatiXxDUrlShortenerInternalModels1(in *jlexer.Lexer, out *DBNodeStats) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "name":
+			out.Name = string(in.String())
+		case "healthy":
+			out.Healthy = bool(in.Bool())
+		case "reads":
+			out.Reads = int64(in.Int64())
+		case "lag_ms":
+			out.LagMs = int64(in.Int64())
+		case "total_conns":
+			out.TotalConns = int32(in.Int32())
+		case "idle_conns":
+			out.IdleConns = int32(in.Int32())
+		case "acquired_conns":
+			out.AcquiredConns = int32(in.Int32())
+		case "max_conns":
+			out.MaxConns = int32(in.Int32())
+		case "acquire_count":
+			out.AcquireCount = int64(in.Int64())
+		case "acquire_time_ms":
+			out.AcquireTimeMs = int64(in.Int64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE3ab7953EncodeGithubComMatiXxDUrlShortenerInternalModels1(out *jwriter.Writer, in DBNodeStats) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"name\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Name))
+	}
+	{
+		const prefix string = ",\"healthy\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Healthy))
+	}
+	{
+		const prefix string = ",\"reads\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Reads))
+	}
+	{
+		const prefix string = ",\"lag_ms\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.LagMs))
+	}
+	{
+		const prefix string = ",\"total_conns\":"
+		out.RawString(prefix)
+		out.Int32(int32(in.TotalConns))
+	}
+	{
+		const prefix string = ",\"idle_conns\":"
+		out.RawString(prefix)
+		out.Int32(int32(in.IdleConns))
+	}
+	{
+		const prefix string = ",\"acquired_conns\":"
+		out.RawString(prefix)
+		out.Int32(int32(in.AcquiredConns))
+	}
+	{
+		const prefix string = ",\"max_conns\":"
+		out.RawString(prefix)
+		out.Int32(int32(in.MaxConns))
+	}
+	{
+		const prefix string = ",\"acquire_count\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.AcquireCount))
+	}
+	{
+		const prefix string = ",\"acquire_time_ms\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.AcquireTimeMs))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v DBNodeStats) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE3ab7953EncodeGithubComMatiXxDUrlShortenerInternalModels1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v DBNodeStats) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE3ab7953EncodeGithubComMatiXxDUrlShortenerInternalModels1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *DBNodeStats) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE3ab7953DecodeGithubComMatiXxDUrlShortenerInternalModels1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *DBNodeStats) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE3ab7953DecodeGithubComMatiXxDUrlShortenerInternalModels1(l, v)
+}
@@ -0,0 +1,8 @@
+package models
+
+//go:generate easyjson -all stream.go
+
+// StreamError is the last line of ndjson response when stream was interrupted
+type StreamError struct {
+	Error string `json:"error"`
+}
@@ -6,10 +6,36 @@ import (
 
 //go:generate easyjson -all url.go
 
+// Passthrough modes define which parts of request are forwarded to destination
+const (
+	PassthroughNone  = ""
+	PassthroughQuery = "query"
+	PassthroughPath  = "path"
+	PassthroughAll   = "all"
+)
+
+// Batch item statuses
+const (
+	BatchStatusCreated  = "created"
+	BatchStatusExisting = "existing" // original url was already shortened, its short url is returned
+	BatchStatusInvalid  = "invalid"
+	BatchStatusFailed   = "failed" // item is valid but wasn't saved
+)
+
 type UrlDTO struct {
-	CorrelationID string `json:"correlation_id"`
-	OriginURL     string `json:"original_url,omitempty"`
-	ShortURL      string `json:"short_url,omitempty"`
+	CorrelationID string   `json:"correlation_id"`
+	OriginURL     string   `json:"original_url,omitempty"`
+	ShortURL      string   `json:"short_url,omitempty"`
+	Preview       bool     `json:"preview,omitempty"`
+	Password      string   `json:"password,omitempty"`
+	RedirectCode  int      `json:"redirect_code,omitempty"`
+	Passthrough   string   `json:"passthrough,omitempty"`
+	Title         string   `json:"title,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+	ShortDomain   string   `json:"short_domain,omitempty"`
+	Status        string   `json:"status,omitempty"`
+	Error         string   `json:"error,omitempty"`
+	UserID        string   `json:"-"`
 }
 
 type URL struct {
@@ -19,12 +45,55 @@ type URL struct {
 	ShortURL      string    `json:"short_url"`
 	CreateAt      time.Time `json:"created_ad,omitempty"`
 	IsDeleted     bool      `json:"deleted,omitempty"`
+	UserID        string    `json:"user_id,omitempty"`
+	Preview       bool      `json:"preview,omitempty"`
+	PasswordHash  string    `json:"password_hash,omitempty"`
+	RedirectCode  int       `json:"redirect_code,omitempty"`
+	Passthrough   string    `json:"passthrough,omitempty"`
+	Title         string    `json:"title,omitempty"`
+	Tags          []string  `json:"tags,omitempty"`
+	// ShortDomain is host of short url, empty for default base url
+	ShortDomain string `json:"short_domain,omitempty"`
+	// Workspace owns url, empty for default workspace
+	Workspace string `json:"workspace,omitempty"`
+	// QuotaKeys are counters of active links quotas which url takes, they are freed when it is deleted
+	QuotaKeys []string `json:"quota_keys,omitempty"`
+	// Existed is set by AddURL and BatchAddURL when original url was already shortened
+	Existed bool `json:"-"`
+	// Event is written to outbox with url when it is inserted
+	Event *Event `json:"-"`
+	// Audit is recorded to audit log with url when it is inserted
+	Audit *AuditEntry `json:"-"`
 }
 
 type ShortenURLReqBody struct {
-	URL string `json:"url"`
+	URL          string   `json:"url"`
+	QR           bool     `json:"qr,omitempty"`
+	Preview      bool     `json:"preview,omitempty"`
+	Password     string   `json:"password,omitempty"`
+	RedirectCode int      `json:"redirect_code,omitempty"`
+	Passthrough  string   `json:"passthrough,omitempty"`
+	Title        string   `json:"title,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	ShortDomain  string   `json:"short_domain,omitempty"`
 }
 
 type ShortenURLRespBody struct {
 	ShortURL string `json:"short_url"`
+	QRURL    string `json:"qr_url,omitempty"`
+}
+
+// Link is public view of URL returned by management API
+type Link struct {
+	ShortURL    string    `json:"short_url"`
+	OriginalURL string    `json:"original_url"`
+	Title       string    `json:"title,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	Deleted     bool      `json:"deleted,omitempty"`
+}
+
+type LinkPage struct {
+	Items      []*Link `json:"items"`
+	NextCursor string  `json:"next_cursor,omitempty"`
 }
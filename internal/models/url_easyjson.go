@@ -42,6 +42,45 @@ func easyjsonF48b0fb9DecodeGithubComMatiXxDUrlShortenerInternalModels(in *jlexer
 			out.OriginURL = string(in.String())
 		case "short_url":
 			out.ShortURL = string(in.String())
+		case "preview":
+			out.Preview = bool(in.Bool())
+		case "password":
+			out.Password = string(in.String())
+		case "redirect_code":
+			out.RedirectCode = int(in.Int())
+		case "passthrough":
+			out.Passthrough = string(in.String())
+		case "title":
+			out.Title = string(in.String())
+		case "tags":
+			if in.IsNull() {
+				in.Skip()
+				out.Tags = nil
+			} else {
+				in.Delim('[')
+				if out.Tags == nil {
+					if !in.IsDelim(']') {
+						out.Tags = make([]string, 0, 4)
+					} else {
+						out.Tags = []string{}
+					}
+				} else {
+					out.Tags = (out.Tags)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 string
+					v1 = string(in.String())
+					out.Tags = append(out.Tags, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "short_domain":
+			out.ShortDomain = string(in.String())
+		case "status":
+			out.Status = string(in.String())
+		case "error":
+			out.Error = string(in.String())
 		default:
 			in.SkipRecursive()
 		}
@@ -71,6 +110,60 @@ func easyjsonF48b0fb9EncodeGithubComMatiXxDUrlShortenerInternalModels(out *jwrit
 		out.RawString(prefix)
 		out.String(string(in.ShortURL))
 	}
+	if in.Preview {
+		const prefix string = ",\"preview\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Preview))
+	}
+	if in.Password != "" {
+		const prefix string = ",\"password\":"
+		out.RawString(prefix)
+		out.String(string(in.Password))
+	}
+	if in.RedirectCode != 0 {
+		const prefix string = ",\"redirect_code\":"
+		out.RawString(prefix)
+		out.Int(int(in.RedirectCode))
+	}
+	if in.Passthrough != "" {
+		const prefix string = ",\"passthrough\":"
+		out.RawString(prefix)
+		out.String(string(in.Passthrough))
+	}
+	if in.Title != "" {
+		const prefix string = ",\"title\":"
+		out.RawString(prefix)
+		out.String(string(in.Title))
+	}
+	if len(in.Tags) != 0 {
+		const prefix string = ",\"tags\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v2, v3 := range in.Tags {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v3))
+			}
+			out.RawByte(']')
+		}
+	}
+	if in.ShortDomain != "" {
+		const prefix string = ",\"short_domain\":"
+		out.RawString(prefix)
+		out.String(string(in.ShortDomain))
+	}
+	if in.Status != "" {
+		const prefix string = ",\"status\":"
+		out.RawString(prefix)
+		out.String(string(in.Status))
+	}
+	if in.Error != "" {
+		const prefix string = ",\"error\":"
+		out.RawString(prefix)
+		out.String(string(in.Error))
+	}
 	out.RawByte('}')
 }
 
@@ -130,6 +223,68 @@ func easyjsonF48b0fb9DecodeGithubComMatiXxDUrlShortenerInternalModels1(in *jlexe
 			}
 		case "deleted":
 			out.IsDeleted = bool(in.Bool())
+		case "user_id":
+			out.UserID = string(in.String())
+		case "preview":
+			out.Preview = bool(in.Bool())
+		case "password_hash":
+			out.PasswordHash = string(in.String())
+		case "redirect_code":
+			out.RedirectCode = int(in.Int())
+		case "passthrough":
+			out.Passthrough = string(in.String())
+		case "title":
+			out.Title = string(in.String())
+		case "tags":
+			if in.IsNull() {
+				in.Skip()
+				out.Tags = nil
+			} else {
+				in.Delim('[')
+				if out.Tags == nil {
+					if !in.IsDelim(']') {
+						out.Tags = make([]string, 0, 4)
+					} else {
+						out.Tags = []string{}
+					}
+				} else {
+					out.Tags = (out.Tags)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v4 string
+					v4 = string(in.String())
+					out.Tags = append(out.Tags, v4)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "short_domain":
+			out.ShortDomain = string(in.String())
+		case "workspace":
+			out.Workspace = string(in.String())
+		case "quota_keys":
+			if in.IsNull() {
+				in.Skip()
+				out.QuotaKeys = nil
+			} else {
+				in.Delim('[')
+				if out.QuotaKeys == nil {
+					if !in.IsDelim(']') {
+						out.QuotaKeys = make([]string, 0, 4)
+					} else {
+						out.QuotaKeys = []string{}
+					}
+				} else {
+					out.QuotaKeys = (out.QuotaKeys)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v5 string
+					v5 = string(in.String())
+					out.QuotaKeys = append(out.QuotaKeys, v5)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -185,6 +340,74 @@ func easyjsonF48b0fb9EncodeGithubComMatiXxDUrlShortenerInternalModels1(out *jwri
 		out.RawString(prefix)
 		out.Bool(bool(in.IsDeleted))
 	}
+	if in.UserID != "" {
+		const prefix string = ",\"user_id\":"
+		out.RawString(prefix)
+		out.String(string(in.UserID))
+	}
+	if in.Preview {
+		const prefix string = ",\"preview\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Preview))
+	}
+	if in.PasswordHash != "" {
+		const prefix string = ",\"password_hash\":"
+		out.RawString(prefix)
+		out.String(string(in.PasswordHash))
+	}
+	if in.RedirectCode != 0 {
+		const prefix string = ",\"redirect_code\":"
+		out.RawString(prefix)
+		out.Int(int(in.RedirectCode))
+	}
+	if in.Passthrough != "" {
+		const prefix string = ",\"passthrough\":"
+		out.RawString(prefix)
+		out.String(string(in.Passthrough))
+	}
+	if in.Title != "" {
+		const prefix string = ",\"title\":"
+		out.RawString(prefix)
+		out.String(string(in.Title))
+	}
+	if len(in.Tags) != 0 {
+		const prefix string = ",\"tags\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v6, v7 := range in.Tags {
+				if v6 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v7))
+			}
+			out.RawByte(']')
+		}
+	}
+	if in.ShortDomain != "" {
+		const prefix string = ",\"short_domain\":"
+		out.RawString(prefix)
+		out.String(string(in.ShortDomain))
+	}
+	if in.Workspace != "" {
+		const prefix string = ",\"workspace\":"
+		out.RawString(prefix)
+		out.String(string(in.Workspace))
+	}
+	if len(in.QuotaKeys) != 0 {
+		const prefix string = ",\"quota_keys\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v8, v9 := range in.QuotaKeys {
+				if v8 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v9))
+			}
+			out.RawByte(']')
+		}
+	}
 	out.RawByte('}')
 }
 
@@ -232,6 +455,8 @@ func easyjsonF48b0fb9DecodeGithubComMatiXxDUrlShortenerInternalModels2(in *jlexe
 		switch key {
 		case "short_url":
 			out.ShortURL = string(in.String())
+		case "qr_url":
+			out.QRURL = string(in.String())
 		default:
 			in.SkipRecursive()
 		}
@@ -251,6 +476,11 @@ func easyjsonF48b0fb9EncodeGithubComMatiXxDUrlShortenerInternalModels2(out *jwri
 		out.RawString(prefix[1:])
 		out.String(string(in.ShortURL))
 	}
+	if in.QRURL != "" {
+		const prefix string = ",\"qr_url\":"
+		out.RawString(prefix)
+		out.String(string(in.QRURL))
+	}
 	out.RawByte('}')
 }
 
@@ -298,6 +528,43 @@ func easyjsonF48b0fb9DecodeGithubComMatiXxDUrlShortenerInternalModels3(in *jlexe
 		switch key {
 		case "url":
 			out.URL = string(in.String())
+		case "qr":
+			out.QR = bool(in.Bool())
+		case "preview":
+			out.Preview = bool(in.Bool())
+		case "password":
+			out.Password = string(in.String())
+		case "redirect_code":
+			out.RedirectCode = int(in.Int())
+		case "passthrough":
+			out.Passthrough = string(in.String())
+		case "title":
+			out.Title = string(in.String())
+		case "tags":
+			if in.IsNull() {
+				in.Skip()
+				out.Tags = nil
+			} else {
+				in.Delim('[')
+				if out.Tags == nil {
+					if !in.IsDelim(']') {
+						out.Tags = make([]string, 0, 4)
+					} else {
+						out.Tags = []string{}
+					}
+				} else {
+					out.Tags = (out.Tags)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v10 string
+					v10 = string(in.String())
+					out.Tags = append(out.Tags, v10)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "short_domain":
+			out.ShortDomain = string(in.String())
 		default:
 			in.SkipRecursive()
 		}
@@ -317,6 +584,55 @@ func easyjsonF48b0fb9EncodeGithubComMatiXxDUrlShortenerInternalModels3(out *jwri
 		out.RawString(prefix[1:])
 		out.String(string(in.URL))
 	}
+	if in.QR {
+		const prefix string = ",\"qr\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.QR))
+	}
+	if in.Preview {
+		const prefix string = ",\"preview\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Preview))
+	}
+	if in.Password != "" {
+		const prefix string = ",\"password\":"
+		out.RawString(prefix)
+		out.String(string(in.Password))
+	}
+	if in.RedirectCode != 0 {
+		const prefix string = ",\"redirect_code\":"
+		out.RawString(prefix)
+		out.Int(int(in.RedirectCode))
+	}
+	if in.Passthrough != "" {
+		const prefix string = ",\"passthrough\":"
+		out.RawString(prefix)
+		out.String(string(in.Passthrough))
+	}
+	if in.Title != "" {
+		const prefix string = ",\"title\":"
+		out.RawString(prefix)
+		out.String(string(in.Title))
+	}
+	if len(in.Tags) != 0 {
+		const prefix string = ",\"tags\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v11, v12 := range in.Tags {
+				if v11 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v12))
+			}
+			out.RawByte(']')
+		}
+	}
+	if in.ShortDomain != "" {
+		const prefix string = ",\"short_domain\":"
+		out.RawString(prefix)
+		out.String(string(in.ShortDomain))
+	}
 	out.RawByte('}')
 }
 
@@ -343,3 +659,253 @@ func (v *ShortenURLReqBody) UnmarshalJSON(data []byte) error {
 func (v *ShortenURLReqBody) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonF48b0fb9DecodeGithubComMatiXxDUrlShortenerInternalModels3(l, v)
 }
+func easyjsonF48b0fb9DecodeGithubComMatiXxDUrlShortenerInternalModels4(in *jlexer.Lexer, out *LinkPage) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "items":
+			if in.IsNull() {
+				in.Skip()
+				out.Items = nil
+			} else {
+				in.Delim('[')
+				if out.Items == nil {
+					if !in.IsDelim(']') {
+						out.Items = make([]*Link, 0, 8)
+					} else {
+						out.Items = []*Link{}
+					}
+				} else {
+					out.Items = (out.Items)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v13 *Link
+					if in.IsNull() {
+						in.Skip()
+						v13 = nil
+					} else {
+						if v13 == nil {
+							v13 = new(Link)
+						}
+						(*v13).UnmarshalEasyJSON(in)
+					}
+					out.Items = append(out.Items, v13)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "next_cursor":
+			out.NextCursor = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonF48b0fb9EncodeGithubComMatiXxDUrlShortenerInternalModels4(out *jwriter.Writer, in LinkPage) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"items\":"
+		out.RawString(prefix[1:])
+		if in.Items == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v14, v15 := range in.Items {
+				if v14 > 0 {
+					out.RawByte(',')
+				}
+				if v15 == nil {
+					out.RawString("null")
+				} else {
+					(*v15).MarshalEasyJSON(out)
+				}
+			}
+			out.RawByte(']')
+		}
+	}
+	if in.NextCursor != "" {
+		const prefix string = ",\"next_cursor\":"
+		out.RawString(prefix)
+		out.String(string(in.NextCursor))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LinkPage) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonF48b0fb9EncodeGithubComMatiXxDUrlShortenerInternalModels4(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LinkPage) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonF48b0fb9EncodeGithubComMatiXxDUrlShortenerInternalModels4(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LinkPage) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonF48b0fb9DecodeGithubComMatiXxDUrlShortenerInternalModels4(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LinkPage) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonF48b0fb9DecodeGithubComMatiXxDUrlShortenerInternalModels4(l, v)
+}
+func easyjsonF48b0fb9DecodeGithubComMatiXxDUrlShortenerInternalModels5(in *jlexer.Lexer, out *Link) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "short_url":
+			out.ShortURL = string(in.String())
+		case "original_url":
+			out.OriginalURL = string(in.String())
+		case "title":
+			out.Title = string(in.String())
+		case "tags":
+			if in.IsNull() {
+				in.Skip()
+				out.Tags = nil
+			} else {
+				in.Delim('[')
+				if out.Tags == nil {
+					if !in.IsDelim(']') {
+						out.Tags = make([]string, 0, 4)
+					} else {
+						out.Tags = []string{}
+					}
+				} else {
+					out.Tags = (out.Tags)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v16 string
+					v16 = string(in.String())
+					out.Tags = append(out.Tags, v16)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "created_at":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.CreatedAt).UnmarshalJSON(data))
+			}
+		case "deleted":
+			out.Deleted = bool(in.Bool())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonF48b0fb9EncodeGithubComMatiXxDUrlShortenerInternalModels5(out *jwriter.Writer, in Link) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"short_url\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.ShortURL))
+	}
+	{
+		const prefix string = ",\"original_url\":"
+		out.RawString(prefix)
+		out.String(string(in.OriginalURL))
+	}
+	if in.Title != "" {
+		const prefix string = ",\"title\":"
+		out.RawString(prefix)
+		out.String(string(in.Title))
+	}
+	if len(in.Tags) != 0 {
+		const prefix string = ",\"tags\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v17, v18 := range in.Tags {
+				if v17 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v18))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"created_at\":"
+		out.RawString(prefix)
+		out.Raw((in.CreatedAt).MarshalJSON())
+	}
+	if in.Deleted {
+		const prefix string = ",\"deleted\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Deleted))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Link) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonF48b0fb9EncodeGithubComMatiXxDUrlShortenerInternalModels5(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Link) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonF48b0fb9EncodeGithubComMatiXxDUrlShortenerInternalModels5(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Link) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonF48b0fb9DecodeGithubComMatiXxDUrlShortenerInternalModels5(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Link) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonF48b0fb9DecodeGithubComMatiXxDUrlShortenerInternalModels5(l, v)
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+//go:generate easyjson -all webhook.go
+
+// Delivery statuses
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead" // attempts are exhausted, delivery is kept until retried by hand
+)
+
+// Webhook is subscription of url to link events
+type Webhook struct {
+	ID     string   `json:"id"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	// Secret signs deliveries, it is returned only when webhook is created
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+//easyjson:json
+type WebhookList []*Webhook
+
+type AddWebhookReqBody struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	// Secret is generated when empty
+	Secret string `json:"secret,omitempty"`
+}
+
+// Delivery is event queued for webhook, Payload is sent as is
+type Delivery struct {
+	ID            int64           `json:"id"`
+	WebhookID     string          `json:"webhook_id"`
+	EventID       string          `json:"event_id"`
+	EventType     string          `json:"event_type"`
+	Payload       json.RawMessage `json:"payload"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	ResponseCode  int             `json:"response_code,omitempty"`
+	LastError     string          `json:"last_error,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+}
+
+type DeliveryPage struct {
+	Items      []*Delivery `json:"items"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
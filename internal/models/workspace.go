@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+)
+
+//go:generate easyjson -all workspace.go
+
+// Workspace owns links, members and short domains of a team, links of users outside of workspaces
+// belong to the default workspace with empty id
+type Workspace struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Settings  WorkspaceSettings `json:"settings"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// WorkspaceSettings are defaults of new links of workspace, zero values mean defaults of service
+type WorkspaceSettings struct {
+	CodeLength   int `json:"code_length,omitempty"`
+	RedirectCode int `json:"redirect_code,omitempty"`
+	// Domains are hosts links can be created on, the first one is used when link has no domain
+	Domains []string `json:"domains,omitempty"`
+}
+
+//easyjson:json
+type WorkspaceList []*Workspace
+
+type WorkspaceReqBody struct {
+	Name     string            `json:"name"`
+	Settings WorkspaceSettings `json:"settings"`
+}
+
+// WorkspaceMember is user of workspace, user is member of one workspace at most
+type WorkspaceMember struct {
+	WorkspaceID string    `json:"workspace_id"`
+	UserID      string    `json:"user_id"`
+	AddedAt     time.Time `json:"added_at"`
+}
+
+//easyjson:json
+type WorkspaceMemberList []*WorkspaceMember
@@ -0,0 +1,540 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package models
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjson66c9e915DecodeGithubComMatiXxDUrlShortenerInternalModels(in *jlexer.Lexer, out *WorkspaceSettings) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "code_length":
+			out.CodeLength = int(in.Int())
+		case "redirect_code":
+			out.RedirectCode = int(in.Int())
+		case "domains":
+			if in.IsNull() {
+				in.Skip()
+				out.Domains = nil
+			} else {
+				in.Delim('[')
+				if out.Domains == nil {
+					if !in.IsDelim(']') {
+						out.Domains = make([]string, 0, 4)
+					} else {
+						out.Domains = []string{}
+					}
+				} else {
+					out.Domains = (out.Domains)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 string
+					v1 = string(in.String())
+					out.Domains = append(out.Domains, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson66c9e915EncodeGithubComMatiXxDUrlShortenerInternalModels(out *jwriter.Writer, in WorkspaceSettings) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if in.CodeLength != 0 {
+		const prefix string = ",\"code_length\":"
+		first = false
+		out.RawString(prefix[1:])
+		out.Int(int(in.CodeLength))
+	}
+	if in.RedirectCode != 0 {
+		const prefix string = ",\"redirect_code\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.RedirectCode))
+	}
+	if len(in.Domains) != 0 {
+		const prefix string = ",\"domains\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		{
+			out.RawByte('[')
+			for v2, v3 := range in.Domains {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v3))
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v WorkspaceSettings) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson66c9e915EncodeGithubComMatiXxDUrlShortenerInternalModels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v WorkspaceSettings) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson66c9e915EncodeGithubComMatiXxDUrlShortenerInternalModels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *WorkspaceSettings) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson66c9e915DecodeGithubComMatiXxDUrlShortenerInternalModels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *WorkspaceSettings) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson66c9e915DecodeGithubComMatiXxDUrlShortenerInternalModels(l, v)
+}
+func easyjson66c9e915DecodeGithubComMatiXxDUrlShortenerInternalModels1(in *jlexer.Lexer, out *WorkspaceReqBody) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "name":
+			out.Name = string(in.String())
+		case "settings":
+			(out.Settings).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson66c9e915EncodeGithubComMatiXxDUrlShortenerInternalModels1(out *jwriter.Writer, in WorkspaceReqBody) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"name\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Name))
+	}
+	{
+		const prefix string = ",\"settings\":"
+		out.RawString(prefix)
+		(in.Settings).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v WorkspaceReqBody) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson66c9e915EncodeGithubComMatiXxDUrlShortenerInternalModels1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v WorkspaceReqBody) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson66c9e915EncodeGithubComMatiXxDUrlShortenerInternalModels1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *WorkspaceReqBody) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson66c9e915DecodeGithubComMatiXxDUrlShortenerInternalModels1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *WorkspaceReqBody) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson66c9e915DecodeGithubComMatiXxDUrlShortenerInternalModels1(l, v)
+}
+func easyjson66c9e915DecodeGithubComMatiXxDUrlShortenerInternalModels2(in *jlexer.Lexer, out *WorkspaceMemberList) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(WorkspaceMemberList, 0, 8)
+			} else {
+				*out = WorkspaceMemberList{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v4 *WorkspaceMember
+			if in.IsNull() {
+				in.Skip()
+				v4 = nil
+			} else {
+				if v4 == nil {
+					v4 = new(WorkspaceMember)
+				}
+				(*v4).UnmarshalEasyJSON(in)
+			}
+			*out = append(*out, v4)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson66c9e915EncodeGithubComMatiXxDUrlShortenerInternalModels2(out *jwriter.Writer, in WorkspaceMemberList) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v5, v6 := range in {
+			if v5 > 0 {
+				out.RawByte(',')
+			}
+			if v6 == nil {
+				out.RawString("null")
+			} else {
+				(*v6).MarshalEasyJSON(out)
+			}
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v WorkspaceMemberList) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson66c9e915EncodeGithubComMatiXxDUrlShortenerInternalModels2(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v WorkspaceMemberList) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson66c9e915EncodeGithubComMatiXxDUrlShortenerInternalModels2(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *WorkspaceMemberList) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson66c9e915DecodeGithubComMatiXxDUrlShortenerInternalModels2(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *WorkspaceMemberList) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson66c9e915DecodeGithubComMatiXxDUrlShortenerInternalModels2(l, v)
+}
+func easyjson66c9e915DecodeGithubComMatiXxDUrlShortenerInternalModels3(in *jlexer.Lexer, out *WorkspaceMember) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "workspace_id":
+			out.WorkspaceID = string(in.String())
+		case "user_id":
+			out.UserID = string(in.String())
+		case "added_at":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.AddedAt).UnmarshalJSON(data))
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson66c9e915EncodeGithubComMatiXxDUrlShortenerInternalModels3(out *jwriter.Writer, in WorkspaceMember) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"workspace_id\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.WorkspaceID))
+	}
+	{
+		const prefix string = ",\"user_id\":"
+		out.RawString(prefix)
+		out.String(string(in.UserID))
+	}
+	{
+		const prefix string = ",\"added_at\":"
+		out.RawString(prefix)
+		out.Raw((in.AddedAt).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v WorkspaceMember) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson66c9e915EncodeGithubComMatiXxDUrlShortenerInternalModels3(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v WorkspaceMember) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson66c9e915EncodeGithubComMatiXxDUrlShortenerInternalModels3(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *WorkspaceMember) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson66c9e915DecodeGithubComMatiXxDUrlShortenerInternalModels3(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *WorkspaceMember) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson66c9e915DecodeGithubComMatiXxDUrlShortenerInternalModels3(l, v)
+}
+func easyjson66c9e915DecodeGithubComMatiXxDUrlShortenerInternalModels4(in *jlexer.Lexer, out *WorkspaceList) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(WorkspaceList, 0, 8)
+			} else {
+				*out = WorkspaceList{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v7 *Workspace
+			if in.IsNull() {
+				in.Skip()
+				v7 = nil
+			} else {
+				if v7 == nil {
+					v7 = new(Workspace)
+				}
+				(*v7).UnmarshalEasyJSON(in)
+			}
+			*out = append(*out, v7)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson66c9e915EncodeGithubComMatiXxDUrlShortenerInternalModels4(out *jwriter.Writer, in WorkspaceList) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v8, v9 := range in {
+			if v8 > 0 {
+				out.RawByte(',')
+			}
+			if v9 == nil {
+				out.RawString("null")
+			} else {
+				(*v9).MarshalEasyJSON(out)
+			}
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v WorkspaceList) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson66c9e915EncodeGithubComMatiXxDUrlShortenerInternalModels4(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v WorkspaceList) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson66c9e915EncodeGithubComMatiXxDUrlShortenerInternalModels4(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *WorkspaceList) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson66c9e915DecodeGithubComMatiXxDUrlShortenerInternalModels4(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *WorkspaceList) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson66c9e915DecodeGithubComMatiXxDUrlShortenerInternalModels4(l, v)
+}
+func easyjson66c9e915DecodeGithubComMatiXxDUrlShortenerInternalModels5(in *jlexer.Lexer, out *Workspace) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "id":
+			out.ID = string(in.String())
+		case "name":
+			out.Name = string(in.String())
+		case "settings":
+			(out.Settings).UnmarshalEasyJSON(in)
+		case "created_at":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.CreatedAt).UnmarshalJSON(data))
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson66c9e915EncodeGithubComMatiXxDUrlShortenerInternalModels5(out *jwriter.Writer, in Workspace) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.ID))
+	}
+	{
+		const prefix string = ",\"name\":"
+		out.RawString(prefix)
+		out.String(string(in.Name))
+	}
+	{
+		const prefix string = ",\"settings\":"
+		out.RawString(prefix)
+		(in.Settings).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"created_at\":"
+		out.RawString(prefix)
+		out.Raw((in.CreatedAt).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Workspace) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson66c9e915EncodeGithubComMatiXxDUrlShortenerInternalModels5(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Workspace) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson66c9e915EncodeGithubComMatiXxDUrlShortenerInternalModels5(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Workspace) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson66c9e915DecodeGithubComMatiXxDUrlShortenerInternalModels5(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Workspace) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson66c9e915DecodeGithubComMatiXxDUrlShortenerInternalModels5(l, v)
+}
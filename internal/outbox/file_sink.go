@@ -0,0 +1,111 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+)
+
+// FileSink appends records to file as json lines. Ids of the latest written events are read back from file,
+// so events sent again after crash are skipped and each one is written once.
+type FileSink struct {
+	name string
+	path string
+	mu   sync.Mutex
+	seen *seenEvents
+}
+
+func NewFileSink(name, path string) (*FileSink, error) {
+	seen, err := writtenEvents(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read outbox file %s: %w", path, err)
+	}
+	return &FileSink{name: name, path: path, seen: seen}, nil
+}
+
+func (s *FileSink) Name() string {
+	return s.name
+}
+
+// Send writes records of events not written yet, write is synced before events are remembered
+func (s *FileSink) Send(ctx context.Context, records []*models.OutboxRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records = s.seen.unseen(records)
+	if len(records) == 0 {
+		return nil
+	}
+
+	data, err := marshalLines(records)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		// half written batch would be written again after it
+		return errors.Join(err, file.Truncate(info.Size()))
+	}
+	if err := file.Sync(); err != nil {
+		return err
+	}
+	s.seen.add(records...)
+
+	return nil
+}
+
+// writtenEvents returns ids of events written to file, line torn by crash is cut off
+func writtenEvents(path string) (*seenEvents, error) {
+	seen := newSeenEvents()
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if errors.Is(err, os.ErrNotExist) {
+		return seen, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var size int64
+	r := bufio.NewReader(file)
+	for {
+		line, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(line) != 0 {
+				return seen, file.Truncate(size)
+			}
+			return seen, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var rec struct {
+			Event struct {
+				ID string `json:"id"`
+			} `json:"event"`
+		}
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return nil, fmt.Errorf("malformed record at byte %d: %w", size, err)
+		}
+		seen.remember(rec.Event.ID)
+		size += int64(len(line))
+	}
+}
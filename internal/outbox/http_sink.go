@@ -0,0 +1,78 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+)
+
+const (
+	// OffsetHeader of http sink request has offset of the last record in body
+	OffsetHeader = "X-Outbox-Offset"
+
+	// userAgent of http sink requests
+	userAgent = "url-shortener-outbox"
+	// maxErrorBody is how much of failed response is kept in error
+	maxErrorBody = 512
+)
+
+// HTTPSink posts records as json array, any status but 2xx fails the batch.
+// Records sent again after failure keep their event ids, so receiver skips events it has already handled.
+type HTTPSink struct {
+	name   string
+	url    string
+	client *http.Client
+}
+
+func NewHTTPSink(name, url string, timeout time.Duration) *HTTPSink {
+	return &HTTPSink{
+		name: name,
+		url:  url,
+		client: &http.Client{
+			Timeout: timeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+func (s *HTTPSink) Name() string {
+	return s.name
+}
+
+func (s *HTTPSink) Send(ctx context.Context, records []*models.OutboxRecord) error {
+	body, err := json.Marshal(records)
+	if err != nil {
+		return fmt.Errorf("cannot encode outbox records: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(OffsetHeader, strconv.FormatInt(records[len(records)-1].Offset, 10))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/config"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+)
+
+// Store is outbox which keeps offset of every consumer, url repositories implement it
+type Store interface {
+	// ConsumeOutbox passes up to limit records after offset of consumer to fn and moves offset past them
+	// when fn succeeds. Concurrent call for the same consumer returns zero records.
+	ConsumeOutbox(ctx context.Context, consumer string, limit int, fn func([]*models.OutboxRecord) error) (int, error)
+}
+
+// Relay sends outbox records to sinks. Every sink is consumer with its own offset, so records reach
+// each of them in order. Batch is sent again when its offset is not saved, sinks skip events
+// they already have by event id, so each consumer handles event once.
+type Relay struct {
+	store        Store
+	sinks        []Sink
+	batchSize    int
+	pollInterval time.Duration
+	logger       *logger.Logger
+}
+
+func NewRelay(s Store, cfg *config.ServiceConfig, l *logger.Logger, sinks ...Sink) *Relay {
+	return &Relay{
+		store:        s,
+		sinks:        sinks,
+		batchSize:    cfg.Outbox.BatchSize,
+		pollInterval: cfg.Outbox.PollInterval.Std(),
+		logger:       l,
+	}
+}
+
+// Run relays records until ctx is done, sinks are served concurrently so failing one doesn't hold up others
+func (r *Relay) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, s := range r.sinks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.run(ctx, s)
+		}()
+	}
+	wg.Wait()
+}
+
+// run sends batches to sink while outbox has records for it and then waits for poll interval,
+// failed batch is sent again after it
+func (r *Relay) run(ctx context.Context, s Sink) {
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			n, err := r.store.ConsumeOutbox(ctx, s.Name(), r.batchSize, func(records []*models.OutboxRecord) error {
+				return s.Send(ctx, records)
+			})
+			if err != nil {
+				if ctx.Err() == nil {
+					r.logger.Errorf("cannot relay outbox to %s: %v", s.Name(), err)
+				}
+				break
+			}
+			if n < r.batchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
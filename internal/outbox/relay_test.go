@@ -0,0 +1,126 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/config"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/url/repository"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var l = &logger.Logger{SugaredLogger: zap.NewNop().Sugar()}
+
+// recordingSink keeps offsets it got, the first failures calls fail
+type recordingSink struct {
+	name     string
+	mu       sync.Mutex
+	offsets  []int64
+	failures int
+}
+
+func (s *recordingSink) Name() string {
+	return s.name
+}
+
+func (s *recordingSink) Send(ctx context.Context, records []*models.OutboxRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("sink is down")
+	}
+	for _, rec := range records {
+		s.offsets = append(s.offsets, rec.Offset)
+	}
+	return nil
+}
+
+func (s *recordingSink) received() []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int64(nil), s.offsets...)
+}
+
+func addURLs(t *testing.T, r *repository.MapRepository, from, to int) {
+	t.Helper()
+	for i := from; i <= to; i++ {
+		u := &models.URL{BaseURL: fmt.Sprintf("https://example.com/%d", i), ShortURL: fmt.Sprintf("c%d", i)}
+		u.Event = &models.Event{ID: fmt.Sprint(i), Type: models.EventLinkCreated, Link: &models.EventLink{Code: u.ShortURL}}
+		_, err := r.AddURL(context.Background(), u)
+		require.NoError(t, err)
+	}
+}
+
+func offsets(from, to int) []int64 {
+	res := make([]int64, 0, to-from+1)
+	for i := from; i <= to; i++ {
+		res = append(res, int64(i))
+	}
+	return res
+}
+
+func TestRelay(t *testing.T) {
+	cfg := config.Default()
+	cfg.Outbox.BatchSize = 3
+	cfg.Outbox.PollInterval = config.Duration(10 * time.Millisecond)
+
+	r := repository.NewMapRepository(map[string]*models.URL{}, l)
+	addURLs(t, r, 1, 7)
+
+	healthy := &recordingSink{name: "healthy"}
+	flaky := &recordingSink{name: "flaky", failures: 2}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		NewRelay(r, cfg, l, healthy, flaky).Run(ctx)
+		close(done)
+	}()
+
+	for _, s := range []*recordingSink{healthy, flaky} {
+		require.Eventually(t, func() bool {
+			return len(s.received()) == 7
+		}, time.Second, 5*time.Millisecond, s.name)
+		require.Equal(t, offsets(1, 7), s.received(), s.name)
+	}
+
+	t.Run("records added later", func(t *testing.T) {
+		addURLs(t, r, 8, 9)
+		require.Eventually(t, func() bool {
+			return len(healthy.received()) == 9 && len(flaky.received()) == 9
+		}, time.Second, 5*time.Millisecond)
+		require.Equal(t, offsets(1, 9), healthy.received())
+	})
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("relay did not stop")
+	}
+
+	t.Run("offsets are kept for consumer", func(t *testing.T) {
+		n, err := r.ConsumeOutbox(context.Background(), "healthy", 10, func([]*models.OutboxRecord) error {
+			t.Fatal("records were handled already")
+			return nil
+		})
+		require.NoError(t, err)
+		require.Zero(t, n)
+
+		n, err = r.ConsumeOutbox(context.Background(), "new", 10, func(records []*models.OutboxRecord) error {
+			require.Equal(t, int64(1), records[0].Offset)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, 9, n)
+	})
+}
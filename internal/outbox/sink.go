@@ -0,0 +1,171 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/MatiXxD/url-shortener/config"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/url"
+	"github.com/mailru/easyjson"
+)
+
+// Sink is consumer of outbox, its name keys offset, so it must not change between restarts
+type Sink interface {
+	Name() string
+	// Send delivers records in order, records of failed call are sent again
+	Send(ctx context.Context, records []*models.OutboxRecord) error
+}
+
+// NewSinks builds sinks enabled in config, they are named after their kind
+func NewSinks(cfg *config.OutboxConfig) ([]Sink, error) {
+	sinks := make([]Sink, 0, len(cfg.Sinks))
+	for _, kind := range cfg.Sinks {
+		switch kind {
+		case config.OutboxSinkStdout:
+			sinks = append(sinks, NewWriterSink(kind, os.Stdout))
+		case config.OutboxSinkFile:
+			s, err := NewFileSink(kind, cfg.FilePath)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, s)
+		case config.OutboxSinkHTTP:
+			sinks = append(sinks, NewHTTPSink(kind, cfg.HTTPURL, cfg.Timeout.Std()))
+		default:
+			return nil, fmt.Errorf("unknown outbox sink %q", kind)
+		}
+	}
+	return sinks, nil
+}
+
+// WriterSink writes records as json lines, event sent again is skipped while its id is remembered.
+// Ids are kept in memory only, so event sent before restart may be written again.
+type WriterSink struct {
+	name string
+	w    io.Writer
+	mu   sync.Mutex
+	seen *seenEvents
+}
+
+func NewWriterSink(name string, w io.Writer) *WriterSink {
+	return &WriterSink{name: name, w: w, seen: newSeenEvents()}
+}
+
+func (s *WriterSink) Name() string {
+	return s.name
+}
+
+func (s *WriterSink) Send(ctx context.Context, records []*models.OutboxRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records = s.seen.unseen(records)
+	if len(records) == 0 {
+		return nil
+	}
+
+	data, err := marshalLines(records)
+	if err != nil {
+		return err
+	}
+	if _, err := s.w.Write(data); err != nil {
+		return err
+	}
+	s.seen.add(records...)
+
+	return nil
+}
+
+// PublisherSink hands events to publisher, it gets every event once if it skips event ids it already has
+type PublisherSink struct {
+	name string
+	p    url.Publisher
+}
+
+func NewPublisherSink(name string, p url.Publisher) *PublisherSink {
+	return &PublisherSink{name: name, p: p}
+}
+
+func (s *PublisherSink) Name() string {
+	return s.name
+}
+
+func (s *PublisherSink) Send(ctx context.Context, records []*models.OutboxRecord) error {
+	events := make([]*models.Event, 0, len(records))
+	for _, rec := range records {
+		events = append(events, rec.Event)
+	}
+	return s.p.Publish(ctx, events...)
+}
+
+// dedupWindow is how many ids of the latest events sink remembers. Relay sends again only batches
+// whose offset is not saved, so they are far newer than the oldest remembered event.
+const dedupWindow = 10000
+
+// seenEvents remembers ids of the latest events sink has written, event id is idempotency key of record
+type seenEvents struct {
+	ids map[string]struct{}
+	// order is ring of remembered ids, next is the oldest one when ring is full
+	order []string
+	next  int
+}
+
+func newSeenEvents() *seenEvents {
+	return &seenEvents{ids: make(map[string]struct{})}
+}
+
+// unseen returns records whose events are not written yet, event repeated in records is kept once
+func (s *seenEvents) unseen(records []*models.OutboxRecord) []*models.OutboxRecord {
+	res := make([]*models.OutboxRecord, 0, len(records))
+	batch := make(map[string]struct{}, len(records))
+	for _, rec := range records {
+		id := rec.Event.ID
+		if _, ok := s.ids[id]; ok {
+			continue
+		}
+		if _, ok := batch[id]; ok {
+			continue
+		}
+		batch[id] = struct{}{}
+		res = append(res, rec)
+	}
+	return res
+}
+
+// add remembers events of records
+func (s *seenEvents) add(records ...*models.OutboxRecord) {
+	for _, rec := range records {
+		s.remember(rec.Event.ID)
+	}
+}
+
+// remember keeps event id, the oldest one is forgotten over dedupWindow
+func (s *seenEvents) remember(id string) {
+	if _, ok := s.ids[id]; ok {
+		return
+	}
+	s.ids[id] = struct{}{}
+	if len(s.order) < dedupWindow {
+		s.order = append(s.order, id)
+		return
+	}
+	delete(s.ids, s.order[s.next])
+	s.order[s.next] = id
+	s.next = (s.next + 1) % dedupWindow
+}
+
+func marshalLines(records []*models.OutboxRecord) ([]byte, error) {
+	var data []byte
+	for _, rec := range records {
+		line, err := easyjson.Marshal(rec)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode outbox record %d: %w", rec.Offset, err)
+		}
+		data = append(append(data, line...), '\n')
+	}
+	return data, nil
+}
@@ -0,0 +1,138 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func records(offsets ...int64) []*models.OutboxRecord {
+	res := make([]*models.OutboxRecord, 0, len(offsets))
+	for _, o := range offsets {
+		res = append(res, &models.OutboxRecord{Offset: o, Event: &models.Event{ID: fmt.Sprint("event-", o), Type: models.EventLinkCreated}})
+	}
+	return res
+}
+
+func readOffsets(t *testing.T, path string) []int64 {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var res []int64
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var rec models.OutboxRecord
+		require.NoError(t, json.Unmarshal([]byte(line), &rec))
+		res = append(res, rec.Offset)
+	}
+	return res
+}
+
+func TestFileSink(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	s, err := NewFileSink("file", path)
+	require.NoError(t, err)
+	require.NoError(t, s.Send(ctx, records(1, 2, 3)))
+
+	t.Run("records sent again are skipped", func(t *testing.T) {
+		require.NoError(t, s.Send(ctx, records(2, 3, 4)))
+		require.Equal(t, []int64{1, 2, 3, 4}, readOffsets(t, path))
+	})
+
+	t.Run("reopened sink skips written events", func(t *testing.T) {
+		reopened, err := NewFileSink("file", path)
+		require.NoError(t, err)
+		require.NoError(t, reopened.Send(ctx, records(3, 4, 5)))
+		require.Equal(t, []int64{1, 2, 3, 4, 5}, readOffsets(t, path))
+	})
+
+	t.Run("event sent again under other offset is skipped", func(t *testing.T) {
+		reopened, err := NewFileSink("file", path)
+		require.NoError(t, err)
+		again := records(7)
+		again[0].Event.ID = "event-5"
+		require.NoError(t, reopened.Send(ctx, again))
+		require.Equal(t, []int64{1, 2, 3, 4, 5}, readOffsets(t, path))
+	})
+
+	t.Run("torn line is cut off", func(t *testing.T) {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0666)
+		require.NoError(t, err)
+		_, err = f.WriteString(`{"offset":6,"ev`)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		reopened, err := NewFileSink("file", path)
+		require.NoError(t, err)
+		require.NoError(t, reopened.Send(ctx, records(6)))
+		require.Equal(t, []int64{1, 2, 3, 4, 5, 6}, readOffsets(t, path))
+	})
+}
+
+func TestWriterSink(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	s := NewWriterSink("stdout", &buf)
+
+	require.NoError(t, s.Send(ctx, records(1, 2)))
+	require.NoError(t, s.Send(ctx, records(2, 3)))
+	require.Equal(t, 3, strings.Count(buf.String(), "\n"))
+
+	t.Run("repeated event within batch is written once", func(t *testing.T) {
+		buf.Reset()
+		batch := append(records(4), records(4)...)
+		require.NoError(t, s.Send(ctx, batch))
+		require.Equal(t, 1, strings.Count(buf.String(), "\n"))
+	})
+}
+
+func TestSeenEvents(t *testing.T) {
+	seen := newSeenEvents()
+	for i := 0; i <= dedupWindow; i++ {
+		seen.remember(fmt.Sprint("event-", i))
+	}
+
+	require.Len(t, seen.ids, dedupWindow)
+	require.Len(t, seen.unseen(records(0)), 1, "the oldest event is forgotten")
+	require.Empty(t, seen.unseen(records(1, dedupWindow)))
+}
+
+func TestHTTPSink(t *testing.T) {
+	status := http.StatusOK
+	var got []*models.OutboxRecord
+	var offset string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = nil
+		_ = json.Unmarshal(body, &got)
+		offset = r.Header.Get(OffsetHeader)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("busy"))
+	}))
+	defer ts.Close()
+
+	s := NewHTTPSink("http", ts.URL, time.Second)
+
+	require.NoError(t, s.Send(context.Background(), records(4, 5)))
+	require.Len(t, got, 2)
+	require.Equal(t, int64(4), got[0].Offset)
+	require.Equal(t, models.EventLinkCreated, got[1].Event.Type)
+	require.Equal(t, "5", offset)
+
+	status = http.StatusServiceUnavailable
+	require.ErrorContains(t, s.Send(context.Background(), records(6)), "unexpected status 503: busy")
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+
+	mw "github.com/MatiXxD/url-shortener/internal/middleware"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/quota"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/mailru/easyjson"
+)
+
+type QuotaHandler struct {
+	quotaUsecase quota.Usecase
+	logger       *logger.Logger
+}
+
+func NewQuotaHandler(u quota.Usecase, l *logger.Logger) *QuotaHandler {
+	return &QuotaHandler{
+		quotaUsecase: u,
+		logger:       l,
+	}
+}
+
+// Usage responds with quotas of user, api key and workspace of request
+func (qh *QuotaHandler) Usage(w http.ResponseWriter, r *http.Request) {
+	logger := qh.logger
+	reqID := mw.GetRequestID(r.Context())
+	if reqID != "" {
+		logger = qh.logger.With("request_id", reqID)
+	}
+
+	usage, err := qh.quotaUsecase.Usage(r.Context())
+	if err != nil {
+		logger.Errorf("can't get quota usage: %v", err)
+		http.Error(w, "Can't get quota usage", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := easyjson.MarshalToWriter(models.QuotaUsageList(usage), w); err != nil {
+		logger.Error("can't marshal response body")
+		http.Error(w, "Can't marshal response body", http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MatiXxD/url-shortener/config"
+	mw "github.com/MatiXxD/url-shortener/internal/middleware"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/quota/repository"
+	"github.com/MatiXxD/url-shortener/internal/quota/usecase"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestQuotaHandler_Usage(t *testing.T) {
+	zl, err := zap.NewDevelopment()
+	require.NoError(t, err)
+	l := &logger.Logger{SugaredLogger: zl.Sugar()}
+
+	r, err := repository.NewFileRepository("", l)
+	require.NoError(t, err)
+	cfg := config.Default()
+	cfg.Quotas.User = config.QuotaLimits{LinksPerDay: 10, ActiveLinks: 100}
+	qu := usecase.NewQuotaUsecase(r, cfg, l)
+	h := NewQuotaHandler(qu, l)
+
+	_, err = qu.Reserve(mw.WithUserID(context.Background(), "u1"), 3)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		user string
+		want []int
+	}{
+		{name: "user with links", user: "u1", want: []int{3, 3}},
+		{name: "new user", user: "u2", want: []int{0, 0}},
+		{name: "no user", want: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/quota", nil)
+			if tt.user != "" {
+				req = req.WithContext(mw.WithUserID(req.Context(), tt.user))
+			}
+			w := httptest.NewRecorder()
+			h.Usage(w, req)
+
+			resp := w.Result()
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+			var usage []*models.QuotaUsage
+			require.NoError(t, json.Unmarshal(body, &usage))
+			used := make([]int, 0, len(usage))
+			for _, u := range usage {
+				used = append(used, u.Used)
+			}
+			require.Equal(t, tt.want, used)
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package quota
+
+import (
+	"context"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+)
+
+// Repository keeps counters of quotas, counters which are not stored yet are zero
+type Repository interface {
+	// Add adds n to all counters at once unless any of them goes over its limit, ok is false then and
+	// nothing is changed. Values of counters are returned either way, they don't go below zero.
+	// Negative n is always added.
+	Add(ctx context.Context, counters []*models.QuotaCounter, n int) (values []int, ok bool, err error)
+	// Get returns values of counters by keys
+	Get(ctx context.Context, keys []string) ([]int, error)
+	// DeleteExpired drops counters expired before time
+	DeleteExpired(context.Context, time.Time) (int, error)
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+)
+
+// quotasSuffix is appended to storage filename for quota counters
+const quotasSuffix = ".quotas"
+
+// FileRepository keeps counters in memory, they are rewritten as a whole next to storage file
+// unless filename is empty
+type FileRepository struct {
+	filename string
+	counters map[string]*models.QuotaCounter
+	logger   *logger.Logger
+	mu       sync.Mutex
+}
+
+func NewFileRepository(filename string, logger *logger.Logger) (*FileRepository, error) {
+	fr := &FileRepository{
+		filename: filename,
+		counters: make(map[string]*models.QuotaCounter),
+		logger:   logger,
+	}
+	if filename == "" {
+		return fr, nil
+	}
+
+	if err := fr.init(); err != nil {
+		logger.Errorf("failed to init quotas %v: %v", filename, err)
+		return nil, fmt.Errorf("failed to init quotas: %w", err)
+	}
+
+	return fr, nil
+}
+
+func (fr *FileRepository) Add(ctx context.Context, counters []*models.QuotaCounter, n int) ([]int, bool, error) {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	values := make([]int, len(counters))
+	for i, c := range counters {
+		if stored, ok := fr.counters[c.Key]; ok {
+			values[i] = stored.Value
+		}
+		if n > 0 && c.Limit > 0 && values[i]+n > c.Limit {
+			return fr.values(counters), false, nil
+		}
+	}
+
+	prev := make(map[string]*models.QuotaCounter, len(counters))
+	for i, c := range counters {
+		prev[c.Key] = fr.counters[c.Key]
+		values[i] = max(values[i]+n, 0)
+		// zero counter is the same as missing one
+		if values[i] == 0 {
+			delete(fr.counters, c.Key)
+		} else {
+			fr.counters[c.Key] = &models.QuotaCounter{Key: c.Key, Value: values[i], ExpiresAt: c.ExpiresAt}
+		}
+	}
+
+	if err := fr.save(); err != nil {
+		for key, c := range prev {
+			if c == nil {
+				delete(fr.counters, key)
+			} else {
+				fr.counters[key] = c
+			}
+		}
+		fr.logger.Errorf("failed to save quotas: %v", err)
+		return nil, false, fmt.Errorf("failed to save quotas: %w", err)
+	}
+
+	return values, true, nil
+}
+
+func (fr *FileRepository) Get(ctx context.Context, keys []string) ([]int, error) {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	values := make([]int, len(keys))
+	for i, key := range keys {
+		if c, ok := fr.counters[key]; ok {
+			values[i] = c.Value
+		}
+	}
+	return values, nil
+}
+
+func (fr *FileRepository) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	deleted := 0
+	for key, c := range fr.counters {
+		if !c.ExpiresAt.IsZero() && c.ExpiresAt.Before(before) {
+			delete(fr.counters, key)
+			deleted++
+		}
+	}
+	if deleted == 0 {
+		return 0, nil
+	}
+
+	// dropped counters are only garbage, so they are not restored on failure
+	if err := fr.save(); err != nil {
+		fr.logger.Errorf("failed to save quotas: %v", err)
+		return 0, fmt.Errorf("failed to save quotas: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// values returns stored values of counters
+func (fr *FileRepository) values(counters []*models.QuotaCounter) []int {
+	values := make([]int, len(counters))
+	for i, c := range counters {
+		if stored, ok := fr.counters[c.Key]; ok {
+			values[i] = stored.Value
+		}
+	}
+	return values
+}
+
+func (fr *FileRepository) init() error {
+	data, err := os.ReadFile(fr.filename + quotasSuffix)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var counters []*models.QuotaCounter
+	if err := json.Unmarshal(data, &counters); err != nil {
+		return fmt.Errorf("malformed quotas: %w", err)
+	}
+	for _, c := range counters {
+		fr.counters[c.Key] = c
+	}
+
+	return nil
+}
+
+func (fr *FileRepository) save() error {
+	if fr.filename == "" {
+		return nil
+	}
+
+	counters := make([]*models.QuotaCounter, 0, len(fr.counters))
+	for _, c := range fr.counters {
+		counters = append(counters, c)
+	}
+	slices.SortFunc(counters, func(a, b *models.QuotaCounter) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	data, err := json.Marshal(counters)
+	if err != nil {
+		return err
+	}
+
+	name := fr.filename + quotasSuffix
+	tmp := name + ".tmp"
+	if err := os.WriteFile(tmp, data, 0666); err != nil {
+		return err
+	}
+	return os.Rename(tmp, name)
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileRepository_Counters(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "db.json")
+	now := time.Now().UTC().Truncate(time.Second)
+
+	fr, err := NewFileRepository(filename, l)
+	require.NoError(t, err)
+
+	day := &models.QuotaCounter{Key: "day", Limit: 3, ExpiresAt: now.Add(time.Hour)}
+	active := &models.QuotaCounter{Key: "active", Limit: 5}
+
+	tests := []struct {
+		name   string
+		n      int
+		want   []int
+		wantOK bool
+	}{
+		{name: "counters are created", n: 2, want: []int{2, 2}, wantOK: true},
+		{name: "one counter goes over limit", n: 2, want: []int{2, 2}},
+		{name: "up to limit", n: 1, want: []int{3, 3}, wantOK: true},
+		{name: "negative is always added", n: -1, want: []int{2, 2}, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, ok, err := fr.Add(ctx, []*models.QuotaCounter{day, active}, tt.n)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantOK, ok)
+			require.Equal(t, tt.want, values)
+		})
+	}
+
+	// counters don't go below zero
+	values, ok, err := fr.Add(ctx, []*models.QuotaCounter{active}, -5)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []int{0}, values)
+
+	reopened, err := NewFileRepository(filename, l)
+	require.NoError(t, err)
+
+	values, err = reopened.Get(ctx, []string{"day", "active", "missing"})
+	require.NoError(t, err)
+	require.Equal(t, []int{2, 0, 0}, values)
+
+	deleted, err := reopened.DeleteExpired(ctx, now.Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 1, deleted)
+
+	values, err = reopened.Get(ctx, []string{"day"})
+	require.NoError(t, err)
+	require.Equal(t, []int{0}, values)
+}
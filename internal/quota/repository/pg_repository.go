@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/MatiXxD/url-shortener/pkg/postgres"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresRepository changes counters on primary, rows of counters are locked while they are checked
+type PostgresRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewPostgresRepository(db *postgres.DB, logger *logger.Logger) *PostgresRepository {
+	return &PostgresRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (pr *PostgresRepository) Add(ctx context.Context, counters []*models.QuotaCounter, n int) ([]int, bool, error) {
+	tx, err := pr.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to add to quotas: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// rows are locked in order of keys, so concurrent requests don't deadlock
+	sorted := slices.Clone(counters)
+	slices.SortFunc(sorted, func(a, b *models.QuotaCounter) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	batch := &pgx.Batch{}
+	keys := make([]string, 0, len(sorted))
+	for _, c := range sorted {
+		var expiresAt *time.Time
+		if !c.ExpiresAt.IsZero() {
+			expiresAt = &c.ExpiresAt
+		}
+		batch.Queue(`
+			INSERT INTO quota_counter (key, value, expires_at) VALUES ($1, 0, $2)
+			ON CONFLICT (key) DO NOTHING
+		`, c.Key, expiresAt)
+		keys = append(keys, c.Key)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return nil, false, fmt.Errorf("failed to add to quotas: %w", err)
+	}
+
+	values, err := counterValues(ctx, tx, `
+		SELECT key, value FROM quota_counter WHERE key = ANY($1) ORDER BY key FOR UPDATE
+	`, keys)
+	if err != nil {
+		return nil, false, err
+	}
+
+	res := make([]int, len(counters))
+	for i, c := range counters {
+		res[i] = values[c.Key]
+		if n > 0 && c.Limit > 0 && res[i]+n > c.Limit {
+			return res, false, nil
+		}
+	}
+
+	values, err = counterValues(ctx, tx, `
+		UPDATE quota_counter SET value = GREATEST(value + $2, 0) WHERE key = ANY($1) RETURNING key, value
+	`, keys, n)
+	if err != nil {
+		return nil, false, err
+	}
+	for i, c := range counters {
+		res[i] = values[c.Key]
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, false, fmt.Errorf("failed to add to quotas: %w", err)
+	}
+
+	return res, true, nil
+}
+
+func (pr *PostgresRepository) Get(ctx context.Context, keys []string) ([]int, error) {
+	values, err := counterValues(ctx, pr.db.Pool, `SELECT key, value FROM quota_counter WHERE key = ANY($1)`, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]int, len(keys))
+	for i, key := range keys {
+		res[i] = values[key]
+	}
+	return res, nil
+}
+
+func (pr *PostgresRepository) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	tag, err := pr.db.Pool.Exec(ctx, `DELETE FROM quota_counter WHERE expires_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired quotas: %w", err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// counterValues runs query which returns keys and values of counters
+func counterValues(ctx context.Context, q querier, query string, args ...any) (map[string]int, error) {
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read quotas: %w", err)
+	}
+	defer rows.Close()
+
+	values := make(map[string]int)
+	for rows.Next() {
+		var (
+			key   string
+			value int
+		)
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan quota: %w", err)
+		}
+		values[key] = value
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read quotas: %w", err)
+	}
+
+	return values, nil
+}
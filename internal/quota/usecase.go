@@ -0,0 +1,42 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+)
+
+var ErrExceeded = errors.New("quota exceeded")
+
+// ExceededError tells which quota rejects request, RetryAfter is zero for quotas which are not reset
+type ExceededError struct {
+	Usage      *models.QuotaUsage
+	RetryAfter time.Duration
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("%s quota of %s %s is exceeded: %d of %d used", e.Usage.Quota, e.Usage.Scope,
+		e.Usage.Subject, e.Usage.Used, e.Usage.Limit)
+}
+
+func (e *ExceededError) Unwrap() error {
+	return ErrExceeded
+}
+
+// Usecase enforces quotas of request subjects: its user, api key and workspace are taken from context
+type Usecase interface {
+	// CheckBatch rejects batch larger than allowed
+	CheckBatch(ctx context.Context, size int) error
+	// Reserve counts n links about to be created, ExceededError tells which quota doesn't allow them.
+	// Nil reservation means request has no quotas.
+	Reserve(ctx context.Context, n int) (*models.QuotaReservation, error)
+	// Cancel returns n links which were not created to reservation counters
+	Cancel(ctx context.Context, r *models.QuotaReservation, n int)
+	// Release frees active link which was deleted, keys are active counters it was created with
+	Release(ctx context.Context, keys []string)
+	// Usage returns counted quotas of request subjects
+	Usage(context.Context) ([]*models.QuotaUsage, error)
+}
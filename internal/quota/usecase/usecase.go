@@ -0,0 +1,233 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/config"
+	mw "github.com/MatiXxD/url-shortener/internal/middleware"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/quota"
+	"github.com/MatiXxD/url-shortener/internal/workspace"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+)
+
+// cleanupInterval is how often counters of past days are dropped
+const cleanupInterval = time.Hour
+
+type QuotaUsecase struct {
+	repo   quota.Repository
+	cfg    config.QuotaConfig
+	logger *logger.Logger
+	now    func() time.Time
+}
+
+func NewQuotaUsecase(r quota.Repository, cfg *config.ServiceConfig, l *logger.Logger) *QuotaUsecase {
+	return &QuotaUsecase{
+		repo:   r,
+		cfg:    cfg.Quotas,
+		logger: l,
+		now:    time.Now,
+	}
+}
+
+// subject is user, api key or workspace of request
+type subject struct {
+	scope  string
+	id     string
+	limits config.QuotaLimits
+}
+
+// counter is counter of quota of subject
+type counter struct {
+	subject
+	quota   string
+	counter *models.QuotaCounter
+}
+
+func (c *counter) usage(used int) *models.QuotaUsage {
+	u := &models.QuotaUsage{
+		Scope:   c.scope,
+		Subject: c.id,
+		Quota:   c.quota,
+		Limit:   c.counter.Limit,
+		Used:    used,
+	}
+	if !c.counter.ExpiresAt.IsZero() {
+		resetAt := c.counter.ExpiresAt
+		u.ResetAt = &resetAt
+	}
+	return u
+}
+
+// CheckBatch rejects batch larger than batch size of any subject
+func (qu *QuotaUsecase) CheckBatch(ctx context.Context, size int) error {
+	for _, s := range qu.subjects(ctx) {
+		if limit := s.limits.BatchSize; limit > 0 && size > limit {
+			return &quota.ExceededError{Usage: &models.QuotaUsage{
+				Scope:   s.scope,
+				Subject: s.id,
+				Quota:   models.QuotaBatchSize,
+				Limit:   limit,
+				Used:    size,
+			}}
+		}
+	}
+	return nil
+}
+
+// Reserve takes n links from daily and active quotas of every subject at once. Reservation keeps
+// counters, so links are returned to them even if subjects of request or day change.
+func (qu *QuotaUsecase) Reserve(ctx context.Context, n int) (*models.QuotaReservation, error) {
+	counters := qu.counters(ctx)
+	if len(counters) == 0 || n <= 0 {
+		return nil, nil
+	}
+
+	values, ok, err := qu.repo.Add(ctx, quotaCounters(counters), n)
+	if err != nil {
+		qu.logger.Errorf("cannot reserve %d links: %v", n, err)
+		return nil, fmt.Errorf("cannot reserve quota: %w", err)
+	}
+	if ok {
+		r := &models.QuotaReservation{Counters: quotaCounters(counters)}
+		for _, c := range counters {
+			if c.quota == models.QuotaActiveLinks {
+				r.Active = append(r.Active, c.counter.Key)
+			}
+		}
+		return r, nil
+	}
+
+	for i, c := range counters {
+		if values[i]+n > c.counter.Limit {
+			e := &quota.ExceededError{Usage: c.usage(values[i])}
+			if c.quota == models.QuotaLinksPerDay {
+				e.RetryAfter = c.counter.ExpiresAt.Sub(qu.now())
+			}
+			return nil, e
+		}
+	}
+	return nil, fmt.Errorf("%w: %d links", quota.ErrExceeded, n)
+}
+
+// Cancel returns links to counters they were reserved from, failure is only logged
+func (qu *QuotaUsecase) Cancel(ctx context.Context, r *models.QuotaReservation, n int) {
+	if r == nil {
+		return
+	}
+	qu.add(ctx, r.Counters, -n)
+}
+
+// Release returns deleted link to active quotas it was counted in, daily ones stay used
+func (qu *QuotaUsecase) Release(ctx context.Context, keys []string) {
+	counters := make([]*models.QuotaCounter, 0, len(keys))
+	for _, key := range keys {
+		counters = append(counters, &models.QuotaCounter{Key: key})
+	}
+	qu.add(ctx, counters, -1)
+}
+
+// Usage returns counted quotas of request, batch size isn't counted so it is not included
+func (qu *QuotaUsecase) Usage(ctx context.Context) ([]*models.QuotaUsage, error) {
+	counters := qu.counters(ctx)
+	keys := make([]string, 0, len(counters))
+	for _, c := range counters {
+		keys = append(keys, c.counter.Key)
+	}
+
+	values, err := qu.repo.Get(ctx, keys)
+	if err != nil {
+		qu.logger.Errorf("cannot get quotas: %v", err)
+		return nil, fmt.Errorf("cannot get quotas: %w", err)
+	}
+
+	res := make([]*models.QuotaUsage, 0, len(counters))
+	for i, c := range counters {
+		res = append(res, c.usage(values[i]))
+	}
+	return res, nil
+}
+
+// Run drops counters of past days until ctx is done
+func (qu *QuotaUsecase) Run(ctx context.Context) {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		deleted, err := qu.repo.DeleteExpired(ctx, qu.now().UTC())
+		if err != nil {
+			qu.logger.Errorf("cannot delete expired quotas: %v", err)
+			continue
+		}
+		if deleted != 0 {
+			qu.logger.Infof("deleted %d expired quota counters", deleted)
+		}
+	}
+}
+
+func (qu *QuotaUsecase) add(ctx context.Context, counters []*models.QuotaCounter, n int) {
+	if len(counters) == 0 || n == 0 {
+		return
+	}
+	// links are already created or deleted, so counters are changed even if request is cancelled
+	if _, _, err := qu.repo.Add(context.WithoutCancel(ctx), counters, n); err != nil {
+		qu.logger.Errorf("cannot add %d to quotas: %v", n, err)
+	}
+}
+
+// subjects returns user, api key and workspace of request, default workspace has no quotas
+func (qu *QuotaUsecase) subjects(ctx context.Context) []subject {
+	var res []subject
+	if ip := mw.NewUserIP(ctx); ip != "" {
+		// user without cookie is new on every request, so its client ip gets quotas of user
+		res = append(res, subject{scope: models.QuotaScopeIP, id: ip, limits: qu.cfg.User})
+	} else if id := mw.GetUserID(ctx); id != "" {
+		res = append(res, subject{scope: models.QuotaScopeUser, id: id, limits: qu.cfg.User})
+	}
+	if k := mw.GetAPIKey(ctx); k != nil {
+		res = append(res, subject{scope: models.QuotaScopeAPIKey, id: k.ID, limits: qu.cfg.APIKey})
+	}
+	if id := workspace.IDFrom(ctx); id != "" {
+		res = append(res, subject{scope: models.QuotaScopeWorkspace, id: id, limits: qu.cfg.Workspace})
+	}
+	return res
+}
+
+// counters returns counters of enabled quotas of request subjects, daily ones are counters of today
+func (qu *QuotaUsecase) counters(ctx context.Context) []*counter {
+	day := qu.now().UTC().Truncate(24 * time.Hour)
+
+	var res []*counter
+	for _, s := range qu.subjects(ctx) {
+		if limit := s.limits.LinksPerDay; limit > 0 {
+			res = append(res, &counter{subject: s, quota: models.QuotaLinksPerDay, counter: &models.QuotaCounter{
+				Key:       fmt.Sprintf("%s:%s:%s:%s", models.QuotaLinksPerDay, s.scope, s.id, day.Format(time.DateOnly)),
+				Limit:     limit,
+				ExpiresAt: day.Add(24 * time.Hour),
+			}})
+		}
+		if limit := s.limits.ActiveLinks; limit > 0 {
+			res = append(res, &counter{subject: s, quota: models.QuotaActiveLinks, counter: &models.QuotaCounter{
+				Key:   fmt.Sprintf("%s:%s:%s", models.QuotaActiveLinks, s.scope, s.id),
+				Limit: limit,
+			}})
+		}
+	}
+	return res
+}
+
+func quotaCounters(counters []*counter) []*models.QuotaCounter {
+	res := make([]*models.QuotaCounter, 0, len(counters))
+	for _, c := range counters {
+		res = append(res, c.counter)
+	}
+	return res
+}
@@ -0,0 +1,193 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/config"
+	mw "github.com/MatiXxD/url-shortener/internal/middleware"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/quota"
+	"github.com/MatiXxD/url-shortener/internal/quota/repository"
+	"github.com/MatiXxD/url-shortener/internal/workspace"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var l *logger.Logger
+
+func TestMain(m *testing.M) {
+	zl, err := zap.NewDevelopment()
+	if err != nil {
+		panic(err)
+	}
+
+	l = &logger.Logger{SugaredLogger: zl.Sugar()}
+
+	os.Exit(m.Run())
+}
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func newTestUsecase(t *testing.T, limits config.QuotaConfig) (*QuotaUsecase, *clock) {
+	r, err := repository.NewFileRepository("", l)
+	require.NoError(t, err)
+
+	cfg := config.Default()
+	cfg.Quotas = limits
+	qu := NewQuotaUsecase(r, cfg, l)
+	c := &clock{now: time.Date(2026, 10, 19, 22, 0, 0, 0, time.UTC)}
+	qu.now = c.Now
+
+	return qu, c
+}
+
+func TestQuotaUsecase_Reserve(t *testing.T) {
+	qu, c := newTestUsecase(t, config.QuotaConfig{
+		User:      config.QuotaLimits{LinksPerDay: 3},
+		Workspace: config.QuotaLimits{ActiveLinks: 4},
+	})
+	ctx := workspace.With(mw.WithUserID(context.Background(), "u1"), &models.Workspace{ID: "team"})
+
+	r, err := qu.Reserve(ctx, 2)
+	require.NoError(t, err)
+	qu.Cancel(ctx, r, 1)
+	_, err = qu.Reserve(ctx, 2)
+	require.NoError(t, err)
+
+	var exceeded *quota.ExceededError
+	_, err = qu.Reserve(ctx, 1)
+	require.True(t, errors.As(err, &exceeded), err)
+	require.Equal(t, models.QuotaLinksPerDay, exceeded.Usage.Quota)
+	require.Equal(t, 3, exceeded.Usage.Used)
+	require.Equal(t, 2*time.Hour, exceeded.RetryAfter)
+
+	// daily quota is reset, active one is shared by workspace and freed by deleted links
+	c.now = c.now.Add(2 * time.Hour)
+	other := workspace.With(mw.WithUserID(context.Background(), "u2"), &models.Workspace{ID: "team"})
+	created, err := qu.Reserve(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, []string{"active_links:workspace:team"}, created.Active)
+	_, err = qu.Reserve(other, 1)
+	require.True(t, errors.As(err, &exceeded), err)
+	require.Equal(t, models.QuotaActiveLinks, exceeded.Usage.Quota)
+	require.Equal(t, models.QuotaScopeWorkspace, exceeded.Usage.Scope)
+	require.Zero(t, exceeded.RetryAfter)
+
+	// link is freed in counters it was created with, whoever deletes it
+	qu.Release(context.Background(), created.Active)
+	_, err = qu.Reserve(other, 1)
+	require.NoError(t, err)
+
+	usage, err := qu.Usage(ctx)
+	require.NoError(t, err)
+	require.Len(t, usage, 2)
+	require.Equal(t, 1, usage[0].Used)
+	require.NotNil(t, usage[0].ResetAt)
+	require.Equal(t, 4, usage[1].Used)
+
+	// requests without subjects are not limited
+	r, err = qu.Reserve(context.Background(), 100)
+	require.NoError(t, err)
+	require.Nil(t, r)
+}
+
+func TestQuotaUsecase_CancelNextDay(t *testing.T) {
+	qu, c := newTestUsecase(t, config.QuotaConfig{User: config.QuotaLimits{LinksPerDay: 2}})
+	ctx := mw.WithUserID(context.Background(), "u1")
+
+	c.now = c.now.Add(time.Hour + 59*time.Minute)
+	r, err := qu.Reserve(ctx, 2)
+	require.NoError(t, err)
+
+	// links reserved before midnight are returned to the day they were taken from
+	c.now = c.now.Add(2 * time.Minute)
+	_, err = qu.Reserve(ctx, 2)
+	require.NoError(t, err)
+	qu.Cancel(ctx, r, 2)
+
+	usage, err := qu.Usage(ctx)
+	require.NoError(t, err)
+	require.Len(t, usage, 1)
+	require.Equal(t, 2, usage[0].Used)
+}
+
+func TestQuotaUsecase_NewUsers(t *testing.T) {
+	qu, _ := newTestUsecase(t, config.QuotaConfig{User: config.QuotaLimits{LinksPerDay: 2}})
+
+	var keys []string
+	h := mw.AuthMiddleware([]byte("secret"), "token", time.Hour, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res, err := qu.Reserve(r.Context(), 1)
+		if err != nil {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		keys = append(keys, res.Counters[0].Key)
+	}))
+
+	// client dropping cookie gets new user every time, but its ip is counted
+	codes := make([]int, 0, 3)
+	var cookie *http.Cookie
+	for range 3 {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", nil))
+		codes = append(codes, w.Code)
+		cookie = w.Result().Cookies()[0]
+	}
+	require.Equal(t, []int{http.StatusOK, http.StatusOK, http.StatusForbidden}, codes)
+	require.Equal(t, "links_per_day:ip:192.0.2.1:2026-10-19", keys[0])
+
+	// user with cookie has quotas of its own
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	r.AddCookie(cookie)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestQuotaUsecase_CheckBatch(t *testing.T) {
+	qu, _ := newTestUsecase(t, config.QuotaConfig{
+		User:   config.QuotaLimits{BatchSize: 10},
+		APIKey: config.QuotaLimits{BatchSize: 5},
+	})
+	ctx := mw.WithUserID(context.Background(), "u1")
+	keyCtx := mw.WithAPIKey(ctx, &models.APIKey{ID: "k1"})
+
+	tests := []struct {
+		name  string
+		ctx   context.Context
+		size  int
+		scope string
+	}{
+		{name: "user", ctx: ctx, size: 10},
+		{name: "user over limit", ctx: ctx, size: 11, scope: models.QuotaScopeUser},
+		{name: "api key over limit", ctx: keyCtx, size: 6, scope: models.QuotaScopeAPIKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := qu.CheckBatch(tt.ctx, tt.size)
+			if tt.scope == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			var exceeded *quota.ExceededError
+			require.True(t, errors.As(err, &exceeded), err)
+			require.Equal(t, tt.scope, exceeded.Usage.Scope)
+			require.Equal(t, tt.size, exceeded.Usage.Used)
+		})
+	}
+}
@@ -1,13 +1,38 @@
 package server
 
 import (
+	"context"
+	"crypto/rand"
+	"net"
 	"net/http"
 
+	"github.com/MatiXxD/url-shortener/internal/apikey"
+	apikeyhandlers "github.com/MatiXxD/url-shortener/internal/apikey/handlers"
+	apikeyrepository "github.com/MatiXxD/url-shortener/internal/apikey/repository"
+	apikeyusecase "github.com/MatiXxD/url-shortener/internal/apikey/usecase"
+	"github.com/MatiXxD/url-shortener/internal/audit"
+	audithandlers "github.com/MatiXxD/url-shortener/internal/audit/handlers"
+	auditrepository "github.com/MatiXxD/url-shortener/internal/audit/repository"
+	auditusecase "github.com/MatiXxD/url-shortener/internal/audit/usecase"
 	mw "github.com/MatiXxD/url-shortener/internal/middleware"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/outbox"
+	"github.com/MatiXxD/url-shortener/internal/quota"
+	quotahandlers "github.com/MatiXxD/url-shortener/internal/quota/handlers"
+	quotarepository "github.com/MatiXxD/url-shortener/internal/quota/repository"
+	quotausecase "github.com/MatiXxD/url-shortener/internal/quota/usecase"
 	"github.com/MatiXxD/url-shortener/internal/url"
 	"github.com/MatiXxD/url-shortener/internal/url/handlers"
 	"github.com/MatiXxD/url-shortener/internal/url/repository"
 	"github.com/MatiXxD/url-shortener/internal/url/usecase"
+	"github.com/MatiXxD/url-shortener/internal/webhook"
+	webhookhandlers "github.com/MatiXxD/url-shortener/internal/webhook/handlers"
+	webhookrepository "github.com/MatiXxD/url-shortener/internal/webhook/repository"
+	webhookusecase "github.com/MatiXxD/url-shortener/internal/webhook/usecase"
+	"github.com/MatiXxD/url-shortener/internal/workspace"
+	workspacehandlers "github.com/MatiXxD/url-shortener/internal/workspace/handlers"
+	workspacerepository "github.com/MatiXxD/url-shortener/internal/workspace/repository"
+	workspaceusecase "github.com/MatiXxD/url-shortener/internal/workspace/usecase"
 	"github.com/MatiXxD/url-shortener/pkg/postgres"
 )
 
@@ -17,45 +42,216 @@ func (s *Server) BindRoutes() error {
 	var (
 		err error
 		r   url.Repository
+		wr  webhook.Repository
+		ar  audit.Repository
+		kr  apikey.Repository
+		wsr workspace.Repository
+		qr  quota.Repository
 	)
 
-	if s.cfg.DSN != "" {
-		db, err := postgres.New(s.cfg.DSN)
+	if s.cfg.Storage.DSN != "" {
+		db, err := postgres.NewCluster(postgres.Config{
+			Primary:       s.cfg.Storage.DSN,
+			Replicas:      s.cfg.Storage.ReplicaDSNs,
+			CheckInterval: s.cfg.Storage.ReplicaCheckInterval.Std(),
+			MaxLag:        s.cfg.Storage.ReplicaMaxLag.Std(),
+		})
 		if err != nil {
 			s.logger.Errorf("failed to connect to postgres: %v", err)
 			return err
 		}
 
-		r = repository.NewPostgresRepository(db, s.logger)
+		r = repository.NewPostgresRepository(db, s.cfg.Storage.CopyThreshold, s.logger)
+		wr = webhookrepository.NewPostgresRepository(db, s.logger)
+		ar = auditrepository.NewPostgresRepository(db, s.logger)
+		kr = apikeyrepository.NewPostgresRepository(db, s.logger)
+		wsr = workspacerepository.NewPostgresRepository(db, s.logger)
+		qr = quotarepository.NewPostgresRepository(db, s.logger)
 	} else {
-		r, err = repository.NewFileRepository(s.cfg.FilePath, s.logger)
+		fr, err := repository.NewFileRepository(s.cfg.Storage.FilePath, s.logger)
 		if err != nil {
 			s.logger.Errorf("failed to create repository: %v", err)
 			return err
 		}
+		// changes of links are recorded to audit log by the same repository
+		r, ar = fr, fr.Audit()
+		wr, err = webhookrepository.NewFileRepository(s.cfg.Storage.FilePath, s.logger)
+		if err != nil {
+			s.logger.Errorf("failed to create webhook repository: %v", err)
+			return err
+		}
+		kr, err = apikeyrepository.NewFileRepository(s.cfg.Storage.FilePath, s.logger)
+		if err != nil {
+			s.logger.Errorf("failed to create api key repository: %v", err)
+			return err
+		}
+		wsr, err = workspacerepository.NewFileRepository(s.cfg.Storage.FilePath, s.logger)
+		if err != nil {
+			s.logger.Errorf("failed to create workspace repository: %v", err)
+			return err
+		}
+		qr, err = quotarepository.NewFileRepository(s.cfg.Storage.FilePath, s.logger)
+		if err != nil {
+			s.logger.Errorf("failed to create quota repository: %v", err)
+			return err
+		}
 	}
 
+	wu := webhookusecase.NewWebhookUsecase(wr, s.cfg, s.logger)
+	wh := webhookhandlers.NewWebhookHandler(wu, s.logger)
+	go wu.Run(context.Background())
+
+	kh := apikeyhandlers.NewAPIKeyHandler(apikeyusecase.NewAPIKeyUsecase(kr, s.cfg, s.logger), s.logger)
+
+	au := auditusecase.NewAuditUsecase(ar, s.logger)
+	ah := audithandlers.NewAuditHandler(au, s.logger)
+
+	wsu := workspaceusecase.NewWorkspaceUsecase(wsr, s.cfg, s.logger)
+	wsh := workspacehandlers.NewWorkspaceHandler(wsu, s.logger)
+
+	qu := quotausecase.NewQuotaUsecase(qr, s.cfg, s.logger)
+	qh := quotahandlers.NewQuotaHandler(qu, s.logger)
+	go qu.Run(context.Background())
+
 	u := usecase.NewUrlUsecase(r, s.cfg, s.logger)
+	u.SetPublisher(wu)
+	u.SetWorkspaces(wsu)
+	u.SetQuotas(qu)
+	go u.RunClicks(context.Background())
 	h := handlers.NewUrlHandler(u, s.cfg, s.logger)
 
+	// webhooks get link.created from outbox, the rest of events is published by usecase
+	sinks, err := outbox.NewSinks(&s.cfg.Outbox)
+	if err != nil {
+		s.logger.Errorf("failed to create outbox sinks: %v", err)
+		return err
+	}
+	sinks = append(sinks, outbox.NewPublisherSink("webhooks", wu))
+	go outbox.NewRelay(r, s.cfg, s.logger, sinks...).Run(context.Background())
+
 	logMiddleware := func(next http.Handler) http.Handler {
 		return mw.LogMiddleware(s.logger, next)
 	}
 
+	limitMiddleware := func(next http.Handler) http.Handler {
+		return mw.LimitBodyMiddleware(s.cfg.Limits.MaxBodySize, next)
+	}
+
+	secret, err := s.authSecret()
+	if err != nil {
+		s.logger.Errorf("failed to create auth secret: %v", err)
+		return err
+	}
+	authMiddleware := func(next http.Handler) http.Handler {
+		return mw.AuthMiddleware(secret, s.cfg.Auth.CookieName, s.cfg.Auth.TokenTTL.Std(), next)
+	}
+
+	var subnet *net.IPNet
+	if s.cfg.TrustedSubnet != "" {
+		_, subnet, _ = net.ParseCIDR(s.cfg.TrustedSubnet) // validated in config
+	}
+	trustedMiddleware := func(next http.Handler) http.Handler {
+		return mw.TrustedSubnetMiddleware(subnet, next)
+	}
+	keyOrTrustedMiddleware := func(next http.Handler) http.Handler {
+		return mw.KeyOrTrustedMiddleware(subnet, next)
+	}
+	adminMiddleware := func(next http.Handler) http.Handler {
+		return mw.AdminMiddleware(s.cfg.Auth.AdminToken, next)
+	}
+
+	proxies := make([]*net.IPNet, 0, len(s.cfg.TrustedProxies))
+	for _, proxy := range s.cfg.TrustedProxies {
+		_, ipNet, _ := net.ParseCIDR(proxy) // validated in config
+		proxies = append(proxies, ipNet)
+	}
+	realIPMiddleware := func(next http.Handler) http.Handler {
+		return mw.RealIPMiddleware(proxies, next)
+	}
+
 	middlewares := []middleware{
+		realIPMiddleware,
 		mw.RequestIdMiddleware,
 		logMiddleware,
 		mw.CompressMiddleware,
+		limitMiddleware,
+		kh.Authenticate,
+		authMiddleware,
+		wsh.Resolve,
+		mw.ActorMiddleware,
 	}
 
 	for _, m := range middlewares {
 		s.mux.Use(m)
 	}
 
-	s.mux.Post("/", h.ReduceURL)
+	// requests with api key reach route only if key has scope of it
+	scope := func(name string) middleware {
+		return func(next http.Handler) http.Handler {
+			return mw.ScopeMiddleware(name, next)
+		}
+	}
+	linksWrite := s.mux.With(scope(models.ScopeLinksWrite))
+	linksRead := s.mux.With(scope(models.ScopeLinksRead))
+
+	linksWrite.Post("/", h.ReduceURL)
 	s.mux.Get("/{url}", h.GetURL)
-	s.mux.Post("/api/shorten", h.ShortenURL)
-	s.mux.Post("/api/shorten/batch", h.BatchReduceURL)
+	s.mux.Head("/{url}", h.GetURL)
+	s.mux.Post("/{url}", h.GetURL)
+	s.mux.Get("/{url}/*", h.GetURL)
+	s.mux.Head("/{url}/*", h.GetURL)
+	s.mux.Post("/{url}/*", h.GetURL)
+	s.mux.Get("/{url}/qr", h.QRCode)
+	linksWrite.Post("/api/shorten", h.ShortenURL)
+	linksRead.Get("/api/urls", h.ListURLs)
+	linksWrite.Delete("/api/urls/{url}", h.DeleteURL)
+	linksWrite.Patch("/api/urls/{url}", h.UpdateURL)
+	linksRead.Get("/api/urls/{url}/history", h.History)
+	linksWrite.Post("/api/urls/{url}/rollback", h.Rollback)
+	linksWrite.Post("/api/shorten/batch", h.BatchReduceURL)
+
+	linksRead.Get("/api/quota", qh.Usage)
+
+	s.mux.Get("/api/domains", h.ListDomains)
+	s.mux.With(trustedMiddleware).Post("/api/domains", h.AddDomain)
+	s.mux.With(trustedMiddleware).Delete("/api/domains/{host}", h.DeleteDomain)
+
+	s.mux.With(scope(models.ScopeStatsRead), keyOrTrustedMiddleware).Get("/api/internal/stats", h.Stats)
+
+	s.mux.With(adminMiddleware).Get("/api/webhooks", wh.ListWebhooks)
+	s.mux.With(adminMiddleware).Post("/api/webhooks", wh.AddWebhook)
+	s.mux.With(adminMiddleware).Delete("/api/webhooks/{id}", wh.DeleteWebhook)
+	s.mux.With(adminMiddleware).Get("/api/webhooks/deliveries", wh.ListDeliveries)
+	s.mux.With(adminMiddleware).Post("/api/webhooks/deliveries/{id}/retry", wh.RetryDelivery)
+
+	s.mux.With(adminMiddleware).Get("/api/audit", ah.ListEntries)
+
+	s.mux.With(adminMiddleware).Get("/api/keys", kh.ListAPIKeys)
+	s.mux.With(adminMiddleware).Post("/api/keys", kh.AddAPIKey)
+	s.mux.With(adminMiddleware).Delete("/api/keys/{id}", kh.RevokeAPIKey)
+
+	s.mux.With(adminMiddleware).Get("/api/workspaces", wsh.ListWorkspaces)
+	s.mux.With(adminMiddleware).Post("/api/workspaces", wsh.AddWorkspace)
+	s.mux.With(adminMiddleware).Put("/api/workspaces/{id}", wsh.UpdateWorkspace)
+	s.mux.With(adminMiddleware).Get("/api/workspaces/{id}/members", wsh.ListMembers)
+	s.mux.With(adminMiddleware).Put("/api/workspaces/{id}/members/{user}", wsh.AddMember)
+	s.mux.With(adminMiddleware).Delete("/api/workspaces/{id}/members/{user}", wsh.RemoveMember)
 
 	return nil
 }
+
+// authSecret returns configured secret or random one, cookies won't survive restart then
+func (s *Server) authSecret() ([]byte, error) {
+	if s.cfg.Auth.Secret != "" {
+		return []byte(s.cfg.Auth.Secret), nil
+	}
+
+	s.logger.Warn("auth secret is not set, using random one")
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
@@ -1,6 +1,8 @@
 package server
 
 import (
+	"context"
+	"crypto/tls"
 	"net/http"
 
 	"github.com/MatiXxD/url-shortener/config"
@@ -23,6 +25,36 @@ func New(cfg *config.ServiceConfig, l *logger.Logger) *Server {
 }
 
 func (s *Server) Start() error {
-	s.logger.Info("Server running on %s\n", s.cfg.Addr)
-	return http.ListenAndServe(s.cfg.Addr, s.mux)
+	srv := &http.Server{
+		Addr:    s.cfg.Addr,
+		Handler: s.mux,
+	}
+
+	if !s.cfg.TLS.Enabled {
+		s.logger.Infof("Server running on %s", s.cfg.Addr)
+		return srv.ListenAndServe()
+	}
+
+	tlsCfg, err := newTLSConfig(context.Background(), s.cfg, s.logger)
+	if err != nil {
+		return err
+	}
+	srv.TLSConfig = tlsCfg
+
+	// empty map turns off automatic HTTP/2 upgrade
+	if !s.cfg.TLS.HTTP2 {
+		srv.TLSNextProto = make(map[string]func(*http.Server, *tls.Conn, http.Handler))
+	}
+
+	if addr := s.cfg.TLS.RedirectAddr; addr != "" {
+		go func() {
+			s.logger.Infof("Redirect to HTTPS running on %s", addr)
+			if err := http.ListenAndServe(addr, redirectHandler(s.cfg.Addr)); err != nil {
+				s.logger.Errorf("redirect listener stopped: %v", err)
+			}
+		}()
+	}
+
+	s.logger.Infof("Server running on %s with TLS", s.cfg.Addr)
+	return srv.ListenAndServeTLS("", "")
 }
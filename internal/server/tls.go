@@ -0,0 +1,285 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/config"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+)
+
+const selfSignedTTL = 365 * 24 * time.Hour
+
+// modern suites are used for TLS 1.2, TLS 1.3 suites are not configurable
+var modernCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+}
+
+var compatibleCipherSuites = append([]uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA,
+}, modernCipherSuites...)
+
+// newTLSConfig builds tls config from service config, cert reloading runs until ctx is done
+func newTLSConfig(ctx context.Context, cfg *config.ServiceConfig, l *logger.Logger) (*tls.Config, error) {
+	tlsCfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if cfg.TLS.MinVersion == config.TLSVersion13 {
+		tlsCfg.MinVersion = tls.VersionTLS13
+	}
+
+	switch cfg.TLS.CipherPolicy {
+	case config.CipherPolicyModern:
+		tlsCfg.CipherSuites = modernCipherSuites
+	case config.CipherPolicyCompatible:
+		tlsCfg.CipherSuites = compatibleCipherSuites
+	}
+
+	if cfg.TLS.HTTP2 {
+		tlsCfg.NextProtos = []string{"h2", "http/1.1"}
+	} else {
+		tlsCfg.NextProtos = []string{"http/1.1"}
+	}
+
+	if cfg.TLS.SelfSigned {
+		cert, err := generateSelfSigned(selfSignedHosts(cfg.BaseURL), selfSignedTTL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate self-signed certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	} else {
+		cr, err := newCertReloader(cfg.TLS.CertFile, cfg.TLS.KeyFile, l)
+		if err != nil {
+			return nil, err
+		}
+		if interval := cfg.TLS.ReloadInterval.Std(); interval > 0 {
+			go cr.watch(ctx, interval)
+		}
+		tlsCfg.GetCertificate = cr.GetCertificate
+	}
+
+	if cfg.TLS.ClientCAFile != "" {
+		pem, err := os.ReadFile(cfg.TLS.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("client CA file doesn't contain certificates")
+		}
+		tlsCfg.ClientCAs = pool
+
+		switch cfg.TLS.ClientAuth {
+		case config.ClientAuthRequest:
+			tlsCfg.ClientAuth = tls.VerifyClientCertIfGiven
+		case config.ClientAuthRequire:
+			tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+
+	return tlsCfg, nil
+}
+
+// certReloader serves certificate from files and reloads it when files change
+type certReloader struct {
+	certFile string
+	keyFile  string
+	logger   *logger.Logger
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+func newCertReloader(certFile, keyFile string, l *logger.Logger) (*certReloader, error) {
+	cr := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		logger:   l,
+	}
+
+	if err := cr.reload(); err != nil {
+		return nil, err
+	}
+
+	return cr, nil
+}
+
+func (cr *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+
+	return cr.cert, nil
+}
+
+func (cr *certReloader) reload() error {
+	modTime, err := cr.lastModified()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(cr.certFile, cr.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load certificate: %w", err)
+	}
+
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	cr.cert = &cert
+	cr.modTime = modTime
+
+	return nil
+}
+
+// lastModified returns latest modification time of cert and key files
+func (cr *certReloader) lastModified() (time.Time, error) {
+	var last time.Time
+
+	for _, name := range []string{cr.certFile, cr.keyFile} {
+		info, err := os.Stat(name)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to stat %s: %w", name, err)
+		}
+		if info.ModTime().After(last) {
+			last = info.ModTime()
+		}
+	}
+
+	return last, nil
+}
+
+func (cr *certReloader) changed() bool {
+	modTime, err := cr.lastModified()
+	if err != nil {
+		cr.logger.Errorf("failed to check certificate files: %v", err)
+		return false
+	}
+
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+
+	return !modTime.Equal(cr.modTime)
+}
+
+func (cr *certReloader) watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !cr.changed() {
+				continue
+			}
+			// keep serving old certificate if new one is broken
+			if err := cr.reload(); err != nil {
+				cr.logger.Errorf("failed to reload certificate: %v", err)
+				continue
+			}
+			cr.logger.Infof("certificate %s reloaded", cr.certFile)
+		}
+	}
+}
+
+func selfSignedHosts(baseURL string) []string {
+	hosts := []string{"localhost", "127.0.0.1", "::1"}
+
+	if u, err := url.Parse(baseURL); err == nil && u.Hostname() != "" {
+		hosts = append(hosts, u.Hostname())
+	}
+
+	return hosts
+}
+
+// generateSelfSigned creates ECDSA certificate for development use only
+func generateSelfSigned(hosts []string, ttl time.Duration) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"url-shortener dev"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(ttl),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else {
+			tmpl.DNSNames = append(tmpl.DNSNames, h)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}, nil
+}
+
+// redirectHandler sends plain http requests to the https listener
+func redirectHandler(httpsAddr string) http.Handler {
+	_, port, _ := net.SplitHostPort(httpsAddr)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(r.Host); err == nil {
+			host = h
+		}
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+
+		target := "https://" + host + r.URL.RequestURI()
+		http.Redirect(w, r, target, http.StatusPermanentRedirect)
+	})
+}
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/config"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func testLogger(t *testing.T) *logger.Logger {
+	zl, err := zap.NewDevelopment()
+	require.NoError(t, err)
+	return &logger.Logger{SugaredLogger: zl.Sugar()}
+}
+
+func writeKeyPair(t *testing.T, dir string, hosts ...string) (string, string) {
+	cert, err := generateSelfSigned(hosts, time.Hour)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600))
+
+	return certFile, keyFile
+}
+
+func TestNewTLSConfig_SelfSignedHTTP2(t *testing.T) {
+	cfg := config.Default()
+	cfg.TLS.Enabled = true
+	cfg.TLS.SelfSigned = true
+	cfg.TLS.MinVersion = config.TLSVersion13
+
+	tlsCfg, err := newTLSConfig(context.Background(), cfg, testLogger(t))
+	require.NoError(t, err)
+	require.Equal(t, uint16(tls.VersionTLS13), tlsCfg.MinVersion)
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Proto))
+	}))
+	ts.TLS = tlsCfg
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	defer ts.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(tlsCfg.Certificates[0].Leaf)
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{RootCAs: pool},
+			ForceAttemptHTTP2: true,
+		},
+	}
+
+	resp, err := client.Get(ts.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 2, resp.ProtoMajor)
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeKeyPair(t, dir, "first.local")
+
+	cr, err := newCertReloader(certFile, keyFile, testLogger(t))
+	require.NoError(t, err)
+
+	cert, err := cr.GetCertificate(nil)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	require.Equal(t, []string{"first.local"}, leaf.DNSNames)
+
+	// broken files must not replace working certificate
+	require.NoError(t, os.WriteFile(certFile, []byte("garbage"), 0600))
+	require.Error(t, cr.reload())
+
+	writeKeyPair(t, dir, "second.local")
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, future, future))
+	require.True(t, cr.changed())
+	require.NoError(t, cr.reload())
+	require.False(t, cr.changed())
+
+	cert, err = cr.GetCertificate(nil)
+	require.NoError(t, err)
+	leaf, err = x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	require.Equal(t, []string{"second.local"}, leaf.DNSNames)
+}
+
+func TestRedirectHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		httpsAddr string
+		target    string
+		want      string
+	}{
+		{
+			name:      "default https port",
+			httpsAddr: ":443",
+			target:    "http://short.io:80/abc?x=1",
+			want:      "https://short.io/abc?x=1",
+		},
+		{
+			name:      "custom https port",
+			httpsAddr: ":8443",
+			target:    "http://short.io/abc",
+			want:      "https://short.io:8443/abc",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			redirectHandler(tt.httpsAddr).ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			require.Equal(t, http.StatusPermanentRedirect, w.Code)
+			require.Equal(t, tt.want, w.Header().Get("Location"))
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package url
+
+import (
+	"context"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+)
+
+// Publisher is told about link events after they are saved
+type Publisher interface {
+	Publish(context.Context, ...*models.Event) error
+}
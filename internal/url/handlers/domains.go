@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	mw "github.com/MatiXxD/url-shortener/internal/middleware"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/url/usecase"
+	"github.com/go-chi/chi/v5"
+	"github.com/mailru/easyjson"
+)
+
+// requestDomain picks short domain of new links: explicit one or the one request came to
+func (uh *UrlHandler) requestDomain(r *http.Request, explicit string) string {
+	if explicit != "" {
+		return explicit
+	}
+	return uh.urlUsecase.ResolveDomain(r.Context(), r.Host)
+}
+
+func (uh *UrlHandler) ListDomains(w http.ResponseWriter, r *http.Request) {
+	logger := uh.logger
+	reqID := mw.GetRequestID(r.Context())
+	if reqID != "" {
+		logger = uh.logger.With("request_id", reqID)
+	}
+
+	domains, err := uh.urlUsecase.ListDomains(r.Context())
+	if err != nil {
+		logger.Errorf("can't list domains: %v", err)
+		http.Error(w, "Can't list domains", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := easyjson.MarshalToWriter(models.DomainList(domains), w); err != nil {
+		logger.Error("can't marshal response body")
+		http.Error(w, "Can't marshal response body", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (uh *UrlHandler) AddDomain(w http.ResponseWriter, r *http.Request) {
+	logger := uh.logger
+	reqID := mw.GetRequestID(r.Context())
+	if reqID != "" {
+		logger = uh.logger.With("request_id", reqID)
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if !strings.Contains(contentType, "application/json") {
+		logger.Error("request contains wrong content type")
+		http.Error(w, "Wrong content type", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	var req models.AddDomainReqBody
+	err := easyjson.UnmarshalFromReader(r.Body, &req)
+	if isTooLarge(err) {
+		logger.Error("request body is too large")
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		logger.Errorf("can't unmarshal request body: %v", err)
+		http.Error(w, "Can't read body", http.StatusBadRequest)
+		return
+	}
+
+	domain, err := uh.urlUsecase.AddDomain(r.Context(), req.BaseURL, req.Workspace)
+	if errors.Is(err, usecase.ErrInvalidDomain) {
+		logger.Errorf("invalid domain: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, usecase.ErrDomainExists) {
+		logger.Errorf("can't add domain: %v", err)
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		logger.Errorf("can't add domain: %v", err)
+		http.Error(w, "Can't add domain", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if _, err := easyjson.MarshalToWriter(domain, w); err != nil {
+		logger.Error("can't marshal response body")
+	}
+}
+
+func (uh *UrlHandler) DeleteDomain(w http.ResponseWriter, r *http.Request) {
+	logger := uh.logger
+	reqID := mw.GetRequestID(r.Context())
+	if reqID != "" {
+		logger = uh.logger.With("request_id", reqID)
+	}
+
+	host := chi.URLParam(r, "host")
+	err := uh.urlUsecase.DeleteDomain(r.Context(), host)
+	if errors.Is(err, usecase.ErrDomainNotFound) {
+		logger.Errorf("can't find domain %s", host)
+		http.Error(w, "Can't find domain", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, usecase.ErrConfiguredDomain) {
+		logger.Errorf("can't delete domain %s: %v", host, err)
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		logger.Errorf("can't delete domain %s: %v", host, err)
+		http.Error(w, "Can't delete domain", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -2,20 +2,29 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
+	"strconv"
 	"strings"
 
 	"github.com/MatiXxD/url-shortener/config"
 	mw "github.com/MatiXxD/url-shortener/internal/middleware"
 	"github.com/MatiXxD/url-shortener/internal/models"
 	"github.com/MatiXxD/url-shortener/internal/url"
+	"github.com/MatiXxD/url-shortener/internal/url/usecase"
 	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/MatiXxD/url-shortener/pkg/qr"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/mailru/easyjson"
 )
 
+// passwordHeader carries password of protected link for API clients
+const passwordHeader = "X-Link-Password"
+
 type UrlHandler struct {
 	urlUsecase url.Usecase
 	cfg        *config.ServiceConfig
@@ -46,16 +55,51 @@ func (uh *UrlHandler) ReduceURL(w http.ResponseWriter, r *http.Request) {
 
 	url, err := io.ReadAll(r.Body)
 	defer r.Body.Close()
+	if isTooLarge(err) {
+		logger.Error("request body is too large")
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
 	if err != nil {
 		logger.Error("request doesn't have body")
 		http.Error(w, "Can't read request body", http.StatusBadRequest)
 		return
 	}
 
+	var redirectCode int
+	if v := r.URL.Query().Get("redirect"); v != "" {
+		if redirectCode, err = strconv.Atoi(v); err != nil {
+			logger.Errorf("invalid redirect code: %s", v)
+			http.Error(w, "Invalid redirect code", http.StatusBadRequest)
+			return
+		}
+	}
+
 	shortURL, err := uh.urlUsecase.ReduceURL(r.Context(), &models.UrlDTO{
 		CorrelationID: uuid.New().String(),
 		OriginURL:     string(url),
+		UserID:        mw.GetUserID(r.Context()),
+		Preview:       isTrue(r.URL.Query().Get("preview")),
+		Password:      r.Header.Get(passwordHeader),
+		RedirectCode:  redirectCode,
+		Passthrough:   r.URL.Query().Get("passthrough"),
+		Title:         r.URL.Query().Get("title"),
+		Tags:          r.URL.Query()["tag"],
+		ShortDomain:   uh.requestDomain(r, r.URL.Query().Get("short_domain")),
 	})
+	if quotaExceeded(w, logger, err) {
+		return
+	}
+	if isInvalidRequest(err) {
+		logger.Errorf("invalid request: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, usecase.ErrPasswordConflict) {
+		logger.Errorf("can't protect url: %v", err)
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
 	if err != nil {
 		logger.Error("can't create short URL")
 		http.Error(w, "Can't create short url", http.StatusInternalServerError)
@@ -75,6 +119,15 @@ func (uh *UrlHandler) BatchReduceURL(w http.ResponseWriter, r *http.Request) {
 	}
 
 	contentType := r.Header.Get("Content-Type")
+	if strings.Contains(contentType, mw.ContentTypeNDJSON) {
+		if isTrue(r.URL.Query().Get("atomic")) {
+			logger.Error("atomic stream is requested")
+			http.Error(w, "Atomic mode is not supported for ndjson", http.StatusBadRequest)
+			return
+		}
+		uh.streamReduceURL(w, r, logger)
+		return
+	}
 	if !strings.Contains(contentType, "application/json") {
 		logger.Error("request contains wrong content type")
 		http.Error(w, "Wrong content type", http.StatusUnsupportedMediaType)
@@ -82,20 +135,54 @@ func (uh *UrlHandler) BatchReduceURL(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var urls []*models.UrlDTO
-	if err := json.NewDecoder(r.Body).Decode(&urls); err != nil {
+	err := json.NewDecoder(r.Body).Decode(&urls)
+	if isTooLarge(err) {
+		logger.Error("request body is too large")
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
 		logger.Error("can't unmarshal request body")
 		http.Error(w, "Can't read body", http.StatusInternalServerError)
 		return
 	}
 
-	shortUrls, err := uh.urlUsecase.BatchReduceURL(r.Context(), urls)
-	if err != nil {
-		logger.Error("can't short all urls")
+	if max := uh.cfg.Limits.MaxBatchSize; max > 0 && len(urls) > max {
+		logger.Errorf("batch contains %d urls, limit is %d", len(urls), max)
+		http.Error(w, "Too many urls in batch", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	userID := mw.GetUserID(r.Context())
+	domain := uh.requestDomain(r, "")
+	for _, u := range urls {
+		u.UserID = userID
+		if u.ShortDomain == "" {
+			u.ShortDomain = domain
+		}
+	}
+
+	// results are sent for partial success and rejected atomic batch too
+	status := http.StatusOK
+	shortUrls, err := uh.urlUsecase.BatchReduceURL(r.Context(), urls, isTrue(r.URL.Query().Get("atomic")))
+	if quotaExceeded(w, logger, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, usecase.ErrSomeBatchShortenFailed):
+		logger.Errorf("batch is saved partially: %v", err)
+		status = http.StatusMultiStatus
+	case errors.Is(err, usecase.ErrBatchRejected):
+		logger.Errorf("atomic batch is rejected: %v", err)
+		status = http.StatusBadRequest
+	case err != nil:
+		logger.Errorf("can't short all urls: %v", err)
 		http.Error(w, "Can't create short urls", http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(shortUrls); err != nil {
 		logger.Error("can't marshal response body")
 		http.Error(w, "Can't marshal response body", http.StatusInternalServerError)
@@ -110,16 +197,142 @@ func (uh *UrlHandler) GetURL(w http.ResponseWriter, r *http.Request) {
 		logger = uh.logger.With("request_id", reqID)
 	}
 
+	// "/{code}+" and "?preview=1" ask for interstitial explicitly
 	shortURL := chi.URLParam(r, "url")
-	url, ok := uh.urlUsecase.GetURL(r.Context(), shortURL)
-	if !ok {
-		logger.Error("can't find url")
-		http.Error(w, "Can't find url", http.StatusBadRequest)
+	query := r.URL.Query()
+	preview := strings.HasSuffix(shortURL, "+") || isTrue(query.Get("preview"))
+	shortURL = strings.TrimSuffix(shortURL, "+")
+	query.Del("preview")
+
+	domain := uh.urlUsecase.ResolveDomain(r.Context(), r.Host)
+	url, err := uh.urlUsecase.GetURL(r.Context(), domain, shortURL)
+	if errors.Is(err, usecase.ErrURLNotFound) {
+		logger.Errorf("can't find url %s", shortURL)
+		http.Error(w, "Can't find url", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, usecase.ErrURLDeleted) {
+		logger.Errorf("url %s was deleted", shortURL)
+		http.Error(w, "Url was deleted", http.StatusGone)
+		return
+	}
+	if err != nil {
+		logger.Errorf("can't get url: %v", err)
+		http.Error(w, "Can't get url", http.StatusInternalServerError)
+		return
+	}
+
+	dest, err := uh.urlUsecase.Destination(url, extraPath(r), query)
+	if errors.Is(err, usecase.ErrURLNotFound) {
+		logger.Errorf("url %s doesn't pass path through", shortURL)
+		http.Error(w, "Can't find url", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		logger.Errorf("can't build destination: %v", err)
+		http.Error(w, "Can't get url", http.StatusInternalServerError)
+		return
+	}
+
+	if !uh.checkPassword(w, r, logger, url) {
+		return
+	}
+
+	// form is posted by browser, 303 makes it follow with GET
+	if r.Method == http.MethodPost {
+		uh.urlUsecase.Click(r.Context(), url, newClick(r))
+		http.Redirect(w, r, dest, http.StatusSeeOther)
+		return
+	}
+
+	if preview || uh.urlUsecase.NeedsPreview(url) {
+		uh.renderPreview(w, logger, url, dest)
 		return
 	}
 
-	w.Header().Set("Location", url)
-	w.WriteHeader(http.StatusTemporaryRedirect)
+	// HEAD only checks link
+	if r.Method == http.MethodGet {
+		uh.urlUsecase.Click(r.Context(), url, newClick(r))
+	}
+	w.Header().Set("Location", dest)
+	w.WriteHeader(uh.urlUsecase.RedirectCode(url))
+}
+
+func newClick(r *http.Request) *models.Click {
+	return &models.Click{
+		Referer:   r.Referer(),
+		UserAgent: r.UserAgent(),
+	}
+}
+
+// checkPassword handles protected urls, it writes response and returns false if access is denied.
+// API clients send password in header, browsers get html form posting it back.
+func (uh *UrlHandler) checkPassword(w http.ResponseWriter, r *http.Request, logger *logger.Logger, url *models.URL) bool {
+	if url.PasswordHash == "" {
+		return true
+	}
+
+	password := r.Header.Get(passwordHeader)
+	fromForm := password == ""
+	if fromForm && r.Method == http.MethodPost {
+		password = r.PostFormValue("password")
+	}
+
+	err := uh.urlUsecase.CheckPassword(url, password)
+	if err == nil {
+		return true
+	}
+
+	var retryErr *usecase.RetryError
+	switch {
+	case errors.As(err, &retryErr):
+		logger.Errorf("password attempts exceeded for %s", url.ShortURL)
+		w.Header().Set("Retry-After", strconv.Itoa(int(retryErr.RetryAfter.Seconds())+1))
+		http.Error(w, "Too many attempts", http.StatusTooManyRequests)
+	case !fromForm:
+		http.Error(w, "Wrong password", http.StatusUnauthorized)
+	case errors.Is(err, usecase.ErrWrongPassword):
+		uh.renderPasswordForm(w, r, logger, url, "Wrong password")
+	default:
+		uh.renderPasswordForm(w, r, logger, url, "")
+	}
+
+	return false
+}
+
+func (uh *UrlHandler) renderPasswordForm(w http.ResponseWriter, r *http.Request, logger *logger.Logger, url *models.URL, msg string) {
+	page := passwordPage{
+		ShortURL: uh.urlUsecase.GetShortURL(url.ShortDomain, url.ShortURL),
+		Action:   r.URL.RequestURI(),
+		Error:    msg,
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusUnauthorized)
+	if err := templates.ExecuteTemplate(w, "password.html", page); err != nil {
+		logger.Errorf("can't render password page: %v", err)
+	}
+}
+
+func (uh *UrlHandler) renderPreview(w http.ResponseWriter, logger *logger.Logger, url *models.URL, dest string) {
+	page := previewPage{
+		ShortURL:    uh.urlUsecase.GetShortURL(url.ShortDomain, url.ShortURL),
+		Destination: dest,
+		Domain:      dest,
+		CreatedAt:   url.CreateAt,
+	}
+	if u, err := neturl.Parse(dest); err == nil && u.Host != "" {
+		page.Domain = u.Hostname()
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := templates.ExecuteTemplate(w, "preview.html", page); err != nil {
+		logger.Errorf("can't render preview page: %v", err)
+		http.Error(w, "Can't render preview page", http.StatusInternalServerError)
+		return
+	}
 }
 
 func (uh *UrlHandler) ShortenURL(w http.ResponseWriter, r *http.Request) {
@@ -137,7 +350,13 @@ func (uh *UrlHandler) ShortenURL(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var reqUrl models.ShortenURLReqBody
-	if err := easyjson.UnmarshalFromReader(r.Body, &reqUrl); err != nil {
+	err := easyjson.UnmarshalFromReader(r.Body, &reqUrl)
+	if isTooLarge(err) {
+		logger.Error("request body is too large")
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
 		logger.Error("can't unmarshal request body")
 		http.Error(w, "Can't read body", http.StatusInternalServerError)
 		return
@@ -146,7 +365,28 @@ func (uh *UrlHandler) ShortenURL(w http.ResponseWriter, r *http.Request) {
 	shortUrl, err := uh.urlUsecase.ReduceURL(r.Context(), &models.UrlDTO{
 		CorrelationID: uuid.New().String(),
 		OriginURL:     reqUrl.URL,
+		UserID:        mw.GetUserID(r.Context()),
+		Preview:       reqUrl.Preview,
+		Password:      reqUrl.Password,
+		RedirectCode:  reqUrl.RedirectCode,
+		Passthrough:   reqUrl.Passthrough,
+		Title:         reqUrl.Title,
+		Tags:          reqUrl.Tags,
+		ShortDomain:   uh.requestDomain(r, reqUrl.ShortDomain),
 	})
+	if quotaExceeded(w, logger, err) {
+		return
+	}
+	if isInvalidRequest(err) {
+		logger.Errorf("invalid request: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, usecase.ErrPasswordConflict) {
+		logger.Errorf("can't protect url: %v", err)
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
 	if err != nil {
 		logger.Error("can't create short URL")
 		http.Error(w, "Can't create short url", http.StatusInternalServerError)
@@ -156,6 +396,9 @@ func (uh *UrlHandler) ShortenURL(w http.ResponseWriter, r *http.Request) {
 	resp := &models.ShortenURLRespBody{
 		ShortURL: shortUrl,
 	}
+	if reqUrl.QR {
+		resp.QRURL = shortUrl + "/qr"
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if _, err := easyjson.MarshalToWriter(resp, w); err != nil {
@@ -164,3 +407,144 @@ func (uh *UrlHandler) ShortenURL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+func (uh *UrlHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	logger := uh.logger
+	reqID := mw.GetRequestID(r.Context())
+	if reqID != "" {
+		logger = uh.logger.With("request_id", reqID)
+	}
+
+	stats, err := uh.urlUsecase.GetStats(r.Context())
+	if err != nil {
+		logger.Errorf("can't get stats: %v", err)
+		http.Error(w, "Can't get stats", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := easyjson.MarshalToWriter(stats, w); err != nil {
+		logger.Error("can't marshal response body")
+		http.Error(w, "Can't marshal response body", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (uh *UrlHandler) QRCode(w http.ResponseWriter, r *http.Request) {
+	logger := uh.logger
+	reqID := mw.GetRequestID(r.Context())
+	if reqID != "" {
+		logger = uh.logger.With("request_id", reqID)
+	}
+
+	opts, err := parseQROptions(r.URL.Query())
+	if err != nil {
+		logger.Errorf("invalid qr options: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	shortURL := chi.URLParam(r, "url")
+	domain := uh.urlUsecase.ResolveDomain(r.Context(), r.Host)
+	img, err := uh.urlUsecase.GetQRCode(r.Context(), domain, shortURL, opts)
+	if errors.Is(err, usecase.ErrURLNotFound) {
+		logger.Error("can't find url")
+		http.Error(w, "Can't find url", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, usecase.ErrURLDeleted) {
+		logger.Errorf("url %s was deleted", shortURL)
+		http.Error(w, "Url was deleted", http.StatusGone)
+		return
+	}
+	if errors.Is(err, qr.ErrInvalidSize) {
+		logger.Errorf("can't render qr code: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		logger.Errorf("can't render qr code: %v", err)
+		http.Error(w, "Can't render qr code", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", opts.ContentType())
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	_, _ = w.Write(img)
+}
+
+// parseQROptions reads format, size, level, margin, fg and bg query params
+func parseQROptions(q neturl.Values) (qr.Options, error) {
+	opts := qr.DefaultOptions()
+	var errs []error
+
+	if v := q.Get("format"); v != "" {
+		opts.Format = qr.Format(strings.ToLower(v))
+	}
+	if v := q.Get("level"); v != "" {
+		opts.Level = strings.ToUpper(v)
+	}
+	if v := q.Get("size"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil {
+			errs = append(errs, qr.ErrInvalidSize)
+		}
+		opts.Size = size
+	}
+	if v := q.Get("margin"); v != "" {
+		margin, err := strconv.Atoi(v)
+		if err != nil {
+			errs = append(errs, qr.ErrInvalidMargin)
+		}
+		opts.Margin = margin
+	}
+	if v := q.Get("fg"); v != "" {
+		c, err := qr.ParseColor(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("fg: %w", err))
+		}
+		opts.Foreground = c
+	}
+	if v := q.Get("bg"); v != "" {
+		c, err := qr.ParseColor(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("bg: %w", err))
+		}
+		opts.Background = c
+	}
+
+	if len(errs) != 0 {
+		return opts, errors.Join(errs...)
+	}
+
+	return opts, opts.Validate()
+}
+
+func isTrue(v string) bool {
+	b, _ := strconv.ParseBool(v)
+	return b
+}
+
+// extraPath returns escaped part of path after short code for "/{url}/*" route
+func extraPath(r *http.Request) string {
+	path := strings.TrimPrefix(r.URL.EscapedPath(), "/")
+	if i := strings.IndexByte(path, '/'); i >= 0 {
+		return path[i+1:]
+	}
+	return ""
+}
+
+func isInvalidRequest(err error) bool {
+	return errors.Is(err, usecase.ErrInvalidURL) ||
+		errors.Is(err, usecase.ErrInvalidPassword) ||
+		errors.Is(err, usecase.ErrInvalidRedirectCode) ||
+		errors.Is(err, usecase.ErrInvalidPassthrough) ||
+		errors.Is(err, usecase.ErrUnknownDomain) ||
+		errors.Is(err, usecase.ErrInvalidDomain) ||
+		errors.Is(err, usecase.ErrDomainNotAllowed)
+}
+
+func isTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
@@ -4,11 +4,16 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"path"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	mw "github.com/MatiXxD/url-shortener/internal/middleware"
 	"github.com/MatiXxD/url-shortener/internal/models"
 	"github.com/MatiXxD/url-shortener/internal/url/repository"
 	"github.com/stretchr/testify/require"
@@ -71,7 +76,9 @@ func TestUrlHandler_ReduceURL(t *testing.T) {
 
 func TestUrlHandler_GetURL(t *testing.T) {
 	d := map[string]*models.URL{
-		"/url": {BaseURL: "/url", ShortURL: "AAAAAAAA"},
+		"/url":   {BaseURL: "/url", ShortURL: "AAAAAAAA"},
+		"/moved": {BaseURL: "/moved", ShortURL: "BBBBBBBB", RedirectCode: http.StatusMovedPermanently},
+		"/gone":  {BaseURL: "/gone", ShortURL: "CCCCCCCC", IsDeleted: true},
 	}
 	r := repository.NewMapRepository(d, l)
 	mux, err := runTestServer(r)
@@ -87,6 +94,7 @@ func TestUrlHandler_GetURL(t *testing.T) {
 
 	tests := []struct {
 		name    string
+		method  string
 		body    []byte
 		url     string
 		isError bool
@@ -109,17 +117,56 @@ func TestUrlHandler_GetURL(t *testing.T) {
 			url:     "/random",
 			isError: true,
 			want: want{
-				code:     400,
+				code:     404,
 				location: "",
 				response: "Can't find url\n",
 			},
 		},
+		{
+			name:    "Per-link redirect code",
+			body:    []byte(""),
+			url:     "/BBBBBBBB",
+			isError: false,
+			want: want{
+				code:     301,
+				location: "/moved",
+				response: "",
+			},
+		},
+		{
+			name:    "Deleted url",
+			body:    []byte(""),
+			url:     "/CCCCCCCC",
+			isError: true,
+			want: want{
+				code:     410,
+				location: "",
+				response: "Url was deleted\n",
+			},
+		},
+		{
+			name:    "Head request",
+			method:  http.MethodHead,
+			body:    []byte(""),
+			url:     "/AAAAAAAA",
+			isError: false,
+			want: want{
+				code:     307,
+				location: "/url",
+				response: "",
+			},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
 			hdrs := []http.Header{}
-			resp, respBody := createTestRequest(t, ts, http.MethodGet, tt.url, hdrs, bytes.NewBuffer(tt.body))
+			resp, respBody := createTestRequest(t, ts, method, tt.url, hdrs, bytes.NewBuffer(tt.body))
 			require.Equal(t, tt.want.code, resp.StatusCode)
 			require.Equal(t, tt.want.response, respBody)
 			if !tt.isError {
@@ -200,9 +247,662 @@ func TestUrlHandler_ShortenURL(t *testing.T) {
 				err := json.Unmarshal([]byte(respBody), jsonBody)
 				require.NoError(t, err)
 				require.True(t, len(jsonBody.URL) > 0)
-				_, err = r.GetURL(context.Background(), path.Base(jsonBody.URL))
+				_, err = r.GetURL(context.Background(), "", path.Base(jsonBody.URL))
 				require.NoError(t, err)
 			}
 		})
 	}
 }
+
+func TestUrlHandler_Stats(t *testing.T) {
+	d := map[string]*models.URL{
+		"https://a.com": {BaseURL: "https://a.com", ShortURL: "AAAAA", UserID: "user-1"},
+		"https://b.com": {BaseURL: "https://b.com", ShortURL: "BBBBB", UserID: "user-2", IsDeleted: true},
+	}
+	r := repository.NewMapRepository(d, l)
+	mux, err := runTestServer(r)
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(mux)
+
+	resp, respBody := createTestRequest(t, ts, http.MethodGet, "/api/internal/stats", nil, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.JSONEq(t, `{"urls": 1, "users": 2, "deleted": 1}`, respBody)
+}
+
+func TestUrlHandler_QRCode(t *testing.T) {
+	d := map[string]*models.URL{
+		"/url": {BaseURL: "/url", ShortURL: "AAAAAAAA", UserID: "user-1"},
+	}
+	r := repository.NewMapRepository(d, l)
+	mux, err := runTestServer(r)
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.ServeHTTP(w, r.WithContext(mw.WithUserID(r.Context(), "user-1")))
+	}))
+
+	tests := []struct {
+		name        string
+		url         string
+		code        int
+		contentType string
+	}{
+		{
+			name:        "Default png",
+			url:         "/AAAAAAAA/qr",
+			code:        200,
+			contentType: "image/png",
+		},
+		{
+			name:        "Svg with options",
+			url:         "/AAAAAAAA/qr?format=svg&size=512&level=h&margin=2&fg=112233&bg=%23ffffff",
+			code:        200,
+			contentType: "image/svg+xml",
+		},
+		{
+			name: "Bad options",
+			url:  "/AAAAAAAA/qr?size=big&fg=red",
+			code: 400,
+		},
+		{
+			name: "Unknown url",
+			url:  "/random/qr",
+			code: 404,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, _ := createTestRequest(t, ts, http.MethodGet, tt.url, nil, nil)
+			require.Equal(t, tt.code, resp.StatusCode)
+			if tt.contentType != "" {
+				require.Equal(t, tt.contentType, resp.Header.Get("Content-Type"))
+			}
+		})
+	}
+
+	// cached image is not served for deleted link
+	resp, _ := createTestRequest(t, ts, http.MethodDelete, "/api/urls/AAAAAAAA", nil, nil)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	resp, _ = createTestRequest(t, ts, http.MethodGet, "/AAAAAAAA/qr", nil, nil)
+	require.Equal(t, http.StatusGone, resp.StatusCode)
+}
+
+func TestUrlHandler_Preview(t *testing.T) {
+	d := map[string]*models.URL{
+		"https://example.com/docs": {BaseURL: "https://example.com/docs", ShortURL: "AAAAAAAA"},
+		"https://example.com/news": {BaseURL: "https://example.com/news", ShortURL: "BBBBBBBB", Preview: true},
+	}
+	r := repository.NewMapRepository(d, l)
+	mux, err := runTestServer(r)
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(mux)
+
+	tests := []struct {
+		name    string
+		url     string
+		preview bool
+	}{
+		{name: "Plain redirect", url: "/AAAAAAAA", preview: false},
+		{name: "Plus suffix", url: "/AAAAAAAA+", preview: true},
+		{name: "Query param", url: "/AAAAAAAA?preview=1", preview: true},
+		{name: "Per-link preview", url: "/BBBBBBBB", preview: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, respBody := createTestRequest(t, ts, http.MethodGet, tt.url, nil, nil)
+			if !tt.preview {
+				require.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+				return
+			}
+
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.Contains(t, resp.Header.Get("Content-Type"), "text/html")
+			require.Contains(t, respBody, "example.com")
+			require.Contains(t, respBody, `href="https://example.com/`)
+		})
+	}
+
+	t.Run("Create link with preview", func(t *testing.T) {
+		hdrs := []http.Header{{"Content-Type": []string{"text/plain"}}}
+		resp, shortURL := createTestRequest(t, ts, http.MethodPost, "/?preview=true", hdrs, bytes.NewBufferString("https://example.com/new"))
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		got, err := r.GetURL(context.Background(), "", path.Base(shortURL))
+		require.NoError(t, err)
+		require.True(t, got.Preview)
+	})
+}
+
+func TestUrlHandler_Password(t *testing.T) {
+	r := repository.NewMapRepository(map[string]*models.URL{}, l)
+	mux, err := runTestServer(r)
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(mux)
+
+	hdrs := []http.Header{{
+		"Content-Type":    []string{"text/plain"},
+		"X-Link-Password": []string{"pa55word"},
+	}}
+	resp, shortURL := createTestRequest(t, ts, http.MethodPost, "/", hdrs, bytes.NewBufferString("https://example.com/secret"))
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	code := "/" + path.Base(shortURL)
+
+	t.Run("Same url with another password", func(t *testing.T) {
+		hdrs := []http.Header{{"Content-Type": []string{"application/json"}}}
+		body := bytes.NewBufferString(`{"url": "https://example.com/secret", "password": "other"}`)
+		resp, _ := createTestRequest(t, ts, http.MethodPost, "/api/shorten", hdrs, body)
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
+
+	tests := []struct {
+		name     string
+		method   string
+		headers  []http.Header
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "Form without password",
+			method:   http.MethodGet,
+			wantCode: http.StatusUnauthorized,
+			wantBody: `type="password"`,
+		},
+		{
+			name:     "Wrong header password",
+			method:   http.MethodGet,
+			headers:  []http.Header{{"X-Link-Password": []string{"wrong"}}},
+			wantCode: http.StatusUnauthorized,
+			wantBody: "Wrong password",
+		},
+		{
+			name:     "Wrong form password",
+			method:   http.MethodPost,
+			headers:  []http.Header{{"Content-Type": []string{"application/x-www-form-urlencoded"}}},
+			body:     "password=wrong",
+			wantCode: http.StatusUnauthorized,
+			wantBody: "Wrong password",
+		},
+		{
+			name:     "Header password",
+			method:   http.MethodGet,
+			headers:  []http.Header{{"X-Link-Password": []string{"pa55word"}}},
+			wantCode: http.StatusTemporaryRedirect,
+		},
+		{
+			name:     "Form password",
+			method:   http.MethodPost,
+			headers:  []http.Header{{"Content-Type": []string{"application/x-www-form-urlencoded"}}},
+			body:     "password=pa55word",
+			wantCode: http.StatusSeeOther,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, respBody := createTestRequest(t, ts, tt.method, code, tt.headers, bytes.NewBufferString(tt.body))
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+			require.Contains(t, respBody, tt.wantBody)
+			if tt.wantCode != http.StatusUnauthorized {
+				require.Equal(t, "https://example.com/secret", resp.Header.Get("Location"))
+			}
+		})
+	}
+}
+
+func TestUrlHandler_DeleteURL(t *testing.T) {
+	d := map[string]*models.URL{
+		"https://a.com": {BaseURL: "https://a.com", ShortURL: "AAAAAAAA", UserID: "user-1"},
+		"https://b.com": {BaseURL: "https://b.com", ShortURL: "BBBBBBBB", UserID: "user-2"},
+	}
+	r := repository.NewMapRepository(d, l)
+	mux, err := runTestServer(r)
+	require.NoError(t, err)
+
+	// user is taken from context, auth middleware is tested separately
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.ServeHTTP(w, r.WithContext(mw.WithUserID(r.Context(), "user-1")))
+	}))
+
+	tests := []struct {
+		name     string
+		url      string
+		wantCode int
+	}{
+		{name: "Own url", url: "/api/urls/AAAAAAAA", wantCode: http.StatusNoContent},
+		{name: "Already deleted", url: "/api/urls/AAAAAAAA", wantCode: http.StatusGone},
+		{name: "Another user url", url: "/api/urls/BBBBBBBB", wantCode: http.StatusForbidden},
+		{name: "Unknown url", url: "/api/urls/CCCCCCCC", wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, _ := createTestRequest(t, ts, http.MethodDelete, tt.url, nil, nil)
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+		})
+	}
+
+	resp, _ := createTestRequest(t, ts, http.MethodGet, "/AAAAAAAA", nil, nil)
+	require.Equal(t, http.StatusGone, resp.StatusCode)
+}
+
+func TestUrlHandler_Passthrough(t *testing.T) {
+	d := map[string]*models.URL{
+		"https://example.com/docs": {BaseURL: "https://example.com/docs", ShortURL: "AAAAAAAA", Passthrough: models.PassthroughAll},
+		"https://example.com/news": {BaseURL: "https://example.com/news", ShortURL: "BBBBBBBB"},
+	}
+	r := repository.NewMapRepository(d, l)
+	mux, err := runTestServer(r)
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(mux)
+
+	tests := []struct {
+		name     string
+		url      string
+		wantCode int
+		want     string
+	}{
+		{
+			name:     "Path and query",
+			url:      "/AAAAAAAA/guide/intro?utm_source=mail",
+			wantCode: http.StatusTemporaryRedirect,
+			want:     "https://example.com/docs/guide/intro?utm_source=mail",
+		},
+		{
+			name:     "Escaped path",
+			url:      "/AAAAAAAA/a%2Fb",
+			wantCode: http.StatusTemporaryRedirect,
+			want:     "https://example.com/docs/a%2Fb",
+		},
+		{
+			name:     "Query ignored without passthrough",
+			url:      "/BBBBBBBB?utm_source=mail",
+			wantCode: http.StatusTemporaryRedirect,
+			want:     "https://example.com/news",
+		},
+		{
+			name:     "Path without passthrough",
+			url:      "/BBBBBBBB/extra",
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, _ := createTestRequest(t, ts, http.MethodGet, tt.url, nil, nil)
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+			require.Equal(t, tt.want, resp.Header.Get("Location"))
+		})
+	}
+
+	t.Run("QR route is not shadowed", func(t *testing.T) {
+		resp, _ := createTestRequest(t, ts, http.MethodGet, "/AAAAAAAA/qr", nil, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+	})
+}
+
+func TestUrlHandler_UpdateURL(t *testing.T) {
+	d := map[string]*models.URL{
+		"https://a.com/typo": {BaseURL: "https://a.com/typo", ShortURL: "AAAAAAAA", UserID: "user-1"},
+		"https://b.com":      {BaseURL: "https://b.com", ShortURL: "BBBBBBBB", UserID: "user-2"},
+	}
+	r := repository.NewMapRepository(d, l)
+	mux, err := runTestServer(r)
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.ServeHTTP(w, r.WithContext(mw.WithUserID(r.Context(), "user-1")))
+	}))
+	jsonHdrs := []http.Header{{"Content-Type": []string{"application/json"}}}
+
+	tests := []struct {
+		name     string
+		url      string
+		body     string
+		wantCode int
+	}{
+		{name: "Fix destination", url: "/api/urls/AAAAAAAA", body: `{"url": "https://a.com/fixed"}`, wantCode: http.StatusOK},
+		{name: "Destination already shortened", url: "/api/urls/AAAAAAAA", body: `{"url": "https://b.com"}`, wantCode: http.StatusConflict},
+		{name: "Empty destination", url: "/api/urls/AAAAAAAA", body: `{"url": ""}`, wantCode: http.StatusBadRequest},
+		{name: "Another user url", url: "/api/urls/BBBBBBBB", body: `{"url": "https://b.com/new"}`, wantCode: http.StatusForbidden},
+		{name: "Unknown url", url: "/api/urls/CCCCCCCC", body: `{"url": "https://c.com"}`, wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, _ := createTestRequest(t, ts, http.MethodPatch, tt.url, jsonHdrs, bytes.NewBufferString(tt.body))
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+		})
+	}
+
+	resp, _ := createTestRequest(t, ts, http.MethodGet, "/AAAAAAAA", nil, nil)
+	require.Equal(t, "https://a.com/fixed", resp.Header.Get("Location"))
+
+	t.Run("History", func(t *testing.T) {
+		resp, body := createTestRequest(t, ts, http.MethodGet, "/api/urls/AAAAAAAA/history", nil, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var history []models.URLChange
+		require.NoError(t, json.Unmarshal([]byte(body), &history))
+		require.Len(t, history, 1)
+		require.Equal(t, "https://a.com/typo", history[0].PreviousURL)
+		require.Equal(t, "https://a.com/fixed", history[0].NewURL)
+		require.Equal(t, "user-1", history[0].ChangedBy)
+	})
+
+	t.Run("Rollback", func(t *testing.T) {
+		resp, body := createTestRequest(t, ts, http.MethodPost, "/api/urls/AAAAAAAA/rollback", nil, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var link models.Link
+		require.NoError(t, json.Unmarshal([]byte(body), &link))
+		require.Equal(t, "https://a.com/typo", link.OriginalURL)
+		require.Equal(t, "http://localhost:8080/AAAAAAAA", link.ShortURL)
+
+		resp, _ = createTestRequest(t, ts, http.MethodPost, "/api/urls/AAAAAAAA/rollback", jsonHdrs, bytes.NewBufferString(`{"change_id": 42}`))
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestUrlHandler_ListURLs(t *testing.T) {
+	now := time.Now()
+	d := map[string]*models.URL{}
+	for i := range 5 {
+		original := fmt.Sprintf("https://example.com/%d", i)
+		d[original] = &models.URL{
+			BaseURL:  original,
+			ShortURL: fmt.Sprintf("CODE%d", i),
+			UserID:   "user-1",
+			CreateAt: now.Add(time.Duration(i) * time.Minute),
+			Tags:     []string{fmt.Sprintf("tag%d", i%2)},
+		}
+	}
+	d["https://other.com"] = &models.URL{BaseURL: "https://other.com", ShortURL: "OTHER", UserID: "user-2"}
+
+	r := repository.NewMapRepository(d, l)
+	mux, err := runTestServer(r)
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.ServeHTTP(w, r.WithContext(mw.WithUserID(r.Context(), "user-1")))
+	}))
+
+	list := func(t *testing.T, query string) (int, models.LinkPage) {
+		resp, body := createTestRequest(t, ts, http.MethodGet, "/api/urls?"+query, nil, nil)
+
+		var page models.LinkPage
+		if resp.StatusCode == http.StatusOK {
+			require.NoError(t, json.Unmarshal([]byte(body), &page))
+		}
+		return resp.StatusCode, page
+	}
+
+	t.Run("Pages", func(t *testing.T) {
+		var got []string
+		cursor := ""
+		for {
+			code, page := list(t, "limit=2&cursor="+cursor)
+			require.Equal(t, http.StatusOK, code)
+			for _, link := range page.Items {
+				got = append(got, path.Base(link.ShortURL))
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			cursor = page.NextCursor
+		}
+		require.Equal(t, []string{"CODE4", "CODE3", "CODE2", "CODE1", "CODE0"}, got)
+	})
+
+	t.Run("Tag filter", func(t *testing.T) {
+		code, page := list(t, "tag=tag1&sort=created_at")
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, page.Items, 2)
+		require.Equal(t, "https://example.com/1", page.Items[0].OriginalURL)
+		require.Equal(t, []string{"tag1"}, page.Items[0].Tags)
+	})
+
+	t.Run("Cursor of another sort", func(t *testing.T) {
+		_, page := list(t, "limit=1")
+		code, _ := list(t, "limit=1&sort=short_url&cursor="+page.NextCursor)
+		require.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("Invalid filter", func(t *testing.T) {
+		for _, query := range []string{"cursor=garbage", "sort=title", "created_from=yesterday", "deleted=maybe"} {
+			code, _ := list(t, query)
+			require.Equal(t, http.StatusBadRequest, code, query)
+		}
+	})
+}
+
+func TestUrlHandler_BatchReduceURL_Stream(t *testing.T) {
+	ndjson := func(from, to int, invalidAt int) string {
+		var b strings.Builder
+		for i := from; i <= to; i++ {
+			url := fmt.Sprintf("https://example.com/%d", i)
+			if i == invalidAt {
+				url = ""
+			}
+			fmt.Fprintf(&b, `{"correlation_id": "%d", "original_url": "%s"}`+"\n", i, url)
+		}
+		return b.String()
+	}
+
+	tests := []struct {
+		name          string
+		body          string
+		maxItems      int
+		atomic        bool
+		wantCode      int
+		wantResults   int
+		wantInvalid   string
+		wantLastError string
+	}{
+		{
+			name:        "several chunks",
+			body:        ndjson(1, 250, 0),
+			wantCode:    http.StatusOK,
+			wantResults: 250,
+		},
+		{
+			name:        "invalid item",
+			body:        ndjson(1, 200, 150),
+			wantCode:    http.StatusOK,
+			wantResults: 200,
+			wantInvalid: "150",
+		},
+		{
+			name:     "atomic",
+			body:     ndjson(1, 10, 0),
+			atomic:   true,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "malformed line",
+			body:     "{\n",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:          "too many items",
+			body:          ndjson(1, 5, 0),
+			maxItems:      3,
+			wantCode:      http.StatusOK,
+			wantResults:   3,
+			wantLastError: "too many urls",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg.Limits.MaxStreamItems = tt.maxItems
+			t.Cleanup(func() { cfg.Limits.MaxStreamItems = 0 })
+
+			r := repository.NewMapRepository(map[string]*models.URL{}, l)
+			mux, err := runTestServer(r)
+			require.NoError(t, err)
+
+			ts := httptest.NewServer(mux)
+			defer ts.Close()
+
+			headers := []http.Header{{"Content-Type": []string{mw.ContentTypeNDJSON}}}
+			target := "/api/shorten/batch"
+			if tt.atomic {
+				target += "?atomic=true"
+			}
+			resp, respBody := createTestRequest(t, ts, http.MethodPost, target, headers, strings.NewReader(tt.body))
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			require.Equal(t, mw.ContentTypeNDJSON, resp.Header.Get("Content-Type"))
+
+			lines := strings.Split(strings.TrimSpace(respBody), "\n")
+			if tt.wantLastError != "" {
+				var streamErr models.StreamError
+				require.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &streamErr))
+				require.Contains(t, streamErr.Error, tt.wantLastError)
+				lines = lines[:len(lines)-1]
+			}
+
+			require.Len(t, lines, tt.wantResults)
+			for i, line := range lines {
+				var u models.UrlDTO
+				require.NoError(t, json.Unmarshal([]byte(line), &u))
+				require.Equal(t, strconv.Itoa(i+1), u.CorrelationID)
+				if u.CorrelationID == tt.wantInvalid {
+					require.Equal(t, models.BatchStatusInvalid, u.Status)
+					continue
+				}
+				require.Equal(t, models.BatchStatusCreated, u.Status)
+				require.NotEmpty(t, u.ShortURL)
+			}
+		})
+	}
+}
+
+func TestUrlHandler_BatchReduceURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		body       string
+		wantCode   int
+		wantStatus []string
+	}{
+		{
+			name:       "created and existing",
+			target:     "/api/shorten/batch",
+			body:       `[{"correlation_id": "1", "original_url": "https://a.com"}, {"correlation_id": "2", "original_url": "https://exists.com"}]`,
+			wantCode:   http.StatusOK,
+			wantStatus: []string{models.BatchStatusCreated, models.BatchStatusExisting},
+		},
+		{
+			name:       "partial success",
+			target:     "/api/shorten/batch",
+			body:       `[{"correlation_id": "1", "original_url": "https://a.com"}, {"correlation_id": "2", "original_url": ""}]`,
+			wantCode:   http.StatusMultiStatus,
+			wantStatus: []string{models.BatchStatusCreated, models.BatchStatusInvalid},
+		},
+		{
+			name:       "atomic rejected",
+			target:     "/api/shorten/batch?atomic=true",
+			body:       `[{"correlation_id": "1", "original_url": "https://a.com"}, {"correlation_id": "2", "original_url": ""}]`,
+			wantCode:   http.StatusBadRequest,
+			wantStatus: []string{models.BatchStatusFailed, models.BatchStatusInvalid},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := map[string]*models.URL{
+				"https://exists.com": {BaseURL: "https://exists.com", ShortURL: "EXIST"},
+			}
+			mux, err := runTestServer(repository.NewMapRepository(d, l))
+			require.NoError(t, err)
+
+			ts := httptest.NewServer(mux)
+			defer ts.Close()
+
+			headers := []http.Header{{"Content-Type": []string{"application/json"}}}
+			resp, respBody := createTestRequest(t, ts, http.MethodPost, tt.target, headers, strings.NewReader(tt.body))
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+
+			var res []*models.UrlDTO
+			require.NoError(t, json.Unmarshal([]byte(respBody), &res))
+			require.Len(t, res, len(tt.wantStatus))
+			for i, item := range res {
+				require.Equal(t, tt.wantStatus[i], item.Status)
+			}
+		})
+	}
+}
+
+func TestUrlHandler_ShortDomains(t *testing.T) {
+	mux, err := runTestServer(repository.NewMapRepository(map[string]*models.URL{}, l))
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	jsonHeader := http.Header{"Content-Type": []string{"application/json"}}
+	domainHost := http.Header{"Host": []string{"go.example.com"}}
+
+	resp, _ := createTestRequest(t, ts, http.MethodPost, "/api/domains",
+		[]http.Header{jsonHeader}, strings.NewReader(`{"base_url": "https://go.example.com"}`))
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp, _ = createTestRequest(t, ts, http.MethodPost, "/api/domains",
+		[]http.Header{jsonHeader}, strings.NewReader(`{"base_url": "https://go.example.com"}`))
+	require.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	resp, _ = createTestRequest(t, ts, http.MethodPost, "/api/domains",
+		[]http.Header{jsonHeader}, strings.NewReader(`{"base_url": "go.example.com"}`))
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// domain of workspace is not listed outside of it
+	resp, _ = createTestRequest(t, ts, http.MethodPost, "/api/domains",
+		[]http.Header{jsonHeader}, strings.NewReader(`{"base_url": "https://go.ws.com", "workspace": "ws1"}`))
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	// link is created on domain request came to
+	resp, respBody := createTestRequest(t, ts, http.MethodPost, "/api/shorten",
+		[]http.Header{jsonHeader, domainHost}, strings.NewReader(`{"url": "https://b.com"}`))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var res models.ShortenURLRespBody
+	require.NoError(t, json.Unmarshal([]byte(respBody), &res))
+	require.True(t, strings.HasPrefix(res.ShortURL, "https://go.example.com/"))
+	code := path.Base(res.ShortURL)
+
+	resp, _ = createTestRequest(t, ts, http.MethodGet, "/"+code, []http.Header{domainHost}, nil)
+	require.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+	require.Equal(t, "https://b.com", resp.Header.Get("Location"))
+
+	resp, _ = createTestRequest(t, ts, http.MethodGet, "/"+code, nil, nil)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, _ = createTestRequest(t, ts, http.MethodGet, "/api/urls?short_domain=unknown.com", nil, nil)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, respBody = createTestRequest(t, ts, http.MethodGet, "/api/domains", nil, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var domains []*models.Domain
+	require.NoError(t, json.Unmarshal([]byte(respBody), &domains))
+	require.Len(t, domains, 1)
+	require.Equal(t, "go.example.com", domains[0].Host)
+	require.NotContains(t, respBody, "ws1")
+
+	resp, _ = createTestRequest(t, ts, http.MethodDelete, "/api/domains/go.example.com", nil, nil)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	resp, _ = createTestRequest(t, ts, http.MethodDelete, "/api/domains/go.example.com", nil, nil)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
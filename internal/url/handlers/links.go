@@ -0,0 +1,306 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	mw "github.com/MatiXxD/url-shortener/internal/middleware"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/url/usecase"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/go-chi/chi/v5"
+	"github.com/mailru/easyjson"
+)
+
+// handlers of /api/urls/{url} manage links of current user
+
+func (uh *UrlHandler) DeleteURL(w http.ResponseWriter, r *http.Request) {
+	logger := uh.logger
+	reqID := mw.GetRequestID(r.Context())
+	if reqID != "" {
+		logger = uh.logger.With("request_id", reqID)
+	}
+
+	shortURL := chi.URLParam(r, "url")
+	domain, err := uh.urlUsecase.ShortDomain(r.Context(), r.URL.Query().Get("short_domain"))
+	if err != nil {
+		writeLinkError(w, logger, shortURL, err)
+		return
+	}
+
+	err = uh.urlUsecase.DeleteURL(r.Context(), domain, shortURL, mw.GetUserID(r.Context()))
+	if err != nil {
+		writeLinkError(w, logger, shortURL, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (uh *UrlHandler) UpdateURL(w http.ResponseWriter, r *http.Request) {
+	logger := uh.logger
+	reqID := mw.GetRequestID(r.Context())
+	if reqID != "" {
+		logger = uh.logger.With("request_id", reqID)
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if !strings.Contains(contentType, "application/json") {
+		logger.Error("request contains wrong content type")
+		http.Error(w, "Wrong content type", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	var req models.UpdateURLReqBody
+	err := easyjson.UnmarshalFromReader(r.Body, &req)
+	if isTooLarge(err) {
+		logger.Error("request body is too large")
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		logger.Errorf("can't unmarshal request body: %v", err)
+		http.Error(w, "Can't read body", http.StatusBadRequest)
+		return
+	}
+
+	shortURL := chi.URLParam(r, "url")
+	domain, err := uh.urlUsecase.ShortDomain(r.Context(), r.URL.Query().Get("short_domain"))
+	if err != nil {
+		writeLinkError(w, logger, shortURL, err)
+		return
+	}
+
+	url, err := uh.urlUsecase.UpdateURL(r.Context(), domain, shortURL, req.URL, mw.GetUserID(r.Context()))
+	if err != nil {
+		writeLinkError(w, logger, shortURL, err)
+		return
+	}
+
+	uh.writeLink(w, logger, url)
+}
+
+func (uh *UrlHandler) History(w http.ResponseWriter, r *http.Request) {
+	logger := uh.logger
+	reqID := mw.GetRequestID(r.Context())
+	if reqID != "" {
+		logger = uh.logger.With("request_id", reqID)
+	}
+
+	shortURL := chi.URLParam(r, "url")
+	domain, err := uh.urlUsecase.ShortDomain(r.Context(), r.URL.Query().Get("short_domain"))
+	if err != nil {
+		writeLinkError(w, logger, shortURL, err)
+		return
+	}
+
+	history, err := uh.urlUsecase.GetHistory(r.Context(), domain, shortURL, mw.GetUserID(r.Context()))
+	if err != nil {
+		writeLinkError(w, logger, shortURL, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := easyjson.MarshalToWriter(models.URLHistory(history), w); err != nil {
+		logger.Error("can't marshal response body")
+		http.Error(w, "Can't marshal response body", http.StatusInternalServerError)
+		return
+	}
+}
+
+// Rollback accepts empty body to undo the latest change
+func (uh *UrlHandler) Rollback(w http.ResponseWriter, r *http.Request) {
+	logger := uh.logger
+	reqID := mw.GetRequestID(r.Context())
+	if reqID != "" {
+		logger = uh.logger.With("request_id", reqID)
+	}
+
+	var req models.RollbackReqBody
+	body, err := io.ReadAll(r.Body)
+	if isTooLarge(err) {
+		logger.Error("request body is too large")
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err == nil && len(body) != 0 {
+		err = easyjson.Unmarshal(body, &req)
+	}
+	if err != nil {
+		logger.Errorf("can't unmarshal request body: %v", err)
+		http.Error(w, "Can't read body", http.StatusBadRequest)
+		return
+	}
+
+	shortURL := chi.URLParam(r, "url")
+	domain, err := uh.urlUsecase.ShortDomain(r.Context(), r.URL.Query().Get("short_domain"))
+	if err != nil {
+		writeLinkError(w, logger, shortURL, err)
+		return
+	}
+
+	url, err := uh.urlUsecase.Rollback(r.Context(), domain, shortURL, req.ChangeID, mw.GetUserID(r.Context()))
+	if err != nil {
+		writeLinkError(w, logger, shortURL, err)
+		return
+	}
+
+	uh.writeLink(w, logger, url)
+}
+
+// ListURLs returns links of current user page by page
+func (uh *UrlHandler) ListURLs(w http.ResponseWriter, r *http.Request) {
+	logger := uh.logger
+	reqID := mw.GetRequestID(r.Context())
+	if reqID != "" {
+		logger = uh.logger.With("request_id", reqID)
+	}
+
+	query := r.URL.Query()
+	filter, err := parseURLFilter(query)
+	if err != nil {
+		logger.Errorf("invalid filter: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.UserID = mw.GetUserID(r.Context())
+	if query.Has("short_domain") {
+		domain, err := uh.urlUsecase.ShortDomain(r.Context(), query.Get("short_domain"))
+		if err != nil {
+			logger.Errorf("invalid filter: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		filter.ShortDomain = &domain
+	}
+
+	urls, next, err := uh.urlUsecase.ListURLs(r.Context(), filter, query.Get("cursor"))
+	if errors.Is(err, usecase.ErrInvalidFilter) || errors.Is(err, usecase.ErrInvalidCursor) {
+		logger.Errorf("invalid filter: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		logger.Errorf("can't list urls: %v", err)
+		http.Error(w, "Can't list urls", http.StatusInternalServerError)
+		return
+	}
+
+	page := &models.LinkPage{
+		Items:      make([]*models.Link, 0, len(urls)),
+		NextCursor: next,
+	}
+	for _, u := range urls {
+		page.Items = append(page.Items, uh.toLink(u))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := easyjson.MarshalToWriter(page, w); err != nil {
+		logger.Error("can't marshal response body")
+		http.Error(w, "Can't marshal response body", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (uh *UrlHandler) toLink(url *models.URL) *models.Link {
+	return &models.Link{
+		ShortURL:    uh.urlUsecase.GetShortURL(url.ShortDomain, url.ShortURL),
+		OriginalURL: url.BaseURL,
+		Title:       url.Title,
+		Tags:        url.Tags,
+		CreatedAt:   url.CreateAt,
+		Deleted:     url.IsDeleted,
+	}
+}
+
+func (uh *UrlHandler) writeLink(w http.ResponseWriter, logger *logger.Logger, url *models.URL) {
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := easyjson.MarshalToWriter(uh.toLink(url), w); err != nil {
+		logger.Error("can't marshal response body")
+		http.Error(w, "Can't marshal response body", http.StatusInternalServerError)
+		return
+	}
+}
+
+// parseURLFilter reads listing query: tag, domain, created_from, created_to (RFC 3339),
+// deleted (true, false or all, active only by default), sort ("-" prefix for descending) and limit
+func parseURLFilter(query neturl.Values) (*models.URLFilter, error) {
+	filter := &models.URLFilter{
+		Tag:    query.Get("tag"),
+		Domain: query.Get("domain"),
+		Sort:   models.SortCreatedAt,
+		Desc:   true,
+	}
+
+	for name, dst := range map[string]*time.Time{"created_from": &filter.CreatedFrom, "created_to": &filter.CreatedTo} {
+		v := query.Get(name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: expected RFC 3339 time", name)
+		}
+		*dst = t.UTC()
+	}
+
+	switch v := query.Get("deleted"); v {
+	case "", "false":
+		deleted := false
+		filter.Deleted = &deleted
+	case "true":
+		deleted := true
+		filter.Deleted = &deleted
+	case "all":
+	default:
+		return nil, fmt.Errorf("deleted: expected true, false or all")
+	}
+
+	if v := query.Get("sort"); v != "" {
+		filter.Desc = strings.HasPrefix(v, "-")
+		filter.Sort = strings.TrimPrefix(v, "-")
+	}
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("limit: not an integer")
+		}
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
+
+// writeLinkError maps usecase errors of link management to responses
+func writeLinkError(w http.ResponseWriter, logger *logger.Logger, shortURL string, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrURLNotFound):
+		logger.Errorf("can't find url %s", shortURL)
+		http.Error(w, "Can't find url", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrURLDeleted):
+		logger.Errorf("url %s was deleted", shortURL)
+		http.Error(w, "Url was deleted", http.StatusGone)
+	case errors.Is(err, usecase.ErrNotOwner):
+		logger.Errorf("access to url %s denied: %v", shortURL, err)
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	case isInvalidRequest(err):
+		logger.Errorf("invalid request: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrChangeNotFound):
+		logger.Errorf("can't find change of url %s", shortURL)
+		http.Error(w, "Can't find change", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrURLConflict), errors.Is(err, usecase.ErrNoHistory):
+		logger.Errorf("can't change url %s: %v", shortURL, err)
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		logger.Errorf("can't manage url %s: %v", shortURL, err)
+		http.Error(w, "Can't process url", http.StatusInternalServerError)
+	}
+}
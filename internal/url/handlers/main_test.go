@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/MatiXxD/url-shortener/pkg/logger"
 	"go.uber.org/zap"
@@ -26,6 +27,13 @@ func TestMain(m *testing.M) {
 	cfg = &config.ServiceConfig{
 		Addr:    ":8080",
 		BaseURL: "http://localhost:8080",
+		Redirect: config.RedirectConfig{
+			Code: http.StatusTemporaryRedirect,
+		},
+		Cache: config.CacheConfig{
+			Size: 16,
+			TTL:  config.Duration(time.Minute),
+		},
 	}
 
 	var err error
@@ -34,7 +42,7 @@ func TestMain(m *testing.M) {
 		panic(err)
 	}
 
-	l = &logger.Logger{zl.Sugar()}
+	l = &logger.Logger{SugaredLogger: zl.Sugar()}
 
 	os.Exit(m.Run())
 }
@@ -47,6 +55,11 @@ func createTestRequest(t *testing.T, ts *httptest.Server,
 
 	for _, header := range headers {
 		for k, v := range header {
+			// client takes host from request, not from headers
+			if k == "Host" {
+				req.Host = v[0]
+				continue
+			}
 			for _, vv := range v {
 				req.Header.Set(k, vv)
 			}
@@ -75,8 +88,24 @@ func runTestServer(r url.Repository) (chi.Router, error) {
 	mux := chi.NewRouter()
 	mux.Post("/", h.ReduceURL)
 	mux.Get("/{url}", h.GetURL)
+	mux.Head("/{url}", h.GetURL)
+	mux.Post("/{url}", h.GetURL)
+	mux.Get("/{url}/*", h.GetURL)
+	mux.Head("/{url}/*", h.GetURL)
+	mux.Post("/{url}/*", h.GetURL)
+	mux.Get("/{url}/qr", h.QRCode)
 
 	mux.Post("/api/shorten", h.ShortenURL)
+	mux.Post("/api/shorten/batch", h.BatchReduceURL)
+	mux.Get("/api/urls", h.ListURLs)
+	mux.Delete("/api/urls/{url}", h.DeleteURL)
+	mux.Patch("/api/urls/{url}", h.UpdateURL)
+	mux.Get("/api/urls/{url}/history", h.History)
+	mux.Post("/api/urls/{url}/rollback", h.Rollback)
+	mux.Get("/api/internal/stats", h.Stats)
+	mux.Get("/api/domains", h.ListDomains)
+	mux.Post("/api/domains", h.AddDomain)
+	mux.Delete("/api/domains/{host}", h.DeleteDomain)
 
 	return mux, nil
 }
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/quota"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/mailru/easyjson"
+)
+
+// quotaExceeded responds to request rejected by quota and reports if it was. Quota which is reset later
+// gives 429 with Retry-After, the rest give 403.
+func quotaExceeded(w http.ResponseWriter, logger *logger.Logger, err error) bool {
+	var exceeded *quota.ExceededError
+	if !errors.As(err, &exceeded) {
+		return false
+	}
+	logger.Errorf("request is rejected: %v", err)
+
+	status := http.StatusForbidden
+	if exceeded.RetryAfter > 0 {
+		status = http.StatusTooManyRequests
+		w.Header().Set("Retry-After", strconv.Itoa(int(exceeded.RetryAfter.Seconds())+1))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := easyjson.MarshalToWriter(&models.QuotaError{Error: exceeded.Error(), Quota: exceeded.Usage}, w); err != nil {
+		logger.Error("can't marshal response body")
+	}
+	return true
+}
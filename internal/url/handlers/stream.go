@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	mw "github.com/MatiXxD/url-shortener/internal/middleware"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/quota"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/mailru/easyjson"
+)
+
+var (
+	errTooManyItems  = errors.New("too many urls in stream")
+	errLineTooLong   = errors.New("line is too long")
+	errMalformedLine = errors.New("malformed line")
+)
+
+// streamReduceURL shortens ndjson batch line by line, result lines are written as soon as
+// their chunk is saved. Status is 200 once first results are sent, later errors end stream with error line.
+func (uh *UrlHandler) streamReduceURL(w http.ResponseWriter, r *http.Request, logger *logger.Logger) {
+	rc := http.NewResponseController(w)
+	// http/1 server stops reading body after first write without it
+	if err := rc.EnableFullDuplex(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		logger.Errorf("can't enable full duplex: %v", err)
+	}
+
+	scanner := bufio.NewScanner(r.Body)
+	defer r.Body.Close()
+	if max := uh.cfg.Limits.MaxBodySize; max > 0 {
+		scanner.Buffer(make([]byte, 0, min(max, bufio.MaxScanTokenSize)), int(max))
+	}
+
+	userID := mw.GetUserID(r.Context())
+	domain := uh.requestDomain(r, "")
+	items := 0
+	next := func() (*models.UrlDTO, error) {
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+
+			items++
+			if max := uh.cfg.Limits.MaxStreamItems; max > 0 && items > max {
+				return nil, fmt.Errorf("%w: limit is %d", errTooManyItems, max)
+			}
+
+			var u models.UrlDTO
+			if err := easyjson.Unmarshal(line, &u); err != nil {
+				return nil, fmt.Errorf("%w: item %d", errMalformedLine, items)
+			}
+			u.UserID = userID
+			if u.ShortDomain == "" {
+				u.ShortDomain = domain
+			}
+
+			return &u, nil
+		}
+
+		if err := scanner.Err(); errors.Is(err, bufio.ErrTooLong) {
+			return nil, fmt.Errorf("%w: item %d", errLineTooLong, items+1)
+		} else if err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+
+	written := false
+	emit := func(urls []*models.UrlDTO) error {
+		if !written {
+			w.Header().Set("Content-Type", mw.ContentTypeNDJSON)
+			w.WriteHeader(http.StatusOK)
+			written = true
+		}
+
+		for _, u := range urls {
+			data, err := easyjson.Marshal(u)
+			if err != nil {
+				return err
+			}
+			if _, err := w.Write(append(data, '\n')); err != nil {
+				return err
+			}
+		}
+
+		// client gets results of saved chunk before next one is read
+		if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+		return nil
+	}
+
+	err := uh.urlUsecase.StreamReduceURL(r.Context(), next, emit)
+	if err == nil {
+		if !written {
+			w.Header().Set("Content-Type", mw.ContentTypeNDJSON)
+			w.WriteHeader(http.StatusOK)
+		}
+		return
+	}
+
+	if !written && quotaExceeded(w, logger, err) {
+		return
+	}
+	logger.Errorf("stream is interrupted after %d items: %v", items, err)
+
+	msg, code := "Can't create short urls", http.StatusInternalServerError
+	switch {
+	case errors.Is(err, errMalformedLine):
+		msg, code = err.Error(), http.StatusBadRequest
+	case errors.Is(err, errTooManyItems), errors.Is(err, errLineTooLong):
+		msg, code = err.Error(), http.StatusRequestEntityTooLarge
+	case errors.Is(err, quota.ErrExceeded):
+		msg, code = err.Error(), http.StatusForbidden
+	}
+
+	if !written {
+		http.Error(w, msg, code)
+		return
+	}
+
+	data, _ := easyjson.Marshal(&models.StreamError{Error: msg})
+	_, _ = w.Write(append(data, '\n'))
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"embed"
+	"html/template"
+	"time"
+)
+
+//go:embed templates/*.html
+var templatesFS embed.FS
+
+var templates = template.Must(template.ParseFS(templatesFS, "templates/*.html"))
+
+type previewPage struct {
+	ShortURL    string
+	Destination string
+	Domain      string
+	CreatedAt   time.Time
+}
+
+type passwordPage struct {
+	ShortURL string
+	Action   string
+	Error    string
+}
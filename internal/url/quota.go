@@ -0,0 +1,18 @@
+package url
+
+import (
+	"context"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+)
+
+// Quotas limit links created by request, its user, api key and workspace are taken from context
+type Quotas interface {
+	CheckBatch(ctx context.Context, size int) error
+	// Reserve counts n links about to be created, nil reservation means no quotas are counted
+	Reserve(ctx context.Context, n int) (*models.QuotaReservation, error)
+	// Cancel returns n links which were not created to reservation counters
+	Cancel(ctx context.Context, r *models.QuotaReservation, n int)
+	// Release frees active link which was deleted, keys are QuotaKeys of link
+	Release(ctx context.Context, keys []string)
+}
@@ -2,12 +2,63 @@ package url
 
 import (
 	"context"
+	"errors"
 
 	"github.com/MatiXxD/url-shortener/internal/models"
 )
 
+var (
+	ErrNotFound = errors.New("url was not found")
+	ErrConflict = errors.New("original url is already shortened")
+	// ErrCodeTaken means generated short url belongs to another original url
+	ErrCodeTaken = errors.New("short url is already taken")
+	// ErrDomainExists means short domain is already registered
+	ErrDomainExists = errors.New("domain already exists")
+)
+
+// Repository keeps links, short url is unique within its short domain, empty domain is the default one.
+// Original url is unique within workspace and short domain, empty workspace is the default one.
+// Links are changed and listed within workspace, GetURL resolves short url of any workspace.
+// Events of inserted urls are written to outbox atomically with them, audit entries of changes are recorded
+// atomically with the changes, nil entry is not recorded.
 type Repository interface {
+	// AddURL returns short url of original which is already shortened in workspace and sets Existed of model then
 	AddURL(context.Context, *models.URL) (string, error)
+	// BatchAddURL writes Event of every inserted url to outbox
 	BatchAddURL(context.Context, []*models.URL) ([]*models.URL, error)
-	GetURL(context.Context, string) (*models.URL, error)
+	GetURL(ctx context.Context, domain, shortURL string) (*models.URL, error)
+	// FindURLs returns active url of original of every url in its workspace and short domain, nil if there is none
+	FindURLs(context.Context, []*models.URL) ([]*models.URL, error)
+	DeleteURL(ctx context.Context, workspace, domain, shortURL string, entry *models.AuditEntry) error
+	// UpdateURL changes destination of short url and records change made by actor
+	UpdateURL(ctx context.Context, workspace, domain, shortURL, originalURL, actor string, entry *models.AuditEntry) (*models.URL, error)
+	GetHistory(ctx context.Context, workspace, domain, shortURL string) ([]*models.URLChange, error)
+	// ListURLs returns up to filter.Limit urls of user in workspace after filter.After cursor
+	ListURLs(context.Context, *models.URLFilter) ([]*models.URL, error)
+	// Stats counts links of all workspaces
+	Stats(context.Context) (*models.Stats, error)
+	// NextID returns next value of short code sequence
+	NextID(context.Context) (uint64, error)
+
+	// AddDomain registers short domain, ErrDomainExists if host is taken
+	AddDomain(context.Context, *models.Domain) error
+	ListDomains(context.Context) ([]*models.Domain, error)
+	// DeleteDomain removes domain from registry, its links are kept
+	DeleteDomain(ctx context.Context, host string) error
+
+	// ConsumeOutbox passes up to limit outbox records after offset of consumer to fn and moves offset past
+	// them when fn succeeds. Consumer is busy meanwhile: concurrent call for it returns zero records.
+	ConsumeOutbox(ctx context.Context, consumer string, limit int, fn func([]*models.OutboxRecord) error) (int, error)
+}
+
+type ctxKeyConsistentRead struct{}
+
+// WithConsistentRead makes repository read data with all committed writes, e.g. from primary instead of replica
+func WithConsistentRead(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ctxKeyConsistentRead{}, true)
+}
+
+func IsConsistentRead(ctx context.Context) bool {
+	consistent, _ := ctx.Value(ctxKeyConsistentRead{}).(bool)
+	return consistent
 }
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"github.com/MatiXxD/url-shortener/internal/models"
+)
+
+// insertedEntries returns audit entries of batch urls which were inserted, saved are results of batch
+func insertedEntries(batch, saved []*models.URL) []*models.AuditEntry {
+	var entries []*models.AuditEntry
+	for i, s := range saved {
+		if !s.Existed && batch[i].Audit != nil {
+			entries = append(entries, batch[i].Audit)
+		}
+	}
+	return entries
+}
+
+// auditEntries returns entry of change as entries to record, nil entry is not recorded
+func auditEntries(entry *models.AuditEntry) []*models.AuditEntry {
+	if entry == nil {
+		return nil
+	}
+	return []*models.AuditEntry{entry}
+}
+
+// changeSaved tells whether link of in-memory storage is in the state entry left it in
+func changeSaved(urls map[string]*models.URL, e *models.AuditEntry) bool {
+	u := findURL(urls, e.ShortDomain, e.Code)
+	if u == nil {
+		return false
+	}
+	return e.After == nil || u.BaseURL == e.After.OriginalURL && u.IsDeleted == e.After.Deleted
+}
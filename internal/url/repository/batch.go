@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"slices"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/url"
+)
+
+// linkKey identifies original or short url within short domain in in-memory storages,
+// urls of default domain are keyed by value itself
+func linkKey(domain, value string) string {
+	if domain == "" {
+		return value
+	}
+	return domain + "\x00" + value
+}
+
+// originKey is key of original url in in-memory storages, it is unique within workspace and short domain
+func originKey(workspace, domain, original string) string {
+	if workspace == "" {
+		return linkKey(domain, original)
+	}
+	return workspace + "\x01" + linkKey(domain, original)
+}
+
+func urlKey(u *models.URL) string {
+	return originKey(u.Workspace, u.ShortDomain, u.BaseURL)
+}
+
+// storeKey is key url is kept by in in-memory storages. Deleted url doesn't hold key of its original,
+// so the original can be shortened again with new short url.
+func storeKey(u *models.URL) string {
+	if u.IsDeleted {
+		return urlKey(u) + "\x02" + codeKey(u)
+	}
+	return urlKey(u)
+}
+
+func codeKey(u *models.URL) string {
+	return linkKey(u.ShortDomain, u.ShortURL)
+}
+
+// codeIndex collects short urls of in-memory storage, so uniqueness check doesn't scan all urls
+func codeIndex(urls map[string]*models.URL) map[string]bool {
+	codes := make(map[string]bool, len(urls))
+	for _, u := range urls {
+		codes[codeKey(u)] = true
+	}
+	return codes
+}
+
+// findURL looks up url by short url, caller must hold the lock
+func findURL(urls map[string]*models.URL, domain, shortURL string) *models.URL {
+	for _, u := range urls {
+		if u.ShortURL == shortURL && u.ShortDomain == domain {
+			return u
+		}
+	}
+	return nil
+}
+
+// findOwnURL is findURL limited to urls of workspace
+func findOwnURL(urls map[string]*models.URL, workspace, domain, shortURL string) *models.URL {
+	if u := findURL(urls, domain, shortURL); u != nil && u.Workspace == workspace {
+		return u
+	}
+	return nil
+}
+
+// newStoredURL copies url as it is kept by in-memory storages
+func newStoredURL(u *models.URL) *models.URL {
+	return &models.URL{
+		CorrelationID: u.CorrelationID,
+		BaseURL:       u.BaseURL,
+		ShortURL:      u.ShortURL,
+		CreateAt:      time.Now(),
+		UserID:        u.UserID,
+		Preview:       u.Preview,
+		PasswordHash:  u.PasswordHash,
+		RedirectCode:  u.RedirectCode,
+		Passthrough:   u.Passthrough,
+		Title:         u.Title,
+		Tags:          slices.Clone(u.Tags),
+		ShortDomain:   u.ShortDomain,
+		Workspace:     u.Workspace,
+		QuotaKeys:     slices.Clone(u.QuotaKeys),
+	}
+}
+
+// findOrigins is FindURLs of in-memory storages, caller must hold the lock
+func findOrigins(urls map[string]*models.URL, batch []*models.URL) []*models.URL {
+	res := make([]*models.URL, len(batch))
+	for i, u := range batch {
+		if v, ok := urls[urlKey(u)]; ok {
+			found := *v
+			res[i] = &found
+		}
+	}
+	return res
+}
+
+// prepareBatch checks batch for in-memory storages without applying it. Results keep order of batch,
+// added urls must be stored by caller. Taken short url rejects the whole batch. Caller must hold the lock.
+func prepareBatch(urls map[string]*models.URL, codes map[string]bool, batch []*models.URL) ([]*models.URL, []*models.URL, error) {
+	res := make([]*models.URL, 0, len(batch))
+	added := make([]*models.URL, 0, len(batch))
+	pending := make(map[string]*models.URL)
+	pendingCodes := make(map[string]bool)
+
+	for _, u := range batch {
+		r := &models.URL{
+			CorrelationID: u.CorrelationID,
+			BaseURL:       u.BaseURL,
+		}
+
+		if got, ok := urls[urlKey(u)]; ok {
+			r.ShortURL, r.Existed = got.ShortURL, true
+		} else if got, ok := pending[urlKey(u)]; ok {
+			r.ShortURL, r.Existed = got.ShortURL, true
+		} else {
+			if codes[codeKey(u)] || pendingCodes[codeKey(u)] {
+				return nil, nil, url.ErrCodeTaken
+			}
+
+			stored := newStoredURL(u)
+			pending[urlKey(u)] = stored
+			pendingCodes[codeKey(u)] = true
+			added = append(added, stored)
+
+			r.ShortURL = u.ShortURL
+		}
+
+		res = append(res, r)
+	}
+
+	return res, added, nil
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/url"
+)
+
+// addDomain registers domain in in-memory storages, caller must hold the lock
+func addDomain(domains map[string]*models.Domain, d *models.Domain) (*models.Domain, error) {
+	if _, ok := domains[d.Host]; ok {
+		return nil, url.ErrDomainExists
+	}
+
+	stored := &models.Domain{
+		Host:      d.Host,
+		BaseURL:   d.BaseURL,
+		Workspace: d.Workspace,
+		CreatedAt: time.Now(),
+	}
+	domains[d.Host] = stored
+
+	return stored, nil
+}
+
+// listDomains returns copies of domains sorted by host, caller must hold the lock
+func listDomains(domains map[string]*models.Domain) []*models.Domain {
+	res := make([]*models.Domain, 0, len(domains))
+	for _, d := range domains {
+		copied := *d
+		res = append(res, &copied)
+	}
+	slices.SortFunc(res, func(a, b *models.Domain) int {
+		return strings.Compare(a.Host, b.Host)
+	})
+	return res
+}
@@ -4,29 +4,61 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
-	"time"
 
+	"github.com/MatiXxD/url-shortener/internal/audit"
+	auditrepository "github.com/MatiXxD/url-shortener/internal/audit/repository"
 	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/url"
 	"github.com/MatiXxD/url-shortener/pkg/logger"
 )
 
 type FileRepository struct {
 	file       *os.File
 	cache      map[string]*models.URL
+	codes      map[string]bool
+	domains    map[string]*models.Domain
+	history    []*models.URLChange
+	outbox     *memoryOutbox
+	audit      *auditrepository.FileRepository
+	seq        uint64
 	logger     *logger.Logger
 	mu         sync.RWMutex
 	isSaveMode bool
 }
 
+const (
+	// historySuffix is appended to storage filename for destination changes journal
+	historySuffix = ".history"
+	// sequenceSuffix is appended to storage filename for short code counter
+	sequenceSuffix = ".seq"
+	// domainsSuffix is appended to storage filename for short domains registry
+	domainsSuffix = ".domains"
+	// outboxSuffix is appended to storage filename for outbox journal
+	outboxSuffix = ".outbox"
+	// offsetsSuffix is appended to storage filename for offsets of outbox consumers
+	offsetsSuffix = ".offsets"
+)
+
 func NewFileRepository(filename string, logger *logger.Logger) (*FileRepository, error) {
 	// empty filename -> disable saving
 	if filename == "" {
+		audit, err := auditrepository.NewFileRepository("", logger)
+		if err != nil {
+			return nil, err
+		}
 		return &FileRepository{
 			file:       nil,
 			cache:      make(map[string]*models.URL),
+			codes:      make(map[string]bool),
+			domains:    make(map[string]*models.Domain),
+			outbox:     newMemoryOutbox(),
+			audit:      audit,
 			logger:     logger,
 			mu:         sync.RWMutex{},
 			isSaveMode: false,
@@ -42,6 +74,9 @@ func NewFileRepository(filename string, logger *logger.Logger) (*FileRepository,
 	fr := &FileRepository{
 		file:       file,
 		cache:      make(map[string]*models.URL),
+		codes:      make(map[string]bool),
+		domains:    make(map[string]*models.Domain),
+		outbox:     newMemoryOutbox(),
 		logger:     logger,
 		mu:         sync.RWMutex{},
 		isSaveMode: true,
@@ -52,14 +87,45 @@ func NewFileRepository(filename string, logger *logger.Logger) (*FileRepository,
 		return nil, fmt.Errorf("failed to init cache: %w", err)
 	}
 
+	if err := fr.initHistory(); err != nil {
+		logger.Errorf("failed to init history %v: %v", filename, err)
+		return nil, fmt.Errorf("failed to init history: %w", err)
+	}
+
+	if err := fr.initSequence(); err != nil {
+		logger.Errorf("failed to init sequence %v: %v", filename, err)
+		return nil, fmt.Errorf("failed to init sequence: %w", err)
+	}
+
+	if err := fr.initDomains(); err != nil {
+		logger.Errorf("failed to init domains %v: %v", filename, err)
+		return nil, fmt.Errorf("failed to init domains: %w", err)
+	}
+
+	if err := fr.initOutbox(); err != nil {
+		logger.Errorf("failed to init outbox %v: %v", filename, err)
+		return nil, fmt.Errorf("failed to init outbox: %w", err)
+	}
+
+	if err := fr.initAudit(); err != nil {
+		logger.Errorf("failed to init audit log %v: %v", filename, err)
+		return nil, fmt.Errorf("failed to init audit log: %w", err)
+	}
+
 	return fr, nil
 }
 
+// Audit returns audit log which changes of links are recorded to
+func (fr *FileRepository) Audit() audit.Repository {
+	return fr.audit
+}
+
 func (fr *FileRepository) AddURL(ctx context.Context, shortenURL *models.URL) (string, error) {
 	fr.mu.RLock()
-	if got, ok := fr.cache[shortenURL.BaseURL]; ok {
+	if got, ok := fr.cache[urlKey(shortenURL)]; ok {
 		fr.logger.Infof("cache hit for %s: %v", shortenURL.BaseURL, got)
 		fr.mu.RUnlock()
+		shortenURL.Existed = true
 		return got.ShortURL, nil
 	}
 	fr.mu.RUnlock()
@@ -67,59 +133,238 @@ func (fr *FileRepository) AddURL(ctx context.Context, shortenURL *models.URL) (s
 	fr.mu.Lock()
 	defer fr.mu.Unlock()
 
-	url := &models.URL{
-		CorrelationID: shortenURL.CorrelationID,
-		BaseURL:       shortenURL.BaseURL,
-		ShortURL:      shortenURL.ShortURL,
-		CreateAt:      time.Now(),
+	// original could be added by concurrent request since the check
+	if got, ok := fr.cache[urlKey(shortenURL)]; ok {
+		shortenURL.Existed = true
+		return got.ShortURL, nil
+	}
+	if fr.codes[codeKey(shortenURL)] {
+		return "", url.ErrCodeTaken
 	}
 
-	fr.cache[shortenURL.BaseURL] = url
+	url := newStoredURL(shortenURL)
+	var records []*models.OutboxRecord
+	if shortenURL.Event != nil {
+		records = fr.outbox.reserve([]*models.Event{shortenURL.Event})
+	}
 
-	if fr.isSaveMode {
-		if err := fr.saveURL(url); err != nil {
-			fr.logger.Errorf("failed to save url %s: %v", url.BaseURL, err)
-			return "", fmt.Errorf("failed to save url: %w", err)
-		}
+	err := fr.record(auditEntries(shortenURL.Audit), func() error {
+		return fr.saveURLWithOutbox(records, url)
+	})
+	if err != nil {
+		fr.logger.Errorf("failed to save url %s: %v", url.BaseURL, err)
+		return "", fmt.Errorf("failed to save url: %w", err)
 	}
 
+	fr.cache[storeKey(url)] = url
+	fr.codes[codeKey(url)] = true
+	fr.outbox.add(records...)
+
 	return shortenURL.ShortURL, nil
 }
 
-func (mr *FileRepository) BatchAddURL(ctx context.Context, urls []*models.URL) ([]*models.URL, error) {
-	res := make([]*models.URL, 0, len(urls))
+// BatchAddURL writes new urls with one write and applies them to cache only after it succeeded
+func (fr *FileRepository) BatchAddURL(ctx context.Context, urls []*models.URL) ([]*models.URL, error) {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
 
-	for _, u := range urls {
-		shortUrl, err := mr.AddURL(ctx, u)
-		if err != nil {
-			return res, fmt.Errorf("failed to add url=%s: %w", u.BaseURL, err)
+	res, added, err := prepareBatch(fr.cache, fr.codes, urls)
+	if err != nil {
+		return nil, err
+	}
+	records := fr.outbox.reserve(insertedEvents(urls, res))
+
+	err = fr.record(insertedEntries(urls, res), func() error {
+		if len(added) == 0 {
+			return nil
 		}
+		return fr.saveURLWithOutbox(records, added...)
+	})
+	if err != nil {
+		fr.logger.Errorf("failed to save batch: %v", err)
+		return nil, fmt.Errorf("failed to save urls: %w", err)
+	}
 
-		res = append(res, &models.URL{
-			CorrelationID: u.CorrelationID,
-			BaseURL:       u.BaseURL,
-			ShortURL:      shortUrl,
-		})
+	for _, u := range added {
+		fr.cache[storeKey(u)] = u
+		fr.codes[codeKey(u)] = true
 	}
+	fr.outbox.add(records...)
 
 	return res, nil
 }
 
-func (fr *FileRepository) GetURL(ctx context.Context, shortURL string) (*models.URL, error) {
+func (fr *FileRepository) GetURL(ctx context.Context, domain, shortURL string) (*models.URL, error) {
 	fr.mu.RLock()
 	defer fr.mu.RUnlock()
 
-	for _, v := range fr.cache {
-		if v.ShortURL == shortURL {
-			return &models.URL{
-				CorrelationID: v.CorrelationID,
-				BaseURL:       v.BaseURL,
-				ShortURL:      v.ShortURL,
-			}, nil
+	if v := findURL(fr.cache, domain, shortURL); v != nil {
+		u := *v
+		return &u, nil
+	}
+
+	return nil, url.ErrNotFound
+}
+
+func (fr *FileRepository) FindURLs(ctx context.Context, urls []*models.URL) ([]*models.URL, error) {
+	fr.mu.RLock()
+	defer fr.mu.RUnlock()
+
+	return findOrigins(fr.cache, urls), nil
+}
+
+// DeleteURL appends updated model to file, last line wins on cache init
+func (fr *FileRepository) DeleteURL(ctx context.Context, workspace, domain, shortURL string, entry *models.AuditEntry) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	v := findOwnURL(fr.cache, workspace, domain, shortURL)
+	if v == nil {
+		return url.ErrNotFound
+	}
+
+	deleted := *v
+	deleted.IsDeleted = true
+
+	err := fr.record(auditEntries(entry), func() error {
+		return fr.saveURL(&deleted)
+	})
+	if err != nil {
+		fr.logger.Errorf("failed to save url %s: %v", v.BaseURL, err)
+		return fmt.Errorf("failed to save url: %w", err)
+	}
+	delete(fr.cache, storeKey(v))
+	fr.cache[storeKey(&deleted)] = &deleted
+
+	return nil
+}
+
+// UpdateURL appends updated model and change to their files before applying it to cache
+func (fr *FileRepository) UpdateURL(ctx context.Context, workspace, domain, shortURL, originalURL, actor string, entry *models.AuditEntry) (*models.URL, error) {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	updated, change, err := prepareUpdate(fr.cache, workspace, domain, shortURL, originalURL, actor)
+	if err != nil {
+		return nil, err
+	}
+	change.ID = len(fr.history) + 1
+
+	err = fr.record(auditEntries(entry), func() error {
+		if err := fr.saveURL(updated); err != nil {
+			fr.logger.Errorf("failed to save url %s: %v", updated.BaseURL, err)
+			return fmt.Errorf("failed to save url: %w", err)
+		}
+		if err := fr.saveChange(change); err != nil {
+			fr.logger.Errorf("failed to save change of %s: %v", shortURL, err)
+			return fmt.Errorf("failed to save change: %w", err)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, fmt.Errorf("url was not found")
+	fr.history = append(fr.history, change)
+	applyUpdate(fr.cache, updated, change)
+
+	u := *updated
+	return &u, nil
+}
+
+func (fr *FileRepository) GetHistory(ctx context.Context, workspace, domain, shortURL string) ([]*models.URLChange, error) {
+	fr.mu.RLock()
+	defer fr.mu.RUnlock()
+
+	return filterHistory(fr.cache, fr.history, workspace, domain, shortURL), nil
+}
+
+func (fr *FileRepository) ListURLs(ctx context.Context, filter *models.URLFilter) ([]*models.URL, error) {
+	fr.mu.RLock()
+	defer fr.mu.RUnlock()
+
+	return listURLs(fr.cache, filter), nil
+}
+
+func (fr *FileRepository) Stats(ctx context.Context) (*models.Stats, error) {
+	fr.mu.RLock()
+	defer fr.mu.RUnlock()
+
+	return collectStats(fr.cache), nil
+}
+
+// NextID persists counter before returning it, so ids are not reused after restart
+func (fr *FileRepository) NextID(ctx context.Context) (uint64, error) {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	next := fr.seq + 1
+	if fr.isSaveMode {
+		if err := fr.saveSequence(next); err != nil {
+			fr.logger.Errorf("failed to save sequence: %v", err)
+			return 0, fmt.Errorf("failed to save sequence: %w", err)
+		}
+	}
+	fr.seq = next
+
+	return next, nil
+}
+
+func (fr *FileRepository) AddDomain(ctx context.Context, d *models.Domain) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	stored, err := addDomain(fr.domains, d)
+	if err != nil {
+		return err
+	}
+
+	if fr.isSaveMode {
+		if err := fr.saveDomains(); err != nil {
+			delete(fr.domains, stored.Host)
+			fr.logger.Errorf("failed to save domains: %v", err)
+			return fmt.Errorf("failed to save domains: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (fr *FileRepository) ListDomains(ctx context.Context) ([]*models.Domain, error) {
+	fr.mu.RLock()
+	defer fr.mu.RUnlock()
+
+	return listDomains(fr.domains), nil
+}
+
+func (fr *FileRepository) DeleteDomain(ctx context.Context, host string) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	d, ok := fr.domains[host]
+	if !ok {
+		return url.ErrNotFound
+	}
+	delete(fr.domains, host)
+
+	if fr.isSaveMode {
+		if err := fr.saveDomains(); err != nil {
+			fr.domains[host] = d
+			fr.logger.Errorf("failed to save domains: %v", err)
+			return fmt.Errorf("failed to save domains: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ConsumeOutbox saves offsets after every batch, records sent before crash are sent again
+func (fr *FileRepository) ConsumeOutbox(ctx context.Context, consumer string, limit int, fn func([]*models.OutboxRecord) error) (int, error) {
+	var commit func(map[string]int64) error
+	if fr.isSaveMode {
+		commit = fr.saveOffsets
+	}
+	return fr.outbox.consume(&fr.mu, consumer, limit, fn, commit)
 }
 
 func (fr *FileRepository) initCache() error {
@@ -129,7 +374,8 @@ func (fr *FileRepository) initCache() error {
 		return err
 	}
 
-	// each model should be on new line
+	// each model should be on new line, updated url replaces previous line with the same short url
+	origins := make(map[string]string)
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
 		var u models.URL
@@ -137,7 +383,12 @@ func (fr *FileRepository) initCache() error {
 			fr.logger.Errorf("failed to unmarshal json: %v", err)
 			return err
 		}
-		fr.cache[u.BaseURL] = &u
+		if prev, ok := origins[codeKey(&u)]; ok && prev != storeKey(&u) {
+			delete(fr.cache, prev)
+		}
+		origins[codeKey(&u)] = storeKey(&u)
+		fr.cache[storeKey(&u)] = &u
+		fr.codes[codeKey(&u)] = true
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -148,14 +399,228 @@ func (fr *FileRepository) initCache() error {
 	return nil
 }
 
-func (fr *FileRepository) saveURL(url *models.URL) error {
-	data, err := json.Marshal(url)
+func (fr *FileRepository) initHistory() error {
+	file, err := os.OpenFile(fr.file.Name()+historySuffix, os.O_RDONLY|os.O_CREATE, 0666)
 	if err != nil {
-		fr.logger.Errorf("failed to marshal url %v: %v", url, err)
+		fr.logger.Errorf("failed to open file %v: %v", fr.file.Name()+historySuffix, err)
 		return err
 	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var rec changeRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			fr.logger.Errorf("failed to unmarshal json: %v", err)
+			return err
+		}
+		fr.history = append(fr.history, rec.toModel())
+	}
+
+	return scanner.Err()
+}
+
+func (fr *FileRepository) initSequence() error {
+	data, err := os.ReadFile(fr.file.Name() + sequenceSuffix)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	seq, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return fmt.Errorf("malformed sequence: %w", err)
+	}
+	fr.seq = seq
+
+	return nil
+}
+
+func (fr *FileRepository) initDomains() error {
+	data, err := os.ReadFile(fr.file.Name() + domainsSuffix)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var domains []*models.Domain
+	if err := json.Unmarshal(data, &domains); err != nil {
+		return fmt.Errorf("malformed domains: %w", err)
+	}
+	for _, d := range domains {
+		fr.domains[d.Host] = d
+	}
+
+	return nil
+}
+
+// initOutbox loads outbox journal and offsets, cache must be loaded first.
+// Records are written before their urls, so records of urls which are missing were not committed.
+func (fr *FileRepository) initOutbox() error {
+	file, err := os.OpenFile(fr.file.Name()+outboxSuffix, os.O_RDONLY|os.O_CREATE, 0666)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var rec models.OutboxRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			fr.logger.Errorf("failed to unmarshal json: %v", err)
+			return err
+		}
+		fr.outbox.seq = max(fr.outbox.seq, rec.Offset)
+
+		if rec.Event != nil && rec.Event.Link != nil && !fr.codes[linkKey(rec.Event.Link.ShortDomain, rec.Event.Link.Code)] {
+			fr.logger.Warnf("skipping outbox record %d, its url %s was not saved", rec.Offset, rec.Event.Link.Code)
+			continue
+		}
+		fr.outbox.add(&rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(fr.file.Name() + offsetsSuffix)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &fr.outbox.offsets); err != nil {
+		return fmt.Errorf("malformed outbox offsets: %w", err)
+	}
+
+	return nil
+}
+
+// initAudit loads audit log, cache must be loaded first. Entries are written before their changes,
+// so entries at the end of log which changes are missing were not committed.
+func (fr *FileRepository) initAudit() error {
+	audit, err := auditrepository.NewFileRepository(fr.file.Name(), fr.logger)
+	if err != nil {
+		return err
+	}
+	fr.audit = audit
+
+	return fr.audit.CutUncommitted(func(e *models.AuditEntry) bool {
+		return changeSaved(fr.cache, e)
+	})
+}
+
+// record writes change with save and its audit entries before it, entries are kept only if save succeeds.
+// Nothing is written to files unless save mode is on.
+func (fr *FileRepository) record(entries []*models.AuditEntry, save func() error) error {
+	if !fr.isSaveMode {
+		save = func() error { return nil }
+	}
+	return fr.audit.AddEntriesWith(entries, save)
+}
+
+func (fr *FileRepository) saveSequence(seq uint64) error {
+	return replaceFile(fr.file.Name()+sequenceSuffix, []byte(strconv.FormatUint(seq, 10)+"\n"))
+}
+
+// saveDomains rewrites whole registry, it is small and changes rarely
+func (fr *FileRepository) saveDomains() error {
+	data, err := json.Marshal(listDomains(fr.domains))
+	if err != nil {
+		return err
+	}
+	return replaceFile(fr.file.Name()+domainsSuffix, data)
+}
+
+// replaceFile writes file atomically, so crash never leaves it half written
+func replaceFile(name string, data []byte) error {
+	tmp := name + ".tmp"
+	if err := os.WriteFile(tmp, data, 0666); err != nil {
+		return err
+	}
+	return os.Rename(tmp, name)
+}
+
+func (fr *FileRepository) saveChange(change *models.URLChange) error {
+	data, err := json.Marshal(newChangeRecord(change))
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(fr.file.Name()+historySuffix, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// saveURLWithOutbox appends outbox records before urls, records are cut off if urls are not written
+func (fr *FileRepository) saveURLWithOutbox(records []*models.OutboxRecord, urls ...*models.URL) error {
+	if len(records) == 0 {
+		return fr.saveURL(urls...)
+	}
+
+	var data []byte
+	for _, rec := range records {
+		line, err := json.Marshal(rec)
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+
+	name := fr.file.Name() + outboxSuffix
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		return err
+	}
+
+	if err := fr.saveURL(urls...); err != nil {
+		if err := file.Truncate(info.Size()); err != nil {
+			fr.logger.Errorf("failed to truncate outbox %v: %v", name, err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (fr *FileRepository) saveOffsets(offsets map[string]int64) error {
+	data, err := json.Marshal(offsets)
+	if err != nil {
+		return err
+	}
+	return replaceFile(fr.file.Name()+offsetsSuffix, data)
+}
+
+// saveURL appends models to file with one write, each one on its own line
+func (fr *FileRepository) saveURL(urls ...*models.URL) error {
+	var data []byte
+	for _, url := range urls {
+		line, err := json.Marshal(url)
+		if err != nil {
+			fr.logger.Errorf("failed to marshal url %v: %v", url, err)
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
 
-	// write url model with new linea
 	file, err := os.OpenFile(fr.file.Name(), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
 	if err != nil {
 		fr.logger.Errorf("failed to open file %v: %v", fr.file.Name(), err)
@@ -163,7 +628,7 @@ func (fr *FileRepository) saveURL(url *models.URL) error {
 	}
 	defer file.Close()
 
-	_, err = file.WriteString(string(data) + "\n")
+	_, err = file.Write(data)
 	if err != nil {
 		fr.logger.Errorf("failed to write file %v: %v", fr.file.Name(), err)
 		return err
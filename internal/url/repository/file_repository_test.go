@@ -3,10 +3,14 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/url"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
@@ -236,7 +240,7 @@ func TestFileRepository_GetURL(t *testing.T) {
 
 			fr.cache = tt.cache
 
-			gotURL, err := fr.GetURL(context.Background(), tt.inputShort)
+			gotURL, err := fr.GetURL(context.Background(), "", tt.inputShort)
 			if !tt.wantFound {
 				require.ErrorContains(t, err, "not found")
 			} else {
@@ -247,3 +251,373 @@ func TestFileRepository_GetURL(t *testing.T) {
 		})
 	}
 }
+
+func TestFileRepository_DeleteURL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.json")
+
+	fr, err := NewFileRepository(path, l)
+	require.NoError(t, err)
+
+	_, err = fr.AddURL(context.Background(), &models.URL{BaseURL: "http://example.com", ShortURL: "abc123"})
+	require.NoError(t, err)
+
+	require.NoError(t, fr.DeleteURL(context.Background(), "", "", "abc123", nil))
+	require.ErrorIs(t, fr.DeleteURL(context.Background(), "", "", "not_found", nil), url.ErrNotFound)
+
+	// deletion must survive restart
+	fr, err = NewFileRepository(path, l)
+	require.NoError(t, err)
+
+	got, err := fr.GetURL(context.Background(), "", "abc123")
+	require.NoError(t, err)
+	require.True(t, got.IsDeleted)
+}
+
+func TestFileRepository_AddDeletedURL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.json")
+	ctx := context.Background()
+
+	fr, err := NewFileRepository(path, l)
+	require.NoError(t, err)
+
+	_, err = fr.AddURL(ctx, &models.URL{BaseURL: "http://example.com", ShortURL: "abc123"})
+	require.NoError(t, err)
+	require.NoError(t, fr.DeleteURL(ctx, "", "", "abc123", nil))
+
+	// deleted url doesn't hold its original
+	got, err := fr.AddURL(ctx, &models.URL{BaseURL: "http://example.com", ShortURL: "def456", QuotaKeys: []string{"q1"}})
+	require.NoError(t, err)
+	require.Equal(t, "def456", got)
+
+	// both urls must survive restart
+	fr, err = NewFileRepository(path, l)
+	require.NoError(t, err)
+	require.Len(t, fr.cache, 2)
+
+	found, err := fr.FindURLs(ctx, []*models.URL{{BaseURL: "http://example.com"}, {BaseURL: "http://example.org"}})
+	require.NoError(t, err)
+	require.Equal(t, "def456", found[0].ShortURL)
+	require.Equal(t, []string{"q1"}, found[0].QuotaKeys)
+	require.Nil(t, found[1])
+
+	res, err := fr.BatchAddURL(ctx, []*models.URL{{CorrelationID: "1", BaseURL: "http://example.com", ShortURL: "ghi789"}})
+	require.NoError(t, err)
+	require.Equal(t, "def456", res[0].ShortURL)
+	require.True(t, res[0].Existed)
+
+	deleted, err := fr.GetURL(ctx, "", "abc123")
+	require.NoError(t, err)
+	require.True(t, deleted.IsDeleted)
+}
+
+func TestFileRepository_UpdateURL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.json")
+
+	fr, err := NewFileRepository(path, l)
+	require.NoError(t, err)
+
+	_, err = fr.AddURL(context.Background(), &models.URL{BaseURL: "http://example.com/typo", ShortURL: "abc123"})
+	require.NoError(t, err)
+	_, err = fr.AddURL(context.Background(), &models.URL{BaseURL: "http://other.com", ShortURL: "def456"})
+	require.NoError(t, err)
+
+	_, err = fr.UpdateURL(context.Background(), "", "", "abc123", "http://other.com", "user-1", nil)
+	require.ErrorIs(t, err, url.ErrConflict)
+
+	updated, err := fr.UpdateURL(context.Background(), "", "", "abc123", "http://example.com/fixed", "user-1", nil)
+	require.NoError(t, err)
+	require.Equal(t, "http://example.com/fixed", updated.BaseURL)
+
+	// updated url and history must survive restart
+	fr, err = NewFileRepository(path, l)
+	require.NoError(t, err)
+	require.Len(t, fr.cache, 2)
+
+	got, err := fr.GetURL(context.Background(), "", "abc123")
+	require.NoError(t, err)
+	require.Equal(t, "http://example.com/fixed", got.BaseURL)
+
+	history, err := fr.GetHistory(context.Background(), "", "", "abc123")
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	require.Equal(t, "http://example.com/typo", history[0].PreviousURL)
+	require.Equal(t, "user-1", history[0].ChangedBy)
+}
+
+func TestFileRepository_NextID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.json")
+
+	fr, err := NewFileRepository(path, l)
+	require.NoError(t, err)
+
+	for want := uint64(1); want <= 3; want++ {
+		got, err := fr.NextID(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+
+	_, err = fr.AddURL(context.Background(), &models.URL{BaseURL: "http://example.com", ShortURL: "abc123"})
+	require.NoError(t, err)
+	_, err = fr.AddURL(context.Background(), &models.URL{BaseURL: "http://example.org", ShortURL: "abc123"})
+	require.ErrorIs(t, err, url.ErrCodeTaken)
+
+	// counter must not go back after restart
+	fr, err = NewFileRepository(path, l)
+	require.NoError(t, err)
+
+	got, err := fr.NextID(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), got)
+}
+
+func TestFileRepository_BatchAddURL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.json")
+
+	fr, err := NewFileRepository(path, l)
+	require.NoError(t, err)
+
+	_, err = fr.AddURL(context.Background(), &models.URL{BaseURL: "http://example.com", ShortURL: "abc123"})
+	require.NoError(t, err)
+
+	// taken short url rejects the whole batch
+	_, err = fr.BatchAddURL(context.Background(), []*models.URL{
+		{BaseURL: "http://example.org", ShortURL: "def456"},
+		{BaseURL: "http://example.net", ShortURL: "abc123"},
+	})
+	require.ErrorIs(t, err, url.ErrCodeTaken)
+	_, err = fr.GetURL(context.Background(), "", "def456")
+	require.ErrorIs(t, err, url.ErrNotFound)
+
+	res, err := fr.BatchAddURL(context.Background(), []*models.URL{
+		{CorrelationID: "1", BaseURL: "http://example.org", ShortURL: "def456"},
+		{CorrelationID: "2", BaseURL: "http://example.com", ShortURL: "ghi789"},
+		{CorrelationID: "3", BaseURL: "http://example.org", ShortURL: "jkl012"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []*models.URL{
+		{CorrelationID: "1", BaseURL: "http://example.org", ShortURL: "def456"},
+		{CorrelationID: "2", BaseURL: "http://example.com", ShortURL: "abc123", Existed: true},
+		{CorrelationID: "3", BaseURL: "http://example.org", ShortURL: "def456", Existed: true},
+	}, res)
+
+	// batch must survive restart
+	fr, err = NewFileRepository(path, l)
+	require.NoError(t, err)
+
+	got, err := fr.GetURL(context.Background(), "", "def456")
+	require.NoError(t, err)
+	require.Equal(t, "http://example.org", got.BaseURL)
+}
+
+func TestFileRepository_ShortDomains(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.json")
+	ctx := context.Background()
+
+	fr, err := NewFileRepository(path, l)
+	require.NoError(t, err)
+
+	_, err = fr.AddURL(ctx, &models.URL{BaseURL: "http://example.com", ShortURL: "abc123"})
+	require.NoError(t, err)
+	_, err = fr.AddURL(ctx, &models.URL{BaseURL: "http://example.org", ShortURL: "abc123", ShortDomain: "go.brand.com"})
+	require.NoError(t, err)
+	require.NoError(t, fr.AddDomain(ctx, &models.Domain{Host: "go.brand.com", BaseURL: "https://go.brand.com"}))
+
+	// links and domains must survive restart
+	fr, err = NewFileRepository(path, l)
+	require.NoError(t, err)
+
+	got, err := fr.GetURL(ctx, "", "abc123")
+	require.NoError(t, err)
+	require.Equal(t, "http://example.com", got.BaseURL)
+
+	got, err = fr.GetURL(ctx, "go.brand.com", "abc123")
+	require.NoError(t, err)
+	require.Equal(t, "http://example.org", got.BaseURL)
+
+	domains, err := fr.ListDomains(ctx)
+	require.NoError(t, err)
+	require.Len(t, domains, 1)
+	require.Equal(t, "go.brand.com", domains[0].Host)
+
+	require.NoError(t, fr.DeleteDomain(ctx, "go.brand.com"))
+	fr, err = NewFileRepository(path, l)
+	require.NoError(t, err)
+
+	domains, err = fr.ListDomains(ctx)
+	require.NoError(t, err)
+	require.Empty(t, domains)
+}
+
+func TestFileRepository_Outbox(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.json")
+	ctx := context.Background()
+
+	withEvent := func(u *models.URL) *models.URL {
+		u.Event = &models.Event{ID: uuid.NewString(), Type: models.EventLinkCreated, Link: &models.EventLink{Code: u.ShortURL}}
+		return u
+	}
+	consume := func(fr *FileRepository, consumer string) []string {
+		var codes []string
+		_, err := fr.ConsumeOutbox(ctx, consumer, 10, func(records []*models.OutboxRecord) error {
+			for _, rec := range records {
+				codes = append(codes, rec.Event.Link.Code)
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		return codes
+	}
+
+	fr, err := NewFileRepository(path, l)
+	require.NoError(t, err)
+
+	_, err = fr.AddURL(ctx, withEvent(&models.URL{BaseURL: "http://example.com", ShortURL: "abc123"}))
+	require.NoError(t, err)
+	// existing url writes no event
+	_, err = fr.AddURL(ctx, withEvent(&models.URL{BaseURL: "http://example.com", ShortURL: "zzz999"}))
+	require.NoError(t, err)
+	_, err = fr.BatchAddURL(ctx, []*models.URL{
+		withEvent(&models.URL{BaseURL: "http://example.org", ShortURL: "def456"}),
+		withEvent(&models.URL{BaseURL: "http://example.com", ShortURL: "ghi789"}),
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"abc123", "def456"}, consume(fr, "a"))
+	require.Empty(t, consume(fr, "a"))
+
+	// record written before crash, its url was not
+	f, err := os.OpenFile(path+outboxSuffix, os.O_WRONLY|os.O_APPEND, 0666)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"offset":3,"event":{"id":"x","type":"link.created","link":{"short_url":"","code":"lost","original_url":"http://lost.com"}}}` + "\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// records and offsets must survive restart
+	fr, err = NewFileRepository(path, l)
+	require.NoError(t, err)
+
+	_, err = fr.AddURL(ctx, withEvent(&models.URL{BaseURL: "http://example.net", ShortURL: "jkl012"}))
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"jkl012"}, consume(fr, "a"))
+	require.Equal(t, []string{"abc123", "def456", "jkl012"}, consume(fr, "b"))
+
+	fr, err = NewFileRepository(path, l)
+	require.NoError(t, err)
+	require.Empty(t, consume(fr, "b"))
+}
+
+func TestFileRepository_Workspaces(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.json")
+	ctx := context.Background()
+
+	fr, err := NewFileRepository(path, l)
+	require.NoError(t, err)
+
+	_, err = fr.AddURL(ctx, &models.URL{BaseURL: "http://example.com", ShortURL: "abc123"})
+	require.NoError(t, err)
+	_, err = fr.AddURL(ctx, &models.URL{BaseURL: "http://example.com", ShortURL: "def456", Workspace: "team"})
+	require.NoError(t, err)
+	_, err = fr.UpdateURL(ctx, "team", "", "def456", "http://example.org", "user-1", nil)
+	require.NoError(t, err)
+
+	// workspace of url is kept in file
+	fr, err = NewFileRepository(path, l)
+	require.NoError(t, err)
+
+	u := &models.URL{BaseURL: "http://example.org", ShortURL: "ghi789", Workspace: "team"}
+	got, err := fr.AddURL(ctx, u)
+	require.NoError(t, err)
+	require.Equal(t, "def456", got)
+	require.True(t, u.Existed)
+
+	require.ErrorIs(t, fr.DeleteURL(ctx, "", "", "def456", nil), url.ErrNotFound)
+	require.NoError(t, fr.DeleteURL(ctx, "team", "", "def456", nil))
+}
+
+func TestFileRepository_Audit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.json")
+	ctx := context.Background()
+
+	created := func(u *models.URL) *models.URL {
+		u.Audit = &models.AuditEntry{Action: models.AuditCreate, Code: u.ShortURL, After: &models.AuditState{OriginalURL: u.BaseURL}}
+		return u
+	}
+	actions := func(fr *FileRepository) []string {
+		entries, err := fr.Audit().ListEntries(ctx, &models.AuditFilter{})
+		require.NoError(t, err)
+		res := make([]string, 0, len(entries))
+		for _, e := range entries {
+			res = append(res, e.Action+" "+e.Code)
+		}
+		return res
+	}
+
+	fr, err := NewFileRepository(path, l)
+	require.NoError(t, err)
+
+	_, err = fr.AddURL(ctx, created(&models.URL{BaseURL: "http://example.com", ShortURL: "abc123"}))
+	require.NoError(t, err)
+	// existing url is not changed, so it is not recorded
+	_, err = fr.AddURL(ctx, created(&models.URL{BaseURL: "http://example.com", ShortURL: "zzz999"}))
+	require.NoError(t, err)
+	_, err = fr.UpdateURL(ctx, "", "", "abc123", "http://example.org", "user-1", &models.AuditEntry{
+		Action: models.AuditUpdate, Code: "abc123", After: &models.AuditState{OriginalURL: "http://example.org"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"update abc123", "create abc123"}, actions(fr))
+
+	// entry written before crash, its change was not
+	f, err := os.OpenFile(path+".audit", os.O_WRONLY|os.O_APPEND, 0666)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"id":3,"action":"delete","code":"abc123","after":{"original_url":"http://example.org","deleted":true}}` + "\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	fr, err = NewFileRepository(path, l)
+	require.NoError(t, err)
+	require.Equal(t, []string{"update abc123", "create abc123"}, actions(fr))
+
+	entry := &models.AuditEntry{Action: models.AuditDelete, Code: "abc123", After: &models.AuditState{OriginalURL: "http://example.org", Deleted: true}}
+	require.NoError(t, fr.DeleteURL(ctx, "", "", "abc123", entry))
+	require.Equal(t, int64(3), entry.ID)
+
+	fr, err = NewFileRepository(path, l)
+	require.NoError(t, err)
+	require.Equal(t, []string{"delete abc123", "update abc123", "create abc123"}, actions(fr))
+}
+
+func TestFileRepository_AddURLConcurrent(t *testing.T) {
+	ctx := context.Background()
+	fr, err := NewFileRepository(filepath.Join(t.TempDir(), "db.json"), l)
+	require.NoError(t, err)
+
+	const n = 20
+	shorts := make([]string, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			code := fmt.Sprintf("code%02d", i)
+			u := &models.URL{BaseURL: "http://example.com", ShortURL: code}
+			u.Event = &models.Event{ID: code, Type: models.EventLinkCreated, Link: &models.EventLink{Code: code}}
+			short, err := fr.AddURL(ctx, u)
+			require.NoError(t, err)
+			shorts[i] = short
+		}()
+	}
+	wg.Wait()
+
+	// the same original is saved once with one event
+	for _, s := range shorts {
+		require.Equal(t, shorts[0], s)
+	}
+	records := 0
+	_, err = fr.ConsumeOutbox(ctx, "a", n, func(batch []*models.OutboxRecord) error {
+		records = len(batch)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, records)
+}
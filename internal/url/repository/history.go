@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/url"
+)
+
+// prepareUpdate checks destination change for in-memory storages without applying it,
+// caller must hold the lock
+func prepareUpdate(urls map[string]*models.URL, workspace, domain, shortURL, originalURL, actor string) (*models.URL, *models.URLChange, error) {
+	current := findOwnURL(urls, workspace, domain, shortURL)
+	if current == nil {
+		return nil, nil, url.ErrNotFound
+	}
+	if _, ok := urls[originKey(workspace, domain, originalURL)]; ok && current.BaseURL != originalURL {
+		return nil, nil, url.ErrConflict
+	}
+
+	change := &models.URLChange{
+		ShortURL:    shortURL,
+		ShortDomain: domain,
+		PreviousURL: current.BaseURL,
+		NewURL:      originalURL,
+		ChangedBy:   actor,
+		ChangedAt:   time.Now(),
+	}
+
+	updated := *current
+	updated.BaseURL = originalURL
+
+	return &updated, change, nil
+}
+
+// applyUpdate moves url to its new original key
+func applyUpdate(urls map[string]*models.URL, updated *models.URL, change *models.URLChange) {
+	delete(urls, originKey(updated.Workspace, change.ShortDomain, change.PreviousURL))
+	urls[storeKey(updated)] = updated
+}
+
+// filterHistory returns changes of short url of workspace, newest first. Caller must hold the lock.
+func filterHistory(urls map[string]*models.URL, history []*models.URLChange, workspace, domain, shortURL string) []*models.URLChange {
+	res := make([]*models.URLChange, 0)
+	if findOwnURL(urls, workspace, domain, shortURL) == nil {
+		return res
+	}
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].ShortURL == shortURL && history[i].ShortDomain == domain {
+			c := *history[i]
+			res = append(res, &c)
+		}
+	}
+	return res
+}
+
+// changeRecord is a line of file history journal, unlike API model it keeps short url
+type changeRecord struct {
+	ID          int       `json:"id"`
+	ShortURL    string    `json:"short_url"`
+	ShortDomain string    `json:"short_domain,omitempty"`
+	PreviousURL string    `json:"previous_url"`
+	NewURL      string    `json:"new_url"`
+	ChangedBy   string    `json:"changed_by,omitempty"`
+	ChangedAt   time.Time `json:"changed_at"`
+}
+
+func newChangeRecord(c *models.URLChange) *changeRecord {
+	return &changeRecord{
+		ID:          c.ID,
+		ShortURL:    c.ShortURL,
+		ShortDomain: c.ShortDomain,
+		PreviousURL: c.PreviousURL,
+		NewURL:      c.NewURL,
+		ChangedBy:   c.ChangedBy,
+		ChangedAt:   c.ChangedAt,
+	}
+}
+
+func (r *changeRecord) toModel() *models.URLChange {
+	return &models.URLChange{
+		ID:          r.ID,
+		ShortURL:    r.ShortURL,
+		ShortDomain: r.ShortDomain,
+		PreviousURL: r.PreviousURL,
+		NewURL:      r.NewURL,
+		ChangedBy:   r.ChangedBy,
+		ChangedAt:   r.ChangedAt,
+	}
+}
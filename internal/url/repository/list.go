@@ -0,0 +1,88 @@
+package repository
+
+import (
+	neturl "net/url"
+	"slices"
+	"strings"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+)
+
+// listURLs filters and pages urls of in-memory storages, caller must hold the lock.
+// Whole user set is sorted on each call which is fine for storages kept in memory.
+func listURLs(urls map[string]*models.URL, filter *models.URLFilter) []*models.URL {
+	res := make([]*models.URL, 0)
+	for _, u := range urls {
+		if matchFilter(u, filter) {
+			res = append(res, u)
+		}
+	}
+
+	compare := func(a, b *models.URL) int {
+		var c int
+		switch filter.Sort {
+		case models.SortShortURL:
+		case models.SortOriginalURL:
+			c = strings.Compare(a.BaseURL, b.BaseURL)
+		default:
+			c = a.CreateAt.Compare(b.CreateAt)
+		}
+		if c == 0 {
+			c = strings.Compare(a.ShortURL, b.ShortURL)
+		}
+		if c == 0 {
+			c = strings.Compare(a.ShortDomain, b.ShortDomain)
+		}
+		if filter.Desc {
+			return -c
+		}
+		return c
+	}
+	slices.SortFunc(res, compare)
+
+	if c := filter.After; c != nil {
+		after := &models.URL{CreateAt: c.CreatedAt, BaseURL: c.Value, ShortURL: c.ShortURL, ShortDomain: c.ShortDomain}
+		start, _ := slices.BinarySearchFunc(res, after, compare)
+		for start < len(res) && compare(res[start], after) <= 0 {
+			start++
+		}
+		res = res[start:]
+	}
+
+	res = res[:min(len(res), filter.Limit)]
+	for i, u := range res {
+		copied := *u
+		copied.Tags = slices.Clone(u.Tags)
+		res[i] = &copied
+	}
+
+	return res
+}
+
+func matchFilter(u *models.URL, filter *models.URLFilter) bool {
+	switch {
+	case u.UserID != filter.UserID, u.Workspace != filter.Workspace:
+		return false
+	case filter.Tag != "" && !slices.Contains(u.Tags, filter.Tag):
+		return false
+	case filter.Domain != "" && !strings.EqualFold(domain(u.BaseURL), filter.Domain):
+		return false
+	case filter.ShortDomain != nil && u.ShortDomain != *filter.ShortDomain:
+		return false
+	case !filter.CreatedFrom.IsZero() && u.CreateAt.Before(filter.CreatedFrom):
+		return false
+	case !filter.CreatedTo.IsZero() && !u.CreateAt.Before(filter.CreatedTo):
+		return false
+	case filter.Deleted != nil && u.IsDeleted != *filter.Deleted:
+		return false
+	}
+	return true
+}
+
+func domain(original string) string {
+	u, err := neturl.Parse(original)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
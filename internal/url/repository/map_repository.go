@@ -2,27 +2,40 @@ package repository
 
 import (
 	"context"
-	"fmt"
 	"sync"
-	"time"
 
+	"github.com/MatiXxD/url-shortener/internal/audit"
+	auditrepository "github.com/MatiXxD/url-shortener/internal/audit/repository"
 	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/url"
 	"github.com/MatiXxD/url-shortener/pkg/logger"
 )
 
 type MapRepository struct {
-	db     map[string]*models.URL
-	pk     int
-	logger *logger.Logger
-	mu     sync.RWMutex
+	db      map[string]*models.URL
+	history []*models.URLChange
+	codes   map[string]bool
+	domains map[string]*models.Domain
+	outbox  *memoryOutbox
+	audit   *auditrepository.FileRepository
+	pk      int
+	seq     uint64
+	logger  *logger.Logger
+	mu      sync.RWMutex
 }
 
 func NewMapRepository(d map[string]*models.URL, l *logger.Logger) *MapRepository {
+	// audit log without file is kept in memory and can't fail
+	audit, _ := auditrepository.NewFileRepository("", l)
 	return &MapRepository{
-		db:     d,
-		pk:     1,
-		logger: l,
-		mu:     sync.RWMutex{},
+		db:      d,
+		codes:   codeIndex(d),
+		domains: make(map[string]*models.Domain),
+		outbox:  newMemoryOutbox(),
+		audit:   audit,
+		pk:      1,
+		logger:  l,
+		mu:      sync.RWMutex{},
 	}
 }
 
@@ -30,54 +43,167 @@ func (mr *MapRepository) AddURL(ctx context.Context, shortenURL *models.URL) (st
 	mr.mu.Lock()
 	defer mr.mu.Unlock()
 
-	if got, ok := mr.db[shortenURL.BaseURL]; ok {
+	if got, ok := mr.db[urlKey(shortenURL)]; ok {
+		shortenURL.Existed = true
 		return got.ShortURL, nil
 	}
+	if mr.codes[codeKey(shortenURL)] {
+		return "", url.ErrCodeTaken
+	}
 
-	mr.db[shortenURL.BaseURL] = &models.URL{
-		ID:            mr.pk,
-		CorrelationID: shortenURL.CorrelationID,
-		BaseURL:       shortenURL.BaseURL,
-		ShortURL:      shortenURL.ShortURL,
-		CreateAt:      time.Now(),
+	mr.add(newStoredURL(shortenURL))
+	if shortenURL.Event != nil {
+		mr.outbox.add(mr.outbox.reserve([]*models.Event{shortenURL.Event})...)
 	}
-	mr.pk++
+	mr.record(auditEntries(shortenURL.Audit))
 
 	return shortenURL.ShortURL, nil
 }
 
+// BatchAddURL saves all urls or none of them
 func (mr *MapRepository) BatchAddURL(ctx context.Context, urls []*models.URL) ([]*models.URL, error) {
-	res := make([]*models.URL, 0, len(urls))
-
-	for _, u := range urls {
-		shortUrl, err := mr.AddURL(ctx, u)
-		if err != nil {
-			return res, fmt.Errorf("failed to add url=%s: %w", u.BaseURL, err)
-		}
-
-		res = append(res, &models.URL{
-			CorrelationID: u.CorrelationID,
-			BaseURL:       u.BaseURL,
-			ShortURL:      shortUrl,
-		})
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	res, added, err := prepareBatch(mr.db, mr.codes, urls)
+	if err != nil {
+		return nil, err
 	}
+	for _, u := range added {
+		mr.add(u)
+	}
+	mr.outbox.add(mr.outbox.reserve(insertedEvents(urls, res))...)
+	mr.record(insertedEntries(urls, res))
 
 	return res, nil
 }
 
-func (mr *MapRepository) GetURL(ctx context.Context, shortURL string) (*models.URL, error) {
+// Audit returns audit log which changes of links are recorded to
+func (mr *MapRepository) Audit() audit.Repository {
+	return mr.audit
+}
+
+// record adds audit entries of change, caller must hold the lock
+func (mr *MapRepository) record(entries []*models.AuditEntry) {
+	_ = mr.audit.AddEntries(context.Background(), entries)
+}
+
+// add stores url with next id, caller must hold the lock
+func (mr *MapRepository) add(u *models.URL) {
+	u.ID = mr.pk
+	mr.pk++
+
+	mr.db[storeKey(u)] = u
+	mr.codes[codeKey(u)] = true
+}
+
+func (mr *MapRepository) GetURL(ctx context.Context, domain, shortURL string) (*models.URL, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	if v := findURL(mr.db, domain, shortURL); v != nil {
+		u := *v
+		return &u, nil
+	}
+
+	return nil, url.ErrNotFound
+}
+
+func (mr *MapRepository) FindURLs(ctx context.Context, urls []*models.URL) ([]*models.URL, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	return findOrigins(mr.db, urls), nil
+}
+
+func (mr *MapRepository) DeleteURL(ctx context.Context, workspace, domain, shortURL string, entry *models.AuditEntry) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	if v := findOwnURL(mr.db, workspace, domain, shortURL); v != nil {
+		delete(mr.db, storeKey(v))
+		v.IsDeleted = true
+		mr.db[storeKey(v)] = v
+		mr.record(auditEntries(entry))
+		return nil
+	}
+
+	return url.ErrNotFound
+}
+
+func (mr *MapRepository) UpdateURL(ctx context.Context, workspace, domain, shortURL, originalURL, actor string, entry *models.AuditEntry) (*models.URL, error) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	updated, change, err := prepareUpdate(mr.db, workspace, domain, shortURL, originalURL, actor)
+	if err != nil {
+		return nil, err
+	}
+	change.ID = len(mr.history) + 1
+	mr.history = append(mr.history, change)
+	applyUpdate(mr.db, updated, change)
+	mr.record(auditEntries(entry))
+
+	u := *updated
+	return &u, nil
+}
+
+func (mr *MapRepository) GetHistory(ctx context.Context, workspace, domain, shortURL string) ([]*models.URLChange, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	return filterHistory(mr.db, mr.history, workspace, domain, shortURL), nil
+}
+
+func (mr *MapRepository) ListURLs(ctx context.Context, filter *models.URLFilter) ([]*models.URL, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	return listURLs(mr.db, filter), nil
+}
+
+func (mr *MapRepository) Stats(ctx context.Context) (*models.Stats, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	return collectStats(mr.db), nil
+}
+
+func (mr *MapRepository) NextID(ctx context.Context) (uint64, error) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	mr.seq++
+	return mr.seq, nil
+}
+
+func (mr *MapRepository) AddDomain(ctx context.Context, d *models.Domain) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	_, err := addDomain(mr.domains, d)
+	return err
+}
+
+func (mr *MapRepository) ListDomains(ctx context.Context) ([]*models.Domain, error) {
 	mr.mu.RLock()
 	defer mr.mu.RUnlock()
 
-	for _, v := range mr.db {
-		if v.ShortURL == shortURL {
-			return &models.URL{
-				CorrelationID: v.CorrelationID,
-				BaseURL:       v.BaseURL,
-				ShortURL:      v.ShortURL,
-			}, nil
-		}
+	return listDomains(mr.domains), nil
+}
+
+func (mr *MapRepository) DeleteDomain(ctx context.Context, host string) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	if _, ok := mr.domains[host]; !ok {
+		return url.ErrNotFound
 	}
+	delete(mr.domains, host)
+
+	return nil
+}
 
-	return nil, fmt.Errorf("url was not found")
+func (mr *MapRepository) ConsumeOutbox(ctx context.Context, consumer string, limit int, fn func([]*models.OutboxRecord) error) (int, error) {
+	return mr.outbox.consume(&mr.mu, consumer, limit, fn, nil)
 }
@@ -3,8 +3,10 @@ package repository
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/url"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
@@ -48,6 +50,34 @@ func TestMapRepository_AddURL(t *testing.T) {
 	})
 }
 
+func TestMapRepository_AddDeletedURL(t *testing.T) {
+	repo := NewMapRepository(map[string]*models.URL{}, l)
+	ctx := context.Background()
+
+	_, err := repo.AddURL(ctx, &models.URL{BaseURL: "http://example.com", ShortURL: "abc123"})
+	require.NoError(t, err)
+	require.NoError(t, repo.DeleteURL(ctx, "", "", "abc123", nil))
+
+	// deleted url doesn't hold its original
+	got, err := repo.AddURL(ctx, &models.URL{BaseURL: "http://example.com", ShortURL: "def456"})
+	require.NoError(t, err)
+	require.Equal(t, "def456", got)
+
+	found, err := repo.FindURLs(ctx, []*models.URL{{BaseURL: "http://example.com"}, {BaseURL: "http://example.org"}})
+	require.NoError(t, err)
+	require.Equal(t, "def456", found[0].ShortURL)
+	require.Nil(t, found[1])
+
+	res, err := repo.BatchAddURL(ctx, []*models.URL{{CorrelationID: "1", BaseURL: "http://example.com", ShortURL: "ghi789"}})
+	require.NoError(t, err)
+	require.Equal(t, "def456", res[0].ShortURL)
+	require.True(t, res[0].Existed)
+
+	deleted, err := repo.GetURL(ctx, "", "abc123")
+	require.NoError(t, err)
+	require.True(t, deleted.IsDeleted)
+}
+
 func TestMapRepository_GetURL(t *testing.T) {
 	testURL := "https://www.google.com"
 	testShortURL := "AAAAA"
@@ -62,14 +92,195 @@ func TestMapRepository_GetURL(t *testing.T) {
 	repo := NewMapRepository(d, l)
 
 	t.Run("Success get", func(t *testing.T) {
-		getURL, err := repo.GetURL(context.Background(), testShortURL)
+		getURL, err := repo.GetURL(context.Background(), "", testShortURL)
 		require.NoError(t, err)
 		require.Equal(t, testURL, getURL.BaseURL)
 	})
 
 	t.Run("Can't get url", func(t *testing.T) {
 		shortURL := "https://www.random.com"
-		_, err := repo.GetURL(context.Background(), shortURL)
+		_, err := repo.GetURL(context.Background(), "", shortURL)
 		require.ErrorContains(t, err, "not found")
 	})
 }
+
+func TestMapRepository_Stats(t *testing.T) {
+	d := map[string]*models.URL{
+		"https://a.com": {BaseURL: "https://a.com", ShortURL: "AAAAA", UserID: "user-1"},
+		"https://b.com": {BaseURL: "https://b.com", ShortURL: "BBBBB", UserID: "user-1"},
+		"https://c.com": {BaseURL: "https://c.com", ShortURL: "CCCCC", UserID: "user-2", IsDeleted: true},
+		"https://d.com": {BaseURL: "https://d.com", ShortURL: "DDDDD"},
+	}
+	repo := NewMapRepository(d, l)
+
+	stats, err := repo.Stats(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, &models.Stats{URLs: 3, Users: 2, Deleted: 1}, stats)
+}
+
+func TestMapRepository_ListURLs(t *testing.T) {
+	now := time.Now()
+	d := map[string]*models.URL{
+		"https://a.com/1": {BaseURL: "https://a.com/1", ShortURL: "AAAAA", UserID: "user-1", CreateAt: now.Add(-3 * time.Hour), Tags: []string{"news"}},
+		"https://b.com/2": {BaseURL: "https://b.com/2", ShortURL: "BBBBB", UserID: "user-1", CreateAt: now.Add(-2 * time.Hour)},
+		"https://A.com/3": {BaseURL: "https://A.com/3", ShortURL: "CCCCC", UserID: "user-1", CreateAt: now.Add(-1 * time.Hour), Tags: []string{"news"}},
+		"https://a.com/4": {BaseURL: "https://a.com/4", ShortURL: "DDDDD", UserID: "user-1", CreateAt: now, IsDeleted: true},
+		"https://a.com/5": {BaseURL: "https://a.com/5", ShortURL: "EEEEE", UserID: "user-2", CreateAt: now},
+	}
+	r := NewMapRepository(d, l)
+	active := false
+
+	tests := []struct {
+		name   string
+		filter models.URLFilter
+		want   []string
+	}{
+		{
+			name:   "All user urls",
+			filter: models.URLFilter{UserID: "user-1", Limit: 10},
+			want:   []string{"AAAAA", "BBBBB", "CCCCC", "DDDDD"},
+		},
+		{
+			name:   "Descending with limit",
+			filter: models.URLFilter{UserID: "user-1", Desc: true, Limit: 2},
+			want:   []string{"DDDDD", "CCCCC"},
+		},
+		{
+			name:   "After cursor",
+			filter: models.URLFilter{UserID: "user-1", Limit: 10, After: &models.URLCursor{CreatedAt: now.Add(-2 * time.Hour), ShortURL: "BBBBB"}},
+			want:   []string{"CCCCC", "DDDDD"},
+		},
+		{
+			name:   "Tag",
+			filter: models.URLFilter{UserID: "user-1", Tag: "news", Limit: 10},
+			want:   []string{"AAAAA", "CCCCC"},
+		},
+		{
+			name:   "Domain ignores case",
+			filter: models.URLFilter{UserID: "user-1", Domain: "a.com", Deleted: &active, Limit: 10},
+			want:   []string{"AAAAA", "CCCCC"},
+		},
+		{
+			name:   "Created range",
+			filter: models.URLFilter{UserID: "user-1", CreatedFrom: now.Add(-150 * time.Minute), CreatedTo: now, Limit: 10},
+			want:   []string{"BBBBB", "CCCCC"},
+		},
+		{
+			name:   "Sort by original url",
+			filter: models.URLFilter{UserID: "user-1", Sort: models.SortOriginalURL, Limit: 10},
+			want:   []string{"CCCCC", "AAAAA", "DDDDD", "BBBBB"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urls, err := r.ListURLs(context.Background(), &tt.filter)
+			require.NoError(t, err)
+
+			got := make([]string, 0, len(urls))
+			for _, u := range urls {
+				got = append(got, u.ShortURL)
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMapRepository_ShortDomains(t *testing.T) {
+	ctx := context.Background()
+	r := NewMapRepository(map[string]*models.URL{}, l)
+
+	for _, u := range []*models.URL{
+		{BaseURL: "https://a.com", ShortURL: "AAAAA"},
+		{BaseURL: "https://b.com", ShortURL: "AAAAA", ShortDomain: "go.brand.com"},
+	} {
+		got, err := r.AddURL(ctx, u)
+		require.NoError(t, err)
+		require.Equal(t, "AAAAA", got)
+	}
+
+	// original url is unique within short domain only
+	got, err := r.AddURL(ctx, &models.URL{BaseURL: "https://a.com", ShortURL: "BBBBB", ShortDomain: "go.brand.com"})
+	require.NoError(t, err)
+	require.Equal(t, "BBBBB", got)
+
+	_, err = r.AddURL(ctx, &models.URL{BaseURL: "https://c.com", ShortURL: "AAAAA", ShortDomain: "go.brand.com"})
+	require.ErrorIs(t, err, url.ErrCodeTaken)
+
+	u, err := r.GetURL(ctx, "go.brand.com", "AAAAA")
+	require.NoError(t, err)
+	require.Equal(t, "https://b.com", u.BaseURL)
+
+	_, err = r.UpdateURL(ctx, "", "go.brand.com", "AAAAA", "https://d.com", "user-1", nil)
+	require.NoError(t, err)
+	u, err = r.GetURL(ctx, "", "AAAAA")
+	require.NoError(t, err)
+	require.Equal(t, "https://a.com", u.BaseURL)
+
+	history, err := r.GetHistory(ctx, "", "", "AAAAA")
+	require.NoError(t, err)
+	require.Empty(t, history)
+
+	require.NoError(t, r.AddDomain(ctx, &models.Domain{Host: "go.brand.com", BaseURL: "https://go.brand.com"}))
+	require.ErrorIs(t, r.AddDomain(ctx, &models.Domain{Host: "go.brand.com", BaseURL: "http://go.brand.com"}), url.ErrDomainExists)
+
+	domains, err := r.ListDomains(ctx)
+	require.NoError(t, err)
+	require.Len(t, domains, 1)
+	require.Equal(t, "https://go.brand.com", domains[0].BaseURL)
+
+	require.NoError(t, r.DeleteDomain(ctx, "go.brand.com"))
+	require.ErrorIs(t, r.DeleteDomain(ctx, "go.brand.com"), url.ErrNotFound)
+}
+
+func TestMapRepository_Workspaces(t *testing.T) {
+	ctx := context.Background()
+	r := NewMapRepository(map[string]*models.URL{}, l)
+
+	_, err := r.AddURL(ctx, &models.URL{BaseURL: "https://a.com", ShortURL: "AAAAA", UserID: "user-1"})
+	require.NoError(t, err)
+
+	// original url is unique within workspace, short url is shared by all of them
+	u := &models.URL{BaseURL: "https://a.com", ShortURL: "BBBBB", UserID: "user-1", Workspace: "team"}
+	got, err := r.AddURL(ctx, u)
+	require.NoError(t, err)
+	require.Equal(t, "BBBBB", got)
+	require.False(t, u.Existed)
+
+	_, err = r.AddURL(ctx, &models.URL{BaseURL: "https://b.com", ShortURL: "AAAAA", Workspace: "team"})
+	require.ErrorIs(t, err, url.ErrCodeTaken)
+
+	res, err := r.BatchAddURL(ctx, []*models.URL{{BaseURL: "https://a.com", ShortURL: "CCCCC", Workspace: "team"}})
+	require.NoError(t, err)
+	require.Equal(t, "BBBBB", res[0].ShortURL)
+	require.True(t, res[0].Existed)
+
+	t.Run("links of other workspace are not changed", func(t *testing.T) {
+		require.ErrorIs(t, r.DeleteURL(ctx, "team", "", "AAAAA", nil), url.ErrNotFound)
+		_, err := r.UpdateURL(ctx, "other", "", "BBBBB", "https://c.com", "user-1", nil)
+		require.ErrorIs(t, err, url.ErrNotFound)
+	})
+
+	t.Run("conflict is checked within workspace", func(t *testing.T) {
+		updated, err := r.UpdateURL(ctx, "team", "", "BBBBB", "https://c.com", "user-1", nil)
+		require.NoError(t, err)
+		require.Equal(t, "team", updated.Workspace)
+
+		_, err = r.UpdateURL(ctx, "", "", "AAAAA", "https://c.com", "user-1", nil)
+		require.NoError(t, err)
+
+		history, err := r.GetHistory(ctx, "", "", "BBBBB")
+		require.NoError(t, err)
+		require.Empty(t, history)
+		history, err = r.GetHistory(ctx, "team", "", "BBBBB")
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+	})
+
+	t.Run("listing is limited to workspace", func(t *testing.T) {
+		urls, err := r.ListURLs(ctx, &models.URLFilter{UserID: "user-1", Workspace: "team", Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, urls, 1)
+		require.Equal(t, "BBBBB", urls[0].ShortURL)
+	})
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+)
+
+// memoryOutbox is outbox of in-memory storages, caller must hold the lock of storage
+type memoryOutbox struct {
+	records []*models.OutboxRecord
+	offsets map[string]int64
+	// busy consumers are being sent records
+	busy map[string]bool
+	seq  int64
+}
+
+func newMemoryOutbox() *memoryOutbox {
+	return &memoryOutbox{
+		offsets: make(map[string]int64),
+		busy:    make(map[string]bool),
+	}
+}
+
+// reserve numbers events as next records without adding them, offsets of records which are not added are skipped
+func (o *memoryOutbox) reserve(events []*models.Event) []*models.OutboxRecord {
+	records := make([]*models.OutboxRecord, 0, len(events))
+	for _, e := range events {
+		o.seq++
+		records = append(records, &models.OutboxRecord{Offset: o.seq, Event: e})
+	}
+	return records
+}
+
+func (o *memoryOutbox) add(records ...*models.OutboxRecord) {
+	o.records = append(o.records, records...)
+}
+
+// after returns up to limit records following offset
+func (o *memoryOutbox) after(offset int64, limit int) []*models.OutboxRecord {
+	i, _ := slices.BinarySearchFunc(o.records, offset+1, func(r *models.OutboxRecord, offset int64) int {
+		return cmp.Compare(r.Offset, offset)
+	})
+	return slices.Clone(o.records[i:min(i+limit, len(o.records))])
+}
+
+// consume is ConsumeOutbox of in-memory storages, mu is released while fn runs.
+// commit persists offsets, it is called with mu held and may be nil.
+func (o *memoryOutbox) consume(mu *sync.RWMutex, consumer string, limit int, fn func([]*models.OutboxRecord) error,
+	commit func(map[string]int64) error) (int, error) {
+	mu.Lock()
+	if o.busy[consumer] {
+		mu.Unlock()
+		return 0, nil
+	}
+	records := o.after(o.offsets[consumer], limit)
+	if len(records) == 0 {
+		mu.Unlock()
+		return 0, nil
+	}
+	o.busy[consumer] = true
+	mu.Unlock()
+
+	err := fn(records)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	delete(o.busy, consumer)
+	if err != nil {
+		return 0, err
+	}
+
+	prev := o.offsets[consumer]
+	o.offsets[consumer] = records[len(records)-1].Offset
+	if commit != nil {
+		if err := commit(o.offsets); err != nil {
+			o.offsets[consumer] = prev
+			return 0, err
+		}
+	}
+
+	return len(records), nil
+}
+
+// insertedEvents returns events of batch urls which were inserted, saved are results of batch
+func insertedEvents(batch, saved []*models.URL) []*models.Event {
+	var events []*models.Event
+	for i, s := range saved {
+		if !s.Existed && batch[i].Event != nil {
+			events = append(events, batch[i].Event)
+		}
+	}
+	return events
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	auditrepository "github.com/MatiXxD/url-shortener/internal/audit/repository"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	urlpkg "github.com/MatiXxD/url-shortener/internal/url"
+	"github.com/jackc/pgx/v5"
+)
+
+// importColumns are copied to temp table, ord keeps position of url in batch
+var importColumns = []string{
+	"ord", "correlation_id", "original", "short", "user_id", "preview", "password_hash",
+	"redirect_code", "passthrough", "title", "tags", "short_domain", "workspace", "quota_keys",
+}
+
+// mergeImport inserts first row of every new original url of workspace and short domain and returns short url
+// of every imported row. Existing url is touched by update, so it is returned by ins even if it was inserted
+// concurrently after statement snapshot, xmax of touched row is not zero.
+const mergeImport = `
+	WITH ins AS (
+		INSERT INTO url (correlation_id, original, short, user_id, preview, password_hash, redirect_code, passthrough, title, tags, short_domain, workspace, quota_keys)
+		SELECT DISTINCT ON (workspace, short_domain, original)
+			correlation_id, original, short, user_id, preview, password_hash, redirect_code, passthrough, title, tags, short_domain, workspace, quota_keys
+		FROM url_import
+		ORDER BY workspace, short_domain, original, ord
+		ON CONFLICT (workspace, short_domain, original) WHERE NOT is_deleted DO UPDATE SET original = EXCLUDED.original
+		RETURNING workspace, short_domain, original, short, xmax <> 0 AS existed
+	)
+	SELECT ins.short,
+		ins.existed OR i.ord <> min(i.ord) OVER (PARTITION BY i.workspace, i.short_domain, i.original)
+	FROM url_import i
+	JOIN ins ON ins.workspace = i.workspace AND ins.short_domain = i.short_domain AND ins.original = i.original
+	ORDER BY i.ord
+`
+
+// copyAddURL is BatchAddURL for big batches: urls are copied to temp table and merged with one statement
+func (pr *PostgresRepository) copyAddURL(ctx context.Context, urls []*models.URL) ([]*models.URL, error) {
+	tx, err := pr.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save urls: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		CREATE TEMP TABLE url_import (
+			ord INT NOT NULL,
+			correlation_id TEXT NOT NULL,
+			original TEXT NOT NULL,
+			short TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			preview BOOLEAN NOT NULL,
+			password_hash TEXT NOT NULL,
+			redirect_code SMALLINT NOT NULL,
+			passthrough TEXT NOT NULL,
+			title TEXT NOT NULL,
+			tags TEXT[] NOT NULL,
+			short_domain TEXT NOT NULL,
+			workspace TEXT NOT NULL,
+			quota_keys TEXT[] NOT NULL
+		) ON COMMIT DROP
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create import table: %w", err)
+	}
+
+	rows := pgx.CopyFromSlice(len(urls), func(i int) ([]any, error) {
+		u := urls[i]
+		return []any{
+			i, u.CorrelationID, u.BaseURL, u.ShortURL, u.UserID, u.Preview, u.PasswordHash,
+			int16(u.RedirectCode), u.Passthrough, u.Title, tagsArray(u.Tags), u.ShortDomain, u.Workspace, tagsArray(u.QuotaKeys),
+		}, nil
+	})
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"url_import"}, importColumns, rows); err != nil {
+		return nil, fmt.Errorf("failed to copy urls: %w", err)
+	}
+
+	// short url taken by another original fails the whole merge
+	merged, err := tx.Query(ctx, mergeImport)
+	if isUniqueViolation(err) {
+		return nil, urlpkg.ErrCodeTaken
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to merge urls: %w", err)
+	}
+
+	res := make([]*models.URL, 0, len(urls))
+	for merged.Next() {
+		u := urls[len(res)]
+		url := &models.URL{
+			CorrelationID: u.CorrelationID,
+			BaseURL:       u.BaseURL,
+		}
+		if err := merged.Scan(&url.ShortURL, &url.Existed); err != nil {
+			merged.Close()
+			return nil, fmt.Errorf("failed to read merged urls: %w", err)
+		}
+		res = append(res, url)
+	}
+	if err := merged.Err(); isUniqueViolation(err) {
+		return nil, urlpkg.ErrCodeTaken
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to merge urls: %w", err)
+	}
+
+	if err := addOutbox(ctx, tx, insertedEvents(urls, res)); err != nil {
+		return nil, err
+	}
+	if err := auditrepository.InsertEntries(ctx, tx, insertedEntries(urls, res)); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to save urls: %w", err)
+	}
+
+	return res, nil
+}
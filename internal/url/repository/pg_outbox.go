@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// outboxLease is how long relay holds consumer while it sends the batch, relay of another instance
+// takes consumer over when lease expires
+const outboxLease = time.Minute
+
+var outboxColumns = []string{"event_id", "event_type", "payload", "created_at"}
+
+// addOutbox writes events in transaction tx, records get id of tx which orders them for relay
+func addOutbox(ctx context.Context, tx pgx.Tx, events []*models.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	rows := pgx.CopyFromSlice(len(events), func(i int) ([]any, error) {
+		e := events[i]
+		payload, err := json.Marshal(e)
+		if err != nil {
+			return nil, err
+		}
+		return []any{e.ID, e.Type, payload, e.OccurredAt}, nil
+	})
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"outbox"}, outboxColumns, rows); err != nil {
+		return fmt.Errorf("failed to write outbox: %w", err)
+	}
+
+	return nil
+}
+
+// outboxPosition is key of the last record consumer handled and its offset
+type outboxPosition struct {
+	txID     int64
+	recordID int64
+	offset   int64
+}
+
+// ConsumeOutbox leases consumer for the time of send, relays of other instances skip it meanwhile.
+// Nothing is locked while fn runs: offset is moved afterwards unless lease was taken over.
+func (pr *PostgresRepository) ConsumeOutbox(ctx context.Context, consumer string, limit int, fn func([]*models.OutboxRecord) error) (int, error) {
+	_, err := pr.db.Pool.Exec(ctx, `INSERT INTO outbox_offset (consumer) VALUES ($1) ON CONFLICT DO NOTHING`, consumer)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add outbox consumer: %w", err)
+	}
+
+	var pos outboxPosition
+	err = pr.db.Pool.QueryRow(ctx, `
+		UPDATE outbox_offset SET locked_until = NOW() + make_interval(secs => $2)
+		WHERE consumer = $1 AND (locked_until IS NULL OR locked_until < NOW())
+		RETURNING tx_id, record_id, position`, consumer, outboxLease.Seconds()).Scan(&pos.txID, &pos.recordID, &pos.offset)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to lease outbox consumer: %w", err)
+	}
+
+	records, last, err := pr.readOutbox(ctx, pos, limit)
+	if err == nil && len(records) != 0 {
+		err = fn(records)
+	}
+	if err != nil || len(records) == 0 {
+		pr.releaseOutbox(ctx, consumer)
+		return 0, err
+	}
+
+	tag, err := pr.db.Pool.Exec(context.WithoutCancel(ctx), `
+		UPDATE outbox_offset SET tx_id = $2, record_id = $3, position = $4, locked_until = NULL, updated_at = NOW()
+		WHERE consumer = $1 AND position = $5`, consumer, last.txID, last.recordID, last.offset, pos.offset)
+	if err != nil {
+		return 0, fmt.Errorf("failed to save outbox offset: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return 0, fmt.Errorf("outbox consumer %s was taken over while its batch was sent", consumer)
+	}
+
+	return len(records), nil
+}
+
+func (pr *PostgresRepository) releaseOutbox(ctx context.Context, consumer string) {
+	_, err := pr.db.Pool.Exec(context.WithoutCancel(ctx), `UPDATE outbox_offset SET locked_until = NULL WHERE consumer = $1`, consumer)
+	if err != nil {
+		pr.logger.Errorf("cannot release outbox consumer %s: %v", consumer, err)
+	}
+}
+
+// readOutbox returns up to limit records after pos and position of the last one. Only records of transactions
+// older than any running one are read: they are all visible, and records written later never precede them.
+func (pr *PostgresRepository) readOutbox(ctx context.Context, pos outboxPosition, limit int) ([]*models.OutboxRecord, outboxPosition, error) {
+	rows, err := pr.db.Pool.Query(ctx, `
+		SELECT tx_id, id, payload FROM outbox
+		WHERE (tx_id, id) > ($1, $2) AND tx_id < pg_snapshot_xmin(pg_current_snapshot())::text::bigint
+		ORDER BY tx_id, id LIMIT $3`, pos.txID, pos.recordID, limit)
+	if err != nil {
+		return nil, pos, fmt.Errorf("failed to read outbox: %w", err)
+	}
+	defer rows.Close()
+
+	res := make([]*models.OutboxRecord, 0, limit)
+	for rows.Next() {
+		var payload []byte
+		if err := rows.Scan(&pos.txID, &pos.recordID, &payload); err != nil {
+			return nil, pos, fmt.Errorf("failed to scan outbox: %w", err)
+		}
+		pos.offset++
+		rec := &models.OutboxRecord{Offset: pos.offset, Event: &models.Event{}}
+		if err := json.Unmarshal(payload, rec.Event); err != nil {
+			return nil, pos, fmt.Errorf("malformed outbox record %d: %w", pos.recordID, err)
+		}
+		res = append(res, rec)
+	}
+
+	return res, pos, rows.Err()
+}
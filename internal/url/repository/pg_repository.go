@@ -2,48 +2,105 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
+	auditrepository "github.com/MatiXxD/url-shortener/internal/audit/repository"
 	"github.com/MatiXxD/url-shortener/internal/models"
+	urlpkg "github.com/MatiXxD/url-shortener/internal/url"
 	"github.com/MatiXxD/url-shortener/pkg/logger"
 	"github.com/MatiXxD/url-shortener/pkg/postgres"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// uniqueViolation is postgres error code for unique constraint violation
+const uniqueViolation = "23505"
+
+// urlColumns are selected by scanURL
+const urlColumns = `id, correlation_id, original, short, created_at, is_deleted, user_id, preview, password_hash,
+	redirect_code, passthrough, title, tags, short_domain, workspace, quota_keys`
+
+// domainExpr extracts lowercase host from original url
+const domainExpr = `lower(substring(original from '^[^:]+://(?:[^@/?#]*@)?([^:/?#]+)'))`
+
+// sortColumns maps listing sort fields to columns
+var sortColumns = map[string]string{
+	models.SortCreatedAt:   "created_at",
+	models.SortOriginalURL: "original",
+	models.SortShortURL:    "short",
+}
+
 type PostgresRepository struct {
-	db     *postgres.DB
-	logger *logger.Logger
+	db            *postgres.DB
+	copyThreshold int
+	logger        *logger.Logger
 }
 
-func NewPostgresRepository(db *postgres.DB, logger *logger.Logger) *PostgresRepository {
+// NewPostgresRepository saves batches of at least copyThreshold urls with COPY, zero disables it
+func NewPostgresRepository(db *postgres.DB, copyThreshold int, logger *logger.Logger) *PostgresRepository {
 	return &PostgresRepository{
-		db:     db,
-		logger: logger,
+		db:            db,
+		copyThreshold: copyThreshold,
+		logger:        logger,
 	}
 }
 
+// AddURL writes event of inserted url to outbox and its audit entry to audit log in the same transaction
 func (pr *PostgresRepository) AddURL(ctx context.Context, url *models.URL) (string, error) {
+	tx, err := pr.db.Pool.Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("postgres add url failed with: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
-		INSERT INTO url (correlation_id, original, short)
-		VALUES ($1, $2, $3)
-		ON CONFLICT(original) DO UPDATE SET
+		INSERT INTO url (correlation_id, original, short, user_id, preview, password_hash, redirect_code, passthrough, title, tags, short_domain, workspace, quota_keys)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT(workspace, short_domain, original) WHERE NOT is_deleted DO UPDATE SET
 			original = EXCLUDED.original
-		RETURNING short
+		RETURNING short, xmax <> 0
 	`
 
-	row := pr.db.Pool.QueryRow(ctx, query, url.CorrelationID, url.BaseURL, url.ShortURL)
+	row := tx.QueryRow(ctx, query, url.CorrelationID, url.BaseURL, url.ShortURL, url.UserID, url.Preview, url.PasswordHash, url.RedirectCode, url.Passthrough, url.Title, tagsArray(url.Tags), url.ShortDomain, url.Workspace, tagsArray(url.QuotaKeys))
 
 	var shortURL string
 
-	err := row.Scan(&shortURL)
+	err = row.Scan(&shortURL, &url.Existed)
+	// conflict on original is handled by query, so only short url can be duplicated
+	if isUniqueViolation(err) {
+		return "", urlpkg.ErrCodeTaken
+	}
 	if err != nil {
 		return "", fmt.Errorf("postgres add url failed with: %w", err)
 	}
 
+	if !url.Existed && url.Event != nil {
+		if err := addOutbox(ctx, tx, []*models.Event{url.Event}); err != nil {
+			return "", err
+		}
+	}
+	if !url.Existed {
+		if err := auditrepository.InsertEntries(ctx, tx, auditEntries(url.Audit)); err != nil {
+			return "", err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", fmt.Errorf("postgres add url failed with: %w", err)
+	}
+
 	return shortURL, nil
 }
 
+// BatchAddURL saves urls in one transaction, xmax of inserted row is zero so existing urls are told apart
 func (pr *PostgresRepository) BatchAddURL(ctx context.Context, urls []*models.URL) ([]*models.URL, error) {
+	if pr.copyThreshold > 0 && len(urls) >= pr.copyThreshold {
+		return pr.copyAddURL(ctx, urls)
+	}
+
 	tx, err := pr.db.Pool.Begin(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to save urls: %v", err)
@@ -51,16 +108,16 @@ func (pr *PostgresRepository) BatchAddURL(ctx context.Context, urls []*models.UR
 	defer tx.Rollback(ctx)
 
 	query := `
-		INSERT INTO url (correlation_id, original, short)
-		VALUES ($1, $2, $3)
-		ON CONFLICT(original) DO UPDATE SET
+		INSERT INTO url (correlation_id, original, short, user_id, preview, password_hash, redirect_code, passthrough, title, tags, short_domain, workspace, quota_keys)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT(workspace, short_domain, original) WHERE NOT is_deleted DO UPDATE SET
 			original = EXCLUDED.original
-		RETURNING correlation_id, original, short
+		RETURNING short, xmax <> 0
 	`
 
 	batch := &pgx.Batch{}
 	for _, url := range urls {
-		batch.Queue(query, url.CorrelationID, url.BaseURL, url.ShortURL)
+		batch.Queue(query, url.CorrelationID, url.BaseURL, url.ShortURL, url.UserID, url.Preview, url.PasswordHash, url.RedirectCode, url.Passthrough, url.Title, tagsArray(url.Tags), url.ShortDomain, url.Workspace, tagsArray(url.QuotaKeys))
 	}
 
 	br := tx.SendBatch(ctx, batch)
@@ -68,9 +125,15 @@ func (pr *PostgresRepository) BatchAddURL(ctx context.Context, urls []*models.UR
 
 	res := make([]*models.URL, 0, len(urls))
 	for _, u := range urls {
-		var url models.URL
+		url := models.URL{
+			CorrelationID: u.CorrelationID,
+			BaseURL:       u.BaseURL,
+		}
 
-		err := br.QueryRow().Scan(&url.CorrelationID, &url.BaseURL, &url.ShortURL)
+		err := br.QueryRow().Scan(&url.ShortURL, &url.Existed)
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("failed to save url=%s: %w", u.BaseURL, urlpkg.ErrCodeTaken)
+		}
 		if err != nil {
 			return nil, fmt.Errorf("failed to save url=%s: %w", u.BaseURL, err)
 		}
@@ -82,6 +145,13 @@ func (pr *PostgresRepository) BatchAddURL(ctx context.Context, urls []*models.UR
 		return nil, fmt.Errorf("failed to save urls: %w", err)
 	}
 
+	if err := addOutbox(ctx, tx, insertedEvents(urls, res)); err != nil {
+		return nil, err
+	}
+	if err := auditrepository.InsertEntries(ctx, tx, insertedEntries(urls, res)); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("failed to save urls: %w", err)
 	}
@@ -89,20 +159,379 @@ func (pr *PostgresRepository) BatchAddURL(ctx context.Context, urls []*models.UR
 	return res, nil
 }
 
-func (pr *PostgresRepository) GetURL(ctx context.Context, shortURL string) (*models.URL, error) {
+// GetURL reads from replica, url missing there is looked up on primary as it may be just created
+func (pr *PostgresRepository) GetURL(ctx context.Context, domain, shortURL string) (*models.URL, error) {
 	query := `
-		SELECT correlation_id, original, short FROM url
-		WHERE short = $1
+		SELECT ` + urlColumns + ` FROM url
+		WHERE short_domain = $1 AND short = $2
 	`
 
-	row := pr.db.Pool.QueryRow(ctx, query, shortURL)
-
 	var url models.URL
+	get := func(pool *pgxpool.Pool) error {
+		return scanURL(pool.QueryRow(ctx, query, domain, shortURL), &url)
+	}
 
-	err := row.Scan(&url.CorrelationID, &url.BaseURL, &url.ShortURL)
+	err := pr.read(ctx, get)
+	if errors.Is(err, pgx.ErrNoRows) && pr.db.HasReplicas() && !urlpkg.IsConsistentRead(ctx) {
+		err = get(pr.db.Pool)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, urlpkg.ErrNotFound
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get url: %w", err)
 	}
 
 	return &url, nil
 }
+
+// FindURLs reads from primary, urls of all originals are read with one query
+func (pr *PostgresRepository) FindURLs(ctx context.Context, urls []*models.URL) ([]*models.URL, error) {
+	workspaces := make([]string, 0, len(urls))
+	domains := make([]string, 0, len(urls))
+	originals := make([]string, 0, len(urls))
+	for _, u := range urls {
+		workspaces = append(workspaces, u.Workspace)
+		domains = append(domains, u.ShortDomain)
+		originals = append(originals, u.BaseURL)
+	}
+
+	rows, err := pr.db.Pool.Query(ctx, `
+		SELECT `+urlColumns+` FROM url
+		WHERE (workspace, short_domain, original) IN (SELECT * FROM unnest($1::text[], $2::text[], $3::text[]))
+			AND NOT is_deleted
+	`, workspaces, domains, originals)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find urls: %w", err)
+	}
+	defer rows.Close()
+
+	found := make(map[string]*models.URL)
+	for rows.Next() {
+		var url models.URL
+		if err := scanURL(rows, &url); err != nil {
+			return nil, fmt.Errorf("failed to scan url: %w", err)
+		}
+		found[urlKey(&url)] = &url
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to find urls: %w", err)
+	}
+
+	res := make([]*models.URL, len(urls))
+	for i, u := range urls {
+		res[i] = found[urlKey(u)]
+	}
+	return res, nil
+}
+
+// DeleteURL marks url as deleted and writes audit entry in one transaction
+func (pr *PostgresRepository) DeleteURL(ctx context.Context, workspace, domain, shortURL string, entry *models.AuditEntry) error {
+	tx, err := pr.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete url: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE url SET is_deleted = TRUE
+		WHERE workspace = $1 AND short_domain = $2 AND short = $3
+	`
+
+	tag, err := tx.Exec(ctx, query, workspace, domain, shortURL)
+	if err != nil {
+		return fmt.Errorf("failed to delete url: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return urlpkg.ErrNotFound
+	}
+
+	if err := auditrepository.InsertEntries(ctx, tx, auditEntries(entry)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to delete url: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateURL changes destination and writes history and audit entry in one transaction
+func (pr *PostgresRepository) UpdateURL(ctx context.Context, workspace, domain, shortURL, originalURL, actor string, entry *models.AuditEntry) (*models.URL, error) {
+	tx, err := pr.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update url: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var previous string
+	err = tx.QueryRow(ctx, `
+		SELECT original FROM url WHERE workspace = $1 AND short_domain = $2 AND short = $3 FOR UPDATE
+	`, workspace, domain, shortURL).Scan(&previous)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, urlpkg.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get url: %w", err)
+	}
+
+	query := `
+		UPDATE url SET original = $3
+		WHERE short_domain = $1 AND short = $2
+		RETURNING ` + urlColumns
+
+	var url models.URL
+	err = scanURL(tx.QueryRow(ctx, query, domain, shortURL, originalURL), &url)
+	if isUniqueViolation(err) {
+		return nil, urlpkg.ErrConflict
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update url: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO url_history (short_domain, short, previous_url, new_url, changed_by)
+		VALUES ($1, $2, $3, $4, $5)
+	`, domain, shortURL, previous, originalURL, actor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save url history: %w", err)
+	}
+
+	if err := auditrepository.InsertEntries(ctx, tx, auditEntries(entry)); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to update url: %w", err)
+	}
+
+	return &url, nil
+}
+
+func (pr *PostgresRepository) GetHistory(ctx context.Context, workspace, domain, shortURL string) ([]*models.URLChange, error) {
+	query := `
+		SELECT h.id, h.short_domain, h.short, h.previous_url, h.new_url, h.changed_by, h.changed_at FROM url_history h
+		JOIN url u ON u.short_domain = h.short_domain AND u.short = h.short
+		WHERE u.workspace = $1 AND h.short_domain = $2 AND h.short = $3
+		ORDER BY h.id DESC
+	`
+
+	rows, err := pr.db.Pool.Query(ctx, query, workspace, domain, shortURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get url history: %w", err)
+	}
+	defer rows.Close()
+
+	res := make([]*models.URLChange, 0)
+	for rows.Next() {
+		var c models.URLChange
+		if err := rows.Scan(&c.ID, &c.ShortDomain, &c.ShortURL, &c.PreviousURL, &c.NewURL, &c.ChangedBy, &c.ChangedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan url history: %w", err)
+		}
+		res = append(res, &c)
+	}
+
+	return res, rows.Err()
+}
+
+// ListURLs uses keyset pagination: next page starts right after the cursor in sort order
+func (pr *PostgresRepository) ListURLs(ctx context.Context, filter *models.URLFilter) ([]*models.URL, error) {
+	conds := []string{"user_id = $1", "workspace = $2"}
+	args := []any{filter.UserID, filter.Workspace}
+	add := func(cond string, values ...any) {
+		placeholders := make([]any, len(values))
+		for i, v := range values {
+			args = append(args, v)
+			placeholders[i] = len(args)
+		}
+		conds = append(conds, fmt.Sprintf(cond, placeholders...))
+	}
+
+	if filter.Tag != "" {
+		add("$%d = ANY(tags)", filter.Tag)
+	}
+	if filter.Domain != "" {
+		add(domainExpr+" = lower($%d)", filter.Domain)
+	}
+	if filter.ShortDomain != nil {
+		add("short_domain = $%d", *filter.ShortDomain)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		add("created_at >= $%d", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		add("created_at < $%d", filter.CreatedTo)
+	}
+	if filter.Deleted != nil {
+		add("is_deleted = $%d", *filter.Deleted)
+	}
+
+	column, ok := sortColumns[filter.Sort]
+	if !ok {
+		column = sortColumns[models.SortCreatedAt]
+	}
+	op, dir := ">", "ASC"
+	if filter.Desc {
+		op, dir = "<", "DESC"
+	}
+
+	if c := filter.After; c != nil {
+		switch filter.Sort {
+		case models.SortShortURL:
+			add("(short, short_domain) "+op+" ($%d, $%d)", c.ShortURL, c.ShortDomain)
+		case models.SortOriginalURL:
+			add("(original, short, short_domain) "+op+" ($%d, $%d, $%d)", c.Value, c.ShortURL, c.ShortDomain)
+		default:
+			add("(created_at, short, short_domain) "+op+" ($%d, $%d, $%d)", c.CreatedAt, c.ShortURL, c.ShortDomain)
+		}
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM url
+		WHERE %s
+		ORDER BY %s %s, short %s, short_domain %s
+		LIMIT %d
+	`, urlColumns, strings.Join(conds, " AND "), column, dir, dir, dir, filter.Limit)
+
+	var res []*models.URL
+	err := pr.read(ctx, func(pool *pgxpool.Pool) error {
+		rows, err := pool.Query(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		res = make([]*models.URL, 0, filter.Limit)
+		for rows.Next() {
+			var url models.URL
+			if err := scanURL(rows, &url); err != nil {
+				return fmt.Errorf("failed to scan url: %w", err)
+			}
+			res = append(res, &url)
+		}
+
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list urls: %w", err)
+	}
+
+	return res, nil
+}
+
+// read sends query to replica unless caller needs its own writes
+func (pr *PostgresRepository) read(ctx context.Context, fn func(*pgxpool.Pool) error) error {
+	if urlpkg.IsConsistentRead(ctx) {
+		return fn(pr.db.Pool)
+	}
+	return pr.db.Read(ctx, fn)
+}
+
+func (pr *PostgresRepository) Stats(ctx context.Context) (*models.Stats, error) {
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE NOT is_deleted),
+			COUNT(DISTINCT NULLIF(user_id, '')),
+			COUNT(*) FILTER (WHERE is_deleted)
+		FROM url
+	`
+
+	var stats models.Stats
+
+	err := pr.db.Pool.QueryRow(ctx, query).Scan(&stats.URLs, &stats.Users, &stats.Deleted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats: %w", err)
+	}
+
+	for _, n := range pr.db.Stats() {
+		stats.DBNodes = append(stats.DBNodes, &models.DBNodeStats{
+			Name:          n.Name,
+			Healthy:       n.Healthy,
+			Reads:         n.Reads,
+			LagMs:         n.Lag.Milliseconds(),
+			TotalConns:    n.TotalConns,
+			IdleConns:     n.IdleConns,
+			AcquiredConns: n.AcquiredConns,
+			MaxConns:      n.MaxConns,
+			AcquireCount:  n.AcquireCount,
+			AcquireTimeMs: n.AcquireTime.Milliseconds(),
+		})
+	}
+
+	return &stats, nil
+}
+
+func (pr *PostgresRepository) NextID(ctx context.Context) (uint64, error) {
+	var id int64
+
+	err := pr.db.Pool.QueryRow(ctx, `SELECT nextval('url_code_seq')`).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get next id: %w", err)
+	}
+
+	return uint64(id), nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
+
+func scanURL(row pgx.Row, url *models.URL) error {
+	return row.Scan(&url.ID, &url.CorrelationID, &url.BaseURL, &url.ShortURL, &url.CreateAt, &url.IsDeleted,
+		&url.UserID, &url.Preview, &url.PasswordHash, &url.RedirectCode, &url.Passthrough, &url.Title, &url.Tags, &url.ShortDomain, &url.Workspace, &url.QuotaKeys)
+}
+
+// tagsArray avoids NULL for array column with NOT NULL constraint
+func tagsArray(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+func (pr *PostgresRepository) AddDomain(ctx context.Context, d *models.Domain) error {
+	_, err := pr.db.Pool.Exec(ctx, `
+		INSERT INTO short_domain (host, base_url, workspace) VALUES ($1, $2, $3)
+	`, d.Host, d.BaseURL, d.Workspace)
+	if isUniqueViolation(err) {
+		return urlpkg.ErrDomainExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to add domain: %w", err)
+	}
+
+	return nil
+}
+
+func (pr *PostgresRepository) ListDomains(ctx context.Context) ([]*models.Domain, error) {
+	rows, err := pr.db.Pool.Query(ctx, `SELECT host, base_url, workspace, created_at FROM short_domain ORDER BY host`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list domains: %w", err)
+	}
+	defer rows.Close()
+
+	res := make([]*models.Domain, 0)
+	for rows.Next() {
+		var d models.Domain
+		if err := rows.Scan(&d.Host, &d.BaseURL, &d.Workspace, &d.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan domain: %w", err)
+		}
+		res = append(res, &d)
+	}
+
+	return res, rows.Err()
+}
+
+func (pr *PostgresRepository) DeleteDomain(ctx context.Context, host string) error {
+	tag, err := pr.db.Pool.Exec(ctx, `DELETE FROM short_domain WHERE host = $1`, host)
+	if err != nil {
+		return fmt.Errorf("failed to delete domain: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return urlpkg.ErrNotFound
+	}
+
+	return nil
+}
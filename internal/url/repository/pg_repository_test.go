@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+	urlpkg "github.com/MatiXxD/url-shortener/internal/url"
+	"github.com/MatiXxD/url-shortener/pkg/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+// tests and benchmarks run against migrated database from TEST_DATABASE_DSN
+func testDB(tb testing.TB) *postgres.DB {
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		tb.Skip("TEST_DATABASE_DSN is not set")
+	}
+
+	db, err := postgres.New(dsn)
+	require.NoError(tb, err)
+	tb.Cleanup(db.Close)
+	return db
+}
+
+func TestPostgresRepository_AddDeletedURL(t *testing.T) {
+	db := testDB(t)
+	repo := NewPostgresRepository(db, 0, l)
+	ctx := context.Background()
+
+	original := fmt.Sprintf("https://example.com/deleted/%d", time.Now().UnixNano())
+	code := fmt.Sprintf("d%d", time.Now().UnixNano())
+	t.Cleanup(func() {
+		_, _ = db.Pool.Exec(context.Background(), "DELETE FROM url WHERE original = $1", original)
+	})
+
+	_, err := repo.AddURL(ctx, &models.URL{BaseURL: original, ShortURL: code + "a"})
+	require.NoError(t, err)
+	require.NoError(t, repo.DeleteURL(ctx, "", "", code+"a", nil))
+
+	// deleted url doesn't hold its original
+	got, err := repo.AddURL(ctx, &models.URL{BaseURL: original, ShortURL: code + "b", QuotaKeys: []string{"q1"}})
+	require.NoError(t, err)
+	require.Equal(t, code+"b", got)
+
+	found, err := repo.FindURLs(ctx, []*models.URL{{BaseURL: original}, {BaseURL: original + "/missing"}})
+	require.NoError(t, err)
+	require.Equal(t, code+"b", found[0].ShortURL)
+	require.Equal(t, []string{"q1"}, found[0].QuotaKeys)
+	require.Nil(t, found[1])
+
+	res, err := repo.BatchAddURL(ctx, []*models.URL{{CorrelationID: "1", BaseURL: original, ShortURL: code + "c"}})
+	require.NoError(t, err)
+	require.Equal(t, code+"b", res[0].ShortURL)
+	require.True(t, res[0].Existed)
+
+	deleted, err := repo.GetURL(ctx, "", code+"a")
+	require.NoError(t, err)
+	require.True(t, deleted.IsDeleted)
+}
+
+func TestPostgresRepository_copyAddURL(t *testing.T) {
+	db := testDB(t)
+	repo := NewPostgresRepository(db, 1, l)
+	ctx := context.Background()
+
+	prefix := fmt.Sprintf("https://example.com/copy/%d/", time.Now().UnixNano())
+	code := fmt.Sprintf("c%d", time.Now().UnixNano())
+	t.Cleanup(func() {
+		_, _ = db.Pool.Exec(context.Background(), "DELETE FROM url WHERE original LIKE $1", prefix+"%")
+	})
+
+	_, err := repo.AddURL(ctx, &models.URL{BaseURL: prefix + "old", ShortURL: code + "o"})
+	require.NoError(t, err)
+
+	res, err := repo.BatchAddURL(ctx, []*models.URL{
+		{CorrelationID: "1", BaseURL: prefix + "a", ShortURL: code + "a"},
+		{CorrelationID: "2", BaseURL: prefix + "old", ShortURL: code + "b"},
+		{CorrelationID: "3", BaseURL: prefix + "a", ShortURL: code + "c"},
+		{CorrelationID: "4", BaseURL: prefix + "d", ShortURL: code + "d"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []*models.URL{
+		{CorrelationID: "1", BaseURL: prefix + "a", ShortURL: code + "a"},
+		{CorrelationID: "2", BaseURL: prefix + "old", ShortURL: code + "o", Existed: true},
+		{CorrelationID: "3", BaseURL: prefix + "a", ShortURL: code + "a", Existed: true},
+		{CorrelationID: "4", BaseURL: prefix + "d", ShortURL: code + "d"},
+	}, res)
+
+	// short url taken by another original fails the whole merge
+	_, err = repo.BatchAddURL(ctx, []*models.URL{
+		{CorrelationID: "1", BaseURL: prefix + "e", ShortURL: code + "e"},
+		{CorrelationID: "2", BaseURL: prefix + "f", ShortURL: code + "a"},
+	})
+	require.ErrorIs(t, err, urlpkg.ErrCodeTaken)
+	_, err = repo.GetURL(ctx, "", code+"e")
+	require.ErrorIs(t, err, urlpkg.ErrNotFound)
+}
+
+func BenchmarkPostgresRepository_BatchAddURL(b *testing.B) {
+	db := testDB(b)
+
+	paths := []struct {
+		name          string
+		copyThreshold int
+	}{
+		{name: "batch", copyThreshold: 0},
+		{name: "copy", copyThreshold: 1},
+	}
+
+	for _, size := range []int{100, 1000, 10000} {
+		for _, path := range paths {
+			b.Run(fmt.Sprintf("%s/%d", path.name, size), func(b *testing.B) {
+				repo := NewPostgresRepository(db, path.copyThreshold, l)
+				ctx := context.Background()
+
+				for i := 0; i < b.N; i++ {
+					b.StopTimer()
+					urls := benchURLs(fmt.Sprintf("%s-%d-%d", path.name, size, i), size)
+					b.StartTimer()
+
+					_, err := repo.BatchAddURL(ctx, urls)
+					require.NoError(b, err)
+				}
+
+				b.StopTimer()
+				_, err := db.Pool.Exec(ctx, "DELETE FROM url WHERE correlation_id LIKE 'bench-%'")
+				require.NoError(b, err)
+			})
+		}
+	}
+}
+
+func benchURLs(prefix string, n int) []*models.URL {
+	urls := make([]*models.URL, n)
+	for i := range urls {
+		urls[i] = &models.URL{
+			CorrelationID: fmt.Sprintf("bench-%d", i),
+			BaseURL:       fmt.Sprintf("https://example.com/%s/%d", prefix, i),
+			ShortURL:      fmt.Sprintf("b%s%d", prefix, i),
+		}
+	}
+	return urls
+}
+
+func TestPostgresRepository_ConsumeOutbox(t *testing.T) {
+	db := testDB(t)
+	repo := NewPostgresRepository(db, 0, l)
+	ctx := context.Background()
+
+	suffix := fmt.Sprint(time.Now().UnixNano())
+	consumer := "test-" + suffix
+	t.Cleanup(func() {
+		_, _ = db.Pool.Exec(context.Background(), "DELETE FROM outbox_offset WHERE consumer = $1", consumer)
+		_, _ = db.Pool.Exec(context.Background(), "DELETE FROM url WHERE short LIKE '%' || $1", suffix)
+	})
+
+	// consumer starts after records of other tests
+	_, err := db.Pool.Exec(ctx, `INSERT INTO outbox_offset (consumer, tx_id, record_id)
+		SELECT $1, COALESCE(max(tx_id), 0), COALESCE(max(id), 0) FROM outbox`, consumer)
+	require.NoError(t, err)
+
+	for _, code := range []string{"a", "b"} {
+		u := &models.URL{BaseURL: "https://example.com/outbox/" + code + suffix, ShortURL: code + suffix}
+		u.Event = &models.Event{ID: u.ShortURL, Type: models.EventLinkCreated, Link: &models.EventLink{Code: u.ShortURL}}
+		_, err := repo.AddURL(ctx, u)
+		require.NoError(t, err)
+	}
+
+	var offsets []int64
+	var codes []string
+	consume := func(records []*models.OutboxRecord) error {
+		offsets, codes = nil, nil
+		for _, rec := range records {
+			offsets = append(offsets, rec.Offset)
+			codes = append(codes, rec.Event.Link.Code)
+		}
+		return nil
+	}
+
+	// failed send doesn't move offset, consumer is busy while batch is sent
+	_, err = repo.ConsumeOutbox(ctx, consumer, 10, func(records []*models.OutboxRecord) error {
+		n, err := repo.ConsumeOutbox(ctx, consumer, 10, consume)
+		require.NoError(t, err)
+		require.Zero(t, n)
+		return errors.New("sink is down")
+	})
+	require.Error(t, err)
+
+	n, err := repo.ConsumeOutbox(ctx, consumer, 10, consume)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, []string{"a" + suffix, "b" + suffix}, codes)
+	require.Equal(t, []int64{1, 2}, offsets)
+
+	n, err = repo.ConsumeOutbox(ctx, consumer, 10, consume)
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
@@ -16,7 +16,7 @@ func TestMain(t *testing.M) {
 		panic(err)
 	}
 
-	l = &logger.Logger{zl.Sugar()}
+	l = &logger.Logger{SugaredLogger: zl.Sugar()}
 
 	os.Exit(t.Run())
 }
@@ -0,0 +1,23 @@
+package repository
+
+import "github.com/MatiXxD/url-shortener/internal/models"
+
+// collectStats counts stats for in-memory storages, caller must hold the lock
+func collectStats(urls map[string]*models.URL) *models.Stats {
+	stats := &models.Stats{}
+	users := make(map[string]struct{})
+
+	for _, u := range urls {
+		if u.IsDeleted {
+			stats.Deleted++
+		} else {
+			stats.URLs++
+		}
+		if u.UserID != "" {
+			users[u.UserID] = struct{}{}
+		}
+	}
+	stats.Users = len(users)
+
+	return stats
+}
@@ -2,12 +2,37 @@ package url
 
 import (
 	"context"
+	neturl "net/url"
 
 	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/pkg/qr"
 )
 
 type Usecase interface {
 	ReduceURL(context.Context, *models.UrlDTO) (string, error)
-	BatchReduceURL(context.Context, []*models.UrlDTO) ([]*models.UrlDTO, error)
-	GetURL(context.Context, string) (string, bool)
+	BatchReduceURL(context.Context, []*models.UrlDTO, bool) ([]*models.UrlDTO, error)
+	StreamReduceURL(context.Context, func() (*models.UrlDTO, error), func([]*models.UrlDTO) error) error
+	GetURL(ctx context.Context, domain, shortURL string) (*models.URL, error)
+	DeleteURL(ctx context.Context, domain, shortURL, userID string) error
+	UpdateURL(ctx context.Context, domain, shortURL, originalURL, userID string) (*models.URL, error)
+	GetHistory(ctx context.Context, domain, shortURL, userID string) ([]*models.URLChange, error)
+	Rollback(ctx context.Context, domain, shortURL string, changeID int, userID string) (*models.URL, error)
+	ListURLs(context.Context, *models.URLFilter, string) ([]*models.URL, string, error)
+	// Click tells that request was redirected to url
+	Click(context.Context, *models.URL, *models.Click)
+	RedirectCode(*models.URL) int
+	Destination(*models.URL, string, neturl.Values) (string, error)
+	NeedsPreview(*models.URL) bool
+	CheckPassword(*models.URL, string) error
+	GetShortURL(domain, code string) string
+	GetStats(context.Context) (*models.Stats, error)
+	GetQRCode(ctx context.Context, domain, shortURL string, opts qr.Options) ([]byte, error)
+	// ResolveDomain returns short domain served on request host, unknown hosts get default one
+	ResolveDomain(ctx context.Context, host string) string
+	// ShortDomain returns short domain of host, error if it isn't registered
+	ShortDomain(ctx context.Context, host string) (string, error)
+	ListDomains(context.Context) ([]*models.Domain, error)
+	// AddDomain registers domain of workspace, empty workspace makes domain shared
+	AddDomain(ctx context.Context, baseURL, workspace string) (*models.Domain, error)
+	DeleteDomain(ctx context.Context, host string) error
 }
@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/audit"
+	"github.com/MatiXxD/url-shortener/internal/models"
+)
+
+// newAuditEntry is entry of change made by user, before is nil for created link.
+// Entry is recorded by repository together with the change, actor and request id are taken from context.
+func newAuditEntry(ctx context.Context, action, userID string, u *models.URL, before, after *models.AuditState) *models.AuditEntry {
+	actor, requestID := audit.ActorFrom(ctx)
+	if userID != "" {
+		actor.UserID = userID
+	}
+	return &models.AuditEntry{
+		At:          time.Now().UTC(),
+		Actor:       actor,
+		Action:      action,
+		ShortDomain: u.ShortDomain,
+		Code:        u.ShortURL,
+		Before:      before,
+		After:       after,
+		RequestID:   requestID,
+	}
+}
+
+func auditState(u *models.URL) *models.AuditState {
+	return &models.AuditState{
+		OriginalURL: u.BaseURL,
+		Title:       u.Title,
+		Tags:        u.Tags,
+		Deleted:     u.IsDeleted,
+	}
+}
@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/MatiXxD/url-shortener/internal/audit"
+	auditusecase "github.com/MatiXxD/url-shortener/internal/audit/usecase"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/url/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsecase_Audit(t *testing.T) {
+	r := repository.NewMapRepository(map[string]*models.URL{}, l)
+	au := auditusecase.NewAuditUsecase(r.Audit(), l)
+	uc := NewUrlUsecase(r, cfg, l)
+
+	ctx := audit.WithActor(context.Background(), models.AuditActor{UserID: "u1", IP: "10.0.0.1"}, "req-1")
+	shortURL, err := uc.ReduceURL(ctx, &models.UrlDTO{OriginURL: "https://example.com/a", UserID: "u1", Title: "A"})
+	require.NoError(t, err)
+	code := strings.TrimPrefix(shortURL, cfg.BaseURL+"/")
+
+	// existing link is not changed
+	_, err = uc.ReduceURL(ctx, &models.UrlDTO{OriginURL: "https://example.com/a", UserID: "u1"})
+	require.NoError(t, err)
+
+	_, err = uc.BatchReduceURL(ctx, []*models.UrlDTO{
+		{CorrelationID: "1", OriginURL: "https://example.com/a"},
+		{CorrelationID: "2", OriginURL: "https://example.com/b"},
+	}, false)
+	require.NoError(t, err)
+
+	_, err = uc.UpdateURL(ctx, "", code, "https://example.com/c", "u1")
+	require.NoError(t, err)
+	_, err = uc.Rollback(ctx, "", code, 0, "u1")
+	require.NoError(t, err)
+	require.NoError(t, uc.DeleteURL(ctx, "", code, "u1"))
+
+	t.Run("failed change is not recorded", func(t *testing.T) {
+		require.ErrorIs(t, uc.DeleteURL(ctx, "", code, "u2"), ErrURLDeleted)
+	})
+
+	entries, _, err := au.ListEntries(context.Background(), &models.AuditFilter{}, "")
+	require.NoError(t, err)
+
+	actions := make([]string, 0, len(entries))
+	for _, e := range entries {
+		actions = append(actions, e.Action)
+		require.Equal(t, "10.0.0.1", e.Actor.IP)
+		require.Equal(t, "req-1", e.RequestID)
+	}
+	require.Equal(t, []string{
+		models.AuditDelete, models.AuditRollback, models.AuditUpdate, models.AuditBatchCreate, models.AuditCreate,
+	}, actions)
+
+	created := entries[4]
+	require.Equal(t, code, created.Code)
+	require.Nil(t, created.Before)
+	require.Equal(t, &models.AuditState{OriginalURL: "https://example.com/a", Title: "A"}, created.After)
+
+	require.Equal(t, "https://example.com/b", entries[3].After.OriginalURL)
+
+	updated := entries[2]
+	require.Equal(t, "https://example.com/a", updated.Before.OriginalURL)
+	require.Equal(t, "https://example.com/c", updated.After.OriginalURL)
+	require.Equal(t, "A", updated.After.Title)
+
+	rolledBack := entries[1]
+	require.Equal(t, "https://example.com/c", rolledBack.Before.OriginalURL)
+	require.Equal(t, "https://example.com/a", rolledBack.After.OriginalURL)
+
+	deleted := entries[0]
+	require.False(t, deleted.Before.Deleted)
+	require.True(t, deleted.After.Deleted)
+	require.Equal(t, "u1", deleted.Actor.UserID)
+}
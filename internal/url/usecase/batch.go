@@ -0,0 +1,179 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+)
+
+// defaultChunkSize is used when config has no chunk size
+const defaultChunkSize = 1000
+
+// batchItem is url of batch request with model built from it or validation error
+type batchItem struct {
+	req *models.UrlDTO
+	url *models.URL
+	err error
+}
+
+// BatchReduceURL returns result for every url in order of request. Invalid urls are skipped and every
+// chunk of urls is saved on its own, ErrSomeBatchShortenFailed comes with results if some url
+// isn't saved. Atomic batch is saved as a whole: invalid url rejects it with ErrBatchRejected.
+// Quota is reserved for valid urls which are not shortened yet before anything is saved.
+func (uu *UrlUsecase) BatchReduceURL(ctx context.Context, urls []*models.UrlDTO, atomic bool) ([]*models.UrlDTO, error) {
+	if err := uu.checkBatchQuota(ctx, len(urls)); err != nil {
+		return nil, err
+	}
+
+	items := make([]*batchItem, 0, len(urls))
+	for _, req := range urls {
+		items = append(items, uu.newBatchItem(ctx, req))
+	}
+
+	if atomic {
+		return uu.atomicBatch(ctx, items)
+	}
+
+	r, reserved, err := uu.reserveQuota(ctx, validURLs(items))
+	if err != nil {
+		return nil, err
+	}
+
+	size := uu.chunkSize()
+	res := make([]*models.UrlDTO, 0, len(urls))
+	partial := false
+	for start := 0; start < len(items); start += size {
+		chunk, failed := uu.shortenChunk(ctx, items[start:min(start+size, len(items))])
+		res = append(res, chunk...)
+		partial = partial || failed
+	}
+	uu.cancelQuota(ctx, r, notCreated(reserved, res))
+
+	if partial {
+		return res, ErrSomeBatchShortenFailed
+	}
+	return res, nil
+}
+
+func (uu *UrlUsecase) atomicBatch(ctx context.Context, items []*batchItem) ([]*models.UrlDTO, error) {
+	valid := validURLs(items)
+	if len(valid) != len(items) {
+		res := make([]*models.UrlDTO, 0, len(items))
+		for _, it := range items {
+			err := it.err
+			if err == nil {
+				err = errNotSaved
+			}
+			res = append(res, batchResult(it, nil, err))
+		}
+		return res, ErrBatchRejected
+	}
+
+	r, reserved, err := uu.reserveQuota(ctx, valid)
+	if err != nil {
+		return nil, err
+	}
+	saved, err := uu.batchAddURL(ctx, valid)
+	if err != nil {
+		uu.cancelQuota(ctx, r, reserved)
+		uu.logger.Errorf("can't add batch to database: %v", err)
+		return nil, fmt.Errorf("can't add short urls to database: %w", err)
+	}
+
+	res := make([]*models.UrlDTO, 0, len(items))
+	for i, it := range items {
+		res = append(res, uu.savedResult(it, saved[i]))
+	}
+	uu.cancelQuota(ctx, r, notCreated(reserved, res))
+	return res, nil
+}
+
+// shortenChunk saves valid urls of chunk with one batch, failed reports urls which are not saved
+func (uu *UrlUsecase) shortenChunk(ctx context.Context, chunk []*batchItem) ([]*models.UrlDTO, bool) {
+	valid := validURLs(chunk)
+
+	var saved []*models.URL
+	var saveErr error
+	if len(valid) != 0 {
+		saved, saveErr = uu.batchAddURL(ctx, valid)
+		if saveErr != nil {
+			uu.logger.Errorf("can't add batch chunk to database: %v", saveErr)
+		}
+	}
+
+	res := make([]*models.UrlDTO, 0, len(chunk))
+	failed := false
+	i := 0
+	for _, it := range chunk {
+		var r *models.UrlDTO
+		switch {
+		case it.err != nil:
+			r = batchResult(it, nil, it.err)
+		case saveErr != nil:
+			r = batchResult(it, nil, errNotSaved)
+		default:
+			r = uu.savedResult(it, saved[i])
+			i++
+		}
+
+		failed = failed || r.Status == models.BatchStatusInvalid || r.Status == models.BatchStatusFailed
+		res = append(res, r)
+	}
+
+	return res, failed
+}
+
+// validURLs returns models of items which passed validation
+func validURLs(items []*batchItem) []*models.URL {
+	valid := make([]*models.URL, 0, len(items))
+	for _, it := range items {
+		if it.err == nil {
+			valid = append(valid, it.url)
+		}
+	}
+	return valid
+}
+
+func (uu *UrlUsecase) chunkSize() int {
+	if size := uu.cfg.Storage.BatchChunkSize; size > 0 {
+		return size
+	}
+	return defaultChunkSize
+}
+
+func (uu *UrlUsecase) newBatchItem(ctx context.Context, req *models.UrlDTO) *batchItem {
+	u, err := uu.newURL(ctx, req)
+	return &batchItem{req: req, url: u, err: err}
+}
+
+func (uu *UrlUsecase) savedResult(it *batchItem, saved *models.URL) *models.UrlDTO {
+	// existing link must not be handed out in place of a protected one
+	if saved.Existed && it.url.PasswordHash != "" {
+		return batchResult(it, nil, ErrPasswordConflict)
+	}
+
+	r := batchResult(it, saved, nil)
+	r.ShortURL = uu.getShortURL(it.url.ShortDomain, saved.ShortURL)
+	return r
+}
+
+func batchResult(it *batchItem, saved *models.URL, err error) *models.UrlDTO {
+	r := &models.UrlDTO{
+		CorrelationID: it.req.CorrelationID,
+		OriginURL:     it.req.OriginURL,
+	}
+
+	switch {
+	case err != nil && it.err != nil:
+		r.Status, r.Error = models.BatchStatusInvalid, err.Error()
+	case err != nil:
+		r.Status, r.Error = models.BatchStatusFailed, err.Error()
+	case saved.Existed:
+		r.Status = models.BatchStatusExisting
+	default:
+		r.Status = models.BatchStatusCreated
+	}
+
+	return r
+}
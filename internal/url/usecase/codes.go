@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/MatiXxD/url-shortener/config"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/url"
+	"github.com/MatiXxD/url-shortener/pkg/tokengen"
+)
+
+// codeAttempts limits regeneration of short code which is already taken
+const codeAttempts = 5
+
+// newCodeGenerator picks strategy from config, counters are kept by repository
+func newCodeGenerator(cfg config.CodeConfig, repo url.Repository) tokengen.CodeGenerator {
+	alphabet := tokengen.Alphabet(cfg.Alphabet)
+
+	var gen tokengen.CodeGenerator
+	switch cfg.Strategy {
+	case config.CodeStrategySequence:
+		gen = tokengen.NewSequence(repo.NextID, alphabet, cfg.Length)
+	case config.CodeStrategyHash:
+		gen = tokengen.NewHash(alphabet, cfg.Length, cfg.Salt)
+	case config.CodeStrategyHashids:
+		gen = tokengen.NewHashids(repo.NextID, alphabet, cfg.Length, cfg.Salt)
+	default:
+		gen = tokengen.NewRandom(alphabet, cfg.Length)
+	}
+
+	if cfg.CheckSymbol {
+		gen = tokengen.NewChecked(gen, alphabet)
+	}
+	// check symbol can complete denied word too, so filter goes last
+	if len(cfg.Denylist) != 0 {
+		gen = tokengen.NewDenylist(gen, cfg.Denylist)
+	}
+
+	return gen
+}
+
+// canonicalCode brings code from request to the form it is stored in
+func (uu *UrlUsecase) canonicalCode(shortURL string) string {
+	if uu.cfg.ShortCode.CaseInsensitive {
+		return strings.ToLower(shortURL)
+	}
+	return shortURL
+}
+
+// mistypedCode reports code with wrong check symbol. Codes issued before check symbol was enabled
+// don't have it either, so such code is still looked up and only its miss is logged as typo.
+func (uu *UrlUsecase) mistypedCode(shortURL string) bool {
+	if !uu.cfg.ShortCode.CheckSymbol {
+		return false
+	}
+	return !tokengen.ValidCheck(shortURL, tokengen.Alphabet(uu.cfg.ShortCode.Alphabet))
+}
+
+// addURL sets generated short url and saves model with link.created event, code is regenerated while it is taken.
+// Created link is recorded in audit with it.
+func (uu *UrlUsecase) addURL(ctx context.Context, u *models.URL) (string, error) {
+	gen := uu.generator(ctx)
+	for attempt := 0; ; attempt++ {
+		code, err := gen.Generate(ctx, u.BaseURL, attempt)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate short url: %w", err)
+		}
+		u.ShortURL = code
+		u.Event = uu.newEvent(models.EventLinkCreated, u)
+		u.Audit = newAuditEntry(ctx, models.AuditCreate, u.UserID, u, nil, auditState(u))
+
+		shortURL, err := uu.repo.AddURL(ctx, u)
+		if errors.Is(err, url.ErrCodeTaken) && attempt+1 < codeAttempts {
+			uu.logger.Warnf("short url %s is taken, attempt %d", code, attempt+1)
+			continue
+		}
+		return shortURL, err
+	}
+}
+
+// batchAddURL is addURL for batch, codes of all urls are regenerated because batch is saved as a whole
+func (uu *UrlUsecase) batchAddURL(ctx context.Context, batch []*models.URL) ([]*models.URL, error) {
+	gen := uu.generator(ctx)
+	for attempt := 0; ; attempt++ {
+		for _, u := range batch {
+			code, err := gen.Generate(ctx, u.BaseURL, attempt)
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate short url: %w", err)
+			}
+			u.ShortURL = code
+			u.Event = uu.newEvent(models.EventLinkCreated, u)
+			u.Audit = newAuditEntry(ctx, models.AuditBatchCreate, u.UserID, u, nil, auditState(u))
+		}
+
+		res, err := uu.repo.BatchAddURL(ctx, batch)
+		if errors.Is(err, url.ErrCodeTaken) && attempt+1 < codeAttempts {
+			uu.logger.Warnf("short url in batch is taken, attempt %d", attempt+1)
+			continue
+		}
+		return res, err
+	}
+}
@@ -0,0 +1,264 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	neturl "net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/config"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/url"
+	"github.com/MatiXxD/url-shortener/internal/workspace"
+)
+
+// domainsTTL is how long domains read from storage are trusted, other instances may change them
+const domainsTTL = time.Minute
+
+// domainRegistry resolves hosts of short domains, configured domains take precedence over stored ones
+type domainRegistry struct {
+	defaultHost string
+	configured  map[string]*models.Domain
+	mu          sync.RWMutex
+	stored      map[string]*models.Domain
+	loadedAt    time.Time
+	now         func() time.Time
+}
+
+func newDomainRegistry(cfg *config.ServiceConfig) *domainRegistry {
+	dr := &domainRegistry{
+		configured: make(map[string]*models.Domain),
+		stored:     make(map[string]*models.Domain),
+		now:        time.Now,
+	}
+	// config is validated, so base urls parse
+	if u, err := neturl.Parse(cfg.BaseURL); err == nil {
+		dr.defaultHost = normalizeHost(u.Host)
+	}
+	for _, baseURL := range cfg.Domains {
+		if u, err := neturl.Parse(baseURL); err == nil {
+			host := normalizeHost(u.Host)
+			dr.configured[host] = &models.Domain{Host: host, BaseURL: baseURL, Configured: true}
+		}
+	}
+	return dr
+}
+
+// get returns registered domain, stored ones are reloaded when stale
+func (dr *domainRegistry) get(ctx context.Context, repo url.Repository, host string) (*models.Domain, error) {
+	if d, ok := dr.configured[host]; ok {
+		return d, nil
+	}
+
+	dr.mu.RLock()
+	d := dr.stored[host]
+	fresh := dr.now().Sub(dr.loadedAt) < domainsTTL
+	dr.mu.RUnlock()
+	if fresh {
+		return d, nil
+	}
+
+	if err := dr.reload(ctx, repo); err != nil {
+		return nil, err
+	}
+
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	return dr.stored[host], nil
+}
+
+// baseURL returns base url of known domain without going to storage
+func (dr *domainRegistry) baseURL(host string) (string, bool) {
+	if d, ok := dr.configured[host]; ok {
+		return d.BaseURL, true
+	}
+
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	if d, ok := dr.stored[host]; ok {
+		return d.BaseURL, true
+	}
+	return "", false
+}
+
+func (dr *domainRegistry) reload(ctx context.Context, repo url.Repository) error {
+	domains, err := repo.ListDomains(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot load domains: %w", err)
+	}
+
+	stored := make(map[string]*models.Domain, len(domains))
+	for _, d := range domains {
+		stored[d.Host] = d
+	}
+
+	dr.mu.Lock()
+	dr.stored, dr.loadedAt = stored, dr.now()
+	dr.mu.Unlock()
+
+	return nil
+}
+
+// invalidate makes next lookup reload domains from storage
+func (dr *domainRegistry) invalidate() {
+	dr.mu.Lock()
+	dr.loadedAt = time.Time{}
+	dr.mu.Unlock()
+}
+
+// ResolveDomain returns short domain of request host, unknown hosts get default domain
+func (uu *UrlUsecase) ResolveDomain(ctx context.Context, host string) string {
+	domain, err := uu.ShortDomain(ctx, host)
+	if err != nil {
+		return ""
+	}
+	return domain
+}
+
+// ShortDomain returns short domain of registered host, empty host and host of base url mean default domain
+func (uu *UrlUsecase) ShortDomain(ctx context.Context, host string) (string, error) {
+	d, err := uu.lookupDomain(ctx, host)
+	if err != nil || d == nil {
+		return "", err
+	}
+	return d.Host, nil
+}
+
+// lookupDomain returns registered domain of host, nil for default domain
+func (uu *UrlUsecase) lookupDomain(ctx context.Context, host string) (*models.Domain, error) {
+	host = normalizeHost(host)
+	if host == "" || host == uu.domains.defaultHost {
+		return nil, nil
+	}
+
+	d, err := uu.domains.get(ctx, uu.repo, host)
+	if err != nil {
+		uu.logger.Errorf("cannot resolve domain=%s: %v", host, err)
+		return nil, err
+	}
+	if d == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDomain, host)
+	}
+
+	return d, nil
+}
+
+// ListDomains returns configured and added short domains, default one is not included.
+// Domains of workspace are listed only in it, workspace ids are not shown.
+func (uu *UrlUsecase) ListDomains(ctx context.Context) ([]*models.Domain, error) {
+	if err := uu.domains.reload(ctx, uu.repo); err != nil {
+		uu.logger.Errorf("cannot list domains: %v", err)
+		return nil, err
+	}
+
+	res := make([]*models.Domain, 0, len(uu.domains.configured))
+	for _, d := range uu.domains.configured {
+		res = append(res, d)
+	}
+
+	ws := workspace.IDFrom(ctx)
+	uu.domains.mu.RLock()
+	for host, d := range uu.domains.stored {
+		if _, ok := uu.domains.configured[host]; ok || (d.Workspace != "" && d.Workspace != ws) {
+			continue
+		}
+		shown := *d
+		shown.Workspace = ""
+		res = append(res, &shown)
+	}
+	uu.domains.mu.RUnlock()
+
+	slices.SortFunc(res, func(a, b *models.Domain) int {
+		return strings.Compare(a.Host, b.Host)
+	})
+
+	return res, nil
+}
+
+// AddDomain registers short domain by its base url, host of base url is domain name.
+// Domain of workspace can't be used by links of other workspaces, empty workspace shares it with everyone.
+func (uu *UrlUsecase) AddDomain(ctx context.Context, baseURL, workspace string) (*models.Domain, error) {
+	if err := config.ValidateBaseURL(baseURL); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDomain, err)
+	}
+	u, err := neturl.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDomain, err)
+	}
+
+	if err := uu.checkWorkspace(ctx, workspace); err != nil {
+		return nil, err
+	}
+
+	d := &models.Domain{Host: normalizeHost(u.Host), BaseURL: baseURL, Workspace: workspace}
+	if _, ok := uu.domains.configured[d.Host]; ok || d.Host == uu.domains.defaultHost {
+		return nil, ErrDomainExists
+	}
+
+	err = uu.repo.AddDomain(ctx, d)
+	if errors.Is(err, url.ErrDomainExists) {
+		return nil, ErrDomainExists
+	}
+	if err != nil {
+		uu.logger.Errorf("cannot add domain=%s: %v", d.Host, err)
+		return nil, fmt.Errorf("cannot add domain: %w", err)
+	}
+	uu.domains.invalidate()
+
+	return d, nil
+}
+
+// DeleteDomain removes added domain, its links stay but are not served until domain is added again
+func (uu *UrlUsecase) DeleteDomain(ctx context.Context, host string) error {
+	host = normalizeHost(host)
+	if _, ok := uu.domains.configured[host]; ok {
+		return ErrConfiguredDomain
+	}
+
+	err := uu.repo.DeleteDomain(ctx, host)
+	if errors.Is(err, url.ErrNotFound) {
+		return ErrDomainNotFound
+	}
+	if err != nil {
+		uu.logger.Errorf("cannot delete domain=%s: %v", host, err)
+		return fmt.Errorf("cannot delete domain: %w", err)
+	}
+	uu.domains.invalidate()
+
+	return nil
+}
+
+// GetShortURL builds full short url from code using base url of its short domain
+func (uu *UrlUsecase) GetShortURL(domain, code string) string {
+	return uu.getShortURL(domain, code)
+}
+
+func (uu *UrlUsecase) getShortURL(domain, code string) string {
+	baseURL := uu.cfg.BaseURL
+	if domain != "" {
+		if b, ok := uu.domains.baseURL(domain); ok {
+			baseURL = b
+		} else {
+			// domain was removed, its links keep scheme of default one
+			scheme, _, _ := strings.Cut(uu.cfg.BaseURL, "://")
+			baseURL = scheme + "://" + domain
+		}
+	}
+	return fmt.Sprintf("%s/%s", baseURL, code)
+}
+
+// linkKey identifies link across short domains in caches and limiters
+func linkKey(domain, code string) string {
+	if domain == "" {
+		return code
+	}
+	return domain + "/" + code
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
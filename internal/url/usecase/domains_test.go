@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/url/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsecase_ShortDomains(t *testing.T) {
+	ctx := context.Background()
+	c := *cfg
+	c.Domains = []string{"https://go.brand.com"}
+	uc := NewUrlUsecase(repository.NewMapRepository(map[string]*models.URL{}, l), &c, l)
+
+	def, err := uc.ReduceURL(ctx, &models.UrlDTO{OriginURL: "https://example.com"})
+	require.NoError(t, err)
+	require.Contains(t, def, c.BaseURL+"/")
+
+	branded, err := uc.ReduceURL(ctx, &models.UrlDTO{OriginURL: "https://example.com", ShortDomain: "GO.brand.com"})
+	require.NoError(t, err)
+	require.Contains(t, branded, "https://go.brand.com/")
+
+	_, err = uc.ReduceURL(ctx, &models.UrlDTO{OriginURL: "https://example.com", ShortDomain: "evil.com"})
+	require.ErrorIs(t, err, ErrUnknownDomain)
+
+	// link is found only on its own domain, unknown hosts are served as default domain
+	code := branded[len("https://go.brand.com/"):]
+	u, err := uc.GetURL(ctx, uc.ResolveDomain(ctx, "go.brand.com"), code)
+	require.NoError(t, err)
+	require.Equal(t, "go.brand.com", u.ShortDomain)
+	require.Equal(t, branded, uc.GetShortURL(u.ShortDomain, u.ShortURL))
+
+	if code != def[len(c.BaseURL)+1:] {
+		_, err = uc.GetURL(ctx, uc.ResolveDomain(ctx, "localhost:8080"), code)
+		require.ErrorIs(t, err, ErrURLNotFound)
+	}
+	require.Equal(t, "", uc.ResolveDomain(ctx, "unknown.com"))
+}
+
+func TestUsecase_Domains(t *testing.T) {
+	ctx := context.Background()
+	c := *cfg
+	c.Domains = []string{"https://go.brand.com"}
+	uc := NewUrlUsecase(repository.NewMapRepository(map[string]*models.URL{}, l), &c, l)
+
+	_, err := uc.AddDomain(ctx, "ftp://short.io", "")
+	require.ErrorIs(t, err, ErrInvalidDomain)
+	_, err = uc.AddDomain(ctx, "https://go.brand.com", "")
+	require.ErrorIs(t, err, ErrDomainExists)
+
+	d, err := uc.AddDomain(ctx, "https://Short.io", "")
+	require.NoError(t, err)
+	require.Equal(t, "short.io", d.Host)
+	_, err = uc.AddDomain(ctx, "http://short.io", "")
+	require.ErrorIs(t, err, ErrDomainExists)
+
+	domains, err := uc.ListDomains(ctx)
+	require.NoError(t, err)
+	require.Len(t, domains, 2)
+	require.Equal(t, "go.brand.com", domains[0].Host)
+	require.True(t, domains[0].Configured)
+	require.Equal(t, "short.io", domains[1].Host)
+
+	short, err := uc.ReduceURL(ctx, &models.UrlDTO{OriginURL: "https://example.com", ShortDomain: "short.io"})
+	require.NoError(t, err)
+	require.Contains(t, short, "https://Short.io/")
+
+	require.ErrorIs(t, uc.DeleteDomain(ctx, "go.brand.com"), ErrConfiguredDomain)
+	require.NoError(t, uc.DeleteDomain(ctx, "short.io"))
+	require.ErrorIs(t, uc.DeleteDomain(ctx, "short.io"), ErrDomainNotFound)
+
+	_, err = uc.ShortDomain(ctx, "short.io")
+	require.ErrorIs(t, err, ErrUnknownDomain)
+}
@@ -1,8 +1,49 @@
 package usecase
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 var (
-	ErrNoBatchShorten         = errors.New("failed to create shorten urls for all batch")
 	ErrSomeBatchShortenFailed = errors.New("failed to create shorten urls for part of the batch")
+	ErrBatchRejected          = errors.New("batch has invalid urls, nothing is saved")
+	errNotSaved               = errors.New("url is not saved")
+	ErrInvalidURL             = errors.New("invalid url")
+	ErrURLNotFound            = errors.New("url not found")
+	ErrURLDeleted             = errors.New("url was deleted")
+	ErrNotOwner               = errors.New("url belongs to another user")
+	ErrURLConflict            = errors.New("destination is already shortened")
+	ErrNoHistory              = errors.New("url has no changes to roll back")
+	ErrChangeNotFound         = errors.New("change not found")
+	ErrInvalidFilter          = errors.New("invalid filter")
+	ErrInvalidCursor          = errors.New("invalid cursor")
+	ErrInvalidRedirectCode    = errors.New("invalid redirect code")
+	ErrInvalidPassthrough     = errors.New("invalid passthrough mode")
+	ErrPasswordRequired       = errors.New("url is protected with password")
+	ErrWrongPassword          = errors.New("wrong password")
+	ErrInvalidPassword        = errors.New("invalid password")
+	ErrPasswordConflict       = errors.New("url is already shortened with different protection")
+	ErrTooManyAttempts        = errors.New("too many attempts")
+	ErrUnknownDomain          = errors.New("unknown short domain")
+	ErrInvalidDomain          = errors.New("invalid short domain")
+	ErrDomainNotAllowed       = errors.New("short domain is not allowed in workspace")
+	ErrDomainExists           = errors.New("short domain already exists")
+	ErrDomainNotFound         = errors.New("short domain not found")
+	ErrConfiguredDomain       = errors.New("short domain is configured and can't be removed")
 )
+
+// RetryError tells client when the operation may be retried
+type RetryError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("%v, retry after %s", e.Err, e.RetryAfter)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
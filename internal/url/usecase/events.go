@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/url"
+	"github.com/google/uuid"
+)
+
+// SetPublisher makes usecase publish link events, nil publisher turns them off.
+// link.created is not published, repository writes it to outbox with the link.
+func (uu *UrlUsecase) SetPublisher(p url.Publisher) {
+	uu.events = p
+}
+
+// clickBatch is the most clicks published at once
+const clickBatch = 100
+
+// Click queues link.clicked for redirect to url, RunClicks publishes it. Click is dropped when queue is full.
+func (uu *UrlUsecase) Click(ctx context.Context, u *models.URL, click *models.Click) {
+	if uu.events == nil {
+		return
+	}
+
+	e := uu.newEvent(models.EventLinkClicked, u)
+	e.Click = click
+	select {
+	case uu.clicks <- e:
+	default:
+		uu.logger.Warnf("click queue is full, click of %s is dropped", u.ShortURL)
+	}
+}
+
+// RunClicks publishes queued clicks until ctx is done, clicks queued meanwhile are published together
+func (uu *UrlUsecase) RunClicks(ctx context.Context) {
+	for {
+		var events []*models.Event
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-uu.clicks:
+			events = append(events, e)
+		}
+
+	drain:
+		for len(events) < clickBatch {
+			select {
+			case e := <-uu.clicks:
+				events = append(events, e)
+			default:
+				break drain
+			}
+		}
+
+		uu.publish(ctx, events...)
+	}
+}
+
+// publish hands events to publisher, link is already changed, so failure is only logged.
+// Events outlive request, client may be gone by then.
+func (uu *UrlUsecase) publish(ctx context.Context, events ...*models.Event) {
+	if uu.events == nil || len(events) == 0 {
+		return
+	}
+	if err := uu.events.Publish(context.WithoutCancel(ctx), events...); err != nil {
+		uu.logger.Errorf("cannot publish %d link events: %v", len(events), err)
+	}
+}
+
+func (uu *UrlUsecase) newEvent(typ string, u *models.URL) *models.Event {
+	return &models.Event{
+		ID:         uuid.New().String(),
+		Type:       typ,
+		OccurredAt: time.Now().UTC(),
+		Link: &models.EventLink{
+			ShortURL:    uu.getShortURL(u.ShortDomain, u.ShortURL),
+			Code:        u.ShortURL,
+			ShortDomain: u.ShortDomain,
+			OriginalURL: u.BaseURL,
+			Title:       u.Title,
+			Tags:        u.Tags,
+			UserID:      u.UserID,
+		},
+	}
+}
@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/url"
+	"github.com/MatiXxD/url-shortener/internal/url/repository"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []*models.Event
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, events ...*models.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, events...)
+	return nil
+}
+
+// take returns published events and forgets them
+func (p *recordingPublisher) take() []*models.Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	events := p.events
+	p.events = nil
+	return events
+}
+
+// takeOutbox returns events written to outbox after previous call
+func takeOutbox(t *testing.T, r url.Repository) []*models.Event {
+	var events []*models.Event
+	_, err := r.ConsumeOutbox(context.Background(), "test", 100, func(records []*models.OutboxRecord) error {
+		for _, rec := range records {
+			events = append(events, rec.Event)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	return events
+}
+
+func TestUsecase_Events(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMapRepository(map[string]*models.URL{}, l)
+	uc := NewUrlUsecase(repo, cfg, l)
+	p := &recordingPublisher{}
+	uc.SetPublisher(p)
+
+	shortURL, err := uc.ReduceURL(ctx, &models.UrlDTO{OriginURL: "https://example.com/a", UserID: "u1", Tags: []string{"docs"}})
+	require.NoError(t, err)
+	require.Empty(t, p.take())
+
+	events := takeOutbox(t, repo)
+	require.Len(t, events, 1)
+	require.Equal(t, models.EventLinkCreated, events[0].Type)
+	require.NotEmpty(t, events[0].ID)
+	require.Equal(t, shortURL, events[0].Link.ShortURL)
+	require.Equal(t, "https://example.com/a", events[0].Link.OriginalURL)
+	require.Equal(t, "u1", events[0].Link.UserID)
+	require.Equal(t, []string{"docs"}, events[0].Link.Tags)
+	code := events[0].Link.Code
+
+	t.Run("existing link is not created again", func(t *testing.T) {
+		_, err := uc.ReduceURL(ctx, &models.UrlDTO{OriginURL: "https://example.com/a"})
+		require.NoError(t, err)
+		require.Empty(t, takeOutbox(t, repo))
+	})
+
+	t.Run("batch writes new links only", func(t *testing.T) {
+		_, err := uc.BatchReduceURL(ctx, []*models.UrlDTO{
+			{CorrelationID: "1", OriginURL: "https://example.com/a"},
+			{CorrelationID: "2", OriginURL: "https://example.com/b"},
+			{CorrelationID: "3", OriginURL: ""},
+		}, false)
+		require.ErrorIs(t, err, ErrSomeBatchShortenFailed)
+
+		events := takeOutbox(t, repo)
+		require.Len(t, events, 1)
+		require.Equal(t, models.EventLinkCreated, events[0].Type)
+		require.Equal(t, "https://example.com/b", events[0].Link.OriginalURL)
+	})
+
+	t.Run("update and rollback carry previous url", func(t *testing.T) {
+		_, err := uc.UpdateURL(ctx, "", code, "https://example.com/c", "u1")
+		require.NoError(t, err)
+		_, err = uc.Rollback(ctx, "", code, 0, "u1")
+		require.NoError(t, err)
+
+		events := p.take()
+		require.Len(t, events, 2)
+		require.Equal(t, models.EventLinkUpdated, events[0].Type)
+		require.Equal(t, "https://example.com/a", events[0].Link.PreviousURL)
+		require.Equal(t, "https://example.com/c", events[0].Link.OriginalURL)
+		require.Equal(t, "https://example.com/c", events[1].Link.PreviousURL)
+		require.Equal(t, "https://example.com/a", events[1].Link.OriginalURL)
+	})
+
+	t.Run("click", func(t *testing.T) {
+		runCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go uc.RunClicks(runCtx)
+
+		u, err := uc.GetURL(ctx, "", code)
+		require.NoError(t, err)
+		uc.Click(ctx, u, &models.Click{Referer: "https://news.io"})
+
+		var events []*models.Event
+		require.Eventually(t, func() bool {
+			events = append(events, p.take()...)
+			return len(events) > 0
+		}, time.Second, 10*time.Millisecond)
+		require.Len(t, events, 1)
+		require.Equal(t, models.EventLinkClicked, events[0].Type)
+		require.Equal(t, "https://news.io", events[0].Click.Referer)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.ErrorIs(t, uc.DeleteURL(ctx, "", code, "u2"), ErrNotOwner)
+		require.Empty(t, p.take())
+
+		require.NoError(t, uc.DeleteURL(ctx, "", code, "u1"))
+		events := p.take()
+		require.Len(t, events, 1)
+		require.Equal(t, models.EventLinkDeleted, events[0].Type)
+		require.Equal(t, code, events[0].Link.Code)
+	})
+}
+
+// blockingPublisher holds publish until it is released
+type blockingPublisher struct {
+	release chan struct{}
+}
+
+func (p *blockingPublisher) Publish(ctx context.Context, events ...*models.Event) error {
+	<-p.release
+	return nil
+}
+
+func TestUsecase_ClickQueue(t *testing.T) {
+	ctx := context.Background()
+	c := *cfg
+	c.Webhooks.ClickQueue = 2
+	uc := NewUrlUsecase(repository.NewMapRepository(map[string]*models.URL{}, l), &c, l)
+	p := &blockingPublisher{release: make(chan struct{})}
+	defer close(p.release)
+	uc.SetPublisher(p)
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go uc.RunClicks(runCtx)
+
+	// redirects don't wait for stuck publisher, clicks over queue are dropped
+	u := &models.URL{BaseURL: "https://example.com", ShortURL: "abc"}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10; i++ {
+			uc.Click(ctx, u, &models.Click{})
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("click waits for publisher")
+	}
+	require.LessOrEqual(t, len(uc.clicks), 2)
+}
@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/workspace"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 1000
+)
+
+// cursorToken is encoded into opaque cursor, sort is kept to reject cursor of another listing order
+type cursorToken struct {
+	Sort        string    `json:"o"`
+	Desc        bool      `json:"d,omitempty"`
+	CreatedAt   time.Time `json:"c"`
+	Value       string    `json:"v,omitempty"`
+	ShortDomain string    `json:"sd,omitempty"`
+	ShortURL    string    `json:"s"`
+}
+
+// ListURLs returns page of urls of workspace in context matching filter and cursor of the next page, empty if there are no more urls
+func (uu *UrlUsecase) ListURLs(ctx context.Context, filter *models.URLFilter, cursor string) ([]*models.URL, string, error) {
+	f := *filter
+	f.Workspace = workspace.IDFrom(ctx)
+	if f.Sort == "" {
+		f.Sort = models.SortCreatedAt
+	}
+	if !slices.Contains([]string{models.SortCreatedAt, models.SortOriginalURL, models.SortShortURL}, f.Sort) {
+		return nil, "", fmt.Errorf("%w: unknown sort %q", ErrInvalidFilter, f.Sort)
+	}
+	if f.Limit < 0 {
+		return nil, "", fmt.Errorf("%w: negative limit", ErrInvalidFilter)
+	}
+	if f.Limit == 0 {
+		f.Limit = defaultPageSize
+	}
+	f.Limit = min(f.Limit, maxPageSize)
+
+	if cursor != "" {
+		after, err := decodeCursor(cursor, &f)
+		if err != nil {
+			return nil, "", err
+		}
+		f.After = after
+	}
+
+	// one extra url tells if there is next page
+	limit := f.Limit
+	f.Limit++
+	urls, err := uu.repo.ListURLs(ctx, &f)
+	if err != nil {
+		uu.logger.Errorf("cannot list urls of user=%s: %v", f.UserID, err)
+		return nil, "", fmt.Errorf("cannot list urls: %w", err)
+	}
+	if len(urls) <= limit {
+		return urls, "", nil
+	}
+
+	urls = urls[:limit]
+	next, err := encodeCursor(urls[limit-1], &f)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return urls, next, nil
+}
+
+func encodeCursor(last *models.URL, filter *models.URLFilter) (string, error) {
+	token := cursorToken{
+		Sort:        filter.Sort,
+		Desc:        filter.Desc,
+		CreatedAt:   last.CreateAt,
+		ShortDomain: last.ShortDomain,
+		ShortURL:    last.ShortURL,
+	}
+	if filter.Sort == models.SortOriginalURL {
+		token.Value = last.BaseURL
+	}
+
+	data, err := json.Marshal(token)
+	if err != nil {
+		return "", fmt.Errorf("cannot encode cursor: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(cursor string, filter *models.URLFilter) (*models.URLCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var token cursorToken
+	if err := json.Unmarshal(data, &token); err != nil || token.ShortURL == "" {
+		return nil, ErrInvalidCursor
+	}
+	if token.Sort != filter.Sort || token.Desc != filter.Desc {
+		return nil, fmt.Errorf("%w: sort order changed", ErrInvalidCursor)
+	}
+
+	return &models.URLCursor{
+		CreatedAt:   token.CreatedAt,
+		Value:       token.Value,
+		ShortDomain: token.ShortDomain,
+		ShortURL:    token.ShortURL,
+	}, nil
+}
+
+// normalizeTags trims tags and drops empty and repeated ones
+func normalizeTags(tags []string) []string {
+	var res []string
+	for _, t := range tags {
+		t = strings.TrimSpace(t)
+		if t != "" && !slices.Contains(res, t) {
+			res = append(res, t)
+		}
+	}
+	return res
+}
@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/url"
+)
+
+// SetQuotas makes usecase count links against quotas of request, nil turns quotas off
+func (uu *UrlUsecase) SetQuotas(q url.Quotas) {
+	uu.quotas = q
+}
+
+func (uu *UrlUsecase) checkBatchQuota(ctx context.Context, size int) error {
+	if uu.quotas == nil {
+		return nil
+	}
+	return uu.quotas.CheckBatch(ctx, size)
+}
+
+// reserveQuota is called before urls are saved, only originals which are not shortened yet are reserved.
+// Created links keep active counters of reservation, links which end up not created are cancelled after saving.
+func (uu *UrlUsecase) reserveQuota(ctx context.Context, urls []*models.URL) (*models.QuotaReservation, int, error) {
+	if uu.quotas == nil || len(urls) == 0 {
+		return nil, 0, nil
+	}
+
+	found, err := uu.repo.FindURLs(ctx, urls)
+	if err != nil {
+		uu.logger.Errorf("cannot find existing urls: %v", err)
+		return nil, 0, fmt.Errorf("cannot find existing urls: %w", err)
+	}
+	n := 0
+	for _, u := range found {
+		if u == nil {
+			n++
+		}
+	}
+	if n == 0 {
+		return nil, 0, nil
+	}
+
+	r, err := uu.quotas.Reserve(ctx, n)
+	if err != nil || r == nil {
+		return nil, 0, err
+	}
+	for _, u := range urls {
+		u.QuotaKeys = r.Active
+	}
+	return r, n, nil
+}
+
+// cancelQuota returns n of reserved links, link which existed at reservation and is created anyway is not counted
+func (uu *UrlUsecase) cancelQuota(ctx context.Context, r *models.QuotaReservation, n int) {
+	if uu.quotas == nil || r == nil || n <= 0 {
+		return
+	}
+	uu.quotas.Cancel(ctx, r, n)
+}
+
+func (uu *UrlUsecase) releaseQuota(ctx context.Context, u *models.URL) {
+	if uu.quotas == nil || len(u.QuotaKeys) == 0 {
+		return
+	}
+	uu.quotas.Release(ctx, u.QuotaKeys)
+}
+
+// notCreated counts reserved links of results which are not created: existing, failed or not saved
+func notCreated(reserved int, res []*models.UrlDTO) int {
+	for _, r := range res {
+		if r.Status == models.BatchStatusCreated {
+			reserved--
+		}
+	}
+	return reserved
+}
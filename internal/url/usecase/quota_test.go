@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/MatiXxD/url-shortener/config"
+	mw "github.com/MatiXxD/url-shortener/internal/middleware"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/quota"
+	quotarepository "github.com/MatiXxD/url-shortener/internal/quota/repository"
+	quotausecase "github.com/MatiXxD/url-shortener/internal/quota/usecase"
+	"github.com/MatiXxD/url-shortener/internal/url/repository"
+	"github.com/MatiXxD/url-shortener/internal/workspace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsecase_Quotas(t *testing.T) {
+	ctx := mw.WithUserID(context.Background(), "u1")
+	uc := NewUrlUsecase(repository.NewMapRepository(map[string]*models.URL{}, l), cfg, l)
+
+	qr, err := quotarepository.NewFileRepository("", l)
+	require.NoError(t, err)
+	c := *cfg
+	c.Quotas.User = config.QuotaLimits{ActiveLinks: 3, BatchSize: 3}
+	qu := quotausecase.NewQuotaUsecase(qr, &c, l)
+	uc.SetQuotas(qu)
+
+	short, err := uc.ReduceURL(ctx, &models.UrlDTO{OriginURL: "https://a.com", UserID: "u1"})
+	require.NoError(t, err)
+	// existing link is not counted again
+	_, err = uc.ReduceURL(ctx, &models.UrlDTO{OriginURL: "https://a.com", UserID: "u1"})
+	require.NoError(t, err)
+
+	_, err = uc.BatchReduceURL(ctx, []*models.UrlDTO{
+		{OriginURL: "https://a.com", UserID: "u1"},
+		{OriginURL: "https://b.com", UserID: "u1"},
+		{OriginURL: "", UserID: "u1"},
+	}, false)
+	require.ErrorIs(t, err, ErrSomeBatchShortenFailed)
+
+	var exceeded *quota.ExceededError
+	_, err = uc.BatchReduceURL(ctx, make([]*models.UrlDTO, 4), false)
+	require.True(t, errors.As(err, &exceeded), err)
+	require.Equal(t, models.QuotaBatchSize, exceeded.Usage.Quota)
+
+	_, err = uc.BatchReduceURL(ctx, []*models.UrlDTO{
+		{OriginURL: "https://c.com", UserID: "u1"},
+		{OriginURL: "https://d.com", UserID: "u1"},
+	}, true)
+	require.True(t, errors.As(err, &exceeded), err)
+	require.Equal(t, models.QuotaActiveLinks, exceeded.Usage.Quota)
+	require.Equal(t, 2, exceeded.Usage.Used)
+
+	_, err = uc.ReduceURL(ctx, &models.UrlDTO{OriginURL: "https://c.com", UserID: "u1"})
+	require.NoError(t, err)
+	_, err = uc.ReduceURL(ctx, &models.UrlDTO{OriginURL: "https://d.com", UserID: "u1"})
+	require.ErrorIs(t, err, quota.ErrExceeded)
+
+	// deleted link frees quota
+	require.NoError(t, uc.DeleteURL(ctx, "", short[len(cfg.BaseURL)+1:], "u1"))
+	_, err = uc.ReduceURL(ctx, &models.UrlDTO{OriginURL: "https://d.com", UserID: "u1"})
+	require.NoError(t, err)
+
+	usage, err := qu.Usage(ctx)
+	require.NoError(t, err)
+	require.Len(t, usage, 1)
+	require.Equal(t, 3, usage[0].Used)
+}
+
+func TestUsecase_StreamBatchQuota(t *testing.T) {
+	ctx := mw.WithUserID(context.Background(), "u1")
+	c := *cfg
+	c.Storage.BatchChunkSize = 2
+	c.Quotas.User = config.QuotaLimits{BatchSize: 3}
+	uc := NewUrlUsecase(repository.NewMapRepository(map[string]*models.URL{}, l), &c, l)
+
+	qr, err := quotarepository.NewFileRepository("", l)
+	require.NoError(t, err)
+	uc.SetQuotas(quotausecase.NewQuotaUsecase(qr, &c, l))
+
+	items := []string{"https://a.com", "https://b.com", "https://c.com", "https://d.com"}
+	next := func() (*models.UrlDTO, error) {
+		if len(items) == 0 {
+			return nil, io.EOF
+		}
+		u := items[0]
+		items = items[1:]
+		return &models.UrlDTO{OriginURL: u, UserID: "u1"}, nil
+	}
+	var emitted []*models.UrlDTO
+	emit := func(res []*models.UrlDTO) error {
+		emitted = append(emitted, res...)
+		return nil
+	}
+
+	// batch can't be sent as stream to get around batch size
+	var exceeded *quota.ExceededError
+	err = uc.StreamReduceURL(ctx, next, emit)
+	require.True(t, errors.As(err, &exceeded), err)
+	require.Equal(t, models.QuotaBatchSize, exceeded.Usage.Quota)
+	require.Equal(t, 4, exceeded.Usage.Used)
+	require.Len(t, emitted, 2)
+}
+
+func TestUsecase_QuotaOfLink(t *testing.T) {
+	team := &models.Workspace{ID: "team"}
+	ctx := workspace.With(mw.WithUserID(context.Background(), "u1"), team)
+	uc := NewUrlUsecase(repository.NewMapRepository(map[string]*models.URL{}, l), cfg, l)
+
+	qr, err := quotarepository.NewFileRepository("", l)
+	require.NoError(t, err)
+	c := *cfg
+	c.Quotas.User = config.QuotaLimits{ActiveLinks: 1}
+	uc.SetQuotas(quotausecase.NewQuotaUsecase(qr, &c, l))
+
+	short, err := uc.ReduceURL(ctx, &models.UrlDTO{OriginURL: "https://a.com", UserID: "u1"})
+	require.NoError(t, err)
+
+	// existing link is returned even if quota is used up
+	again, err := uc.ReduceURL(ctx, &models.UrlDTO{OriginURL: "https://a.com", UserID: "u1"})
+	require.NoError(t, err)
+	require.Equal(t, short, again)
+	res, err := uc.BatchReduceURL(ctx, []*models.UrlDTO{{OriginURL: "https://a.com", UserID: "u1"}}, true)
+	require.NoError(t, err)
+	require.Equal(t, short, res[0].ShortURL)
+
+	_, err = uc.ReduceURL(ctx, &models.UrlDTO{OriginURL: "https://b.com", UserID: "u1"})
+	require.ErrorIs(t, err, quota.ErrExceeded)
+
+	// link frees only quotas it was counted in, workspace quota is turned on after it was created
+	c.Quotas.Workspace = config.QuotaLimits{ActiveLinks: 5}
+	qu := quotausecase.NewQuotaUsecase(qr, &c, l)
+	uc.SetQuotas(qu)
+	other := workspace.With(mw.WithUserID(context.Background(), "u2"), team)
+	_, err = uc.ReduceURL(other, &models.UrlDTO{OriginURL: "https://c.com", UserID: "u2"})
+	require.NoError(t, err)
+
+	require.NoError(t, uc.DeleteURL(ctx, "", short[len(cfg.BaseURL)+1:], "u1"))
+	usage, err := qu.Usage(ctx)
+	require.NoError(t, err)
+	require.Len(t, usage, 2)
+	require.Zero(t, usage[0].Used)
+	require.Equal(t, 1, usage[1].Used)
+}
@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+)
+
+// StreamReduceURL shortens urls returned by next until it returns io.EOF. Urls are saved in chunks
+// and results of every chunk are passed to emit before next chunk is read, so slow reader
+// slows down the whole stream. Invalid and not saved urls get their status like in BatchReduceURL.
+// Stream stops when quota doesn't allow the next chunk, batch size quota limits the whole stream.
+func (uu *UrlUsecase) StreamReduceURL(ctx context.Context, next func() (*models.UrlDTO, error), emit func([]*models.UrlDTO) error) error {
+	size := uu.chunkSize()
+	chunk := make([]*batchItem, 0, size)
+	total := 0
+
+	flush := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+
+		total += len(chunk)
+		if err := uu.checkBatchQuota(ctx, total); err != nil {
+			return err
+		}
+		r, reserved, err := uu.reserveQuota(ctx, validURLs(chunk))
+		if err != nil {
+			return err
+		}
+		res, _ := uu.shortenChunk(ctx, chunk)
+		chunk = chunk[:0]
+		uu.cancelQuota(ctx, r, notCreated(reserved, res))
+
+		return emit(res)
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		req, err := next()
+		if errors.Is(err, io.EOF) {
+			return flush()
+		}
+		if err != nil {
+			return errors.Join(flush(), err)
+		}
+
+		chunk = append(chunk, uu.newBatchItem(ctx, req))
+		if len(chunk) == size {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
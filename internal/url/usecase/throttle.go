@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"sync"
+	"time"
+)
+
+// attemptLimiter locks key for lockout after max failed attempts in a row
+type attemptLimiter struct {
+	max     int
+	lockout time.Duration
+	mu      sync.Mutex
+	entries map[string]*attempts
+	now     func() time.Time
+}
+
+type attempts struct {
+	failures    int
+	lockedUntil time.Time
+}
+
+func newAttemptLimiter(max int, lockout time.Duration) *attemptLimiter {
+	return &attemptLimiter{
+		max:     max,
+		lockout: lockout,
+		entries: make(map[string]*attempts),
+		now:     time.Now,
+	}
+}
+
+// locked returns time left until key is unlocked
+func (al *attemptLimiter) locked(key string) (time.Duration, bool) {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	a, ok := al.entries[key]
+	if !ok || a.lockedUntil.IsZero() {
+		return 0, false
+	}
+
+	left := a.lockedUntil.Sub(al.now())
+	if left <= 0 {
+		delete(al.entries, key)
+		return 0, false
+	}
+
+	return left, true
+}
+
+func (al *attemptLimiter) fail(key string) {
+	if al.max <= 0 {
+		return
+	}
+
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	a, ok := al.entries[key]
+	if !ok {
+		a = &attempts{}
+		al.entries[key] = a
+	}
+
+	a.failures++
+	if a.failures >= al.max {
+		a.lockedUntil = al.now().Add(al.lockout)
+	}
+}
+
+func (al *attemptLimiter) reset(key string) {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	delete(al.entries, key)
+}
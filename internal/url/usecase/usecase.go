@@ -2,115 +2,420 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	neturl "net/url"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/MatiXxD/url-shortener/config"
 	"github.com/MatiXxD/url-shortener/internal/models"
 	"github.com/MatiXxD/url-shortener/internal/url"
+	"github.com/MatiXxD/url-shortener/internal/workspace"
+	"github.com/MatiXxD/url-shortener/pkg/cache"
 	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/MatiXxD/url-shortener/pkg/qr"
 	"github.com/MatiXxD/url-shortener/pkg/tokengen"
-)
-
-const (
-	tokenSize = 10
-	batchSize = 5
+	"golang.org/x/crypto/bcrypt"
 )
 
 type UrlUsecase struct {
-	repo   url.Repository
-	cfg    *config.ServiceConfig
-	logger *logger.Logger
+	repo     url.Repository
+	cfg      *config.ServiceConfig
+	logger   *logger.Logger
+	qrCache  *cache.LRU[string, []byte]
+	attempts *attemptLimiter
+	codes    tokengen.CodeGenerator
+	// generators are made for code lengths of workspaces
+	genMu      sync.Mutex
+	generators map[int]tokengen.CodeGenerator
+	domains    *domainRegistry
+	events     url.Publisher
+	// clicks wait here for RunClicks, redirect doesn't wait for publisher
+	clicks     chan *models.Event
+	workspaces url.Workspaces
+	quotas     url.Quotas
 }
 
 func NewUrlUsecase(r url.Repository, cfg *config.ServiceConfig, l *logger.Logger) *UrlUsecase {
 	return &UrlUsecase{
-		repo:   r,
-		cfg:    cfg,
-		logger: l,
+		repo:       r,
+		cfg:        cfg,
+		logger:     l,
+		qrCache:    cache.NewLRU[string, []byte](cfg.Cache.Size, cfg.Cache.TTL.Std()),
+		attempts:   newAttemptLimiter(cfg.Limits.PasswordAttempts, cfg.Limits.PasswordLockout.Std()),
+		codes:      newCodeGenerator(cfg.ShortCode, r),
+		generators: make(map[int]tokengen.CodeGenerator),
+		domains:    newDomainRegistry(cfg),
+		clicks:     make(chan *models.Event, max(cfg.Webhooks.ClickQueue, 1)),
 	}
 }
 
 func (uu *UrlUsecase) ReduceURL(ctx context.Context, req *models.UrlDTO) (string, error) {
-	genURL := tokengen.GenerateToken(tokenSize)
+	u, err := uu.newURL(ctx, req)
+	if err != nil {
+		return "", err
+	}
 
-	shortURL, err := uu.repo.AddURL(ctx, &models.URL{
-		CorrelationID: req.CorrelationID,
-		BaseURL:       req.OriginURL,
-		ShortURL:      genURL,
-	})
+	r, reserved, err := uu.reserveQuota(ctx, []*models.URL{u})
+	if err != nil {
+		return "", err
+	}
+	shortURL, err := uu.addURL(ctx, u)
+	if err != nil || u.Existed {
+		uu.cancelQuota(ctx, r, reserved)
+	}
 	if err != nil {
 		uu.logger.Error("can't add short url to database")
 		return "", fmt.Errorf("can't add short url to database: %v", err)
 	}
 
-	return uu.getShortURL(shortURL), nil
+	// existing link must not be handed out in place of a protected one
+	if u.PasswordHash != "" && shortURL != u.ShortURL {
+		return "", ErrPasswordConflict
+	}
+
+	return uu.getShortURL(u.ShortDomain, shortURL), nil
 }
 
-func (uu *UrlUsecase) BatchReduceURL(ctx context.Context, urls []*models.UrlDTO) ([]*models.UrlDTO, error) {
-	batch := make([]*models.URL, 0, batchSize)
-	shortUrls := make([]*models.UrlDTO, 0, len(urls))
+// newURL validates request and builds model without short url, link belongs to workspace in context
+func (uu *UrlUsecase) newURL(ctx context.Context, req *models.UrlDTO) (*models.URL, error) {
+	if err := uu.validateURL(req.OriginURL); err != nil {
+		return nil, err
+	}
+	if err := validateRedirectCode(req.RedirectCode); err != nil {
+		return nil, err
+	}
+	if err := validatePassthrough(req.Passthrough); err != nil {
+		return nil, err
+	}
 
-	for _, url := range urls {
-		shortUrl := tokengen.GenerateToken(tokenSize)
+	domain, err := uu.linkDomain(ctx, req.ShortDomain)
+	if err != nil {
+		return nil, err
+	}
 
-		batch = append(batch, &models.URL{
-			CorrelationID: url.CorrelationID,
-			BaseURL:       url.OriginURL,
-			ShortURL:      shortUrl,
-		})
+	redirectCode := req.RedirectCode
+	if ws := workspace.From(ctx); ws != nil && redirectCode == 0 {
+		redirectCode = ws.Settings.RedirectCode
+	}
 
-		if len(batch) != batchSize {
-			continue
-		}
+	passwordHash, err := hashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
 
-		dbUrls, err := uu.repo.BatchAddURL(ctx, batch)
-		if err != nil {
-			if len(shortUrls) == 0 {
-				return nil, ErrNoBatchShorten
-			}
-			return shortUrls, ErrSomeBatchShortenFailed
+	return &models.URL{
+		CorrelationID: req.CorrelationID,
+		BaseURL:       req.OriginURL,
+		UserID:        req.UserID,
+		Preview:       req.Preview,
+		PasswordHash:  passwordHash,
+		RedirectCode:  redirectCode,
+		Passthrough:   req.Passthrough,
+		Title:         strings.TrimSpace(req.Title),
+		Tags:          normalizeTags(req.Tags),
+		ShortDomain:   domain,
+		Workspace:     workspace.IDFrom(ctx),
+	}, nil
+}
+
+func (uu *UrlUsecase) GetURL(ctx context.Context, domain, shortURL string) (*models.URL, error) {
+	shortURL = uu.canonicalCode(shortURL)
+
+	u, err := uu.repo.GetURL(ctx, domain, shortURL)
+	if errors.Is(err, url.ErrNotFound) {
+		if uu.mistypedCode(shortURL) {
+			uu.logger.Debugf("short_url=%s has wrong check symbol", shortURL)
 		}
-		batch = batch[:0]
-
-		for _, u := range dbUrls {
-			shortUrls = append(shortUrls, &models.UrlDTO{
-				CorrelationID: u.CorrelationID,
-				OriginURL:     u.BaseURL,
-				ShortURL:      uu.getShortURL(u.ShortURL),
-			})
+		return nil, ErrURLNotFound
+	}
+	if err != nil {
+		uu.logger.Errorf("cannot get base_url for short_url=%s: %v", shortURL, err)
+		return nil, fmt.Errorf("cannot get url: %w", err)
+	}
+	if u.IsDeleted {
+		return nil, ErrURLDeleted
+	}
+
+	return u, nil
+}
+
+// DeleteURL marks url as deleted, only its creator can do it
+func (uu *UrlUsecase) DeleteURL(ctx context.Context, domain, shortURL, userID string) error {
+	shortURL = uu.canonicalCode(shortURL)
+	u, err := uu.ownURL(ctx, domain, shortURL, userID)
+	if err != nil {
+		return err
+	}
+
+	after := auditState(u)
+	after.Deleted = true
+	entry := newAuditEntry(ctx, models.AuditDelete, userID, u, auditState(u), after)
+
+	if err := uu.repo.DeleteURL(ctx, workspace.IDFrom(ctx), domain, shortURL, entry); err != nil {
+		uu.logger.Errorf("cannot delete short_url=%s: %v", shortURL, err)
+		return fmt.Errorf("cannot delete url: %w", err)
+	}
+	uu.releaseQuota(ctx, u)
+	uu.publish(ctx, uu.newEvent(models.EventLinkDeleted, u))
+
+	return nil
+}
+
+// UpdateURL changes destination of url owned by user
+func (uu *UrlUsecase) UpdateURL(ctx context.Context, domain, shortURL, originalURL, userID string) (*models.URL, error) {
+	if err := uu.validateURL(originalURL); err != nil {
+		return nil, err
+	}
+
+	u, err := uu.ownURL(ctx, domain, shortURL, userID)
+	if err != nil {
+		return nil, err
+	}
+	if u.BaseURL == originalURL {
+		return u, nil
+	}
+
+	return uu.updateURL(ctx, models.AuditUpdate, u, originalURL, userID)
+}
+
+// GetHistory returns destination changes of url owned by user, newest first
+func (uu *UrlUsecase) GetHistory(ctx context.Context, domain, shortURL, userID string) ([]*models.URLChange, error) {
+	shortURL = uu.canonicalCode(shortURL)
+	if _, err := uu.ownURL(ctx, domain, shortURL, userID); err != nil {
+		return nil, err
+	}
+
+	history, err := uu.repo.GetHistory(ctx, workspace.IDFrom(ctx), domain, shortURL)
+	if err != nil {
+		uu.logger.Errorf("cannot get history of short_url=%s: %v", shortURL, err)
+		return nil, fmt.Errorf("cannot get url history: %w", err)
+	}
+
+	return history, nil
+}
+
+// Rollback restores destination which was replaced by change, zero change id means the latest one.
+// Rollback is recorded in history as a regular change.
+func (uu *UrlUsecase) Rollback(ctx context.Context, domain, shortURL string, changeID int, userID string) (*models.URL, error) {
+	shortURL = uu.canonicalCode(shortURL)
+	u, err := uu.ownURL(ctx, domain, shortURL, userID)
+	if err != nil {
+		return nil, err
+	}
+	history, err := uu.repo.GetHistory(ctx, workspace.IDFrom(ctx), domain, shortURL)
+	if err != nil {
+		uu.logger.Errorf("cannot get history of short_url=%s: %v", shortURL, err)
+		return nil, fmt.Errorf("cannot get url history: %w", err)
+	}
+	if len(history) == 0 {
+		return nil, ErrNoHistory
+	}
+
+	change := history[0]
+	if changeID != 0 {
+		i := slices.IndexFunc(history, func(c *models.URLChange) bool { return c.ID == changeID })
+		if i < 0 {
+			return nil, ErrChangeNotFound
 		}
+		change = history[i]
+	}
+
+	return uu.updateURL(ctx, models.AuditRollback, u, change.PreviousURL, userID)
+}
+
+// updateURL replaces destination of current url with originalURL, change is audited as action
+func (uu *UrlUsecase) updateURL(ctx context.Context, action string, current *models.URL, originalURL, userID string) (*models.URL, error) {
+	after := auditState(current)
+	after.OriginalURL = originalURL
+	entry := newAuditEntry(ctx, action, userID, current, auditState(current), after)
+
+	u, err := uu.repo.UpdateURL(ctx, workspace.IDFrom(ctx), current.ShortDomain, current.ShortURL, originalURL, userID, entry)
+	if errors.Is(err, url.ErrConflict) {
+		return nil, ErrURLConflict
+	}
+	if err != nil {
+		uu.logger.Errorf("cannot update short_url=%s: %v", current.ShortURL, err)
+		return nil, fmt.Errorf("cannot update url: %w", err)
 	}
 
-	dbUrls, err := uu.repo.BatchAddURL(ctx, batch)
+	e := uu.newEvent(models.EventLinkUpdated, u)
+	e.Link.PreviousURL = current.BaseURL
+	uu.publish(ctx, e)
+
+	return u, nil
+}
+
+// ownURL returns url if it exists and belongs to user, it is read consistently as it is about to change.
+// Links of other workspaces are not found.
+func (uu *UrlUsecase) ownURL(ctx context.Context, domain, shortURL, userID string) (*models.URL, error) {
+	u, err := uu.GetURL(url.WithConsistentRead(ctx), domain, shortURL)
 	if err != nil {
-		if len(shortUrls) == 0 {
-			return nil, ErrNoBatchShorten
+		return nil, err
+	}
+	if u.Workspace != workspace.IDFrom(ctx) {
+		return nil, ErrURLNotFound
+	}
+	if u.UserID == "" || u.UserID != userID {
+		return nil, ErrNotOwner
+	}
+
+	return u, nil
+}
+
+// RedirectCode returns status code for redirect to url destination
+func (uu *UrlUsecase) RedirectCode(u *models.URL) int {
+	if u.RedirectCode != 0 {
+		return u.RedirectCode
+	}
+	return uu.cfg.Redirect.Code
+}
+
+// NeedsPreview reports if interstitial page must be shown instead of redirect
+func (uu *UrlUsecase) NeedsPreview(url *models.URL) bool {
+	if url.Preview {
+		return true
+	}
+	if !uu.cfg.Preview.Untrusted {
+		return false
+	}
+
+	return !slices.Contains(uu.cfg.Preview.TrustedUsers, url.UserID)
+}
+
+func (uu *UrlUsecase) GetStats(ctx context.Context) (*models.Stats, error) {
+	stats, err := uu.repo.Stats(ctx)
+	if err != nil {
+		uu.logger.Errorf("cannot get stats: %v", err)
+		return nil, fmt.Errorf("cannot get stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// CheckPassword verifies password of protected url, failed attempts are throttled per code
+func (uu *UrlUsecase) CheckPassword(url *models.URL, password string) error {
+	if url.PasswordHash == "" {
+		return nil
+	}
+	if password == "" {
+		return ErrPasswordRequired
+	}
+
+	key := linkKey(url.ShortDomain, url.ShortURL)
+	if left, ok := uu.attempts.locked(key); ok {
+		return &RetryError{Err: ErrTooManyAttempts, RetryAfter: left}
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(url.PasswordHash), []byte(password)); err != nil {
+		uu.logger.Infof("wrong password for short_url=%s", key)
+		uu.attempts.fail(key)
+		return ErrWrongPassword
+	}
+	uu.attempts.reset(key)
+
+	return nil
+}
+
+// Destination builds redirect target, passthrough links get extra path and query of request.
+// Path is expected to be escaped, extra path for links without path passthrough is not found.
+func (uu *UrlUsecase) Destination(u *models.URL, path string, query neturl.Values) (string, error) {
+	passPath := u.Passthrough == models.PassthroughPath || u.Passthrough == models.PassthroughAll
+	passQuery := u.Passthrough == models.PassthroughQuery || u.Passthrough == models.PassthroughAll
+
+	if path != "" && !passPath {
+		return "", ErrURLNotFound
+	}
+	if path == "" && (!passQuery || len(query) == 0) {
+		return u.BaseURL, nil
+	}
+
+	dest, err := neturl.Parse(u.BaseURL)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse destination: %w", err)
+	}
+
+	if path != "" {
+		dest = dest.JoinPath(path)
+	}
+
+	if passQuery && len(query) != 0 {
+		values := dest.Query()
+		for k, v := range query {
+			if _, ok := values[k]; ok && uu.cfg.Redirect.QueryPrecedence != config.QueryPrecedenceRequest {
+				continue
+			}
+			values[k] = v
 		}
-		return shortUrls, ErrSomeBatchShortenFailed
+		dest.RawQuery = values.Encode()
+	}
+
+	return dest.String(), nil
+}
+
+// GetQRCode renders qr code with full short url, images are cached per code and options
+func (uu *UrlUsecase) GetQRCode(ctx context.Context, domain, shortURL string, opts qr.Options) ([]byte, error) {
+	shortURL = uu.canonicalCode(shortURL)
+	// link is resolved even for cached image, deleted one must not get it
+	if _, err := uu.GetURL(ctx, domain, shortURL); err != nil {
+		return nil, err
+	}
+
+	key := linkKey(domain, shortURL) + "|" + opts.Key()
+	if img, ok := uu.qrCache.Get(key); ok {
+		return img, nil
 	}
-	batch = batch[:0]
 
-	for _, u := range dbUrls {
-		shortUrls = append(shortUrls, &models.UrlDTO{
-			CorrelationID: u.CorrelationID,
-			OriginURL:     u.BaseURL,
-			ShortURL:      uu.getShortURL(u.ShortURL),
-		})
+	img, err := qr.Render(uu.getShortURL(domain, shortURL), opts)
+	if err != nil {
+		return nil, fmt.Errorf("cannot render qr code: %w", err)
 	}
+	uu.qrCache.Set(key, img)
 
-	return shortUrls, nil
+	return img, nil
 }
 
-func (uu *UrlUsecase) GetURL(ctx context.Context, shortURL string) (string, bool) {
-	url, err := uu.repo.GetURL(ctx, shortURL)
+// hashPassword returns salted hash, empty password means url isn't protected
+func hashPassword(password string) (string, error) {
+	if password == "" {
+		return "", nil
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		return "", fmt.Errorf("%w: longer than 72 bytes", ErrInvalidPassword)
+	}
 	if err != nil {
-		uu.logger.Errorf("cannot get base_url for short_url=%s: %v", shortURL, err)
-		return "", false
+		return "", fmt.Errorf("can't hash password: %w", err)
 	}
 
-	return url.BaseURL, true
+	return string(hash), nil
 }
 
-func (uu *UrlUsecase) getShortURL(url string) string {
-	return fmt.Sprintf("%s/%s", uu.cfg.BaseURL, url)
+func (uu *UrlUsecase) validateURL(url string) error {
+	if url == "" {
+		return ErrInvalidURL
+	}
+	if max := uu.cfg.Limits.MaxURLLength; max > 0 && len(url) > max {
+		return fmt.Errorf("%w: longer than %d", ErrInvalidURL, max)
+	}
+	return nil
+}
+
+// validateRedirectCode allows zero which means service default
+func validateRedirectCode(code int) error {
+	if code != 0 && !slices.Contains(config.RedirectCodes, code) {
+		return fmt.Errorf("%w: %d", ErrInvalidRedirectCode, code)
+	}
+	return nil
+}
+
+func validatePassthrough(mode string) error {
+	switch mode {
+	case models.PassthroughNone, models.PassthroughQuery, models.PassthroughPath, models.PassthroughAll:
+		return nil
+	}
+	return fmt.Errorf("%w: %q", ErrInvalidPassthrough, mode)
 }
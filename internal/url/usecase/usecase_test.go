@@ -3,11 +3,15 @@ package usecase
 import (
 	"context"
 	"fmt"
+	neturl "net/url"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/MatiXxD/url-shortener/config"
 	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/MatiXxD/url-shortener/pkg/tokengen"
 	"go.uber.org/zap"
 
 	"github.com/MatiXxD/url-shortener/internal/models"
@@ -26,14 +30,16 @@ func TestMain(t *testing.M) {
 		panic(err)
 	}
 
-	l = &logger.Logger{zl.Sugar()}
+	l = &logger.Logger{SugaredLogger: zl.Sugar()}
 
 	cfg = &config.ServiceConfig{
 		Addr:        ":8080",
 		BaseURL:     "http://localhost:8080",
 		LoggerLevel: "info",
-		FilePath:    "/tmp/short-url-db.json",
-		DSN:         "",
+		Storage: config.StorageConfig{
+			FilePath: "/tmp/short-url-db.json",
+			DSN:      "",
+		},
 	}
 
 	os.Exit(t.Run())
@@ -71,6 +77,177 @@ func TestUsecase_ReduceURL(t *testing.T) {
 	})
 }
 
+func TestUsecase_ShortCodes(t *testing.T) {
+	codesCfg := *cfg
+
+	t.Run("Sequence skips taken code", func(t *testing.T) {
+		codesCfg.ShortCode = config.CodeConfig{Strategy: config.CodeStrategySequence, Alphabet: "0123456789abcdef", Length: 3}
+		d := map[string]*models.URL{
+			"https://taken.com": {BaseURL: "https://taken.com", ShortURL: "001"},
+		}
+		uc := NewUrlUsecase(repository.NewMapRepository(d, l), &codesCfg, l)
+
+		shortURL, err := uc.ReduceURL(context.Background(), &models.UrlDTO{OriginURL: "https://www.google.com"})
+		require.NoError(t, err)
+		require.Equal(t, cfg.BaseURL+"/002", shortURL)
+
+		urls, err := uc.BatchReduceURL(context.Background(), []*models.UrlDTO{
+			{CorrelationID: "1", OriginURL: "https://a.com"},
+			{CorrelationID: "2", OriginURL: "https://b.com"},
+		}, false)
+		require.NoError(t, err)
+		require.Equal(t, cfg.BaseURL+"/003", urls[0].ShortURL)
+		require.Equal(t, cfg.BaseURL+"/004", urls[1].ShortURL)
+	})
+
+	t.Run("Hash is deterministic", func(t *testing.T) {
+		codesCfg.ShortCode = config.CodeConfig{Strategy: config.CodeStrategyHash, Length: 8}
+
+		first, err := NewUrlUsecase(repository.NewMapRepository(map[string]*models.URL{}, l), &codesCfg, l).
+			ReduceURL(context.Background(), &models.UrlDTO{OriginURL: "https://www.google.com"})
+		require.NoError(t, err)
+		second, err := NewUrlUsecase(repository.NewMapRepository(map[string]*models.URL{}, l), &codesCfg, l).
+			ReduceURL(context.Background(), &models.UrlDTO{OriginURL: "https://www.google.com"})
+		require.NoError(t, err)
+
+		require.Equal(t, first, second)
+		require.Len(t, first, len(cfg.BaseURL)+1+8)
+	})
+
+	t.Run("Readable codes", func(t *testing.T) {
+		codesCfg.ShortCode = config.CodeConfig{
+			Strategy:        config.CodeStrategyRandom,
+			Alphabet:        tokengen.AlphabetReadableLower,
+			Length:          6,
+			CheckSymbol:     true,
+			CaseInsensitive: true,
+		}
+		uc := NewUrlUsecase(repository.NewMapRepository(map[string]*models.URL{}, l), &codesCfg, l)
+
+		shortURL, err := uc.ReduceURL(context.Background(), &models.UrlDTO{OriginURL: "https://www.google.com"})
+		require.NoError(t, err)
+		code := strings.TrimPrefix(shortURL, cfg.BaseURL+"/")
+		require.Len(t, code, 7)
+
+		got, err := uc.GetURL(context.Background(), "", strings.ToUpper(code))
+		require.NoError(t, err)
+		require.Equal(t, "https://www.google.com", got.BaseURL)
+
+		// mistyped code is rejected by check symbol
+		typo := "2" + code[1:]
+		if code[0] == '2' {
+			typo = "3" + code[1:]
+		}
+		_, err = uc.GetURL(context.Background(), "", typo)
+		require.ErrorIs(t, err, ErrURLNotFound)
+	})
+
+	t.Run("Codes issued before check symbol keep working", func(t *testing.T) {
+		repo := repository.NewMapRepository(map[string]*models.URL{}, l)
+		codesCfg.ShortCode = config.CodeConfig{Strategy: config.CodeStrategyRandom, Length: 6}
+		shortURL, err := NewUrlUsecase(repo, &codesCfg, l).
+			ReduceURL(context.Background(), &models.UrlDTO{OriginURL: "https://www.google.com"})
+		require.NoError(t, err)
+		code := strings.TrimPrefix(shortURL, cfg.BaseURL+"/")
+
+		checkedCfg := codesCfg
+		checkedCfg.ShortCode.CheckSymbol = true
+		uc := NewUrlUsecase(repo, &checkedCfg, l)
+
+		got, err := uc.GetURL(context.Background(), "", code)
+		require.NoError(t, err)
+		require.Equal(t, "https://www.google.com", got.BaseURL)
+	})
+}
+
+func TestUsecase_BatchReduceURL(t *testing.T) {
+	batch := []*models.UrlDTO{
+		{CorrelationID: "1", OriginURL: "https://a.com"},
+		{CorrelationID: "2", OriginURL: "https://exists.com"},
+		{CorrelationID: "3", OriginURL: ""},
+		{CorrelationID: "4", OriginURL: "https://b.com"},
+		{CorrelationID: "5", OriginURL: "https://exists.com", Password: "secret"},
+		{CorrelationID: "6", OriginURL: "https://c.com"},
+	}
+
+	tests := []struct {
+		name       string
+		urls       []*models.UrlDTO
+		atomic     bool
+		wantErr    error
+		wantStatus []string
+		wantURLs   int
+	}{
+		{
+			name:    "partial success",
+			urls:    batch,
+			wantErr: ErrSomeBatchShortenFailed,
+			wantStatus: []string{
+				models.BatchStatusCreated, models.BatchStatusExisting, models.BatchStatusInvalid,
+				models.BatchStatusCreated, models.BatchStatusFailed, models.BatchStatusCreated,
+			},
+			wantURLs: 4,
+		},
+		{
+			name:    "atomic rejected",
+			urls:    batch,
+			atomic:  true,
+			wantErr: ErrBatchRejected,
+			wantStatus: []string{
+				models.BatchStatusFailed, models.BatchStatusFailed, models.BatchStatusInvalid,
+				models.BatchStatusFailed, models.BatchStatusFailed, models.BatchStatusFailed,
+			},
+			wantURLs: 1,
+		},
+		{
+			name:       "atomic success",
+			urls:       []*models.UrlDTO{batch[0], batch[1], batch[3]},
+			atomic:     true,
+			wantStatus: []string{models.BatchStatusCreated, models.BatchStatusExisting, models.BatchStatusCreated},
+			wantURLs:   3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := map[string]*models.URL{
+				"https://exists.com": {BaseURL: "https://exists.com", ShortURL: "EXIST"},
+			}
+			r := repository.NewMapRepository(d, l)
+			uc := NewUrlUsecase(r, cfg, l)
+
+			res, err := uc.BatchReduceURL(context.Background(), tt.urls, tt.atomic)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Len(t, res, len(tt.urls))
+			for i, item := range res {
+				require.Equal(t, tt.urls[i].CorrelationID, item.CorrelationID)
+				require.Equal(t, tt.wantStatus[i], item.Status, item.CorrelationID)
+
+				switch item.Status {
+				case models.BatchStatusCreated, models.BatchStatusExisting:
+					require.NotEmpty(t, item.ShortURL)
+					require.Empty(t, item.Error)
+				default:
+					require.Empty(t, item.ShortURL)
+					require.NotEmpty(t, item.Error)
+				}
+			}
+			if tt.wantStatus[1] == models.BatchStatusExisting {
+				require.Equal(t, cfg.BaseURL+"/EXIST", res[1].ShortURL)
+			}
+
+			stats, err := r.Stats(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, tt.wantURLs, stats.URLs)
+		})
+	}
+}
+
 func TestUsecase_GetURL(t *testing.T) {
 	testURL := "https://www.google.com"
 	testShortURL := "AAAAA"
@@ -84,14 +261,170 @@ func TestUsecase_GetURL(t *testing.T) {
 	uc := NewUrlUsecase(r, cfg, l)
 
 	t.Run("Success get", func(t *testing.T) {
-		got, ok := uc.GetURL(context.Background(), testShortURL)
-		require.Equal(t, true, ok)
-		require.Equal(t, testURL, got)
+		got, err := uc.GetURL(context.Background(), "", testShortURL)
+		require.NoError(t, err)
+		require.Equal(t, testURL, got.BaseURL)
 	})
 
 	t.Run("Can't get url", func(t *testing.T) {
-		got, ok := uc.GetURL(context.Background(), "https://random.com")
-		require.Equal(t, false, ok)
-		require.Zero(t, got)
+		got, err := uc.GetURL(context.Background(), "", "https://random.com")
+		require.ErrorIs(t, err, ErrURLNotFound)
+		require.Nil(t, got)
+	})
+}
+
+func TestUsecase_NeedsPreview(t *testing.T) {
+	tests := []struct {
+		name    string
+		preview config.PreviewConfig
+		url     *models.URL
+		want    bool
+	}{
+		{
+			name: "Plain link",
+			url:  &models.URL{UserID: "user-1"},
+			want: false,
+		},
+		{
+			name: "Link with preview",
+			url:  &models.URL{UserID: "user-1", Preview: true},
+			want: true,
+		},
+		{
+			name:    "Untrusted creator",
+			preview: config.PreviewConfig{Untrusted: true, TrustedUsers: []string{"admin"}},
+			url:     &models.URL{UserID: "user-1"},
+			want:    true,
+		},
+		{
+			name:    "Trusted creator",
+			preview: config.PreviewConfig{Untrusted: true, TrustedUsers: []string{"admin"}},
+			url:     &models.URL{UserID: "admin"},
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := *cfg
+			c.Preview = tt.preview
+			uc := NewUrlUsecase(repository.NewMapRepository(map[string]*models.URL{}, l), &c, l)
+
+			require.Equal(t, tt.want, uc.NeedsPreview(tt.url))
+		})
+	}
+}
+
+func TestUsecase_CheckPassword(t *testing.T) {
+	c := *cfg
+	c.Limits.PasswordAttempts = 2
+	c.Limits.PasswordLockout = config.Duration(time.Minute)
+	r := repository.NewMapRepository(map[string]*models.URL{}, l)
+	uc := NewUrlUsecase(r, &c, l)
+
+	shortURL, err := uc.ReduceURL(context.Background(), &models.UrlDTO{
+		OriginURL: "https://example.com/secret",
+		Password:  "pa55word",
+	})
+	require.NoError(t, err)
+
+	url, err := uc.GetURL(context.Background(), "", shortURL[len(c.BaseURL)+1:])
+	require.NoError(t, err)
+	require.NotEmpty(t, url.PasswordHash)
+	require.NotEqual(t, "pa55word", url.PasswordHash)
+
+	require.ErrorIs(t, uc.CheckPassword(url, ""), ErrPasswordRequired)
+	require.NoError(t, uc.CheckPassword(url, "pa55word"))
+
+	require.ErrorIs(t, uc.CheckPassword(url, "wrong"), ErrWrongPassword)
+	require.ErrorIs(t, uc.CheckPassword(url, "wrong"), ErrWrongPassword)
+
+	// correct password is rejected too while code is locked
+	err = uc.CheckPassword(url, "pa55word")
+	require.ErrorIs(t, err, ErrTooManyAttempts)
+	var retryErr *RetryError
+	require.ErrorAs(t, err, &retryErr)
+	require.Greater(t, retryErr.RetryAfter, time.Duration(0))
+
+	t.Run("Conflict with existing url", func(t *testing.T) {
+		_, err := uc.ReduceURL(context.Background(), &models.UrlDTO{
+			OriginURL: "https://example.com/secret",
+			Password:  "other",
+		})
+		require.ErrorIs(t, err, ErrPasswordConflict)
 	})
 }
+
+func TestUsecase_Destination(t *testing.T) {
+	tests := []struct {
+		name       string
+		precedence string
+		url        *models.URL
+		path       string
+		query      neturl.Values
+		want       string
+		wantErr    error
+	}{
+		{
+			name:  "Passthrough disabled",
+			url:   &models.URL{BaseURL: "https://example.com/page?a=1"},
+			query: neturl.Values{"utm_source": {"mail"}},
+			want:  "https://example.com/page?a=1",
+		},
+		{
+			name:    "Extra path without passthrough",
+			url:     &models.URL{BaseURL: "https://example.com/page"},
+			path:    "extra",
+			wantErr: ErrURLNotFound,
+		},
+		{
+			name:       "Destination wins",
+			precedence: config.QueryPrecedenceDestination,
+			url:        &models.URL{BaseURL: "https://example.com/page?a=1", Passthrough: models.PassthroughQuery},
+			query:      neturl.Values{"a": {"2"}, "utm_source": {"mail"}},
+			want:       "https://example.com/page?a=1&utm_source=mail",
+		},
+		{
+			name:       "Request wins",
+			precedence: config.QueryPrecedenceRequest,
+			url:        &models.URL{BaseURL: "https://example.com/page?a=1", Passthrough: models.PassthroughQuery},
+			query:      neturl.Values{"a": {"2"}},
+			want:       "https://example.com/page?a=2",
+		},
+		{
+			name:  "Query is encoded",
+			url:   &models.URL{BaseURL: "https://example.com/", Passthrough: models.PassthroughAll},
+			query: neturl.Values{"q": {"a b&c"}},
+			want:  "https://example.com/?q=a+b%26c",
+		},
+		{
+			name: "Path is appended",
+			url:  &models.URL{BaseURL: "https://example.com/docs?v=1", Passthrough: models.PassthroughPath},
+			path: "guide/caf%C3%A9%20menu",
+			want: "https://example.com/docs/guide/caf%C3%A9%20menu?v=1",
+		},
+		{
+			name:  "Path and query",
+			url:   &models.URL{BaseURL: "https://example.com", Passthrough: models.PassthroughAll},
+			path:  "a/b",
+			query: neturl.Values{"x": {"1"}},
+			want:  "https://example.com/a/b?x=1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := *cfg
+			c.Redirect.QueryPrecedence = tt.precedence
+			uc := NewUrlUsecase(repository.NewMapRepository(map[string]*models.URL{}, l), &c, l)
+
+			got, err := uc.Destination(tt.url, tt.path, tt.query)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/MatiXxD/url-shortener/internal/url"
+	"github.com/MatiXxD/url-shortener/internal/workspace"
+	"github.com/MatiXxD/url-shortener/pkg/tokengen"
+)
+
+// SetWorkspaces makes usecase check workspace of added domains, nil accepts any workspace
+func (uu *UrlUsecase) SetWorkspaces(w url.Workspaces) {
+	uu.workspaces = w
+}
+
+// generator returns code generator of workspace in context, default one is used unless workspace sets code length
+func (uu *UrlUsecase) generator(ctx context.Context) tokengen.CodeGenerator {
+	ws := workspace.From(ctx)
+	if ws == nil || ws.Settings.CodeLength == 0 || ws.Settings.CodeLength == uu.cfg.ShortCode.Length {
+		return uu.codes
+	}
+
+	uu.genMu.Lock()
+	defer uu.genMu.Unlock()
+
+	length := ws.Settings.CodeLength
+	gen, ok := uu.generators[length]
+	if !ok {
+		cfg := uu.cfg.ShortCode
+		cfg.Length = length
+		gen = newCodeGenerator(cfg, uu.repo)
+		uu.generators[length] = gen
+	}
+	return gen
+}
+
+// linkDomain returns short domain for new link of workspace in context. Domains owned by other workspaces are unknown,
+// link without domain gets the first allowed one of workspace unless default domain is allowed too.
+func (uu *UrlUsecase) linkDomain(ctx context.Context, host string) (string, error) {
+	var allowed []string
+	if ws := workspace.From(ctx); ws != nil {
+		allowed = ws.Settings.Domains
+	}
+
+	host = normalizeHost(host)
+	if host == "" && len(allowed) != 0 && !slices.Contains(allowed, uu.domains.defaultHost) {
+		host = allowed[0]
+	}
+
+	d, err := uu.lookupDomain(ctx, host)
+	if err != nil {
+		return "", err
+	}
+
+	domain, name := "", uu.domains.defaultHost
+	if d != nil {
+		if d.Workspace != "" && d.Workspace != workspace.IDFrom(ctx) {
+			return "", fmt.Errorf("%w: %s", ErrUnknownDomain, d.Host)
+		}
+		domain, name = d.Host, d.Host
+	}
+	if len(allowed) != 0 && !slices.Contains(allowed, name) {
+		return "", fmt.Errorf("%w: %s", ErrDomainNotAllowed, name)
+	}
+
+	return domain, nil
+}
+
+// checkWorkspace rejects domain of workspace which doesn't exist
+func (uu *UrlUsecase) checkWorkspace(ctx context.Context, id string) error {
+	if id == "" || uu.workspaces == nil {
+		return nil
+	}
+
+	ok, err := uu.workspaces.HasWorkspace(ctx, id)
+	if err != nil {
+		uu.logger.Errorf("cannot check workspace=%s: %v", id, err)
+		return fmt.Errorf("cannot check workspace: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("%w: unknown workspace %s", ErrInvalidDomain, id)
+	}
+
+	return nil
+}
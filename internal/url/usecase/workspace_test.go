@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/url/repository"
+	"github.com/MatiXxD/url-shortener/internal/workspace"
+	"github.com/stretchr/testify/require"
+)
+
+type workspaceSet map[string]bool
+
+func (ws workspaceSet) HasWorkspace(ctx context.Context, id string) (bool, error) {
+	return ws[id], nil
+}
+
+func TestUsecase_Workspaces(t *testing.T) {
+	ctx := context.Background()
+	uc := NewUrlUsecase(repository.NewMapRepository(map[string]*models.URL{}, l), cfg, l)
+	uc.SetWorkspaces(workspaceSet{"a": true, "b": true})
+
+	ctxA := workspace.With(ctx, &models.Workspace{ID: "a", Settings: models.WorkspaceSettings{
+		CodeLength:   10,
+		RedirectCode: 301,
+		Domains:      []string{"go.a.com"},
+	}})
+	ctxB := workspace.With(ctx, &models.Workspace{ID: "b"})
+
+	_, err := uc.AddDomain(ctx, "https://go.c.com", "c")
+	require.ErrorIs(t, err, ErrInvalidDomain)
+	_, err = uc.AddDomain(ctx, "https://go.a.com", "a")
+	require.NoError(t, err)
+
+	// workspace links go to its domain with its code length and redirect code
+	shortA, err := uc.ReduceURL(ctxA, &models.UrlDTO{OriginURL: "https://example.com", UserID: "u1"})
+	require.NoError(t, err)
+	codeA, ok := strings.CutPrefix(shortA, "https://go.a.com/")
+	require.True(t, ok, shortA)
+	require.Len(t, codeA, 10)
+
+	u, err := uc.GetURL(ctx, "go.a.com", codeA)
+	require.NoError(t, err)
+	require.Equal(t, 301, uc.RedirectCode(u))
+	require.Equal(t, "a", u.Workspace)
+
+	again, err := uc.ReduceURL(ctxA, &models.UrlDTO{OriginURL: "https://example.com", UserID: "u1"})
+	require.NoError(t, err)
+	require.Equal(t, shortA, again)
+
+	// the same original is shortened separately in another workspace
+	shortB, err := uc.ReduceURL(ctxB, &models.UrlDTO{OriginURL: "https://example.com", UserID: "u1"})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(shortB, cfg.BaseURL+"/"), shortB)
+
+	t.Run("domains", func(t *testing.T) {
+		_, err := uc.ReduceURL(ctxB, &models.UrlDTO{OriginURL: "https://example.com/b", ShortDomain: "go.a.com"})
+		require.ErrorIs(t, err, ErrUnknownDomain)
+		_, err = uc.ReduceURL(ctxA, &models.UrlDTO{OriginURL: "https://example.com/a", ShortDomain: "localhost:8080"})
+		require.ErrorIs(t, err, ErrDomainNotAllowed)
+	})
+
+	t.Run("domains of another workspace are not listed", func(t *testing.T) {
+		domains, err := uc.ListDomains(ctxA)
+		require.NoError(t, err)
+		require.Len(t, domains, 1)
+		require.Equal(t, "go.a.com", domains[0].Host)
+		require.Empty(t, domains[0].Workspace)
+
+		domains, err = uc.ListDomains(ctxB)
+		require.NoError(t, err)
+		require.Empty(t, domains)
+	})
+
+	t.Run("links of another workspace are not found", func(t *testing.T) {
+		require.ErrorIs(t, uc.DeleteURL(ctxB, "go.a.com", codeA, "u1"), ErrURLNotFound)
+		_, err := uc.UpdateURL(ctxB, "go.a.com", codeA, "https://example.com/b", "u1")
+		require.ErrorIs(t, err, ErrURLNotFound)
+		_, err = uc.GetHistory(ctx, "go.a.com", codeA, "u1")
+		require.ErrorIs(t, err, ErrURLNotFound)
+
+		urls, _, err := uc.ListURLs(ctxB, &models.URLFilter{UserID: "u1"}, "")
+		require.NoError(t, err)
+		require.Len(t, urls, 1)
+		require.Equal(t, "b", urls[0].Workspace)
+	})
+
+	require.NoError(t, uc.DeleteURL(ctxA, "go.a.com", codeA, "u1"))
+}
@@ -0,0 +1,8 @@
+package url
+
+import "context"
+
+// Workspaces tells which workspaces exist, so domains are not given to unknown ones
+type Workspaces interface {
+	HasWorkspace(ctx context.Context, id string) (bool, error)
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+
+	mw "github.com/MatiXxD/url-shortener/internal/middleware"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/webhook"
+	"github.com/MatiXxD/url-shortener/internal/webhook/usecase"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/go-chi/chi/v5"
+	"github.com/mailru/easyjson"
+)
+
+type WebhookHandler struct {
+	webhookUsecase webhook.Usecase
+	logger         *logger.Logger
+}
+
+func NewWebhookHandler(u webhook.Usecase, l *logger.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		webhookUsecase: u,
+		logger:         l,
+	}
+}
+
+func (wh *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	logger := wh.logger
+	reqID := mw.GetRequestID(r.Context())
+	if reqID != "" {
+		logger = wh.logger.With("request_id", reqID)
+	}
+
+	webhooks, err := wh.webhookUsecase.ListWebhooks(r.Context())
+	if err != nil {
+		logger.Errorf("can't list webhooks: %v", err)
+		http.Error(w, "Can't list webhooks", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := easyjson.MarshalToWriter(models.WebhookList(webhooks), w); err != nil {
+		logger.Error("can't marshal response body")
+		http.Error(w, "Can't marshal response body", http.StatusInternalServerError)
+		return
+	}
+}
+
+// AddWebhook responds with created webhook, its secret is not shown again
+func (wh *WebhookHandler) AddWebhook(w http.ResponseWriter, r *http.Request) {
+	logger := wh.logger
+	reqID := mw.GetRequestID(r.Context())
+	if reqID != "" {
+		logger = wh.logger.With("request_id", reqID)
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if !strings.Contains(contentType, "application/json") {
+		logger.Error("request contains wrong content type")
+		http.Error(w, "Wrong content type", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	var req models.AddWebhookReqBody
+	err := easyjson.UnmarshalFromReader(r.Body, &req)
+	if isTooLarge(err) {
+		logger.Error("request body is too large")
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		logger.Errorf("can't unmarshal request body: %v", err)
+		http.Error(w, "Can't read body", http.StatusBadRequest)
+		return
+	}
+
+	hook, err := wh.webhookUsecase.AddWebhook(r.Context(), &req)
+	if errors.Is(err, usecase.ErrInvalidWebhook) {
+		logger.Errorf("invalid webhook: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		logger.Errorf("can't add webhook: %v", err)
+		http.Error(w, "Can't add webhook", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if _, err := easyjson.MarshalToWriter(hook, w); err != nil {
+		logger.Error("can't marshal response body")
+	}
+}
+
+func (wh *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	logger := wh.logger
+	reqID := mw.GetRequestID(r.Context())
+	if reqID != "" {
+		logger = wh.logger.With("request_id", reqID)
+	}
+
+	id := chi.URLParam(r, "id")
+	err := wh.webhookUsecase.DeleteWebhook(r.Context(), id)
+	if errors.Is(err, usecase.ErrWebhookNotFound) {
+		logger.Errorf("can't find webhook %s", id)
+		http.Error(w, "Can't find webhook", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		logger.Errorf("can't delete webhook %s: %v", id, err)
+		http.Error(w, "Can't delete webhook", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListDeliveries serves delivery log, newest deliveries first
+func (wh *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	logger := wh.logger
+	reqID := mw.GetRequestID(r.Context())
+	if reqID != "" {
+		logger = wh.logger.With("request_id", reqID)
+	}
+
+	query := r.URL.Query()
+	filter, err := parseDeliveryFilter(query)
+	if err != nil {
+		logger.Errorf("invalid filter: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	deliveries, next, err := wh.webhookUsecase.ListDeliveries(r.Context(), filter, query.Get("cursor"))
+	if errors.Is(err, usecase.ErrInvalidFilter) || errors.Is(err, usecase.ErrInvalidCursor) {
+		logger.Errorf("invalid filter: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		logger.Errorf("can't list deliveries: %v", err)
+		http.Error(w, "Can't list deliveries", http.StatusInternalServerError)
+		return
+	}
+
+	page := &models.DeliveryPage{
+		Items:      deliveries,
+		NextCursor: next,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := easyjson.MarshalToWriter(page, w); err != nil {
+		logger.Error("can't marshal response body")
+		http.Error(w, "Can't marshal response body", http.StatusInternalServerError)
+		return
+	}
+}
+
+// RetryDelivery queues delivery again, it is used to replay dead ones
+func (wh *WebhookHandler) RetryDelivery(w http.ResponseWriter, r *http.Request) {
+	logger := wh.logger
+	reqID := mw.GetRequestID(r.Context())
+	if reqID != "" {
+		logger = wh.logger.With("request_id", reqID)
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		logger.Errorf("invalid delivery id: %v", err)
+		http.Error(w, "Can't find delivery", http.StatusNotFound)
+		return
+	}
+
+	d, err := wh.webhookUsecase.RetryDelivery(r.Context(), id)
+	if errors.Is(err, usecase.ErrDeliveryNotFound) {
+		logger.Errorf("can't find delivery %d", id)
+		http.Error(w, "Can't find delivery", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		logger.Errorf("can't retry delivery %d: %v", id, err)
+		http.Error(w, "Can't retry delivery", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := easyjson.MarshalToWriter(d, w); err != nil {
+		logger.Error("can't marshal response body")
+	}
+}
+
+func parseDeliveryFilter(query neturl.Values) (*models.DeliveryFilter, error) {
+	filter := &models.DeliveryFilter{
+		WebhookID: query.Get("webhook_id"),
+		Status:    query.Get("status"),
+		EventType: query.Get("event"),
+	}
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("limit: not an integer")
+		}
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
+
+func isTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/config"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/webhook/repository"
+	"github.com/MatiXxD/url-shortener/internal/webhook/usecase"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func doRequest(t *testing.T, ts *httptest.Server, method, path, body string) (*http.Response, string) {
+	req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+	require.NoError(t, err)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := ts.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp, string(data)
+}
+
+func TestWebhookHandler(t *testing.T) {
+	zl, err := zap.NewDevelopment()
+	require.NoError(t, err)
+	l := &logger.Logger{SugaredLogger: zl.Sugar()}
+
+	r, err := repository.NewFileRepository("", l)
+	require.NoError(t, err)
+	u := usecase.NewWebhookUsecase(r, config.Default(), l)
+	h := NewWebhookHandler(u, l)
+
+	mux := chi.NewRouter()
+	mux.Get("/api/webhooks", h.ListWebhooks)
+	mux.Post("/api/webhooks", h.AddWebhook)
+	mux.Delete("/api/webhooks/{id}", h.DeleteWebhook)
+	mux.Get("/api/webhooks/deliveries", h.ListDeliveries)
+	mux.Post("/api/webhooks/deliveries/{id}/retry", h.RetryDelivery)
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	resp, body := doRequest(t, ts, http.MethodPost, "/api/webhooks", `{"url":"http://receiver.io/hook","events":["link.created","link.clicked"]}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var created models.Webhook
+	require.NoError(t, json.Unmarshal([]byte(body), &created))
+	require.NotEmpty(t, created.ID)
+	require.NotEmpty(t, created.Secret)
+	require.Equal(t, []string{models.EventLinkCreated, models.EventLinkClicked}, created.Events)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "invalid webhook",
+			method:   http.MethodPost,
+			path:     "/api/webhooks",
+			body:     `{"url":"http://receiver.io","events":["link.viewed"]}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: "unknown event",
+		},
+		{
+			name:     "list hides secret",
+			method:   http.MethodGet,
+			path:     "/api/webhooks",
+			wantCode: http.StatusOK,
+			wantBody: `"id":"` + created.ID + `"`,
+		},
+		{
+			name:     "deliveries",
+			method:   http.MethodGet,
+			path:     "/api/webhooks/deliveries?event=link.clicked&status=pending&limit=1",
+			wantCode: http.StatusOK,
+			wantBody: `"event_type":"link.clicked"`,
+		},
+		{
+			name:     "deliveries with invalid filter",
+			method:   http.MethodGet,
+			path:     "/api/webhooks/deliveries?status=lost",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "deliveries with invalid cursor",
+			method:   http.MethodGet,
+			path:     "/api/webhooks/deliveries?cursor=abc",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "retry",
+			method:   http.MethodPost,
+			path:     "/api/webhooks/deliveries/1/retry",
+			wantCode: http.StatusOK,
+			wantBody: `"status":"pending"`,
+		},
+		{
+			name:     "retry unknown delivery",
+			method:   http.MethodPost,
+			path:     "/api/webhooks/deliveries/100/retry",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "delete",
+			method:   http.MethodDelete,
+			path:     "/api/webhooks/" + created.ID,
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:     "delete unknown",
+			method:   http.MethodDelete,
+			path:     "/api/webhooks/" + created.ID,
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	event := &models.Event{ID: "e1", Type: models.EventLinkClicked, OccurredAt: time.Now(), Link: &models.EventLink{Code: "abc"}}
+	require.NoError(t, u.Publish(context.Background(), event))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := doRequest(t, ts, tt.method, tt.path, tt.body)
+			require.Equal(t, tt.wantCode, resp.StatusCode, body)
+			require.Contains(t, body, tt.wantBody)
+			require.NotContains(t, body, created.Secret)
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+)
+
+var ErrNotFound = errors.New("webhook was not found")
+
+// Repository keeps subscriptions and their delivery queue, deliveries of removed webhook are removed too
+type Repository interface {
+	AddWebhook(context.Context, *models.Webhook) error
+	// ListWebhooks returns webhooks with secrets
+	ListWebhooks(context.Context) ([]*models.Webhook, error)
+	DeleteWebhook(ctx context.Context, id string) error
+
+	// AddDeliveries enqueues deliveries and sets their ids. Event is queued for webhook once,
+	// repeated deliveries of it are skipped and keep zero id.
+	AddDeliveries(context.Context, []*models.Delivery) error
+	// ClaimDeliveries returns up to limit pending deliveries due at now and postpones them by lease,
+	// so other workers skip them while they are sent
+	ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*models.Delivery, error)
+	// UpdateDelivery saves status, attempts, result and next attempt time of delivery
+	UpdateDelivery(context.Context, *models.Delivery) error
+	GetDelivery(ctx context.Context, id int64) (*models.Delivery, error)
+	ListDeliveries(context.Context, *models.DeliveryFilter) ([]*models.Delivery, error)
+	// PurgeDeliveries removes delivered and dead deliveries updated before and returns their number
+	PurgeDeliveries(ctx context.Context, before time.Time) (int, error)
+}
@@ -0,0 +1,447 @@
+package repository
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/webhook"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+)
+
+const (
+	// webhooksSuffix is appended to storage filename for subscriptions
+	webhooksSuffix = ".webhooks"
+	// deliveriesSuffix is appended to storage filename for delivery journal
+	deliveriesSuffix = ".deliveries"
+)
+
+// FileRepository keeps webhooks in memory, they are saved next to storage file unless filename is empty.
+// Webhooks are rewritten as a whole, delivery changes are appended to journal where the last line of
+// delivery wins.
+type FileRepository struct {
+	filename   string
+	webhooks   map[string]*models.Webhook
+	deliveries map[int64]*models.Delivery
+	// queued are events of webhooks with deliveries, keyed by eventKey
+	queued map[string]bool
+	seq    int64
+	// lines is number of lines in journal, it is compacted on purge when it has stale lines
+	lines  int
+	logger *logger.Logger
+	mu     sync.Mutex
+}
+
+func NewFileRepository(filename string, logger *logger.Logger) (*FileRepository, error) {
+	fr := &FileRepository{
+		filename:   filename,
+		webhooks:   make(map[string]*models.Webhook),
+		deliveries: make(map[int64]*models.Delivery),
+		queued:     make(map[string]bool),
+		logger:     logger,
+	}
+	if filename == "" {
+		return fr, nil
+	}
+
+	if err := fr.initWebhooks(); err != nil {
+		logger.Errorf("failed to init webhooks %v: %v", filename, err)
+		return nil, fmt.Errorf("failed to init webhooks: %w", err)
+	}
+
+	if err := fr.initDeliveries(); err != nil {
+		logger.Errorf("failed to init webhook deliveries %v: %v", filename, err)
+		return nil, fmt.Errorf("failed to init webhook deliveries: %w", err)
+	}
+
+	return fr, nil
+}
+
+func (fr *FileRepository) AddWebhook(ctx context.Context, w *models.Webhook) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	stored := *w
+	fr.webhooks[w.ID] = &stored
+
+	if err := fr.saveWebhooks(); err != nil {
+		delete(fr.webhooks, w.ID)
+		fr.logger.Errorf("failed to save webhooks: %v", err)
+		return fmt.Errorf("failed to save webhooks: %w", err)
+	}
+
+	return nil
+}
+
+func (fr *FileRepository) ListWebhooks(ctx context.Context) ([]*models.Webhook, error) {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	return listWebhooks(fr.webhooks), nil
+}
+
+// DeleteWebhook removes webhook with its deliveries, journal lines of them are skipped on load
+func (fr *FileRepository) DeleteWebhook(ctx context.Context, id string) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	w, ok := fr.webhooks[id]
+	if !ok {
+		return webhook.ErrNotFound
+	}
+	delete(fr.webhooks, id)
+
+	if err := fr.saveWebhooks(); err != nil {
+		fr.webhooks[id] = w
+		fr.logger.Errorf("failed to save webhooks: %v", err)
+		return fmt.Errorf("failed to save webhooks: %w", err)
+	}
+
+	for did, d := range fr.deliveries {
+		if d.WebhookID == id {
+			delete(fr.deliveries, did)
+			delete(fr.queued, eventKey(d))
+		}
+	}
+
+	return nil
+}
+
+// AddDeliveries appends deliveries to journal with one write
+func (fr *FileRepository) AddDeliveries(ctx context.Context, deliveries []*models.Delivery) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	added := make([]*models.Delivery, 0, len(deliveries))
+	origins := make([]*models.Delivery, 0, len(deliveries))
+	seen := make(map[string]bool)
+	for i, d := range deliveries {
+		if _, ok := fr.webhooks[d.WebhookID]; !ok {
+			return fmt.Errorf("delivery %d: %w", i, webhook.ErrNotFound)
+		}
+		if fr.queued[eventKey(d)] || seen[eventKey(d)] {
+			continue
+		}
+		seen[eventKey(d)] = true
+
+		stored := *d
+		stored.ID = fr.seq + int64(len(added)) + 1
+		added = append(added, &stored)
+		origins = append(origins, d)
+	}
+
+	if err := fr.saveDeliveries(added...); err != nil {
+		fr.logger.Errorf("failed to save webhook deliveries: %v", err)
+		return fmt.Errorf("failed to save deliveries: %w", err)
+	}
+
+	fr.lines += len(added)
+	for i, d := range added {
+		fr.deliveries[d.ID] = d
+		fr.queued[eventKey(d)] = true
+		origins[i].ID = d.ID
+	}
+	fr.seq += int64(len(added))
+
+	return nil
+}
+
+// ClaimDeliveries postpones claimed deliveries in memory only, there are no other workers of the file
+func (fr *FileRepository) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*models.Delivery, error) {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	due := make([]*models.Delivery, 0)
+	for _, d := range fr.deliveries {
+		if d.Status == models.DeliveryPending && !d.NextAttemptAt.After(now) {
+			due = append(due, d)
+		}
+	}
+	slices.SortFunc(due, cmpDue)
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	res := make([]*models.Delivery, 0, len(due))
+	for _, d := range due {
+		d.NextAttemptAt = now.Add(lease)
+		copied := *d
+		res = append(res, &copied)
+	}
+
+	return res, nil
+}
+
+func (fr *FileRepository) UpdateDelivery(ctx context.Context, d *models.Delivery) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	if _, ok := fr.deliveries[d.ID]; !ok {
+		return webhook.ErrNotFound
+	}
+
+	stored := *d
+	if err := fr.saveDeliveries(&stored); err != nil {
+		fr.logger.Errorf("failed to save webhook delivery %d: %v", d.ID, err)
+		return fmt.Errorf("failed to save delivery: %w", err)
+	}
+	fr.deliveries[d.ID] = &stored
+	fr.lines++
+
+	return nil
+}
+
+// PurgeDeliveries forgets finished deliveries and rewrites journal without them. The last delivery is kept,
+// so its id is not given again after restart.
+func (fr *FileRepository) PurgeDeliveries(ctx context.Context, before time.Time) (int, error) {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	var purged []*models.Delivery
+	for id, d := range fr.deliveries {
+		if id != fr.seq && d.Status != models.DeliveryPending && d.UpdatedAt.Before(before) {
+			purged = append(purged, d)
+		}
+	}
+	if len(purged) == 0 && fr.lines == len(fr.deliveries) {
+		return 0, nil
+	}
+
+	for _, d := range purged {
+		delete(fr.deliveries, d.ID)
+	}
+	if err := fr.compactDeliveries(); err != nil {
+		for _, d := range purged {
+			fr.deliveries[d.ID] = d
+		}
+		fr.logger.Errorf("failed to compact webhook deliveries: %v", err)
+		return 0, fmt.Errorf("failed to purge deliveries: %w", err)
+	}
+	for _, d := range purged {
+		delete(fr.queued, eventKey(d))
+	}
+
+	return len(purged), nil
+}
+
+func (fr *FileRepository) GetDelivery(ctx context.Context, id int64) (*models.Delivery, error) {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	d, ok := fr.deliveries[id]
+	if !ok {
+		return nil, webhook.ErrNotFound
+	}
+	copied := *d
+	return &copied, nil
+}
+
+func (fr *FileRepository) ListDeliveries(ctx context.Context, filter *models.DeliveryFilter) ([]*models.Delivery, error) {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	res := make([]*models.Delivery, 0)
+	for _, d := range fr.deliveries {
+		if matchDelivery(d, filter) {
+			copied := *d
+			res = append(res, &copied)
+		}
+	}
+	slices.SortFunc(res, func(a, b *models.Delivery) int {
+		return cmp.Compare(b.ID, a.ID)
+	})
+	if filter.Limit > 0 && len(res) > filter.Limit {
+		res = res[:filter.Limit]
+	}
+
+	return res, nil
+}
+
+func (fr *FileRepository) initWebhooks() error {
+	data, err := os.ReadFile(fr.filename + webhooksSuffix)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var webhooks []*models.Webhook
+	if err := json.Unmarshal(data, &webhooks); err != nil {
+		return fmt.Errorf("malformed webhooks: %w", err)
+	}
+	for _, w := range webhooks {
+		fr.webhooks[w.ID] = w
+	}
+
+	return nil
+}
+
+// initDeliveries replays journal and compacts it when it has stale lines
+func (fr *FileRepository) initDeliveries() error {
+	file, err := os.Open(fr.filename + deliveriesSuffix)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	// payloads may exceed default token size
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var d models.Delivery
+		if err := json.Unmarshal(scanner.Bytes(), &d); err != nil {
+			return fmt.Errorf("malformed delivery: %w", err)
+		}
+		fr.lines++
+		fr.seq = max(fr.seq, d.ID)
+		if _, ok := fr.webhooks[d.WebhookID]; ok {
+			fr.deliveries[d.ID] = &d
+			fr.queued[eventKey(&d)] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if fr.lines == len(fr.deliveries) {
+		return nil
+	}
+	return fr.compactDeliveries()
+}
+
+// compactDeliveries rewrites journal with one line per delivery
+func (fr *FileRepository) compactDeliveries() error {
+	if fr.filename != "" {
+		data, err := marshalDeliveries(sortedDeliveries(fr.deliveries)...)
+		if err != nil {
+			return err
+		}
+		if err := replaceFile(fr.filename+deliveriesSuffix, data); err != nil {
+			return err
+		}
+	}
+
+	fr.lines = len(fr.deliveries)
+	return nil
+}
+
+// saveWebhooks rewrites all webhooks, there are few of them and they change rarely
+func (fr *FileRepository) saveWebhooks() error {
+	if fr.filename == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(listWebhooks(fr.webhooks))
+	if err != nil {
+		return err
+	}
+	return replaceFile(fr.filename+webhooksSuffix, data)
+}
+
+// saveDeliveries appends deliveries to journal with one write
+func (fr *FileRepository) saveDeliveries(deliveries ...*models.Delivery) error {
+	if fr.filename == "" || len(deliveries) == 0 {
+		return nil
+	}
+
+	data, err := marshalDeliveries(deliveries...)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(fr.filename+deliveriesSuffix, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(data)
+	return err
+}
+
+func marshalDeliveries(deliveries ...*models.Delivery) ([]byte, error) {
+	var data []byte
+	for _, d := range deliveries {
+		line, err := json.Marshal(d)
+		if err != nil {
+			return nil, err
+		}
+		data = append(append(data, line...), '\n')
+	}
+	return data, nil
+}
+
+func replaceFile(name string, data []byte) error {
+	tmp := name + ".tmp"
+	if err := os.WriteFile(tmp, data, 0666); err != nil {
+		return err
+	}
+	return os.Rename(tmp, name)
+}
+
+// listWebhooks returns copies of webhooks, the oldest first
+func listWebhooks(webhooks map[string]*models.Webhook) []*models.Webhook {
+	res := make([]*models.Webhook, 0, len(webhooks))
+	for _, w := range webhooks {
+		copied := *w
+		res = append(res, &copied)
+	}
+	slices.SortFunc(res, func(a, b *models.Webhook) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return res
+}
+
+func sortedDeliveries(deliveries map[int64]*models.Delivery) []*models.Delivery {
+	res := make([]*models.Delivery, 0, len(deliveries))
+	for _, d := range deliveries {
+		res = append(res, d)
+	}
+	slices.SortFunc(res, func(a, b *models.Delivery) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return res
+}
+
+func matchDelivery(d *models.Delivery, filter *models.DeliveryFilter) bool {
+	switch {
+	case filter.WebhookID != "" && d.WebhookID != filter.WebhookID:
+		return false
+	case filter.Status != "" && d.Status != filter.Status:
+		return false
+	case filter.EventType != "" && d.EventType != filter.EventType:
+		return false
+	case filter.Before != 0 && d.ID >= filter.Before:
+		return false
+	}
+	return true
+}
+
+// eventKey identifies event of webhook, it is delivered once
+func eventKey(d *models.Delivery) string {
+	return d.WebhookID + "\x00" + d.EventID
+}
+
+// cmpDue orders deliveries by time of next attempt, then by id
+func cmpDue(a, b *models.Delivery) int {
+	if c := a.NextAttemptAt.Compare(b.NextAttemptAt); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
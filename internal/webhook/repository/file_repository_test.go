@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/webhook"
+	"github.com/stretchr/testify/require"
+)
+
+func newDelivery(webhookID, eventID string, at time.Time) *models.Delivery {
+	return &models.Delivery{
+		WebhookID:     webhookID,
+		EventID:       eventID,
+		EventType:     models.EventLinkCreated,
+		Payload:       []byte(`{"id":"` + eventID + `"}`),
+		Status:        models.DeliveryPending,
+		CreatedAt:     at,
+		UpdatedAt:     at,
+		NextAttemptAt: at,
+	}
+}
+
+func TestFileRepository_Webhooks(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "db.json")
+	now := time.Now().UTC().Truncate(time.Second)
+
+	fr, err := NewFileRepository(filename, l)
+	require.NoError(t, err)
+
+	for i, id := range []string{"b", "a"} {
+		err := fr.AddWebhook(ctx, &models.Webhook{
+			ID:        id,
+			URL:       "http://receiver.io/" + id,
+			Events:    []string{models.EventLinkCreated},
+			Secret:    "0123456789abcdef",
+			CreatedAt: now.Add(time.Duration(i) * time.Second),
+		})
+		require.NoError(t, err)
+	}
+
+	deliveries := []*models.Delivery{newDelivery("a", "e1", now), newDelivery("b", "e1", now)}
+	require.NoError(t, fr.AddDeliveries(ctx, deliveries))
+	require.Equal(t, int64(1), deliveries[0].ID)
+	require.Equal(t, int64(2), deliveries[1].ID)
+
+	err = fr.AddDeliveries(ctx, []*models.Delivery{newDelivery("missing", "e1", now)})
+	require.ErrorIs(t, err, webhook.ErrNotFound)
+
+	require.NoError(t, fr.DeleteWebhook(ctx, "a"))
+	require.ErrorIs(t, fr.DeleteWebhook(ctx, "a"), webhook.ErrNotFound)
+
+	reopened, err := NewFileRepository(filename, l)
+	require.NoError(t, err)
+
+	webhooks, err := reopened.ListWebhooks(ctx)
+	require.NoError(t, err)
+	require.Len(t, webhooks, 1)
+	require.Equal(t, "b", webhooks[0].ID)
+	require.Equal(t, "0123456789abcdef", webhooks[0].Secret)
+
+	// deliveries of removed webhook are gone and journal is compacted
+	got, err := reopened.ListDeliveries(ctx, &models.DeliveryFilter{})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	require.Equal(t, "b", got[0].WebhookID)
+	require.JSONEq(t, `{"id":"e1"}`, string(got[0].Payload))
+
+	data, err := os.ReadFile(filename + deliveriesSuffix)
+	require.NoError(t, err)
+	require.Equal(t, 1, bytes.Count(data, []byte("\n")))
+
+	// ids are not reused
+	next := newDelivery("b", "e2", now)
+	require.NoError(t, reopened.AddDeliveries(ctx, []*models.Delivery{next}))
+	require.Equal(t, int64(3), next.ID)
+}
+
+func TestFileRepository_Deliveries(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "db.json")
+	now := time.Now().UTC().Truncate(time.Second)
+
+	fr, err := NewFileRepository(filename, l)
+	require.NoError(t, err)
+	require.NoError(t, fr.AddWebhook(ctx, &models.Webhook{ID: "w", URL: "http://receiver.io", Events: models.EventTypes}))
+
+	deliveries := []*models.Delivery{
+		newDelivery("w", "late", now.Add(time.Minute)),
+		newDelivery("w", "second", now.Add(-time.Second)),
+		newDelivery("w", "first", now.Add(-time.Minute)),
+	}
+	require.NoError(t, fr.AddDeliveries(ctx, deliveries))
+
+	t.Run("claims due deliveries in order", func(t *testing.T) {
+		claimed, err := fr.ClaimDeliveries(ctx, now, time.Minute, 1)
+		require.NoError(t, err)
+		require.Len(t, claimed, 1)
+		require.Equal(t, "first", claimed[0].EventID)
+
+		claimed, err = fr.ClaimDeliveries(ctx, now, time.Minute, 10)
+		require.NoError(t, err)
+		require.Len(t, claimed, 1)
+		require.Equal(t, "second", claimed[0].EventID)
+
+		// claimed ones are leased, late one is not due yet
+		claimed, err = fr.ClaimDeliveries(ctx, now, time.Minute, 10)
+		require.NoError(t, err)
+		require.Empty(t, claimed)
+	})
+
+	t.Run("update is persisted", func(t *testing.T) {
+		d, err := fr.GetDelivery(ctx, deliveries[2].ID)
+		require.NoError(t, err)
+
+		d.Status, d.Attempts, d.ResponseCode = models.DeliveryDelivered, 1, 200
+		require.NoError(t, fr.UpdateDelivery(ctx, d))
+
+		reopened, err := NewFileRepository(filename, l)
+		require.NoError(t, err)
+
+		got, err := reopened.GetDelivery(ctx, d.ID)
+		require.NoError(t, err)
+		require.Equal(t, models.DeliveryDelivered, got.Status)
+		require.Equal(t, 1, got.Attempts)
+
+		_, err = reopened.GetDelivery(ctx, 100)
+		require.ErrorIs(t, err, webhook.ErrNotFound)
+	})
+
+	t.Run("list filters", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			filter *models.DeliveryFilter
+			want   []string
+		}{
+			{name: "all newest first", filter: &models.DeliveryFilter{}, want: []string{"first", "second", "late"}},
+			{name: "status", filter: &models.DeliveryFilter{Status: models.DeliveryPending}, want: []string{"second", "late"}},
+			{name: "before", filter: &models.DeliveryFilter{Before: deliveries[2].ID}, want: []string{"second", "late"}},
+			{name: "limit", filter: &models.DeliveryFilter{Limit: 1}, want: []string{"first"}},
+			{name: "other webhook", filter: &models.DeliveryFilter{WebhookID: "x"}, want: []string{}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := fr.ListDeliveries(ctx, tt.filter)
+				require.NoError(t, err)
+
+				ids := make([]string, 0, len(got))
+				for _, d := range got {
+					ids = append(ids, d.EventID)
+				}
+				require.Equal(t, tt.want, ids)
+			})
+		}
+	})
+}
+
+func TestFileRepository_QueuedOnce(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "db.json")
+	now := time.Now().UTC()
+
+	fr, err := NewFileRepository(filename, l)
+	require.NoError(t, err)
+	require.NoError(t, fr.AddWebhook(ctx, &models.Webhook{ID: "w", URL: "http://receiver.io", Events: models.EventTypes}))
+	require.NoError(t, fr.AddDeliveries(ctx, []*models.Delivery{newDelivery("w", "first", now)}))
+
+	again := []*models.Delivery{newDelivery("w", "first", now), newDelivery("w", "second", now), newDelivery("w", "second", now)}
+	require.NoError(t, fr.AddDeliveries(ctx, again))
+	require.Zero(t, again[0].ID)
+	require.NotZero(t, again[1].ID)
+	require.Zero(t, again[2].ID)
+
+	// queued events are known after restart
+	fr, err = NewFileRepository(filename, l)
+	require.NoError(t, err)
+	require.NoError(t, fr.AddDeliveries(ctx, []*models.Delivery{newDelivery("w", "second", now)}))
+
+	got, err := fr.ListDeliveries(ctx, &models.DeliveryFilter{})
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+}
+
+func TestFileRepository_PurgeDeliveries(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "db.json")
+	now := time.Now().UTC().Truncate(time.Second)
+
+	fr, err := NewFileRepository(filename, l)
+	require.NoError(t, err)
+	require.NoError(t, fr.AddWebhook(ctx, &models.Webhook{ID: "w", URL: "http://receiver.io", Events: models.EventTypes}))
+
+	deliveries := []*models.Delivery{
+		newDelivery("w", "delivered", now.Add(-time.Hour)),
+		newDelivery("w", "dead", now.Add(-time.Hour)),
+		newDelivery("w", "pending", now.Add(-time.Hour)),
+		newDelivery("w", "fresh", now),
+		newDelivery("w", "last", now.Add(-time.Hour)),
+	}
+	require.NoError(t, fr.AddDeliveries(ctx, deliveries))
+
+	statuses := []string{models.DeliveryDelivered, models.DeliveryDead, models.DeliveryPending, models.DeliveryDelivered, models.DeliveryDelivered}
+	for i, d := range deliveries {
+		d.Status = statuses[i]
+		require.NoError(t, fr.UpdateDelivery(ctx, d))
+	}
+
+	// the last delivery keeps sequence of ids
+	n, err := fr.PurgeDeliveries(ctx, now.Add(-time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	data, err := os.ReadFile(filename + deliveriesSuffix)
+	require.NoError(t, err)
+	require.Equal(t, 3, bytes.Count(data, []byte("\n")))
+
+	// purged event can be queued again, ids go on after restart
+	fr, err = NewFileRepository(filename, l)
+	require.NoError(t, err)
+	again := []*models.Delivery{newDelivery("w", "delivered", now)}
+	require.NoError(t, fr.AddDeliveries(ctx, again))
+	require.Equal(t, deliveries[4].ID+1, again[0].ID)
+
+	got, err := fr.ListDeliveries(ctx, &models.DeliveryFilter{})
+	require.NoError(t, err)
+	ids := make([]string, 0, len(got))
+	for _, d := range got {
+		ids = append(ids, d.EventID)
+	}
+	require.Equal(t, []string{"delivered", "last", "fresh", "pending"}, ids)
+}
+
+func TestFileRepository_InMemory(t *testing.T) {
+	ctx := context.Background()
+
+	fr, err := NewFileRepository("", l)
+	require.NoError(t, err)
+	require.NoError(t, fr.AddWebhook(ctx, &models.Webhook{ID: "w", URL: "http://receiver.io", Events: models.EventTypes}))
+	require.NoError(t, fr.AddDeliveries(ctx, []*models.Delivery{newDelivery("w", "e", time.Now())}))
+
+	got, err := fr.ListDeliveries(ctx, &models.DeliveryFilter{})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+}
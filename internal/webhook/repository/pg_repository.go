@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/webhook"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/MatiXxD/url-shortener/pkg/postgres"
+	"github.com/jackc/pgx/v5"
+)
+
+// deliveryColumns are selected by scanDelivery
+const deliveryColumns = `id, webhook_id, event_id, event_type, payload, status, attempts, response_code, last_error,
+	created_at, updated_at, next_attempt_at`
+
+// PostgresRepository keeps delivery queue in primary, claimed rows are locked with SKIP LOCKED,
+// so any number of instances can send deliveries
+type PostgresRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewPostgresRepository(db *postgres.DB, logger *logger.Logger) *PostgresRepository {
+	return &PostgresRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (pr *PostgresRepository) AddWebhook(ctx context.Context, w *models.Webhook) error {
+	_, err := pr.db.Pool.Exec(ctx, `INSERT INTO webhook (id, url, events, secret, created_at) VALUES ($1, $2, $3, $4, $5)`,
+		w.ID, w.URL, w.Events, w.Secret, w.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to add webhook: %w", err)
+	}
+
+	return nil
+}
+
+func (pr *PostgresRepository) ListWebhooks(ctx context.Context) ([]*models.Webhook, error) {
+	rows, err := pr.db.Pool.Query(ctx, `SELECT id, url, events, secret, created_at FROM webhook ORDER BY created_at, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	res := make([]*models.Webhook, 0)
+	for rows.Next() {
+		var w models.Webhook
+		if err := rows.Scan(&w.ID, &w.URL, &w.Events, &w.Secret, &w.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		res = append(res, &w)
+	}
+
+	return res, rows.Err()
+}
+
+// DeleteWebhook removes webhook, its deliveries are removed by cascade
+func (pr *PostgresRepository) DeleteWebhook(ctx context.Context, id string) error {
+	tag, err := pr.db.Pool.Exec(ctx, `DELETE FROM webhook WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return webhook.ErrNotFound
+	}
+
+	return nil
+}
+
+// AddDeliveries inserts deliveries in one transaction
+func (pr *PostgresRepository) AddDeliveries(ctx context.Context, deliveries []*models.Delivery) error {
+	tx, err := pr.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to save deliveries: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO webhook_delivery (webhook_id, event_id, event_type, payload, status, created_at, updated_at, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6, $7)
+		ON CONFLICT (webhook_id, event_id) DO NOTHING
+		RETURNING id
+	`
+
+	batch := &pgx.Batch{}
+	for _, d := range deliveries {
+		batch.Queue(query, d.WebhookID, d.EventID, d.EventType, []byte(d.Payload), d.Status, d.CreatedAt, d.NextAttemptAt)
+	}
+
+	br := tx.SendBatch(ctx, batch)
+	defer br.Close()
+
+	for _, d := range deliveries {
+		err := br.QueryRow().Scan(&d.ID)
+		// event is already queued for webhook
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save delivery of webhook=%s: %w", d.WebhookID, err)
+		}
+	}
+
+	if err := br.Close(); err != nil {
+		return fmt.Errorf("failed to save deliveries: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to save deliveries: %w", err)
+	}
+
+	return nil
+}
+
+func (pr *PostgresRepository) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*models.Delivery, error) {
+	rows, err := pr.db.Pool.Query(ctx, `
+		UPDATE webhook_delivery SET next_attempt_at = $2
+		WHERE id IN (
+			SELECT id FROM webhook_delivery
+			WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at, id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+deliveryColumns, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim deliveries: %w", err)
+	}
+
+	return scanDeliveries(rows)
+}
+
+func (pr *PostgresRepository) UpdateDelivery(ctx context.Context, d *models.Delivery) error {
+	tag, err := pr.db.Pool.Exec(ctx, `
+		UPDATE webhook_delivery
+		SET status = $2, attempts = $3, response_code = $4, last_error = $5, updated_at = $6, next_attempt_at = $7
+		WHERE id = $1
+	`, d.ID, d.Status, d.Attempts, d.ResponseCode, d.LastError, d.UpdatedAt, d.NextAttemptAt)
+	if err != nil {
+		return fmt.Errorf("failed to update delivery: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return webhook.ErrNotFound
+	}
+
+	return nil
+}
+
+func (pr *PostgresRepository) PurgeDeliveries(ctx context.Context, before time.Time) (int, error) {
+	tag, err := pr.db.Pool.Exec(ctx, `
+		DELETE FROM webhook_delivery
+		WHERE status IN ($1, $2) AND updated_at < $3
+	`, models.DeliveryDelivered, models.DeliveryDead, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deliveries: %w", err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+func (pr *PostgresRepository) GetDelivery(ctx context.Context, id int64) (*models.Delivery, error) {
+	rows, err := pr.db.Pool.Query(ctx, `SELECT `+deliveryColumns+` FROM webhook_delivery WHERE id = $1`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get delivery: %w", err)
+	}
+
+	res, err := scanDeliveries(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, webhook.ErrNotFound
+	}
+
+	return res[0], nil
+}
+
+func (pr *PostgresRepository) ListDeliveries(ctx context.Context, filter *models.DeliveryFilter) ([]*models.Delivery, error) {
+	var conds []string
+	var args []any
+	where := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, strings.ReplaceAll(cond, "?", "$"+strconv.Itoa(len(args))))
+	}
+
+	if filter.WebhookID != "" {
+		where("webhook_id = ?", filter.WebhookID)
+	}
+	if filter.Status != "" {
+		where("status = ?", filter.Status)
+	}
+	if filter.EventType != "" {
+		where("event_type = ?", filter.EventType)
+	}
+	if filter.Before != 0 {
+		where("id < ?", filter.Before)
+	}
+
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_delivery`
+	if len(conds) != 0 {
+		query += ` WHERE ` + strings.Join(conds, " AND ")
+	}
+	query += ` ORDER BY id DESC`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += ` LIMIT $` + strconv.Itoa(len(args))
+	}
+
+	rows, err := pr.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deliveries: %w", err)
+	}
+
+	return scanDeliveries(rows)
+}
+
+func scanDeliveries(rows pgx.Rows) ([]*models.Delivery, error) {
+	defer rows.Close()
+
+	res := make([]*models.Delivery, 0)
+	for rows.Next() {
+		var d models.Delivery
+		var payload []byte
+		err := rows.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &payload, &d.Status, &d.Attempts,
+			&d.ResponseCode, &d.LastError, &d.CreatedAt, &d.UpdatedAt, &d.NextAttemptAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan delivery: %w", err)
+		}
+		d.Payload = payload
+		res = append(res, &d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read deliveries: %w", err)
+	}
+
+	return res, nil
+}
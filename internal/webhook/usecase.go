@@ -0,0 +1,19 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+)
+
+type Usecase interface {
+	// AddWebhook subscribes url to events, the returned webhook has its secret
+	AddWebhook(context.Context, *models.AddWebhookReqBody) (*models.Webhook, error)
+	// ListWebhooks returns webhooks without secrets
+	ListWebhooks(context.Context) ([]*models.Webhook, error)
+	DeleteWebhook(ctx context.Context, id string) error
+	// ListDeliveries returns page of delivery log after cursor and cursor of the next page
+	ListDeliveries(context.Context, *models.DeliveryFilter, string) ([]*models.Delivery, string, error)
+	// RetryDelivery sends delivery again as soon as possible, dead deliveries get all attempts back
+	RetryDelivery(ctx context.Context, id int64) (*models.Delivery, error)
+}
@@ -0,0 +1,13 @@
+package usecase
+
+import (
+	"errors"
+)
+
+var (
+	ErrInvalidWebhook   = errors.New("invalid webhook")
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	ErrDeliveryNotFound = errors.New("delivery not found")
+	ErrInvalidFilter    = errors.New("invalid filter")
+	ErrInvalidCursor    = errors.New("invalid cursor")
+)
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/webhook"
+)
+
+// subscriptionsTTL is how long webhooks read from storage are trusted, other instances may change them
+const subscriptionsTTL = time.Minute
+
+// subscriptions caches webhooks, events are published on every click and must not hit storage
+type subscriptions struct {
+	mu       sync.RWMutex
+	webhooks map[string]*models.Webhook
+	loadedAt time.Time
+	now      func() time.Time
+}
+
+func newSubscriptions() *subscriptions {
+	return &subscriptions{
+		webhooks: make(map[string]*models.Webhook),
+		now:      time.Now,
+	}
+}
+
+// subscribers returns webhooks subscribed to event type
+func (s *subscriptions) subscribers(ctx context.Context, repo webhook.Repository, eventType string) ([]*models.Webhook, error) {
+	if err := s.refresh(ctx, repo); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var res []*models.Webhook
+	for _, w := range s.webhooks {
+		if slices.Contains(w.Events, eventType) {
+			res = append(res, w)
+		}
+	}
+	return res, nil
+}
+
+// get returns webhook by id, missing webhook is looked up in storage once more as it may be just added
+func (s *subscriptions) get(ctx context.Context, repo webhook.Repository, id string) (*models.Webhook, error) {
+	if err := s.refresh(ctx, repo); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	w, ok := s.webhooks[id]
+	s.mu.RUnlock()
+	if ok {
+		return w, nil
+	}
+
+	if err := s.reload(ctx, repo); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.webhooks[id], nil
+}
+
+// refresh reloads webhooks when they are stale
+func (s *subscriptions) refresh(ctx context.Context, repo webhook.Repository) error {
+	s.mu.RLock()
+	fresh := s.now().Sub(s.loadedAt) < subscriptionsTTL
+	s.mu.RUnlock()
+	if fresh {
+		return nil
+	}
+
+	return s.reload(ctx, repo)
+}
+
+func (s *subscriptions) reload(ctx context.Context, repo webhook.Repository) error {
+	list, err := repo.ListWebhooks(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot load webhooks: %w", err)
+	}
+
+	webhooks := make(map[string]*models.Webhook, len(list))
+	for _, w := range list {
+		webhooks[w.ID] = w
+	}
+
+	s.mu.Lock()
+	s.webhooks, s.loadedAt = webhooks, s.now()
+	s.mu.Unlock()
+
+	return nil
+}
+
+// invalidate makes next lookup reload webhooks from storage
+func (s *subscriptions) invalidate() {
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
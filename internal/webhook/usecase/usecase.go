@@ -0,0 +1,289 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/config"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/webhook"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/mailru/easyjson"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 1000
+
+	// secretSize is length of generated secret in bytes
+	secretSize = 32
+	// minSecretLength is shortest secret accepted from client
+	minSecretLength = 16
+)
+
+type WebhookUsecase struct {
+	repo   webhook.Repository
+	cfg    config.WebhookConfig
+	logger *logger.Logger
+	subs   *subscriptions
+	client *http.Client
+	now    func() time.Time
+	// wake tells worker that deliveries were queued
+	wake chan struct{}
+	// purgedAt is time of the last purge, it is used by worker only
+	purgedAt time.Time
+}
+
+func NewWebhookUsecase(r webhook.Repository, cfg *config.ServiceConfig, l *logger.Logger) *WebhookUsecase {
+	return &WebhookUsecase{
+		repo:   r,
+		cfg:    cfg.Webhooks,
+		logger: l,
+		subs:   newSubscriptions(),
+		client: &http.Client{
+			Timeout: cfg.Webhooks.Timeout.Std(),
+			// redirect is a failed delivery, receiver must be configured with its final url
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		now:  time.Now,
+		wake: make(chan struct{}, 1),
+	}
+}
+
+func (wu *WebhookUsecase) AddWebhook(ctx context.Context, req *models.AddWebhookReqBody) (*models.Webhook, error) {
+	if err := validateURL(req.URL); err != nil {
+		return nil, err
+	}
+
+	events, err := normalizeEvents(req.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		if secret, err = newSecret(); err != nil {
+			return nil, err
+		}
+	}
+	if len(secret) < minSecretLength {
+		return nil, fmt.Errorf("%w: secret must be at least %d characters", ErrInvalidWebhook, minSecretLength)
+	}
+
+	w := &models.Webhook{
+		ID:        uuid.New().String(),
+		URL:       req.URL,
+		Events:    events,
+		Secret:    secret,
+		CreatedAt: wu.now().UTC(),
+	}
+	if err := wu.repo.AddWebhook(ctx, w); err != nil {
+		wu.logger.Errorf("cannot add webhook url=%s: %v", w.URL, err)
+		return nil, fmt.Errorf("cannot add webhook: %w", err)
+	}
+	wu.subs.invalidate()
+
+	return w, nil
+}
+
+// ListWebhooks returns webhooks without secrets
+func (wu *WebhookUsecase) ListWebhooks(ctx context.Context) ([]*models.Webhook, error) {
+	webhooks, err := wu.repo.ListWebhooks(ctx)
+	if err != nil {
+		wu.logger.Errorf("cannot list webhooks: %v", err)
+		return nil, fmt.Errorf("cannot list webhooks: %w", err)
+	}
+
+	for _, w := range webhooks {
+		w.Secret = ""
+	}
+	return webhooks, nil
+}
+
+// DeleteWebhook removes webhook with deliveries which are still queued
+func (wu *WebhookUsecase) DeleteWebhook(ctx context.Context, id string) error {
+	err := wu.repo.DeleteWebhook(ctx, id)
+	if errors.Is(err, webhook.ErrNotFound) {
+		return ErrWebhookNotFound
+	}
+	if err != nil {
+		wu.logger.Errorf("cannot delete webhook=%s: %v", id, err)
+		return fmt.Errorf("cannot delete webhook: %w", err)
+	}
+	wu.subs.invalidate()
+
+	return nil
+}
+
+// Publish queues delivery of every event to webhooks subscribed to its type
+func (wu *WebhookUsecase) Publish(ctx context.Context, events ...*models.Event) error {
+	var deliveries []*models.Delivery
+	for _, e := range events {
+		subs, err := wu.subs.subscribers(ctx, wu.repo, e.Type)
+		if err != nil {
+			return err
+		}
+		if len(subs) == 0 {
+			continue
+		}
+
+		payload, err := easyjson.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("cannot encode event: %w", err)
+		}
+
+		now := wu.now().UTC()
+		for _, w := range subs {
+			deliveries = append(deliveries, &models.Delivery{
+				WebhookID:     w.ID,
+				EventID:       e.ID,
+				EventType:     e.Type,
+				Payload:       payload,
+				Status:        models.DeliveryPending,
+				CreatedAt:     now,
+				UpdatedAt:     now,
+				NextAttemptAt: now,
+			})
+		}
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	if err := wu.repo.AddDeliveries(ctx, deliveries); err != nil {
+		return fmt.Errorf("cannot queue deliveries: %w", err)
+	}
+	wu.notify()
+
+	return nil
+}
+
+// ListDeliveries returns deliveries newest first, cursor is id of the last delivery of previous page
+func (wu *WebhookUsecase) ListDeliveries(ctx context.Context, filter *models.DeliveryFilter, cursor string) ([]*models.Delivery, string, error) {
+	f := *filter
+	switch f.Status {
+	case "", models.DeliveryPending, models.DeliveryDelivered, models.DeliveryDead:
+	default:
+		return nil, "", fmt.Errorf("%w: unknown status %q", ErrInvalidFilter, f.Status)
+	}
+	if f.EventType != "" && !slices.Contains(models.EventTypes, f.EventType) {
+		return nil, "", fmt.Errorf("%w: unknown event %q", ErrInvalidFilter, f.EventType)
+	}
+	if f.Limit < 0 {
+		return nil, "", fmt.Errorf("%w: negative limit", ErrInvalidFilter)
+	}
+	if f.Limit == 0 {
+		f.Limit = defaultPageSize
+	}
+	f.Limit = min(f.Limit, maxPageSize)
+
+	if cursor != "" {
+		before, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil || before <= 0 {
+			return nil, "", ErrInvalidCursor
+		}
+		f.Before = before
+	}
+
+	// one extra delivery tells if there is next page
+	limit := f.Limit
+	f.Limit++
+	deliveries, err := wu.repo.ListDeliveries(ctx, &f)
+	if err != nil {
+		wu.logger.Errorf("cannot list webhook deliveries: %v", err)
+		return nil, "", fmt.Errorf("cannot list deliveries: %w", err)
+	}
+	if len(deliveries) <= limit {
+		return deliveries, "", nil
+	}
+
+	deliveries = deliveries[:limit]
+	return deliveries, strconv.FormatInt(deliveries[limit-1].ID, 10), nil
+}
+
+// RetryDelivery queues delivery right away with all attempts, delivered one is sent once more
+func (wu *WebhookUsecase) RetryDelivery(ctx context.Context, id int64) (*models.Delivery, error) {
+	d, err := wu.repo.GetDelivery(ctx, id)
+	if errors.Is(err, webhook.ErrNotFound) {
+		return nil, ErrDeliveryNotFound
+	}
+	if err != nil {
+		wu.logger.Errorf("cannot get webhook delivery=%d: %v", id, err)
+		return nil, fmt.Errorf("cannot get delivery: %w", err)
+	}
+
+	now := wu.now().UTC()
+	d.Status, d.Attempts = models.DeliveryPending, 0
+	d.UpdatedAt, d.NextAttemptAt = now, now
+
+	err = wu.repo.UpdateDelivery(ctx, d)
+	if errors.Is(err, webhook.ErrNotFound) {
+		return nil, ErrDeliveryNotFound
+	}
+	if err != nil {
+		wu.logger.Errorf("cannot retry webhook delivery=%d: %v", id, err)
+		return nil, fmt.Errorf("cannot retry delivery: %w", err)
+	}
+	wu.notify()
+
+	return d, nil
+}
+
+// notify wakes worker up, pending wake up is enough
+func (wu *WebhookUsecase) notify() {
+	select {
+	case wu.wake <- struct{}{}:
+	default:
+	}
+}
+
+func validateURL(rawURL string) error {
+	u, err := neturl.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: malformed url", ErrInvalidWebhook)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme must be http or https", ErrInvalidWebhook)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("%w: host is missing", ErrInvalidWebhook)
+	}
+	return nil
+}
+
+// normalizeEvents checks event types and removes duplicates
+func normalizeEvents(events []string) ([]string, error) {
+	if len(events) == 0 {
+		return nil, fmt.Errorf("%w: no events", ErrInvalidWebhook)
+	}
+
+	res := make([]string, 0, len(events))
+	for _, e := range events {
+		if !slices.Contains(models.EventTypes, e) {
+			return nil, fmt.Errorf("%w: unknown event %q", ErrInvalidWebhook, e)
+		}
+		if !slices.Contains(res, e) {
+			res = append(res, e)
+		}
+	}
+	return res, nil
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, secretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("cannot generate secret: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
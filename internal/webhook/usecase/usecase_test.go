@@ -0,0 +1,334 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/config"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/webhook/repository"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+	sign "github.com/MatiXxD/url-shortener/pkg/webhook"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var l *logger.Logger
+
+func TestMain(m *testing.M) {
+	zl, err := zap.NewDevelopment()
+	if err != nil {
+		panic(err)
+	}
+
+	l = &logger.Logger{SugaredLogger: zl.Sugar()}
+
+	os.Exit(m.Run())
+}
+
+func newTestUsecase(t *testing.T) *WebhookUsecase {
+	r, err := repository.NewFileRepository("", l)
+	require.NoError(t, err)
+
+	cfg := config.Default()
+	cfg.Webhooks.MaxAttempts = 3
+	cfg.Webhooks.MinBackoff = config.Duration(time.Second)
+	cfg.Webhooks.MaxBackoff = config.Duration(time.Minute)
+	cfg.Webhooks.PollInterval = config.Duration(time.Hour)
+
+	return NewWebhookUsecase(r, cfg, l)
+}
+
+// receiver records verified deliveries and answers with statuses in order, the last one repeats
+type receiver struct {
+	t        *testing.T
+	secret   string
+	mu       sync.Mutex
+	statuses []int
+	got      []*http.Request
+	bodies   []string
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	require.NoError(rc.t, err)
+	require.NoError(rc.t, sign.Verify(rc.secret, body, r.Header.Get(sign.SignatureHeader), sign.DefaultTolerance))
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.got = append(rc.got, r)
+	rc.bodies = append(rc.bodies, string(body))
+
+	status := rc.statuses[min(len(rc.got), len(rc.statuses))-1]
+	w.WriteHeader(status)
+}
+
+func (rc *receiver) count() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return len(rc.got)
+}
+
+func newEvent(typ string) *models.Event {
+	return &models.Event{
+		ID:         typ + "-id",
+		Type:       typ,
+		OccurredAt: time.Now().UTC(),
+		Link:       &models.EventLink{ShortURL: "http://localhost:8080/abc", Code: "abc", OriginalURL: "https://example.com"},
+	}
+}
+
+func TestWebhookUsecase_AddWebhook(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *models.AddWebhookReqBody
+		wantErr bool
+	}{
+		{name: "valid", req: &models.AddWebhookReqBody{URL: "https://receiver.io/hook", Events: []string{models.EventLinkCreated}}},
+		{name: "own secret", req: &models.AddWebhookReqBody{URL: "http://receiver.io", Events: models.EventTypes, Secret: "0123456789abcdef"}},
+		{name: "short secret", req: &models.AddWebhookReqBody{URL: "http://receiver.io", Events: models.EventTypes, Secret: "short"}, wantErr: true},
+		{name: "no events", req: &models.AddWebhookReqBody{URL: "http://receiver.io"}, wantErr: true},
+		{name: "unknown event", req: &models.AddWebhookReqBody{URL: "http://receiver.io", Events: []string{"link.viewed"}}, wantErr: true},
+		{name: "not http", req: &models.AddWebhookReqBody{URL: "ftp://receiver.io", Events: models.EventTypes}, wantErr: true},
+		{name: "no host", req: &models.AddWebhookReqBody{URL: "http:///hook", Events: models.EventTypes}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wu := newTestUsecase(t)
+
+			w, err := wu.AddWebhook(context.Background(), tt.req)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidWebhook)
+				return
+			}
+			require.NoError(t, err)
+			require.NotEmpty(t, w.ID)
+			require.GreaterOrEqual(t, len(w.Secret), minSecretLength)
+
+			list, err := wu.ListWebhooks(context.Background())
+			require.NoError(t, err)
+			require.Len(t, list, 1)
+			require.Empty(t, list[0].Secret)
+		})
+	}
+}
+
+func TestWebhookUsecase_Delivery(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("signed delivery of subscribed events", func(t *testing.T) {
+		wu := newTestUsecase(t)
+		rc := &receiver{t: t, statuses: []int{http.StatusOK}}
+		ts := httptest.NewServer(rc)
+		defer ts.Close()
+
+		w, err := wu.AddWebhook(ctx, &models.AddWebhookReqBody{URL: ts.URL, Events: []string{models.EventLinkCreated}})
+		require.NoError(t, err)
+		rc.secret = w.Secret
+
+		created := newEvent(models.EventLinkCreated)
+		require.NoError(t, wu.Publish(ctx, created, newEvent(models.EventLinkDeleted)))
+		wu.deliverDue(ctx)
+
+		require.Equal(t, 1, rc.count())
+		require.Equal(t, models.EventLinkCreated, rc.got[0].Header.Get(sign.EventHeader))
+		require.Equal(t, "1", rc.got[0].Header.Get(sign.DeliveryHeader))
+		require.Equal(t, "application/json", rc.got[0].Header.Get("Content-Type"))
+
+		var got models.Event
+		require.NoError(t, json.Unmarshal([]byte(rc.bodies[0]), &got))
+		require.Equal(t, created.ID, got.ID)
+		require.Equal(t, created.Type, got.Type)
+		require.Equal(t, created.Link, got.Link)
+
+		log, next, err := wu.ListDeliveries(ctx, &models.DeliveryFilter{}, "")
+		require.NoError(t, err)
+		require.Empty(t, next)
+		require.Len(t, log, 1)
+		require.Equal(t, models.DeliveryDelivered, log[0].Status)
+		require.Equal(t, 1, log[0].Attempts)
+		require.Equal(t, http.StatusOK, log[0].ResponseCode)
+	})
+
+	t.Run("retries with backoff and dead letters", func(t *testing.T) {
+		wu := newTestUsecase(t)
+		now := time.Now()
+		wu.now = func() time.Time { return now }
+
+		rc := &receiver{t: t, statuses: []int{http.StatusInternalServerError}}
+		ts := httptest.NewServer(rc)
+		defer ts.Close()
+
+		w, err := wu.AddWebhook(ctx, &models.AddWebhookReqBody{URL: ts.URL, Events: models.EventTypes})
+		require.NoError(t, err)
+		rc.secret = w.Secret
+		require.NoError(t, wu.Publish(ctx, newEvent(models.EventLinkClicked)))
+
+		wu.deliverDue(ctx)
+		require.Equal(t, 1, rc.count())
+
+		// next attempt is not due yet
+		wu.deliverDue(ctx)
+		require.Equal(t, 1, rc.count())
+
+		log, _, err := wu.ListDeliveries(ctx, &models.DeliveryFilter{}, "")
+		require.NoError(t, err)
+		require.Equal(t, models.DeliveryPending, log[0].Status)
+		require.Equal(t, http.StatusInternalServerError, log[0].ResponseCode)
+		require.Contains(t, log[0].LastError, "unexpected status 500")
+		require.True(t, log[0].NextAttemptAt.After(now))
+
+		for range 2 {
+			now = now.Add(time.Minute)
+			wu.deliverDue(ctx)
+		}
+		require.Equal(t, 3, rc.count())
+
+		// attempts are exhausted
+		now = now.Add(time.Hour)
+		wu.deliverDue(ctx)
+		require.Equal(t, 3, rc.count())
+
+		dead, _, err := wu.ListDeliveries(ctx, &models.DeliveryFilter{Status: models.DeliveryDead}, "")
+		require.NoError(t, err)
+		require.Len(t, dead, 1)
+		require.Equal(t, 3, dead[0].Attempts)
+
+		// dead delivery is replayed by hand
+		rc.mu.Lock()
+		rc.statuses = []int{http.StatusNoContent}
+		rc.mu.Unlock()
+
+		d, err := wu.RetryDelivery(ctx, dead[0].ID)
+		require.NoError(t, err)
+		require.Equal(t, models.DeliveryPending, d.Status)
+		require.Zero(t, d.Attempts)
+
+		wu.deliverDue(ctx)
+		require.Equal(t, 4, rc.count())
+
+		log, _, err = wu.ListDeliveries(ctx, &models.DeliveryFilter{}, "")
+		require.NoError(t, err)
+		require.Equal(t, models.DeliveryDelivered, log[0].Status)
+
+		_, err = wu.RetryDelivery(ctx, 100)
+		require.ErrorIs(t, err, ErrDeliveryNotFound)
+	})
+
+	t.Run("worker wakes up on publish", func(t *testing.T) {
+		wu := newTestUsecase(t)
+		rc := &receiver{t: t, statuses: []int{http.StatusOK}}
+		ts := httptest.NewServer(rc)
+		defer ts.Close()
+
+		w, err := wu.AddWebhook(ctx, &models.AddWebhookReqBody{URL: ts.URL, Events: models.EventTypes})
+		require.NoError(t, err)
+		rc.secret = w.Secret
+
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			wu.Run(runCtx)
+			close(done)
+		}()
+
+		require.NoError(t, wu.Publish(ctx, newEvent(models.EventLinkUpdated)))
+		require.Eventually(t, func() bool { return rc.count() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+		cancel()
+		<-done
+	})
+
+	t.Run("removed webhook gets nothing", func(t *testing.T) {
+		wu := newTestUsecase(t)
+		rc := &receiver{t: t, statuses: []int{http.StatusOK}}
+		ts := httptest.NewServer(rc)
+		defer ts.Close()
+
+		w, err := wu.AddWebhook(ctx, &models.AddWebhookReqBody{URL: ts.URL, Events: models.EventTypes})
+		require.NoError(t, err)
+		require.NoError(t, wu.DeleteWebhook(ctx, w.ID))
+		require.ErrorIs(t, wu.DeleteWebhook(ctx, w.ID), ErrWebhookNotFound)
+
+		require.NoError(t, wu.Publish(ctx, newEvent(models.EventLinkCreated)))
+		wu.deliverDue(ctx)
+		require.Zero(t, rc.count())
+	})
+}
+
+func TestWebhookUsecase_ListDeliveries(t *testing.T) {
+	ctx := context.Background()
+	wu := newTestUsecase(t)
+
+	_, err := wu.AddWebhook(ctx, &models.AddWebhookReqBody{URL: "http://receiver.io", Events: models.EventTypes})
+	require.NoError(t, err)
+	for _, typ := range models.EventTypes {
+		require.NoError(t, wu.Publish(ctx, newEvent(typ)))
+	}
+
+	page, next, err := wu.ListDeliveries(ctx, &models.DeliveryFilter{Limit: 3}, "")
+	require.NoError(t, err)
+	require.Len(t, page, 3)
+	require.Equal(t, models.EventLinkClicked, page[0].EventType)
+	require.NotEmpty(t, next)
+
+	page, next, err = wu.ListDeliveries(ctx, &models.DeliveryFilter{Limit: 3}, next)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, models.EventLinkCreated, page[0].EventType)
+	require.Empty(t, next)
+
+	page, _, err = wu.ListDeliveries(ctx, &models.DeliveryFilter{EventType: models.EventLinkDeleted}, "")
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+
+	_, _, err = wu.ListDeliveries(ctx, &models.DeliveryFilter{Status: "lost"}, "")
+	require.ErrorIs(t, err, ErrInvalidFilter)
+	_, _, err = wu.ListDeliveries(ctx, &models.DeliveryFilter{}, "abc")
+	require.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestWebhookUsecase_backoff(t *testing.T) {
+	wu := newTestUsecase(t)
+
+	for attempts, want := range map[int]time.Duration{1: time.Second, 3: 4 * time.Second, 10: time.Minute, 100: time.Minute} {
+		d := wu.backoff(attempts)
+		require.GreaterOrEqual(t, d, want/2)
+		require.LessOrEqual(t, d, want)
+	}
+}
+
+func TestWebhookUsecase_purge(t *testing.T) {
+	ctx := context.Background()
+	wu := newTestUsecase(t)
+	wu.cfg.Retention = config.Duration(24 * time.Hour)
+	now := time.Now()
+	wu.now = func() time.Time { return now }
+
+	_, err := wu.AddWebhook(ctx, &models.AddWebhookReqBody{URL: "http://receiver.io", Events: models.EventTypes})
+	require.NoError(t, err)
+	require.NoError(t, wu.Publish(ctx, newEvent(models.EventLinkCreated), newEvent(models.EventLinkClicked)))
+
+	page, _, err := wu.ListDeliveries(ctx, &models.DeliveryFilter{}, "")
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	for _, d := range page {
+		d.Status, d.UpdatedAt = models.DeliveryDelivered, now.Add(-48*time.Hour)
+		require.NoError(t, wu.repo.UpdateDelivery(ctx, d))
+	}
+
+	// the newest delivery is kept by file repository
+	wu.purge(ctx)
+	page, _, err = wu.ListDeliveries(ctx, &models.DeliveryFilter{}, "")
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, now, wu.purgedAt)
+}
@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/webhook"
+	sign "github.com/MatiXxD/url-shortener/pkg/webhook"
+)
+
+const (
+	// userAgent of delivery requests
+	userAgent = "url-shortener-webhooks"
+	// maxErrorBody is how much of failed response is kept in delivery log
+	maxErrorBody = 512
+	// purgeInterval is how often finished deliveries older than retention are removed
+	purgeInterval = time.Hour
+)
+
+// Run sends due deliveries until ctx is done, queue is checked every poll interval
+// and right after new deliveries are published
+func (wu *WebhookUsecase) Run(ctx context.Context) {
+	ticker := time.NewTicker(wu.cfg.PollInterval.Std())
+	defer ticker.Stop()
+
+	for {
+		wu.deliverDue(ctx)
+		wu.purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-wu.wake:
+		}
+	}
+}
+
+// deliverDue claims due deliveries by one per worker and sends them until queue has no due ones
+func (wu *WebhookUsecase) deliverDue(ctx context.Context) {
+	workers := max(wu.cfg.Workers, 1)
+	// claim outlives the slowest request, so nobody else takes delivery while it is sent
+	lease := 2 * wu.cfg.Timeout.Std()
+
+	for ctx.Err() == nil {
+		claimed, err := wu.repo.ClaimDeliveries(ctx, wu.now().UTC(), lease, workers)
+		if err != nil {
+			wu.logger.Errorf("cannot claim webhook deliveries: %v", err)
+			return
+		}
+
+		var wg sync.WaitGroup
+		for _, d := range claimed {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				wu.deliver(ctx, d)
+			}()
+		}
+		wg.Wait()
+
+		if len(claimed) < workers {
+			return
+		}
+	}
+}
+
+// purge removes finished deliveries older than retention, it runs once per purge interval
+func (wu *WebhookUsecase) purge(ctx context.Context) {
+	now := wu.now()
+	if wu.cfg.Retention <= 0 || now.Sub(wu.purgedAt) < purgeInterval {
+		return
+	}
+	wu.purgedAt = now
+
+	n, err := wu.repo.PurgeDeliveries(ctx, now.UTC().Add(-wu.cfg.Retention.Std()))
+	if err != nil {
+		wu.logger.Errorf("cannot purge webhook deliveries: %v", err)
+		return
+	}
+	if n > 0 {
+		wu.logger.Infof("purged %d finished webhook deliveries", n)
+	}
+}
+
+// deliver makes attempt of delivery and saves its result, failed delivery is retried with exponential
+// backoff until attempts are exhausted and it becomes dead
+func (wu *WebhookUsecase) deliver(ctx context.Context, d *models.Delivery) {
+	w, err := wu.subs.get(ctx, wu.repo, d.WebhookID)
+	if err != nil {
+		wu.logger.Errorf("cannot get webhook of delivery=%d: %v", d.ID, err)
+		return
+	}
+
+	if w == nil {
+		// deliveries are removed with webhook, this one was claimed just before
+		d.Status, d.LastError = models.DeliveryDead, "webhook was removed"
+	} else {
+		code, err := wu.send(ctx, w, d)
+		// attempt interrupted by shutdown is repeated when claim expires
+		if ctx.Err() != nil {
+			return
+		}
+
+		d.Attempts++
+		d.ResponseCode = code
+		switch {
+		case err == nil:
+			d.Status, d.LastError = models.DeliveryDelivered, ""
+		case d.Attempts >= wu.cfg.MaxAttempts:
+			d.Status, d.LastError = models.DeliveryDead, err.Error()
+			wu.logger.Warnf("webhook delivery=%d to %s is dead after %d attempts: %v", d.ID, w.URL, d.Attempts, err)
+		default:
+			d.LastError = err.Error()
+			d.NextAttemptAt = wu.now().UTC().Add(wu.backoff(d.Attempts))
+		}
+	}
+	d.UpdatedAt = wu.now().UTC()
+
+	err = wu.repo.UpdateDelivery(ctx, d)
+	if err != nil && !errors.Is(err, webhook.ErrNotFound) {
+		wu.logger.Errorf("cannot save webhook delivery=%d: %v", d.ID, err)
+	}
+}
+
+// send posts signed payload, any status but 2xx is a failure
+func (wu *WebhookUsecase) send(ctx context.Context, w *models.Webhook, d *models.Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("cannot create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(sign.EventHeader, d.EventType)
+	req.Header.Set(sign.DeliveryHeader, strconv.FormatInt(d.ID, 10))
+	req.Header.Set(sign.SignatureHeader, sign.Sign(w.Secret, d.Payload, wu.now()))
+
+	resp, err := wu.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+
+	return resp.StatusCode, nil
+}
+
+// backoff returns delay after failed attempt, jitter spreads retries of deliveries failed together
+func (wu *WebhookUsecase) backoff(attempts int) time.Duration {
+	minBackoff, maxBackoff := wu.cfg.MinBackoff.Std(), wu.cfg.MaxBackoff.Std()
+
+	d := minBackoff << (attempts - 1)
+	if d <= 0 || d > maxBackoff {
+		d = maxBackoff
+	}
+	if d > 0 {
+		d = d/2 + rand.N(d/2+1)
+	}
+	return d
+}
@@ -0,0 +1,28 @@
+package workspace
+
+import (
+	"context"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+)
+
+type ctxKeyWorkspace struct{}
+
+// With returns context of request made in workspace
+func With(ctx context.Context, ws *models.Workspace) context.Context {
+	return context.WithValue(ctx, ctxKeyWorkspace{}, ws)
+}
+
+// From returns workspace of context, nil means default workspace
+func From(ctx context.Context) *models.Workspace {
+	ws, _ := ctx.Value(ctxKeyWorkspace{}).(*models.Workspace)
+	return ws
+}
+
+// IDFrom returns id of workspace of context, empty for default workspace
+func IDFrom(ctx context.Context) string {
+	if ws := From(ctx); ws != nil {
+		return ws.ID
+	}
+	return ""
+}
@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	mw "github.com/MatiXxD/url-shortener/internal/middleware"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/workspace"
+	"github.com/MatiXxD/url-shortener/internal/workspace/usecase"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/go-chi/chi/v5"
+	"github.com/mailru/easyjson"
+)
+
+type WorkspaceHandler struct {
+	workspaceUsecase workspace.Usecase
+	logger           *logger.Logger
+}
+
+func NewWorkspaceHandler(u workspace.Usecase, l *logger.Logger) *WorkspaceHandler {
+	return &WorkspaceHandler{
+		workspaceUsecase: u,
+		logger:           l,
+	}
+}
+
+// Resolve puts workspace of authenticated user to request context, it goes after auth.
+// Users without workspace stay in default one.
+func (wh *WorkspaceHandler) Resolve(next http.Handler) http.Handler {
+	wf := func(w http.ResponseWriter, r *http.Request) {
+		userID := mw.GetUserID(r.Context())
+		if userID == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ws, err := wh.workspaceUsecase.UserWorkspace(r.Context(), userID)
+		if err != nil {
+			logger := wh.logger
+			reqID := mw.GetRequestID(r.Context())
+			if reqID != "" {
+				logger = wh.logger.With("request_id", reqID)
+			}
+			logger.Errorf("can't get workspace of user %s: %v", userID, err)
+			http.Error(w, "Can't get workspace", http.StatusInternalServerError)
+			return
+		}
+		if ws == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(workspace.With(r.Context(), ws)))
+	}
+	return http.HandlerFunc(wf)
+}
+
+func (wh *WorkspaceHandler) ListWorkspaces(w http.ResponseWriter, r *http.Request) {
+	logger := wh.logger
+	reqID := mw.GetRequestID(r.Context())
+	if reqID != "" {
+		logger = wh.logger.With("request_id", reqID)
+	}
+
+	workspaces, err := wh.workspaceUsecase.ListWorkspaces(r.Context())
+	if err != nil {
+		logger.Errorf("can't list workspaces: %v", err)
+		http.Error(w, "Can't list workspaces", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := easyjson.MarshalToWriter(models.WorkspaceList(workspaces), w); err != nil {
+		logger.Error("can't marshal response body")
+		http.Error(w, "Can't marshal response body", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (wh *WorkspaceHandler) AddWorkspace(w http.ResponseWriter, r *http.Request) {
+	logger := wh.logger
+	reqID := mw.GetRequestID(r.Context())
+	if reqID != "" {
+		logger = wh.logger.With("request_id", reqID)
+	}
+
+	req, ok := wh.readWorkspace(w, r)
+	if !ok {
+		return
+	}
+
+	ws, err := wh.workspaceUsecase.AddWorkspace(r.Context(), req)
+	if errors.Is(err, usecase.ErrInvalidWorkspace) {
+		logger.Errorf("invalid workspace: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		logger.Errorf("can't add workspace: %v", err)
+		http.Error(w, "Can't add workspace", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if _, err := easyjson.MarshalToWriter(ws, w); err != nil {
+		logger.Error("can't marshal response body")
+	}
+}
+
+// UpdateWorkspace replaces name and settings of workspace
+func (wh *WorkspaceHandler) UpdateWorkspace(w http.ResponseWriter, r *http.Request) {
+	logger := wh.logger
+	reqID := mw.GetRequestID(r.Context())
+	if reqID != "" {
+		logger = wh.logger.With("request_id", reqID)
+	}
+
+	req, ok := wh.readWorkspace(w, r)
+	if !ok {
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	ws, err := wh.workspaceUsecase.UpdateWorkspace(r.Context(), id, req)
+	switch {
+	case errors.Is(err, usecase.ErrInvalidWorkspace):
+		logger.Errorf("invalid workspace: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, usecase.ErrWorkspaceNotFound):
+		logger.Errorf("can't find workspace %s", id)
+		http.Error(w, "Can't find workspace", http.StatusNotFound)
+		return
+	case err != nil:
+		logger.Errorf("can't update workspace %s: %v", id, err)
+		http.Error(w, "Can't update workspace", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := easyjson.MarshalToWriter(ws, w); err != nil {
+		logger.Error("can't marshal response body")
+		http.Error(w, "Can't marshal response body", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (wh *WorkspaceHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	logger := wh.logger
+	reqID := mw.GetRequestID(r.Context())
+	if reqID != "" {
+		logger = wh.logger.With("request_id", reqID)
+	}
+
+	id := chi.URLParam(r, "id")
+	members, err := wh.workspaceUsecase.ListMembers(r.Context(), id)
+	if errors.Is(err, usecase.ErrWorkspaceNotFound) {
+		logger.Errorf("can't find workspace %s", id)
+		http.Error(w, "Can't find workspace", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		logger.Errorf("can't list members of workspace %s: %v", id, err)
+		http.Error(w, "Can't list members", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := easyjson.MarshalToWriter(models.WorkspaceMemberList(members), w); err != nil {
+		logger.Error("can't marshal response body")
+		http.Error(w, "Can't marshal response body", http.StatusInternalServerError)
+		return
+	}
+}
+
+// AddMember moves user to workspace, its links made before stay where they were
+func (wh *WorkspaceHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	logger := wh.logger
+	reqID := mw.GetRequestID(r.Context())
+	if reqID != "" {
+		logger = wh.logger.With("request_id", reqID)
+	}
+
+	id, userID := chi.URLParam(r, "id"), chi.URLParam(r, "user")
+	m, err := wh.workspaceUsecase.AddMember(r.Context(), id, userID)
+	switch {
+	case errors.Is(err, usecase.ErrInvalidWorkspace):
+		logger.Errorf("invalid member: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, usecase.ErrWorkspaceNotFound):
+		logger.Errorf("can't find workspace %s", id)
+		http.Error(w, "Can't find workspace", http.StatusNotFound)
+		return
+	case err != nil:
+		logger.Errorf("can't add user %s to workspace %s: %v", userID, id, err)
+		http.Error(w, "Can't add member", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := easyjson.MarshalToWriter(m, w); err != nil {
+		logger.Error("can't marshal response body")
+		http.Error(w, "Can't marshal response body", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (wh *WorkspaceHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	logger := wh.logger
+	reqID := mw.GetRequestID(r.Context())
+	if reqID != "" {
+		logger = wh.logger.With("request_id", reqID)
+	}
+
+	id, userID := chi.URLParam(r, "id"), chi.URLParam(r, "user")
+	err := wh.workspaceUsecase.RemoveMember(r.Context(), id, userID)
+	if errors.Is(err, usecase.ErrMemberNotFound) {
+		logger.Errorf("user %s is not member of workspace %s", userID, id)
+		http.Error(w, "Can't find member", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		logger.Errorf("can't remove user %s from workspace %s: %v", userID, id, err)
+		http.Error(w, "Can't remove member", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// readWorkspace reads workspace request body, response is written on failure
+func (wh *WorkspaceHandler) readWorkspace(w http.ResponseWriter, r *http.Request) (*models.WorkspaceReqBody, bool) {
+	logger := wh.logger
+	reqID := mw.GetRequestID(r.Context())
+	if reqID != "" {
+		logger = wh.logger.With("request_id", reqID)
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if !strings.Contains(contentType, "application/json") {
+		logger.Error("request contains wrong content type")
+		http.Error(w, "Wrong content type", http.StatusUnsupportedMediaType)
+		return nil, false
+	}
+
+	var req models.WorkspaceReqBody
+	err := easyjson.UnmarshalFromReader(r.Body, &req)
+	if isTooLarge(err) {
+		logger.Error("request body is too large")
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return nil, false
+	}
+	if err != nil {
+		logger.Errorf("can't unmarshal request body: %v", err)
+		http.Error(w, "Can't read body", http.StatusBadRequest)
+		return nil, false
+	}
+
+	return &req, true
+}
+
+func isTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MatiXxD/url-shortener/config"
+	mw "github.com/MatiXxD/url-shortener/internal/middleware"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/workspace"
+	"github.com/MatiXxD/url-shortener/internal/workspace/repository"
+	"github.com/MatiXxD/url-shortener/internal/workspace/usecase"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func doRequest(t *testing.T, ts *httptest.Server, method, path, user, body string) (*http.Response, string) {
+	req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+	require.NoError(t, err)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if user != "" {
+		req.Header.Set("X-User", user)
+	}
+
+	resp, err := ts.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp, string(data)
+}
+
+func TestWorkspaceHandler(t *testing.T) {
+	zl, err := zap.NewDevelopment()
+	require.NoError(t, err)
+	l := &logger.Logger{SugaredLogger: zl.Sugar()}
+
+	r, err := repository.NewFileRepository("", l)
+	require.NoError(t, err)
+	h := NewWorkspaceHandler(usecase.NewWorkspaceUsecase(r, config.Default(), l), l)
+
+	mux := chi.NewRouter()
+	// user is taken from header instead of cookie to keep test short
+	mux.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(mw.WithUserID(r.Context(), r.Header.Get("X-User"))))
+		})
+	})
+	mux.Use(h.Resolve)
+	mux.Get("/api/workspaces", h.ListWorkspaces)
+	mux.Post("/api/workspaces", h.AddWorkspace)
+	mux.Put("/api/workspaces/{id}", h.UpdateWorkspace)
+	mux.Get("/api/workspaces/{id}/members", h.ListMembers)
+	mux.Put("/api/workspaces/{id}/members/{user}", h.AddMember)
+	mux.Delete("/api/workspaces/{id}/members/{user}", h.RemoveMember)
+	mux.Get("/api/whoami", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, workspace.IDFrom(r.Context()))
+	})
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	resp, body := doRequest(t, ts, http.MethodPost, "/api/workspaces", "", `{"name":"team","settings":{"code_length":6}}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode, body)
+	var team models.Workspace
+	require.NoError(t, json.Unmarshal([]byte(body), &team))
+	require.NotEmpty(t, team.ID)
+
+	resp, body = doRequest(t, ts, http.MethodPut, "/api/workspaces/"+team.ID+"/members/u1", "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, body)
+
+	resp, body = doRequest(t, ts, http.MethodGet, "/api/whoami", "u1", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, team.ID, body)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		user     string
+		body     string
+		wantCode int
+		want     string
+	}{
+		{
+			name:     "user of default workspace",
+			method:   http.MethodGet,
+			path:     "/api/whoami",
+			user:     "u2",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "invalid workspace",
+			method:   http.MethodPost,
+			path:     "/api/workspaces",
+			body:     `{"name":""}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "wrong content type",
+			method:   http.MethodPut,
+			path:     "/api/workspaces/" + team.ID,
+			wantCode: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:     "update unknown workspace",
+			method:   http.MethodPut,
+			path:     "/api/workspaces/missing",
+			body:     `{"name":"team"}`,
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "update workspace",
+			method:   http.MethodPut,
+			path:     "/api/workspaces/" + team.ID,
+			body:     `{"name":"renamed","settings":{"redirect_code":308}}`,
+			wantCode: http.StatusOK,
+			want:     `"redirect_code":308`,
+		},
+		{
+			name:     "add member to unknown workspace",
+			method:   http.MethodPut,
+			path:     "/api/workspaces/missing/members/u2",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "members of unknown workspace",
+			method:   http.MethodGet,
+			path:     "/api/workspaces/missing/members",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "members",
+			method:   http.MethodGet,
+			path:     "/api/workspaces/" + team.ID + "/members",
+			wantCode: http.StatusOK,
+			want:     `"user_id":"u1"`,
+		},
+		{
+			name:     "remove not member",
+			method:   http.MethodDelete,
+			path:     "/api/workspaces/" + team.ID + "/members/u2",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "list workspaces",
+			method:   http.MethodGet,
+			path:     "/api/workspaces",
+			wantCode: http.StatusOK,
+			want:     `"name":"renamed"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := doRequest(t, ts, tt.method, tt.path, tt.user, tt.body)
+			require.Equal(t, tt.wantCode, resp.StatusCode, body)
+			require.Contains(t, body, tt.want)
+		})
+	}
+
+	resp, _ = doRequest(t, ts, http.MethodDelete, "/api/workspaces/"+team.ID+"/members/u1", "", "")
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	_, body = doRequest(t, ts, http.MethodGet, "/api/whoami", "u1", "")
+	require.Empty(t, body)
+}
@@ -0,0 +1,26 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+)
+
+var ErrNotFound = errors.New("workspace was not found")
+
+// Repository keeps workspaces and their members, user is member of one workspace at most
+type Repository interface {
+	AddWorkspace(context.Context, *models.Workspace) error
+	GetWorkspace(ctx context.Context, id string) (*models.Workspace, error)
+	ListWorkspaces(context.Context) ([]*models.Workspace, error)
+	// UpdateWorkspace replaces name and settings of workspace and sets its creation time to model
+	UpdateWorkspace(context.Context, *models.Workspace) error
+
+	// AddMember moves user to workspace from the one user was member of
+	AddMember(context.Context, *models.WorkspaceMember) error
+	RemoveMember(ctx context.Context, workspaceID, userID string) error
+	ListMembers(ctx context.Context, workspaceID string) ([]*models.WorkspaceMember, error)
+	// UserWorkspace returns workspace user is member of, ErrNotFound if there is none
+	UserWorkspace(ctx context.Context, userID string) (*models.Workspace, error)
+}
@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/workspace"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+)
+
+// workspacesSuffix is appended to storage filename for workspaces and their members
+const workspacesSuffix = ".workspaces"
+
+// snapshot is content of workspaces file
+type snapshot struct {
+	Workspaces []*models.Workspace       `json:"workspaces"`
+	Members    []*models.WorkspaceMember `json:"members"`
+}
+
+// FileRepository keeps workspaces in memory, they are rewritten as a whole next to storage file
+// unless filename is empty
+type FileRepository struct {
+	filename   string
+	workspaces map[string]*models.Workspace
+	// members are keyed by user id
+	members map[string]*models.WorkspaceMember
+	logger  *logger.Logger
+	mu      sync.RWMutex
+}
+
+func NewFileRepository(filename string, logger *logger.Logger) (*FileRepository, error) {
+	fr := &FileRepository{
+		filename:   filename,
+		workspaces: make(map[string]*models.Workspace),
+		members:    make(map[string]*models.WorkspaceMember),
+		logger:     logger,
+	}
+	if filename == "" {
+		return fr, nil
+	}
+
+	if err := fr.init(); err != nil {
+		logger.Errorf("failed to init workspaces %v: %v", filename, err)
+		return nil, fmt.Errorf("failed to init workspaces: %w", err)
+	}
+
+	return fr, nil
+}
+
+func (fr *FileRepository) AddWorkspace(ctx context.Context, ws *models.Workspace) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	fr.workspaces[ws.ID] = copyWorkspace(ws)
+
+	if err := fr.save(); err != nil {
+		delete(fr.workspaces, ws.ID)
+		fr.logger.Errorf("failed to save workspaces: %v", err)
+		return fmt.Errorf("failed to save workspaces: %w", err)
+	}
+
+	return nil
+}
+
+func (fr *FileRepository) GetWorkspace(ctx context.Context, id string) (*models.Workspace, error) {
+	fr.mu.RLock()
+	defer fr.mu.RUnlock()
+
+	ws, ok := fr.workspaces[id]
+	if !ok {
+		return nil, workspace.ErrNotFound
+	}
+	return copyWorkspace(ws), nil
+}
+
+func (fr *FileRepository) ListWorkspaces(ctx context.Context) ([]*models.Workspace, error) {
+	fr.mu.RLock()
+	defer fr.mu.RUnlock()
+
+	return listWorkspaces(fr.workspaces), nil
+}
+
+func (fr *FileRepository) UpdateWorkspace(ctx context.Context, ws *models.Workspace) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	prev, ok := fr.workspaces[ws.ID]
+	if !ok {
+		return workspace.ErrNotFound
+	}
+	ws.CreatedAt = prev.CreatedAt
+	fr.workspaces[ws.ID] = copyWorkspace(ws)
+
+	if err := fr.save(); err != nil {
+		fr.workspaces[ws.ID] = prev
+		fr.logger.Errorf("failed to save workspaces: %v", err)
+		return fmt.Errorf("failed to save workspaces: %w", err)
+	}
+
+	return nil
+}
+
+func (fr *FileRepository) AddMember(ctx context.Context, m *models.WorkspaceMember) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	if _, ok := fr.workspaces[m.WorkspaceID]; !ok {
+		return workspace.ErrNotFound
+	}
+
+	prev, ok := fr.members[m.UserID]
+	stored := *m
+	fr.members[m.UserID] = &stored
+
+	if err := fr.save(); err != nil {
+		if ok {
+			fr.members[m.UserID] = prev
+		} else {
+			delete(fr.members, m.UserID)
+		}
+		fr.logger.Errorf("failed to save workspaces: %v", err)
+		return fmt.Errorf("failed to save workspaces: %w", err)
+	}
+
+	return nil
+}
+
+func (fr *FileRepository) RemoveMember(ctx context.Context, workspaceID, userID string) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	m, ok := fr.members[userID]
+	if !ok || m.WorkspaceID != workspaceID {
+		return workspace.ErrNotFound
+	}
+	delete(fr.members, userID)
+
+	if err := fr.save(); err != nil {
+		fr.members[userID] = m
+		fr.logger.Errorf("failed to save workspaces: %v", err)
+		return fmt.Errorf("failed to save workspaces: %w", err)
+	}
+
+	return nil
+}
+
+func (fr *FileRepository) ListMembers(ctx context.Context, workspaceID string) ([]*models.WorkspaceMember, error) {
+	fr.mu.RLock()
+	defer fr.mu.RUnlock()
+
+	if _, ok := fr.workspaces[workspaceID]; !ok {
+		return nil, workspace.ErrNotFound
+	}
+
+	res := make([]*models.WorkspaceMember, 0)
+	for _, m := range listMembers(fr.members) {
+		if m.WorkspaceID == workspaceID {
+			res = append(res, m)
+		}
+	}
+	return res, nil
+}
+
+func (fr *FileRepository) UserWorkspace(ctx context.Context, userID string) (*models.Workspace, error) {
+	fr.mu.RLock()
+	defer fr.mu.RUnlock()
+
+	m, ok := fr.members[userID]
+	if !ok {
+		return nil, workspace.ErrNotFound
+	}
+	ws, ok := fr.workspaces[m.WorkspaceID]
+	if !ok {
+		return nil, workspace.ErrNotFound
+	}
+	return copyWorkspace(ws), nil
+}
+
+func (fr *FileRepository) init() error {
+	data, err := os.ReadFile(fr.filename + workspacesSuffix)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var s snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("malformed workspaces: %w", err)
+	}
+	for _, ws := range s.Workspaces {
+		fr.workspaces[ws.ID] = ws
+	}
+	for _, m := range s.Members {
+		fr.members[m.UserID] = m
+	}
+
+	return nil
+}
+
+func (fr *FileRepository) save() error {
+	if fr.filename == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(snapshot{
+		Workspaces: listWorkspaces(fr.workspaces),
+		Members:    listMembers(fr.members),
+	})
+	if err != nil {
+		return err
+	}
+
+	name := fr.filename + workspacesSuffix
+	tmp := name + ".tmp"
+	if err := os.WriteFile(tmp, data, 0666); err != nil {
+		return err
+	}
+	return os.Rename(tmp, name)
+}
+
+func copyWorkspace(ws *models.Workspace) *models.Workspace {
+	copied := *ws
+	copied.Settings.Domains = slices.Clone(ws.Settings.Domains)
+	return &copied
+}
+
+// listWorkspaces returns copies of workspaces, the oldest first
+func listWorkspaces(workspaces map[string]*models.Workspace) []*models.Workspace {
+	res := make([]*models.Workspace, 0, len(workspaces))
+	for _, ws := range workspaces {
+		res = append(res, copyWorkspace(ws))
+	}
+	slices.SortFunc(res, func(a, b *models.Workspace) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return res
+}
+
+// listMembers returns copies of members in order they were added
+func listMembers(members map[string]*models.WorkspaceMember) []*models.WorkspaceMember {
+	res := make([]*models.WorkspaceMember, 0, len(members))
+	for _, m := range members {
+		copied := *m
+		res = append(res, &copied)
+	}
+	slices.SortFunc(res, func(a, b *models.WorkspaceMember) int {
+		if c := a.AddedAt.Compare(b.AddedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.UserID, b.UserID)
+	})
+	return res
+}
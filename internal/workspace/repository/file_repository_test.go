@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/workspace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileRepository_Workspaces(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "db.json")
+	now := time.Now().UTC().Truncate(time.Second)
+
+	fr, err := NewFileRepository(filename, l)
+	require.NoError(t, err)
+
+	for i, id := range []string{"b", "a"} {
+		err := fr.AddWorkspace(ctx, &models.Workspace{ID: id, Name: "team " + id, CreatedAt: now.Add(time.Duration(i) * time.Second)})
+		require.NoError(t, err)
+	}
+
+	err = fr.UpdateWorkspace(ctx, &models.Workspace{ID: "a", Name: "renamed", Settings: models.WorkspaceSettings{
+		CodeLength: 6,
+		Domains:    []string{"go.a.com"},
+	}})
+	require.NoError(t, err)
+	require.ErrorIs(t, fr.UpdateWorkspace(ctx, &models.Workspace{ID: "missing"}), workspace.ErrNotFound)
+
+	require.NoError(t, fr.AddMember(ctx, &models.WorkspaceMember{WorkspaceID: "b", UserID: "u1", AddedAt: now}))
+	require.NoError(t, fr.AddMember(ctx, &models.WorkspaceMember{WorkspaceID: "b", UserID: "u2", AddedAt: now}))
+	// user is moved to another workspace
+	require.NoError(t, fr.AddMember(ctx, &models.WorkspaceMember{WorkspaceID: "a", UserID: "u2", AddedAt: now}))
+	require.ErrorIs(t, fr.AddMember(ctx, &models.WorkspaceMember{WorkspaceID: "missing", UserID: "u3"}), workspace.ErrNotFound)
+
+	require.ErrorIs(t, fr.RemoveMember(ctx, "b", "u2"), workspace.ErrNotFound)
+
+	reopened, err := NewFileRepository(filename, l)
+	require.NoError(t, err)
+
+	workspaces, err := reopened.ListWorkspaces(ctx)
+	require.NoError(t, err)
+	require.Len(t, workspaces, 2)
+	require.Equal(t, "b", workspaces[0].ID)
+	require.Equal(t, "renamed", workspaces[1].Name)
+	require.Equal(t, now.Add(time.Second), workspaces[1].CreatedAt)
+
+	ws, err := reopened.UserWorkspace(ctx, "u2")
+	require.NoError(t, err)
+	require.Equal(t, "a", ws.ID)
+	require.Equal(t, []string{"go.a.com"}, ws.Settings.Domains)
+
+	members, err := reopened.ListMembers(ctx, "b")
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	require.Equal(t, "u1", members[0].UserID)
+
+	require.NoError(t, reopened.RemoveMember(ctx, "b", "u1"))
+	_, err = reopened.UserWorkspace(ctx, "u1")
+	require.ErrorIs(t, err, workspace.ErrNotFound)
+
+	_, err = reopened.ListMembers(ctx, "missing")
+	require.ErrorIs(t, err, workspace.ErrNotFound)
+}
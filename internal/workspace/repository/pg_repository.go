@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/workspace"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/MatiXxD/url-shortener/pkg/postgres"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// foreignKeyViolation is postgres error code for missing referenced row
+const foreignKeyViolation = "23503"
+
+// workspaceColumns are selected by scanWorkspaces
+const workspaceColumns = `w.id, w.name, w.code_length, w.redirect_code, w.domains, w.created_at`
+
+// PostgresRepository reads workspaces from primary, so changed membership is seen by the next request
+type PostgresRepository struct {
+	db     *postgres.DB
+	logger *logger.Logger
+}
+
+func NewPostgresRepository(db *postgres.DB, logger *logger.Logger) *PostgresRepository {
+	return &PostgresRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (pr *PostgresRepository) AddWorkspace(ctx context.Context, ws *models.Workspace) error {
+	_, err := pr.db.Pool.Exec(ctx, `
+		INSERT INTO workspace (id, name, code_length, redirect_code, domains, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, ws.ID, ws.Name, ws.Settings.CodeLength, ws.Settings.RedirectCode, domainsArray(ws.Settings.Domains), ws.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to add workspace: %w", err)
+	}
+
+	return nil
+}
+
+func (pr *PostgresRepository) GetWorkspace(ctx context.Context, id string) (*models.Workspace, error) {
+	rows, err := pr.db.Pool.Query(ctx, `SELECT `+workspaceColumns+` FROM workspace w WHERE w.id = $1`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+
+	return firstWorkspace(rows)
+}
+
+func (pr *PostgresRepository) ListWorkspaces(ctx context.Context) ([]*models.Workspace, error) {
+	rows, err := pr.db.Pool.Query(ctx, `SELECT `+workspaceColumns+` FROM workspace w ORDER BY w.created_at, w.id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+
+	return scanWorkspaces(rows)
+}
+
+func (pr *PostgresRepository) UpdateWorkspace(ctx context.Context, ws *models.Workspace) error {
+	err := pr.db.Pool.QueryRow(ctx, `
+		UPDATE workspace SET name = $2, code_length = $3, redirect_code = $4, domains = $5
+		WHERE id = $1
+		RETURNING created_at
+	`, ws.ID, ws.Name, ws.Settings.CodeLength, ws.Settings.RedirectCode, domainsArray(ws.Settings.Domains)).Scan(&ws.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return workspace.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update workspace: %w", err)
+	}
+
+	return nil
+}
+
+func (pr *PostgresRepository) AddMember(ctx context.Context, m *models.WorkspaceMember) error {
+	_, err := pr.db.Pool.Exec(ctx, `
+		INSERT INTO workspace_member (user_id, workspace_id, added_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET
+			workspace_id = EXCLUDED.workspace_id,
+			added_at = EXCLUDED.added_at
+	`, m.UserID, m.WorkspaceID, m.AddedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+		return workspace.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to add workspace member: %w", err)
+	}
+
+	return nil
+}
+
+func (pr *PostgresRepository) RemoveMember(ctx context.Context, workspaceID, userID string) error {
+	tag, err := pr.db.Pool.Exec(ctx, `
+		DELETE FROM workspace_member WHERE workspace_id = $1 AND user_id = $2
+	`, workspaceID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove workspace member: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return workspace.ErrNotFound
+	}
+
+	return nil
+}
+
+func (pr *PostgresRepository) ListMembers(ctx context.Context, workspaceID string) ([]*models.WorkspaceMember, error) {
+	if _, err := pr.GetWorkspace(ctx, workspaceID); err != nil {
+		return nil, err
+	}
+
+	rows, err := pr.db.Pool.Query(ctx, `
+		SELECT workspace_id, user_id, added_at FROM workspace_member
+		WHERE workspace_id = $1
+		ORDER BY added_at, user_id
+	`, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspace members: %w", err)
+	}
+	defer rows.Close()
+
+	res := make([]*models.WorkspaceMember, 0)
+	for rows.Next() {
+		var m models.WorkspaceMember
+		if err := rows.Scan(&m.WorkspaceID, &m.UserID, &m.AddedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan workspace member: %w", err)
+		}
+		res = append(res, &m)
+	}
+
+	return res, rows.Err()
+}
+
+func (pr *PostgresRepository) UserWorkspace(ctx context.Context, userID string) (*models.Workspace, error) {
+	rows, err := pr.db.Pool.Query(ctx, `
+		SELECT `+workspaceColumns+` FROM workspace w
+		JOIN workspace_member m ON m.workspace_id = w.id
+		WHERE m.user_id = $1
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace of user: %w", err)
+	}
+
+	return firstWorkspace(rows)
+}
+
+// firstWorkspace returns the only workspace of rows, ErrNotFound if there is none
+func firstWorkspace(rows pgx.Rows) (*models.Workspace, error) {
+	res, err := scanWorkspaces(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, workspace.ErrNotFound
+	}
+	return res[0], nil
+}
+
+func scanWorkspaces(rows pgx.Rows) ([]*models.Workspace, error) {
+	defer rows.Close()
+
+	res := make([]*models.Workspace, 0)
+	for rows.Next() {
+		var ws models.Workspace
+		err := rows.Scan(&ws.ID, &ws.Name, &ws.Settings.CodeLength, &ws.Settings.RedirectCode, &ws.Settings.Domains,
+			&ws.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan workspace: %w", err)
+		}
+		if len(ws.Settings.Domains) == 0 {
+			ws.Settings.Domains = nil
+		}
+		res = append(res, &ws)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read workspaces: %w", err)
+	}
+
+	return res, nil
+}
+
+// domainsArray avoids NULL for column with NOT NULL constraint
+func domainsArray(domains []string) []string {
+	if domains == nil {
+		return []string{}
+	}
+	return domains
+}
@@ -0,0 +1,21 @@
+package workspace
+
+import (
+	"context"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+)
+
+type Usecase interface {
+	AddWorkspace(context.Context, *models.WorkspaceReqBody) (*models.Workspace, error)
+	ListWorkspaces(context.Context) ([]*models.Workspace, error)
+	UpdateWorkspace(ctx context.Context, id string, req *models.WorkspaceReqBody) (*models.Workspace, error)
+	// HasWorkspace reports if workspace with id exists
+	HasWorkspace(ctx context.Context, id string) (bool, error)
+
+	AddMember(ctx context.Context, workspaceID, userID string) (*models.WorkspaceMember, error)
+	RemoveMember(ctx context.Context, workspaceID, userID string) error
+	ListMembers(ctx context.Context, workspaceID string) ([]*models.WorkspaceMember, error)
+	// UserWorkspace returns workspace of user, nil for users of default workspace
+	UserWorkspace(ctx context.Context, userID string) (*models.Workspace, error)
+}
@@ -0,0 +1,9 @@
+package usecase
+
+import "errors"
+
+var (
+	ErrInvalidWorkspace  = errors.New("invalid workspace")
+	ErrWorkspaceNotFound = errors.New("workspace not found")
+	ErrMemberNotFound    = errors.New("user is not member of workspace")
+)
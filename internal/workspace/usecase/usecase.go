@@ -0,0 +1,221 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/config"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/workspace"
+	"github.com/MatiXxD/url-shortener/pkg/cache"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/google/uuid"
+)
+
+const (
+	maxNameLength = 100
+	maxDomains    = 100
+
+	// membersTTL is how long workspace of user is cached, other instances may change it
+	membersTTL = time.Minute
+)
+
+type WorkspaceUsecase struct {
+	repo   workspace.Repository
+	logger *logger.Logger
+	now    func() time.Time
+	// members caches workspace id of user, empty for default workspace
+	members    *cache.LRU[string, string]
+	workspaces *cache.LRU[string, *models.Workspace]
+}
+
+func NewWorkspaceUsecase(r workspace.Repository, cfg *config.ServiceConfig, l *logger.Logger) *WorkspaceUsecase {
+	return &WorkspaceUsecase{
+		repo:       r,
+		logger:     l,
+		now:        time.Now,
+		members:    cache.NewLRU[string, string](cfg.Cache.Size, membersTTL),
+		workspaces: cache.NewLRU[string, *models.Workspace](cfg.Cache.Size, membersTTL),
+	}
+}
+
+func (wu *WorkspaceUsecase) AddWorkspace(ctx context.Context, req *models.WorkspaceReqBody) (*models.Workspace, error) {
+	ws, err := newWorkspace(req)
+	if err != nil {
+		return nil, err
+	}
+	ws.ID = uuid.New().String()
+	ws.CreatedAt = wu.now().UTC()
+
+	if err := wu.repo.AddWorkspace(ctx, ws); err != nil {
+		wu.logger.Errorf("cannot add workspace: %v", err)
+		return nil, fmt.Errorf("cannot add workspace: %w", err)
+	}
+
+	return ws, nil
+}
+
+func (wu *WorkspaceUsecase) ListWorkspaces(ctx context.Context) ([]*models.Workspace, error) {
+	workspaces, err := wu.repo.ListWorkspaces(ctx)
+	if err != nil {
+		wu.logger.Errorf("cannot list workspaces: %v", err)
+		return nil, fmt.Errorf("cannot list workspaces: %w", err)
+	}
+
+	return workspaces, nil
+}
+
+// UpdateWorkspace replaces name and settings, links created before keep their defaults
+func (wu *WorkspaceUsecase) UpdateWorkspace(ctx context.Context, id string, req *models.WorkspaceReqBody) (*models.Workspace, error) {
+	ws, err := newWorkspace(req)
+	if err != nil {
+		return nil, err
+	}
+	ws.ID = id
+
+	err = wu.repo.UpdateWorkspace(ctx, ws)
+	if errors.Is(err, workspace.ErrNotFound) {
+		return nil, ErrWorkspaceNotFound
+	}
+	if err != nil {
+		wu.logger.Errorf("cannot update workspace=%s: %v", id, err)
+		return nil, fmt.Errorf("cannot update workspace: %w", err)
+	}
+	wu.workspaces.Delete(id)
+
+	return ws, nil
+}
+
+func (wu *WorkspaceUsecase) HasWorkspace(ctx context.Context, id string) (bool, error) {
+	_, err := wu.repo.GetWorkspace(ctx, id)
+	if errors.Is(err, workspace.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		wu.logger.Errorf("cannot get workspace=%s: %v", id, err)
+		return false, fmt.Errorf("cannot get workspace: %w", err)
+	}
+
+	return true, nil
+}
+
+// AddMember moves user to workspace, links user created before stay in the previous one
+func (wu *WorkspaceUsecase) AddMember(ctx context.Context, workspaceID, userID string) (*models.WorkspaceMember, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("%w: user id is required", ErrInvalidWorkspace)
+	}
+
+	m := &models.WorkspaceMember{
+		WorkspaceID: workspaceID,
+		UserID:      userID,
+		AddedAt:     wu.now().UTC(),
+	}
+	err := wu.repo.AddMember(ctx, m)
+	if errors.Is(err, workspace.ErrNotFound) {
+		return nil, ErrWorkspaceNotFound
+	}
+	if err != nil {
+		wu.logger.Errorf("cannot add user=%s to workspace=%s: %v", userID, workspaceID, err)
+		return nil, fmt.Errorf("cannot add workspace member: %w", err)
+	}
+	wu.members.Delete(userID)
+
+	return m, nil
+}
+
+// RemoveMember returns user to default workspace
+func (wu *WorkspaceUsecase) RemoveMember(ctx context.Context, workspaceID, userID string) error {
+	err := wu.repo.RemoveMember(ctx, workspaceID, userID)
+	if errors.Is(err, workspace.ErrNotFound) {
+		return ErrMemberNotFound
+	}
+	if err != nil {
+		wu.logger.Errorf("cannot remove user=%s from workspace=%s: %v", userID, workspaceID, err)
+		return fmt.Errorf("cannot remove workspace member: %w", err)
+	}
+	wu.members.Delete(userID)
+
+	return nil
+}
+
+func (wu *WorkspaceUsecase) ListMembers(ctx context.Context, workspaceID string) ([]*models.WorkspaceMember, error) {
+	members, err := wu.repo.ListMembers(ctx, workspaceID)
+	if errors.Is(err, workspace.ErrNotFound) {
+		return nil, ErrWorkspaceNotFound
+	}
+	if err != nil {
+		wu.logger.Errorf("cannot list members of workspace=%s: %v", workspaceID, err)
+		return nil, fmt.Errorf("cannot list workspace members: %w", err)
+	}
+
+	return members, nil
+}
+
+// UserWorkspace is asked on every request, so membership and workspaces are cached for a while
+func (wu *WorkspaceUsecase) UserWorkspace(ctx context.Context, userID string) (*models.Workspace, error) {
+	if id, ok := wu.members.Get(userID); ok {
+		if id == "" {
+			return nil, nil
+		}
+		if ws, ok := wu.workspaces.Get(id); ok {
+			return ws, nil
+		}
+	}
+
+	ws, err := wu.repo.UserWorkspace(ctx, userID)
+	if errors.Is(err, workspace.ErrNotFound) {
+		wu.members.Set(userID, "")
+		return nil, nil
+	}
+	if err != nil {
+		wu.logger.Errorf("cannot get workspace of user=%s: %v", userID, err)
+		return nil, fmt.Errorf("cannot get workspace of user: %w", err)
+	}
+	wu.members.Set(userID, ws.ID)
+	wu.workspaces.Set(ws.ID, ws)
+
+	return ws, nil
+}
+
+// newWorkspace validates request, domains are normalized like hosts of short domains
+func newWorkspace(req *models.WorkspaceReqBody) (*models.Workspace, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > maxNameLength {
+		return nil, fmt.Errorf("%w: name must have 1 to %d symbols", ErrInvalidWorkspace, maxNameLength)
+	}
+
+	s := req.Settings
+	if s.CodeLength < 0 || s.CodeLength > config.MaxCodeLength {
+		return nil, fmt.Errorf("%w: code length must be between 1 and %d", ErrInvalidWorkspace, config.MaxCodeLength)
+	}
+	if s.RedirectCode != 0 && !slices.Contains(config.RedirectCodes, s.RedirectCode) {
+		return nil, fmt.Errorf("%w: redirect code must be one of %v", ErrInvalidWorkspace, config.RedirectCodes)
+	}
+	if len(s.Domains) > maxDomains {
+		return nil, fmt.Errorf("%w: more than %d domains", ErrInvalidWorkspace, maxDomains)
+	}
+
+	var domains []string
+	for _, d := range s.Domains {
+		d = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(d)), ".")
+		if d == "" || strings.ContainsAny(d, "/?#@ ") {
+			return nil, fmt.Errorf("%w: domain must be host, got %q", ErrInvalidWorkspace, d)
+		}
+		if !slices.Contains(domains, d) {
+			domains = append(domains, d)
+		}
+	}
+
+	return &models.Workspace{
+		Name: name,
+		Settings: models.WorkspaceSettings{
+			CodeLength:   s.CodeLength,
+			RedirectCode: s.RedirectCode,
+			Domains:      domains,
+		},
+	}, nil
+}
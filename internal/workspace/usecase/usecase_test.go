@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/MatiXxD/url-shortener/config"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/workspace/repository"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var l *logger.Logger
+
+func TestMain(m *testing.M) {
+	zl, err := zap.NewDevelopment()
+	if err != nil {
+		panic(err)
+	}
+
+	l = &logger.Logger{SugaredLogger: zl.Sugar()}
+
+	os.Exit(m.Run())
+}
+
+func newTestUsecase(t *testing.T) *WorkspaceUsecase {
+	r, err := repository.NewFileRepository("", l)
+	require.NoError(t, err)
+	return NewWorkspaceUsecase(r, config.Default(), l)
+}
+
+func TestWorkspaceUsecase_AddWorkspace(t *testing.T) {
+	wu := newTestUsecase(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		req  models.WorkspaceReqBody
+		want models.WorkspaceSettings
+		err  error
+	}{
+		{
+			name: "defaults of service",
+			req:  models.WorkspaceReqBody{Name: "marketing"},
+		},
+		{
+			name: "domains are normalized",
+			req: models.WorkspaceReqBody{Name: "sales", Settings: models.WorkspaceSettings{
+				CodeLength:   6,
+				RedirectCode: 301,
+				Domains:      []string{"Go.Sales.com.", "go.sales.com", "localhost:8080"},
+			}},
+			want: models.WorkspaceSettings{CodeLength: 6, RedirectCode: 301, Domains: []string{"go.sales.com", "localhost:8080"}},
+		},
+		{
+			name: "empty name",
+			req:  models.WorkspaceReqBody{Name: " "},
+			err:  ErrInvalidWorkspace,
+		},
+		{
+			name: "too long code",
+			req:  models.WorkspaceReqBody{Name: "ops", Settings: models.WorkspaceSettings{CodeLength: 65}},
+			err:  ErrInvalidWorkspace,
+		},
+		{
+			name: "unknown redirect code",
+			req:  models.WorkspaceReqBody{Name: "ops", Settings: models.WorkspaceSettings{RedirectCode: 200}},
+			err:  ErrInvalidWorkspace,
+		},
+		{
+			name: "domain is url",
+			req:  models.WorkspaceReqBody{Name: "ops", Settings: models.WorkspaceSettings{Domains: []string{"https://go.ops.com/"}}},
+			err:  ErrInvalidWorkspace,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws, err := wu.AddWorkspace(ctx, &tt.req)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.NotEmpty(t, ws.ID)
+			require.Equal(t, tt.want, ws.Settings)
+		})
+	}
+}
+
+func TestWorkspaceUsecase_Members(t *testing.T) {
+	wu := newTestUsecase(t)
+	ctx := context.Background()
+
+	team, err := wu.AddWorkspace(ctx, &models.WorkspaceReqBody{Name: "team"})
+	require.NoError(t, err)
+
+	ws, err := wu.UserWorkspace(ctx, "u1")
+	require.NoError(t, err)
+	require.Nil(t, ws)
+
+	_, err = wu.AddMember(ctx, "missing", "u1")
+	require.ErrorIs(t, err, ErrWorkspaceNotFound)
+	_, err = wu.AddMember(ctx, team.ID, "")
+	require.ErrorIs(t, err, ErrInvalidWorkspace)
+
+	// cached membership is dropped when it changes
+	_, err = wu.AddMember(ctx, team.ID, "u1")
+	require.NoError(t, err)
+	ws, err = wu.UserWorkspace(ctx, "u1")
+	require.NoError(t, err)
+	require.Equal(t, team.ID, ws.ID)
+
+	t.Run("settings are updated", func(t *testing.T) {
+		_, err := wu.UpdateWorkspace(ctx, team.ID, &models.WorkspaceReqBody{Name: "team", Settings: models.WorkspaceSettings{CodeLength: 4}})
+		require.NoError(t, err)
+		ws, err := wu.UserWorkspace(ctx, "u1")
+		require.NoError(t, err)
+		require.Equal(t, 4, ws.Settings.CodeLength)
+
+		_, err = wu.UpdateWorkspace(ctx, "missing", &models.WorkspaceReqBody{Name: "team"})
+		require.ErrorIs(t, err, ErrWorkspaceNotFound)
+	})
+
+	members, err := wu.ListMembers(ctx, team.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+
+	require.ErrorIs(t, wu.RemoveMember(ctx, team.ID, "u2"), ErrMemberNotFound)
+	require.NoError(t, wu.RemoveMember(ctx, team.ID, "u1"))
+	ws, err = wu.UserWorkspace(ctx, "u1")
+	require.NoError(t, err)
+	require.Nil(t, ws)
+
+	ok, err := wu.HasWorkspace(ctx, team.ID)
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, err = wu.HasWorkspace(ctx, "missing")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE url ADD COLUMN IF NOT EXISTS user_id TEXT NOT NULL DEFAULT '';
+UPDATE url SET is_deleted = FALSE WHERE is_deleted IS NULL;
+ALTER TABLE url ALTER COLUMN is_deleted SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_url_user_id ON url (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_url_user_id;
+
+ALTER TABLE url ALTER COLUMN is_deleted DROP NOT NULL;
+ALTER TABLE url DROP COLUMN IF EXISTS user_id;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- 0 means service default redirect code
+ALTER TABLE url ADD COLUMN IF NOT EXISTS redirect_code SMALLINT NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE url DROP COLUMN IF EXISTS redirect_code;
+-- +goose StatementEnd
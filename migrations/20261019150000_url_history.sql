@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS url_history (
+  id SERIAL PRIMARY KEY,
+  short TEXT NOT NULL REFERENCES url (short) ON DELETE CASCADE,
+  previous_url TEXT NOT NULL,
+  new_url TEXT NOT NULL,
+  changed_by TEXT NOT NULL DEFAULT '',
+  changed_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_url_history_short ON url_history (short, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_url_history_short;
+
+DROP TABLE IF EXISTS url_history;
+-- +goose StatementEnd
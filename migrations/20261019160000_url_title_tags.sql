@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE url ADD COLUMN IF NOT EXISTS title TEXT NOT NULL DEFAULT '';
+ALTER TABLE url ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+-- keyset pagination of user links
+CREATE INDEX IF NOT EXISTS idx_url_user_created ON url (user_id, created_at, short);
+CREATE INDEX IF NOT EXISTS idx_url_tags ON url USING GIN (tags);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_url_tags;
+DROP INDEX IF EXISTS idx_url_user_created;
+
+ALTER TABLE url DROP COLUMN IF EXISTS tags;
+ALTER TABLE url DROP COLUMN IF EXISTS title;
+-- +goose StatementEnd
@@ -0,0 +1,48 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS short_domain (
+  host TEXT PRIMARY KEY,
+  base_url TEXT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE url ADD COLUMN IF NOT EXISTS short_domain TEXT NOT NULL DEFAULT '';
+ALTER TABLE url_history ADD COLUMN IF NOT EXISTS short_domain TEXT NOT NULL DEFAULT '';
+
+-- short and original urls become unique within short domain
+ALTER TABLE url_history DROP CONSTRAINT IF EXISTS url_history_short_fkey;
+ALTER TABLE url DROP CONSTRAINT IF EXISTS url_short_key;
+ALTER TABLE url DROP CONSTRAINT IF EXISTS url_original_key;
+DROP INDEX IF EXISTS idx_short_url;
+DROP INDEX IF EXISTS idx_original_url;
+
+ALTER TABLE url ADD CONSTRAINT url_domain_short_key UNIQUE (short_domain, short);
+ALTER TABLE url ADD CONSTRAINT url_domain_original_key UNIQUE (short_domain, original);
+ALTER TABLE url_history ADD CONSTRAINT url_history_short_fkey
+  FOREIGN KEY (short_domain, short) REFERENCES url (short_domain, short) ON DELETE CASCADE;
+
+DROP INDEX IF EXISTS idx_url_history_short;
+CREATE INDEX IF NOT EXISTS idx_url_history_short ON url_history (short_domain, short, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_url_history_short;
+CREATE INDEX IF NOT EXISTS idx_url_history_short ON url_history (short, id);
+
+ALTER TABLE url_history DROP CONSTRAINT IF EXISTS url_history_short_fkey;
+ALTER TABLE url DROP CONSTRAINT IF EXISTS url_domain_original_key;
+ALTER TABLE url DROP CONSTRAINT IF EXISTS url_domain_short_key;
+
+ALTER TABLE url ADD CONSTRAINT url_short_key UNIQUE (short);
+ALTER TABLE url ADD CONSTRAINT url_original_key UNIQUE (original);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_short_url ON url (short);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_original_url ON url (original);
+ALTER TABLE url_history ADD CONSTRAINT url_history_short_fkey
+  FOREIGN KEY (short) REFERENCES url (short) ON DELETE CASCADE;
+
+ALTER TABLE url_history DROP COLUMN IF EXISTS short_domain;
+ALTER TABLE url DROP COLUMN IF EXISTS short_domain;
+
+DROP TABLE IF EXISTS short_domain;
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+-- records are ordered by id of writing transaction
+CREATE TABLE IF NOT EXISTS outbox (
+  id BIGSERIAL PRIMARY KEY,
+  tx_id BIGINT NOT NULL DEFAULT pg_current_xact_id()::text::bigint,
+  event_id TEXT NOT NULL,
+  event_type TEXT NOT NULL,
+  payload BYTEA NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_tx ON outbox (tx_id, id);
+
+-- tx_id and record_id are key of the last outbox record consumer handled, position is its offset.
+-- Relay holds consumer until locked_until while it sends the batch.
+CREATE TABLE IF NOT EXISTS outbox_offset (
+  consumer TEXT PRIMARY KEY,
+  tx_id BIGINT NOT NULL DEFAULT 0,
+  record_id BIGINT NOT NULL DEFAULT 0,
+  position BIGINT NOT NULL DEFAULT 0,
+  locked_until TIMESTAMPTZ,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- relay may hand event to webhooks again after crash, it is queued once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_delivery_event ON webhook_delivery (webhook_id, event_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_webhook_delivery_event;
+DROP TABLE IF EXISTS outbox_offset;
+DROP TABLE IF EXISTS outbox;
+-- +goose StatementEnd
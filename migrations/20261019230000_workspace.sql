@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS workspace (
+  id TEXT PRIMARY KEY,
+  name TEXT NOT NULL,
+  code_length INTEGER NOT NULL DEFAULT 0,
+  redirect_code SMALLINT NOT NULL DEFAULT 0,
+  domains TEXT[] NOT NULL DEFAULT '{}',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- user is member of one workspace at most
+CREATE TABLE IF NOT EXISTS workspace_member (
+  user_id TEXT PRIMARY KEY,
+  workspace_id TEXT NOT NULL REFERENCES workspace (id),
+  added_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_workspace_member_workspace ON workspace_member (workspace_id, added_at);
+
+-- empty workspace is the default one, it has no row
+ALTER TABLE url ADD COLUMN IF NOT EXISTS workspace TEXT NOT NULL DEFAULT '';
+ALTER TABLE short_domain ADD COLUMN IF NOT EXISTS workspace TEXT NOT NULL DEFAULT '';
+
+-- original url becomes unique within workspace, short url stays unique within short domain
+ALTER TABLE url DROP CONSTRAINT IF EXISTS url_domain_original_key;
+ALTER TABLE url ADD CONSTRAINT url_workspace_original_key UNIQUE (workspace, short_domain, original);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE url DROP CONSTRAINT IF EXISTS url_workspace_original_key;
+ALTER TABLE url ADD CONSTRAINT url_domain_original_key UNIQUE (short_domain, original);
+
+ALTER TABLE short_domain DROP COLUMN IF EXISTS workspace;
+ALTER TABLE url DROP COLUMN IF EXISTS workspace;
+
+DROP TABLE IF EXISTS workspace_member;
+DROP TABLE IF EXISTS workspace;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- counters without expires_at are never dropped
+CREATE TABLE IF NOT EXISTS quota_counter (
+  key TEXT PRIMARY KEY,
+  value BIGINT NOT NULL DEFAULT 0,
+  expires_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_quota_counter_expires_at ON quota_counter (expires_at) WHERE expires_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS quota_counter;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- deleted url doesn't hold its original, the original can be shortened again with new short url
+ALTER TABLE url DROP CONSTRAINT IF EXISTS url_workspace_original_key;
+CREATE UNIQUE INDEX IF NOT EXISTS url_workspace_original_active_key ON url (workspace, short_domain, original) WHERE NOT is_deleted;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- fails if original was shortened again after deletion
+DROP INDEX IF EXISTS url_workspace_original_active_key;
+ALTER TABLE url ADD CONSTRAINT url_workspace_original_key UNIQUE (workspace, short_domain, original);
+-- +goose StatementEnd
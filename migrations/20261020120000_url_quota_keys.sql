@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- counters of active links quotas link is counted in, links created before quotas are not counted
+ALTER TABLE url ADD COLUMN IF NOT EXISTS quota_keys TEXT[] NOT NULL DEFAULT '{}';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE url DROP COLUMN IF EXISTS quota_keys;
+-- +goose StatementEnd
@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRU(t *testing.T) {
+	t.Run("evicts least recently used", func(t *testing.T) {
+		c := NewLRU[string, int](2, 0)
+		c.Set("a", 1)
+		c.Set("b", 2)
+
+		_, ok := c.Get("a")
+		require.True(t, ok)
+
+		c.Set("c", 3)
+		_, ok = c.Get("b")
+		require.False(t, ok)
+		require.Equal(t, 2, c.Len())
+	})
+
+	t.Run("expires entries", func(t *testing.T) {
+		now := time.Now()
+		c := NewLRU[string, int](2, time.Minute)
+		c.now = func() time.Time { return now }
+
+		c.Set("a", 1)
+		v, ok := c.Get("a")
+		require.True(t, ok)
+		require.Equal(t, 1, v)
+
+		now = now.Add(2 * time.Minute)
+		_, ok = c.Get("a")
+		require.False(t, ok)
+		require.Equal(t, 0, c.Len())
+	})
+
+	t.Run("zero size disables cache", func(t *testing.T) {
+		c := NewLRU[string, int](0, 0)
+		c.Set("a", 1)
+
+		_, ok := c.Get("a")
+		require.False(t, ok)
+	})
+}
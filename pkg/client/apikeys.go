@@ -0,0 +1,40 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	neturl "net/url"
+)
+
+// ListAPIKeys returns keys without secrets, revoked ones too. Server allows it only with Config.AdminToken.
+func (c *Client) ListAPIKeys(ctx context.Context) ([]*APIKey, error) {
+	var keys []*APIKey
+	if err := c.doJSON(ctx, &request{method: http.MethodGet, path: "/api/keys"}, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// AddAPIKey creates key, its Key is returned only here, so it must be kept by caller
+func (c *Client) AddAPIKey(ctx context.Context, req *AddAPIKeyRequest) (*APIKey, error) {
+	r, err := c.jsonRequest(http.MethodPost, "/api/keys", req)
+	if err != nil {
+		return nil, err
+	}
+	r.okCodes = []int{http.StatusCreated}
+
+	var k APIKey
+	if err := c.doJSON(ctx, r, &k); err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// RevokeAPIKey stops key, requests with it are rejected right away
+func (c *Client) RevokeAPIKey(ctx context.Context, id string) error {
+	return c.doJSON(ctx, &request{
+		method:  http.MethodDelete,
+		path:    "/api/keys/" + neturl.PathEscape(id),
+		okCodes: []int{http.StatusNoContent},
+	}, nil)
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"time"
+)
+
+// AuditOptions filter audit log, zero fields match everything
+type AuditOptions struct {
+	UserID   string
+	APIKeyID string
+	IP       string
+	// Action is one of Audit* actions
+	Action string
+	// ShortDomain is custom domain of links, empty one means all domains
+	ShortDomain string
+	Code        string
+	RequestID   string
+	From        time.Time
+	To          time.Time
+	Limit       int
+	Cursor      string
+}
+
+func (o *AuditOptions) query() neturl.Values {
+	q := neturl.Values{}
+	if o == nil {
+		return q
+	}
+
+	for name, v := range map[string]string{
+		"user_id":      o.UserID,
+		"api_key_id":   o.APIKeyID,
+		"ip":           o.IP,
+		"action":       o.Action,
+		"short_domain": o.ShortDomain,
+		"code":         o.Code,
+		"request_id":   o.RequestID,
+		"cursor":       o.Cursor,
+	} {
+		if v != "" {
+			q.Set(name, v)
+		}
+	}
+	if !o.From.IsZero() {
+		q.Set("from", o.From.Format(time.RFC3339))
+	}
+	if !o.To.IsZero() {
+		q.Set("to", o.To.Format(time.RFC3339))
+	}
+	if o.Limit != 0 {
+		q.Set("limit", strconv.Itoa(o.Limit))
+	}
+	return q
+}
+
+// Audit returns page of audit log newest first, server allows it only with Config.AdminToken
+func (c *Client) Audit(ctx context.Context, opts *AuditOptions) (*AuditPage, error) {
+	var page AuditPage
+	if err := c.doJSON(ctx, &request{method: http.MethodGet, path: "/api/audit", query: opts.query()}, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
@@ -0,0 +1,345 @@
+// Package client is typed Go client of url shortener http api.
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries request id to server and back
+const RequestIDHeader = "X-Request-ID"
+
+// AdminTokenHeader carries admin token of server
+const AdminTokenHeader = "X-Admin-Token"
+
+const (
+	defaultBaseURL    = "http://localhost:8080"
+	defaultCookieName = "token"
+	defaultTimeout    = 30 * time.Second
+
+	defaultMaxRetries = 3
+	defaultMinBackoff = 100 * time.Millisecond
+	defaultMaxBackoff = 5 * time.Second
+
+	// maxErrorBody is how much of error response is kept in Error
+	maxErrorBody = 4096
+)
+
+type Config struct {
+	BaseURL string
+	// HTTPClient is used for requests, its redirect policy is replaced to not follow short links
+	HTTPClient *http.Client
+	// Timeout of one attempt, used when HTTPClient is nil
+	Timeout time.Duration
+	// Token is auth cookie value, empty token is taken from the first response
+	Token      string
+	CookieName string
+	// APIKey is sent as bearer token instead of cookie, client acts as user of key
+	APIKey string
+	// AdminToken is sent with every request, server requires it for management of api keys,
+	// webhooks and workspaces and for audit log
+	AdminToken string
+	// Gzip compresses request bodies
+	Gzip  bool
+	Retry RetryConfig
+}
+
+// RetryConfig controls retries of requests failed with 5xx or 429
+type RetryConfig struct {
+	// MaxRetries is number of retries after the first attempt, zero disables retries
+	MaxRetries int
+	// MinBackoff doubles with every retry up to MaxBackoff, Retry-After of response takes precedence
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		BaseURL:    defaultBaseURL,
+		Timeout:    defaultTimeout,
+		CookieName: defaultCookieName,
+		Retry: RetryConfig{
+			MaxRetries: defaultMaxRetries,
+			MinBackoff: defaultMinBackoff,
+			MaxBackoff: defaultMaxBackoff,
+		},
+	}
+}
+
+// Client is safe for concurrent use
+type Client struct {
+	baseURL    string
+	http       *http.Client
+	cookieName string
+	gzip       bool
+	retry      RetryConfig
+	apiKey     string
+	adminToken string
+
+	mu    sync.RWMutex
+	token string
+}
+
+func New(cfg Config) (*Client, error) {
+	u, err := neturl.Parse(cfg.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid base url: scheme must be http or https, got %q", u.Scheme)
+	}
+	if cfg.Retry.MaxRetries < 0 || cfg.Retry.MinBackoff < 0 || cfg.Retry.MaxBackoff < cfg.Retry.MinBackoff {
+		return nil, errors.New("invalid retry config")
+	}
+
+	hc := &http.Client{Timeout: cfg.Timeout}
+	if cfg.HTTPClient != nil {
+		copied := *cfg.HTTPClient
+		hc = &copied
+	}
+	// redirects of short links are results, not something to follow
+	hc.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	cookieName := cfg.CookieName
+	if cookieName == "" {
+		cookieName = defaultCookieName
+	}
+
+	return &Client{
+		baseURL:    strings.TrimSuffix(cfg.BaseURL, "/"),
+		http:       hc,
+		cookieName: cookieName,
+		gzip:       cfg.Gzip,
+		retry:      cfg.Retry,
+		apiKey:     cfg.APIKey,
+		adminToken: cfg.AdminToken,
+		token:      cfg.Token,
+	}, nil
+}
+
+// Token returns auth token client acts with, links are owned by its user
+func (c *Client) Token() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.token
+}
+
+type ctxKeyRequestID struct{}
+
+// WithRequestID makes requests sent with ctx carry id, otherwise every call gets fresh one
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKeyRequestID{}, id)
+}
+
+func requestID(ctx context.Context) string {
+	if id, ok := ctx.Value(ctxKeyRequestID{}).(string); ok && id != "" {
+		return id
+	}
+	return uuid.New().String()
+}
+
+// request describes api call, the same request is sent again on retry
+type request struct {
+	method      string
+	path        string
+	query       neturl.Values
+	header      http.Header
+	body        []byte
+	contentType string
+	// okCodes are successful statuses, 200 when empty
+	okCodes []int
+}
+
+func (c *Client) jsonRequest(method, path string, body any) (*request, error) {
+	req := &request{method: method, path: path}
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode request: %w", err)
+		}
+		req.body, req.contentType = data, "application/json"
+	}
+	return req, nil
+}
+
+// doJSON sends request and decodes json response into out
+func (c *Client) doJSON(ctx context.Context, req *request, out any) error {
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("cannot decode response: %w", err)
+	}
+	return nil
+}
+
+// do sends request with retries, successful response body must be closed by caller
+func (c *Client) do(ctx context.Context, req *request) (*http.Response, error) {
+	body := req.body
+	if c.gzip && body != nil {
+		var err error
+		if body, err = compress(body); err != nil {
+			return nil, err
+		}
+	}
+
+	reqID := requestID(ctx)
+	for attempt := 0; ; attempt++ {
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+
+		resp, err := c.send(ctx, req, reader, reqID)
+		if err != nil {
+			return nil, err
+		}
+
+		if isOK(resp.StatusCode, req.okCodes) {
+			return resp, nil
+		}
+
+		apiErr := newError(resp)
+		resp.Body.Close()
+		// quota is not freed in time of retries
+		if attempt >= c.retry.MaxRetries || !retryable(resp.StatusCode) || apiErr.Quota() != nil {
+			return nil, apiErr
+		}
+
+		t := time.NewTimer(c.backoff(attempt, resp.Header.Get("Retry-After")))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, errors.Join(ctx.Err(), apiErr)
+		case <-t.C:
+		}
+	}
+}
+
+// send makes one attempt of request, body is already compressed
+func (c *Client) send(ctx context.Context, req *request, body io.Reader, reqID string) (*http.Response, error) {
+	target := req.path
+	// full short urls are requested as is
+	if !strings.Contains(target, "://") {
+		target = c.baseURL + target
+	}
+	if len(req.query) != 0 {
+		target += "?" + req.query.Encode()
+	}
+
+	r, err := http.NewRequestWithContext(ctx, req.method, target, body)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create request: %w", err)
+	}
+
+	for k, v := range req.header {
+		r.Header[k] = v
+	}
+	if req.contentType != "" {
+		r.Header.Set("Content-Type", req.contentType)
+	}
+	if c.gzip && body != nil {
+		r.Header.Set("Content-Encoding", "gzip")
+	}
+	r.Header.Set(RequestIDHeader, reqID)
+	if c.adminToken != "" {
+		r.Header.Set(AdminTokenHeader, c.adminToken)
+	}
+	if c.apiKey != "" {
+		r.Header.Set("Authorization", "Bearer "+c.apiKey)
+	} else if token := c.Token(); token != "" {
+		r.AddCookie(&http.Cookie{Name: c.cookieName, Value: token})
+	}
+
+	resp, err := c.http.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	c.keepToken(resp)
+
+	return resp, nil
+}
+
+// keepToken remembers token server issued to new user
+func (c *Client) keepToken(resp *http.Response) {
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name != c.cookieName || cookie.Value == "" {
+			continue
+		}
+		c.mu.Lock()
+		if c.token == "" {
+			c.token = cookie.Value
+		}
+		c.mu.Unlock()
+	}
+}
+
+func (c *Client) backoff(attempt int, retryAfter string) time.Duration {
+	if secs, err := strconv.Atoi(retryAfter); err == nil && secs >= 0 {
+		return min(time.Duration(secs)*time.Second, c.retry.MaxBackoff)
+	}
+
+	d := c.retry.MinBackoff << attempt
+	if d <= 0 || d > c.retry.MaxBackoff {
+		d = c.retry.MaxBackoff
+	}
+	// jitter keeps clients from retrying in lockstep
+	if d > 0 {
+		d = d/2 + rand.N(d/2+1)
+	}
+	return d
+}
+
+func retryable(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+func isOK(code int, okCodes []int) bool {
+	if len(okCodes) == 0 {
+		return code == http.StatusOK
+	}
+	for _, c := range okCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+func newGzipWriter(w io.Writer) *gzip.Writer {
+	return gzip.NewWriter(w)
+}
+
+func compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	gw := newGzipWriter(&buf)
+	if _, err := gw.Write(data); err != nil {
+		return nil, fmt.Errorf("cannot compress request: %w", err)
+	}
+	if err := gw.Close(); err != nil {
+		return nil, fmt.Errorf("cannot compress request: %w", err)
+	}
+	return buf.Bytes(), nil
+}
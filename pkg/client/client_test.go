@@ -0,0 +1,679 @@
+package client
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/config"
+	apikeyhandlers "github.com/MatiXxD/url-shortener/internal/apikey/handlers"
+	apikeyrepository "github.com/MatiXxD/url-shortener/internal/apikey/repository"
+	apikeyusecase "github.com/MatiXxD/url-shortener/internal/apikey/usecase"
+	audithandlers "github.com/MatiXxD/url-shortener/internal/audit/handlers"
+	auditusecase "github.com/MatiXxD/url-shortener/internal/audit/usecase"
+	mw "github.com/MatiXxD/url-shortener/internal/middleware"
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/MatiXxD/url-shortener/internal/outbox"
+	quotahandlers "github.com/MatiXxD/url-shortener/internal/quota/handlers"
+	quotarepository "github.com/MatiXxD/url-shortener/internal/quota/repository"
+	quotausecase "github.com/MatiXxD/url-shortener/internal/quota/usecase"
+	"github.com/MatiXxD/url-shortener/internal/url/handlers"
+	"github.com/MatiXxD/url-shortener/internal/url/repository"
+	"github.com/MatiXxD/url-shortener/internal/url/usecase"
+	webhookhandlers "github.com/MatiXxD/url-shortener/internal/webhook/handlers"
+	webhookrepository "github.com/MatiXxD/url-shortener/internal/webhook/repository"
+	webhookusecase "github.com/MatiXxD/url-shortener/internal/webhook/usecase"
+	workspacehandlers "github.com/MatiXxD/url-shortener/internal/workspace/handlers"
+	workspacerepository "github.com/MatiXxD/url-shortener/internal/workspace/repository"
+	workspaceusecase "github.com/MatiXxD/url-shortener/internal/workspace/usecase"
+	"github.com/MatiXxD/url-shortener/pkg/logger"
+	"github.com/MatiXxD/url-shortener/pkg/webhook"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// testAdminToken is admin token of test server
+const testAdminToken = "admin-token"
+
+func newTestClient(t *testing.T, url string, modify func(cfg *Config)) *Client {
+	t.Helper()
+
+	cfg := DefaultConfig()
+	cfg.BaseURL = url
+	cfg.Retry.MinBackoff = time.Millisecond
+	cfg.Retry.MaxBackoff = 10 * time.Millisecond
+	if modify != nil {
+		modify(&cfg)
+	}
+
+	c, err := New(cfg)
+	require.NoError(t, err)
+	return c
+}
+
+func TestClient_retry(t *testing.T) {
+	tests := []struct {
+		name       string
+		statuses   []int
+		maxRetries int
+		wantErr    error
+		wantCalls  int
+	}{
+		{
+			name:       "retried until success",
+			statuses:   []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK},
+			maxRetries: 3,
+			wantCalls:  3,
+		},
+		{
+			name:       "retries exhausted",
+			statuses:   []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway},
+			maxRetries: 2,
+			wantErr:    ErrServer,
+			wantCalls:  3,
+		},
+		{
+			name:       "client error is not retried",
+			statuses:   []int{http.StatusConflict, http.StatusOK},
+			maxRetries: 3,
+			wantErr:    ErrConflict,
+			wantCalls:  1,
+		},
+		{
+			name:       "retries disabled",
+			statuses:   []int{http.StatusTooManyRequests, http.StatusOK},
+			maxRetries: 0,
+			wantErr:    ErrTooManyRequests,
+			wantCalls:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			var bodies, ids []string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(calls.Add(1)) - 1
+				body, _ := io.ReadAll(r.Body)
+				bodies = append(bodies, string(body))
+				ids = append(ids, r.Header.Get(RequestIDHeader))
+
+				if code := tt.statuses[n]; code != http.StatusOK {
+					w.Header().Set("Retry-After", "0")
+					http.Error(w, "try later", code)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"short_url": "http://localhost/abc"}`)
+			}))
+			defer ts.Close()
+
+			c := newTestClient(t, ts.URL, func(cfg *Config) { cfg.Retry.MaxRetries = tt.maxRetries })
+			res, err := c.Shorten(context.Background(), &ShortenRequest{URL: "https://a.com"})
+			require.Equal(t, tt.wantCalls, int(calls.Load()))
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "http://localhost/abc", res.ShortURL)
+
+			// retries send the same body and request id
+			for i := range bodies {
+				require.Equal(t, bodies[0], bodies[i])
+				require.Equal(t, ids[0], ids[i])
+			}
+		})
+	}
+}
+
+func TestClient_backoff(t *testing.T) {
+	c := newTestClient(t, "http://localhost", func(cfg *Config) {
+		cfg.Retry.MinBackoff = 100 * time.Millisecond
+		cfg.Retry.MaxBackoff = time.Second
+	})
+
+	for attempt, max := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second} {
+		d := c.backoff(attempt, "")
+		require.GreaterOrEqual(t, d, max/2)
+		require.LessOrEqual(t, d, max)
+	}
+
+	require.Equal(t, 0*time.Second, c.backoff(0, "0"))
+	require.Equal(t, time.Second, c.backoff(0, "30"))
+}
+
+func TestClient_errors(t *testing.T) {
+	ts := httptest.NewServer(mw.RequestIdMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gone":
+			http.Error(w, "Url was deleted", http.StatusGone)
+		default:
+			http.Error(w, "Can't find url", http.StatusNotFound)
+		}
+	})))
+	defer ts.Close()
+
+	c := newTestClient(t, ts.URL, nil)
+	reqID := uuid.New().String()
+	ctx := WithRequestID(context.Background(), reqID)
+
+	_, err := c.Resolve(ctx, "missing", nil)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	require.Equal(t, "Can't find url", apiErr.Message)
+	require.Equal(t, reqID, apiErr.RequestID)
+
+	_, err = c.Resolve(ctx, "gone", nil)
+	require.ErrorIs(t, err, ErrGone)
+	require.False(t, errors.Is(err, ErrNotFound))
+}
+
+func TestClient_gzip(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+		gr, err := gzip.NewReader(r.Body)
+		require.NoError(t, err)
+
+		var req ShortenRequest
+		require.NoError(t, json.NewDecoder(gr).Decode(&req))
+		require.Equal(t, "https://a.com", req.URL)
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"short_url": "http://localhost/abc"}`)
+	}))
+	defer ts.Close()
+
+	c := newTestClient(t, ts.URL, func(cfg *Config) { cfg.Gzip = true })
+	_, err := c.Shorten(context.Background(), &ShortenRequest{URL: "https://a.com"})
+	require.NoError(t, err)
+}
+
+func runTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	l := &logger.Logger{SugaredLogger: zap.NewNop().Sugar()}
+
+	ts := httptest.NewUnstartedServer(nil)
+	cfg := config.Default()
+	cfg.BaseURL = "http://" + ts.Listener.Addr().String()
+	cfg.Outbox.PollInterval = config.Duration(10 * time.Millisecond)
+	cfg.Quotas.Workspace = config.QuotaLimits{LinksPerDay: 3, ActiveLinks: 2, BatchSize: 2}
+
+	wr, err := webhookrepository.NewFileRepository("", l)
+	require.NoError(t, err)
+	wu := webhookusecase.NewWebhookUsecase(wr, cfg, l)
+	wh := webhookhandlers.NewWebhookHandler(wu, l)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go wu.Run(ctx)
+
+	r := repository.NewMapRepository(map[string]*models.URL{}, l)
+	u := usecase.NewUrlUsecase(r, cfg, l)
+	u.SetPublisher(wu)
+	au := auditusecase.NewAuditUsecase(r.Audit(), l)
+	ah := audithandlers.NewAuditHandler(au, l)
+	kr, err := apikeyrepository.NewFileRepository("", l)
+	require.NoError(t, err)
+	kh := apikeyhandlers.NewAPIKeyHandler(apikeyusecase.NewAPIKeyUsecase(kr, cfg, l), l)
+	wsr, err := workspacerepository.NewFileRepository("", l)
+	require.NoError(t, err)
+	wsu := workspaceusecase.NewWorkspaceUsecase(wsr, cfg, l)
+	u.SetWorkspaces(wsu)
+	wsh := workspacehandlers.NewWorkspaceHandler(wsu, l)
+	qr, err := quotarepository.NewFileRepository("", l)
+	require.NoError(t, err)
+	qu := quotausecase.NewQuotaUsecase(qr, cfg, l)
+	u.SetQuotas(qu)
+	go u.RunClicks(ctx)
+	qh := quotahandlers.NewQuotaHandler(qu, l)
+	h := handlers.NewUrlHandler(u, cfg, l)
+	go outbox.NewRelay(r, cfg, l, outbox.NewPublisherSink("webhooks", wu)).Run(ctx)
+
+	mux := chi.NewRouter()
+	mux.Use(mw.RequestIdMiddleware)
+	mux.Use(mw.CompressMiddleware)
+	mux.Use(kh.Authenticate)
+	mux.Use(func(next http.Handler) http.Handler {
+		return mw.AuthMiddleware([]byte("secret"), cfg.Auth.CookieName, time.Hour, next)
+	})
+	mux.Use(wsh.Resolve)
+	mux.Use(mw.ActorMiddleware)
+	linksWrite := mux.With(func(next http.Handler) http.Handler {
+		return mw.ScopeMiddleware(models.ScopeLinksWrite, next)
+	})
+	linksRead := mux.With(func(next http.Handler) http.Handler {
+		return mw.ScopeMiddleware(models.ScopeLinksRead, next)
+	})
+	linksWrite.Post("/", h.ReduceURL)
+	mux.Get("/{url}", h.GetURL)
+	mux.Get("/{url}/qr", h.QRCode)
+	linksWrite.Post("/api/shorten", h.ShortenURL)
+	linksWrite.Post("/api/shorten/batch", h.BatchReduceURL)
+	linksRead.Get("/api/urls", h.ListURLs)
+	linksWrite.Delete("/api/urls/{url}", h.DeleteURL)
+	linksWrite.Patch("/api/urls/{url}", h.UpdateURL)
+	linksRead.Get("/api/urls/{url}/history", h.History)
+	linksWrite.Post("/api/urls/{url}/rollback", h.Rollback)
+	mux.Get("/api/domains", h.ListDomains)
+	mux.Post("/api/domains", h.AddDomain)
+	mux.Delete("/api/domains/{host}", h.DeleteDomain)
+	admin := mux.With(func(next http.Handler) http.Handler {
+		return mw.AdminMiddleware(testAdminToken, next)
+	})
+	admin.Get("/api/audit", ah.ListEntries)
+	admin.Get("/api/keys", kh.ListAPIKeys)
+	admin.Post("/api/keys", kh.AddAPIKey)
+	admin.Delete("/api/keys/{id}", kh.RevokeAPIKey)
+	admin.Get("/api/webhooks", wh.ListWebhooks)
+	admin.Post("/api/webhooks", wh.AddWebhook)
+	admin.Delete("/api/webhooks/{id}", wh.DeleteWebhook)
+	admin.Get("/api/webhooks/deliveries", wh.ListDeliveries)
+	admin.Post("/api/webhooks/deliveries/{id}/retry", wh.RetryDelivery)
+	admin.Get("/api/workspaces", wsh.ListWorkspaces)
+	admin.Post("/api/workspaces", wsh.AddWorkspace)
+	admin.Put("/api/workspaces/{id}", wsh.UpdateWorkspace)
+	admin.Get("/api/workspaces/{id}/members", wsh.ListMembers)
+	admin.Put("/api/workspaces/{id}/members/{user}", wsh.AddMember)
+	admin.Delete("/api/workspaces/{id}/members/{user}", wsh.RemoveMember)
+	linksRead.Get("/api/quota", qh.Usage)
+
+	ts.Config.Handler = mux
+	ts.Start()
+	t.Cleanup(ts.Close)
+
+	return ts
+}
+
+func TestClient_api(t *testing.T) {
+	ts := runTestServer(t)
+	c := newTestClient(t, ts.URL, func(cfg *Config) { cfg.Gzip = true })
+	ctx := context.Background()
+
+	res, err := c.Shorten(ctx, &ShortenRequest{URL: "https://a.com", Tags: []string{"sdk"}})
+	require.NoError(t, err)
+	require.NotEmpty(t, c.Token())
+	code := strings.TrimPrefix(res.ShortURL, ts.URL+"/")
+
+	short, err := c.ShortenText(ctx, "https://text.com")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(short, ts.URL+"/"))
+
+	resolved, err := c.Resolve(ctx, res.ShortURL, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusTemporaryRedirect, resolved.StatusCode)
+	require.Equal(t, "https://a.com", resolved.Location)
+
+	img, err := c.QRCode(ctx, code, &QROptions{Format: "svg", Size: 128})
+	require.NoError(t, err)
+	require.Contains(t, string(img), "<svg")
+
+	items, err := c.ShortenBatch(ctx, []*BatchItem{
+		{CorrelationID: "1", OriginURL: "https://b.com"},
+		{CorrelationID: "2", OriginURL: ""},
+	}, false)
+	require.ErrorIs(t, err, ErrPartialBatch)
+	require.Len(t, items, 2)
+	require.Equal(t, BatchStatusCreated, items[0].Status)
+	require.Equal(t, BatchStatusInvalid, items[1].Status)
+
+	_, err = c.ShortenBatch(ctx, []*BatchItem{
+		{CorrelationID: "1", OriginURL: "https://c.com"},
+		{CorrelationID: "2", OriginURL: ""},
+	}, true)
+	require.ErrorIs(t, err, ErrBatchRejected)
+
+	var streamed []string
+	err = c.ShortenStream(ctx, slices.Values([]*BatchItem{
+		{CorrelationID: "1", OriginURL: "https://d.com"},
+		{CorrelationID: "2", OriginURL: "https://e.com"},
+	}), func(item *BatchItem) error {
+		streamed = append(streamed, item.Status)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{BatchStatusCreated, BatchStatusCreated}, streamed)
+
+	link, err := c.UpdateURL(ctx, code, "https://a2.com", nil)
+	require.NoError(t, err)
+	require.Equal(t, "https://a2.com", link.OriginalURL)
+
+	history, err := c.History(ctx, code, nil)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+
+	link, err = c.Rollback(ctx, code, 0, nil)
+	require.NoError(t, err)
+	require.Equal(t, "https://a.com", link.OriginalURL)
+
+	page, err := c.ListURLs(ctx, &ListOptions{Tag: "sdk"})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+
+	var all []string
+	for l, err := range c.Links(ctx, &ListOptions{Limit: 2, Sort: SortOriginalURL}) {
+		require.NoError(t, err)
+		all = append(all, l.OriginalURL)
+	}
+	require.Equal(t, []string{"https://a.com", "https://b.com", "https://d.com", "https://e.com", "https://text.com"}, all)
+
+	require.NoError(t, c.DeleteURL(ctx, code, nil))
+	_, err = c.Resolve(ctx, code, nil)
+	require.ErrorIs(t, err, ErrGone)
+
+	other := newTestClient(t, ts.URL, nil)
+	err = other.DeleteURL(ctx, strings.TrimPrefix(short, ts.URL+"/"), nil)
+	require.ErrorIs(t, err, ErrForbidden)
+
+	domain, err := c.AddDomain(ctx, "https://go.example.com")
+	require.NoError(t, err)
+	require.Equal(t, "go.example.com", domain.Host)
+
+	_, err = c.AddDomain(ctx, "https://go.example.com")
+	require.ErrorIs(t, err, ErrConflict)
+
+	domains, err := c.ListDomains(ctx)
+	require.NoError(t, err)
+	require.Len(t, domains, 1)
+
+	require.NoError(t, c.DeleteDomain(ctx, "go.example.com"))
+	require.ErrorIs(t, c.DeleteDomain(ctx, "go.example.com"), ErrNotFound)
+}
+
+func TestClient_webhooks(t *testing.T) {
+	ts := runTestServer(t)
+	c := newTestClient(t, ts.URL, func(cfg *Config) { cfg.AdminToken = testAdminToken })
+	ctx := context.Background()
+
+	_, err := newTestClient(t, ts.URL, nil).ListWebhooks(ctx)
+	require.ErrorIs(t, err, ErrForbidden)
+
+	secret := "0123456789abcdef"
+	events := make(chan *Event, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := webhook.Verify(secret, body, r.Header.Get(webhook.SignatureHeader), webhook.DefaultTolerance); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		var e Event
+		if err := json.Unmarshal(body, &e); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		events <- &e
+	}))
+	defer receiver.Close()
+
+	hook, err := c.AddWebhook(ctx, receiver.URL, []string{EventLinkCreated, EventLinkClicked}, secret)
+	require.NoError(t, err)
+	require.Equal(t, secret, hook.Secret)
+
+	_, err = c.AddWebhook(ctx, receiver.URL, []string{"link.viewed"}, "")
+	require.ErrorIs(t, err, ErrBadRequest)
+
+	webhooks, err := c.ListWebhooks(ctx)
+	require.NoError(t, err)
+	require.Len(t, webhooks, 1)
+	require.Empty(t, webhooks[0].Secret)
+
+	res, err := c.Shorten(ctx, &ShortenRequest{URL: "https://hooked.com"})
+	require.NoError(t, err)
+	_, err = c.Resolve(ctx, res.ShortURL, nil)
+	require.NoError(t, err)
+
+	// link.created comes through outbox relay, so events may arrive in any order
+	received := make(map[string]*Event)
+	for range 2 {
+		select {
+		case e := <-events:
+			received[e.Type] = e
+		case <-time.After(5 * time.Second):
+			t.Fatalf("only %d events were delivered", len(received))
+		}
+	}
+	for _, typ := range []string{EventLinkCreated, EventLinkClicked} {
+		require.Contains(t, received, typ)
+		require.Equal(t, res.ShortURL, received[typ].Link.ShortURL)
+	}
+
+	require.Eventually(t, func() bool {
+		page, err := c.Deliveries(ctx, &DeliveryOptions{WebhookID: hook.ID, Status: DeliveryDelivered})
+		return err == nil && len(page.Items) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	page, err := c.Deliveries(ctx, &DeliveryOptions{Event: EventLinkCreated})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	d, err := c.RetryDelivery(ctx, page.Items[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, DeliveryPending, d.Status)
+	select {
+	case e := <-events:
+		require.Equal(t, EventLinkCreated, e.Type)
+	case <-time.After(5 * time.Second):
+		t.Fatal("retried delivery was not sent")
+	}
+
+	require.NoError(t, c.DeleteWebhook(ctx, hook.ID))
+	require.ErrorIs(t, c.DeleteWebhook(ctx, hook.ID), ErrNotFound)
+}
+
+func TestClient_audit(t *testing.T) {
+	ts := runTestServer(t)
+	c := newTestClient(t, ts.URL, nil)
+	ctx := context.Background()
+
+	res, err := c.Shorten(ctx, &ShortenRequest{URL: "https://audited.com"})
+	require.NoError(t, err)
+	code := strings.TrimPrefix(res.ShortURL, ts.URL+"/")
+
+	_, err = c.UpdateURL(ctx, code, "https://audited.com/v2", nil)
+	require.NoError(t, err)
+	require.NoError(t, c.DeleteURL(ctx, code, nil))
+
+	_, err = c.Audit(ctx, &AuditOptions{Code: code})
+	require.ErrorIs(t, err, ErrForbidden)
+
+	admin := newTestClient(t, ts.URL, func(cfg *Config) { cfg.AdminToken = testAdminToken })
+	page, err := admin.Audit(ctx, &AuditOptions{Code: code})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 3)
+
+	deleted, updated, created := page.Items[0], page.Items[1], page.Items[2]
+	require.Equal(t, AuditDelete, deleted.Action)
+	require.Equal(t, AuditUpdate, updated.Action)
+	require.Equal(t, "https://audited.com", updated.Before.OriginalURL)
+	require.Equal(t, "https://audited.com/v2", updated.After.OriginalURL)
+	require.Equal(t, AuditCreate, created.Action)
+	require.NotEmpty(t, created.Actor.UserID)
+	require.Equal(t, created.Actor.UserID, deleted.Actor.UserID)
+	require.NotEmpty(t, created.RequestID)
+
+	page, err = admin.Audit(ctx, &AuditOptions{Action: AuditUpdate, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	require.Empty(t, page.NextCursor)
+
+	_, err = admin.Audit(ctx, &AuditOptions{Action: "purge"})
+	require.ErrorIs(t, err, ErrBadRequest)
+}
+
+func TestClient_apiKeys(t *testing.T) {
+	ts := runTestServer(t)
+	admin := newTestClient(t, ts.URL, func(cfg *Config) { cfg.AdminToken = testAdminToken })
+	ctx := context.Background()
+
+	k, err := admin.AddAPIKey(ctx, &AddAPIKeyRequest{Name: "importer", Scopes: []string{ScopeLinksWrite}})
+	require.NoError(t, err)
+	require.NotEmpty(t, k.Key)
+
+	_, err = admin.AddAPIKey(ctx, &AddAPIKeyRequest{Name: "importer", Scopes: []string{"admin"}})
+	require.ErrorIs(t, err, ErrBadRequest)
+
+	job := newTestClient(t, ts.URL, func(cfg *Config) { cfg.APIKey = k.Key })
+	res, err := job.Shorten(ctx, &ShortenRequest{URL: "https://imported.com"})
+	require.NoError(t, err)
+	require.Empty(t, job.Token())
+
+	_, err = job.ListURLs(ctx, nil)
+	require.ErrorIs(t, err, ErrForbidden)
+	_, err = job.QuotaUsage(ctx)
+	require.ErrorIs(t, err, ErrForbidden)
+	_, err = job.ListAPIKeys(ctx)
+	require.ErrorIs(t, err, ErrForbidden)
+	_, err = newTestClient(t, ts.URL, nil).ListAPIKeys(ctx)
+	require.ErrorIs(t, err, ErrForbidden)
+
+	// link belongs to user of key
+	code := strings.TrimPrefix(res.ShortURL, ts.URL+"/")
+	_, err = job.UpdateURL(ctx, code, "https://imported.com/v2", nil)
+	require.NoError(t, err)
+
+	page, err := admin.Audit(ctx, &AuditOptions{APIKeyID: k.ID})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 2)
+	require.Equal(t, k.UserID, page.Items[0].Actor.UserID)
+
+	keys, err := admin.ListAPIKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.Empty(t, keys[0].Key)
+	require.NotNil(t, keys[0].LastUsedAt)
+
+	require.NoError(t, admin.RevokeAPIKey(ctx, k.ID))
+	_, err = job.Shorten(ctx, &ShortenRequest{URL: "https://imported.com/late"})
+	require.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestClient_workspaces(t *testing.T) {
+	ts := runTestServer(t)
+	admin := newTestClient(t, ts.URL, func(cfg *Config) { cfg.AdminToken = testAdminToken })
+	ctx := context.Background()
+
+	ws, err := admin.AddWorkspace(ctx, &WorkspaceRequest{Name: "team", Settings: WorkspaceSettings{CodeLength: 12}})
+	require.NoError(t, err)
+	_, err = admin.AddWorkspace(ctx, &WorkspaceRequest{})
+	require.ErrorIs(t, err, ErrBadRequest)
+
+	domain, err := admin.AddWorkspaceDomain(ctx, "https://go.team.com", ws.ID)
+	require.NoError(t, err)
+	require.Equal(t, ws.ID, domain.Workspace)
+
+	k, err := admin.AddAPIKey(ctx, &AddAPIKeyRequest{Name: "team", UserID: "member", Scopes: []string{ScopeLinksWrite, ScopeLinksRead}})
+	require.NoError(t, err)
+	member := newTestClient(t, ts.URL, func(cfg *Config) { cfg.APIKey = k.Key })
+
+	before, err := member.Shorten(ctx, &ShortenRequest{URL: "https://a.com"})
+	require.NoError(t, err)
+
+	_, err = admin.AddMember(ctx, ws.ID, "member")
+	require.NoError(t, err)
+	_, err = admin.AddMember(ctx, "missing", "member")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	// links of default workspace are not seen from team one
+	res, err := member.Shorten(ctx, &ShortenRequest{URL: "https://a.com", ShortDomain: "go.team.com"})
+	require.NoError(t, err)
+	require.NotEqual(t, before.ShortURL, res.ShortURL)
+	require.Len(t, strings.TrimPrefix(res.ShortURL, "https://go.team.com/"), 12)
+
+	page, err := member.ListURLs(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	require.Equal(t, res.ShortURL, page.Items[0].ShortURL)
+
+	_, err = admin.Shorten(ctx, &ShortenRequest{URL: "https://b.com", ShortDomain: "go.team.com"})
+	require.ErrorIs(t, err, ErrBadRequest)
+
+	members, err := admin.ListMembers(ctx, ws.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+
+	ws, err = admin.UpdateWorkspace(ctx, ws.ID, &WorkspaceRequest{Name: "renamed"})
+	require.NoError(t, err)
+	require.Zero(t, ws.Settings.CodeLength)
+
+	require.NoError(t, admin.RemoveMember(ctx, ws.ID, "member"))
+	require.ErrorIs(t, admin.RemoveMember(ctx, ws.ID, "member"), ErrNotFound)
+
+	workspaces, err := admin.ListWorkspaces(ctx)
+	require.NoError(t, err)
+	require.Len(t, workspaces, 1)
+	require.Equal(t, "renamed", workspaces[0].Name)
+	_, err = newTestClient(t, ts.URL, nil).ListWorkspaces(ctx)
+	require.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestClient_quotas(t *testing.T) {
+	ts := runTestServer(t)
+	admin := newTestClient(t, ts.URL, func(cfg *Config) { cfg.AdminToken = testAdminToken })
+	ctx := context.Background()
+
+	ws, err := admin.AddWorkspace(ctx, &WorkspaceRequest{Name: "team"})
+	require.NoError(t, err)
+	_, err = admin.AddMember(ctx, ws.ID, "member")
+	require.NoError(t, err)
+	k, err := admin.AddAPIKey(ctx, &AddAPIKeyRequest{Name: "team", UserID: "member", Scopes: []string{ScopeLinksWrite, ScopeLinksRead}})
+	require.NoError(t, err)
+	member := newTestClient(t, ts.URL, func(cfg *Config) { cfg.APIKey = k.Key })
+
+	var codes []string
+	for _, u := range []string{"https://a.com", "https://b.com"} {
+		res, err := member.Shorten(ctx, &ShortenRequest{URL: u})
+		require.NoError(t, err)
+		codes = append(codes, strings.TrimPrefix(res.ShortURL, ts.URL+"/"))
+	}
+
+	_, err = member.Shorten(ctx, &ShortenRequest{URL: "https://c.com"})
+	require.ErrorIs(t, err, ErrForbidden)
+	var apiErr *Error
+	require.True(t, errors.As(err, &apiErr))
+	require.Equal(t, QuotaActiveLinks, apiErr.Quota().Quota)
+	require.Equal(t, ws.ID, apiErr.Quota().Subject)
+
+	_, err = member.ShortenBatch(ctx, []*BatchItem{
+		{CorrelationID: "1", OriginURL: "https://c.com"},
+		{CorrelationID: "2", OriginURL: "https://d.com"},
+		{CorrelationID: "3", OriginURL: "https://e.com"},
+	}, false)
+	require.ErrorIs(t, err, ErrForbidden)
+
+	// deleted links free active quota, daily one is used up then
+	require.NoError(t, member.DeleteURL(ctx, codes[0], nil))
+	_, err = member.Shorten(ctx, &ShortenRequest{URL: "https://c.com"})
+	require.NoError(t, err)
+	require.NoError(t, member.DeleteURL(ctx, codes[1], nil))
+
+	_, err = member.Shorten(ctx, &ShortenRequest{URL: "https://d.com"})
+	require.ErrorIs(t, err, ErrTooManyRequests)
+	require.True(t, errors.As(err, &apiErr))
+	require.Equal(t, QuotaLinksPerDay, apiErr.Quota().Quota)
+
+	usage, err := member.QuotaUsage(ctx)
+	require.NoError(t, err)
+	require.Len(t, usage, 2)
+	require.Equal(t, 3, usage[0].Used)
+	require.NotNil(t, usage[0].ResetAt)
+	require.Equal(t, 1, usage[1].Used)
+
+	// links out of workspace are not limited
+	_, err = admin.Shorten(ctx, &ShortenRequest{URL: "https://d.com"})
+	require.NoError(t, err)
+}
@@ -0,0 +1,47 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	neturl "net/url"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+)
+
+// ListDomains returns short domains links can be created on, default one is not included
+func (c *Client) ListDomains(ctx context.Context) ([]*Domain, error) {
+	var domains []*Domain
+	if err := c.doJSON(ctx, &request{method: http.MethodGet, path: "/api/domains"}, &domains); err != nil {
+		return nil, err
+	}
+	return domains, nil
+}
+
+// AddDomain registers short domain by its base url, server allows it only from trusted subnet
+func (c *Client) AddDomain(ctx context.Context, baseURL string) (*Domain, error) {
+	return c.AddWorkspaceDomain(ctx, baseURL, "")
+}
+
+// AddWorkspaceDomain registers short domain which only links of workspace can use
+func (c *Client) AddWorkspaceDomain(ctx context.Context, baseURL, workspace string) (*Domain, error) {
+	r, err := c.jsonRequest(http.MethodPost, "/api/domains", &models.AddDomainReqBody{BaseURL: baseURL, Workspace: workspace})
+	if err != nil {
+		return nil, err
+	}
+	r.okCodes = []int{http.StatusCreated}
+
+	var domain Domain
+	if err := c.doJSON(ctx, r, &domain); err != nil {
+		return nil, err
+	}
+	return &domain, nil
+}
+
+// DeleteDomain removes added short domain, server allows it only from trusted subnet
+func (c *Client) DeleteDomain(ctx context.Context, host string) error {
+	return c.doJSON(ctx, &request{
+		method:  http.MethodDelete,
+		path:    "/api/domains/" + neturl.PathEscape(host),
+		okCodes: []int{http.StatusNoContent},
+	}, nil)
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+	"github.com/mailru/easyjson"
+)
+
+// Errors matched by errors.Is against Error of response
+var (
+	ErrBadRequest      = errors.New("bad request")
+	ErrUnauthorized    = errors.New("unauthorized")
+	ErrForbidden       = errors.New("forbidden")
+	ErrNotFound        = errors.New("not found")
+	ErrGone            = errors.New("gone")
+	ErrConflict        = errors.New("conflict")
+	ErrTooLarge        = errors.New("request too large")
+	ErrTooManyRequests = errors.New("too many requests")
+	ErrServer          = errors.New("server error")
+)
+
+var statusErrors = map[int]error{
+	http.StatusBadRequest:            ErrBadRequest,
+	http.StatusUnauthorized:          ErrUnauthorized,
+	http.StatusForbidden:             ErrForbidden,
+	http.StatusNotFound:              ErrNotFound,
+	http.StatusGone:                  ErrGone,
+	http.StatusConflict:              ErrConflict,
+	http.StatusRequestEntityTooLarge: ErrTooLarge,
+	http.StatusTooManyRequests:       ErrTooManyRequests,
+}
+
+// Error is unsuccessful response of server
+type Error struct {
+	StatusCode int
+	// Message is response body, server sends plain text reason
+	Message   string
+	RequestID string
+}
+
+func newError(resp *http.Response) *Error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	return &Error{
+		StatusCode: resp.StatusCode,
+		Message:    strings.TrimSpace(string(msg)),
+		RequestID:  resp.Header.Get(RequestIDHeader),
+	}
+}
+
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	if e.RequestID == "" {
+		return fmt.Sprintf("server responded %d: %s", e.StatusCode, msg)
+	}
+	return fmt.Sprintf("server responded %d: %s (request_id=%s)", e.StatusCode, msg, e.RequestID)
+}
+
+// Quota returns quota which rejected request, it is nil for other errors
+func (e *Error) Quota() *QuotaUsage {
+	if e.StatusCode != http.StatusForbidden && e.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+	var qe models.QuotaError
+	if err := easyjson.Unmarshal([]byte(e.Message), &qe); err != nil {
+		return nil
+	}
+	return qe.Quota
+}
+
+func (e *Error) Is(target error) bool {
+	if target == ErrServer {
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+	return statusErrors[e.StatusCode] == target
+}
@@ -0,0 +1,123 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/pkg/client"
+)
+
+func Example() {
+	cfg := client.DefaultConfig()
+	cfg.BaseURL = "https://short.example.com"
+	cfg.Token = "auth-cookie-value"
+
+	c, err := client.New(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	res, err := c.Shorten(context.Background(), &client.ShortenRequest{
+		URL:   "https://example.com/very/long/path",
+		Title: "Example",
+		Tags:  []string{"docs"},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(res.ShortURL)
+}
+
+func ExampleNew_retries() {
+	cfg := client.DefaultConfig()
+	cfg.BaseURL = "https://short.example.com"
+	cfg.Gzip = true
+	cfg.Retry = client.RetryConfig{
+		MaxRetries: 5,
+		MinBackoff: 200 * time.Millisecond,
+		MaxBackoff: 10 * time.Second,
+	}
+
+	if _, err := client.New(cfg); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func ExampleClient_Resolve() {
+	c, err := client.New(client.DefaultConfig())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// request id shows up in server logs and in returned errors
+	ctx := client.WithRequestID(context.Background(), "3f1c2d9e-8a4b-4c6d-9e0f-1a2b3c4d5e6f")
+
+	res, err := c.Resolve(ctx, "AbC123", nil)
+	switch {
+	case errors.Is(err, client.ErrNotFound):
+		fmt.Println("no such link")
+	case errors.Is(err, client.ErrGone):
+		fmt.Println("link was deleted")
+	case err != nil:
+		log.Fatal(err)
+	default:
+		fmt.Println(res.Location)
+	}
+}
+
+func ExampleClient_ShortenBatch() {
+	c, err := client.New(client.DefaultConfig())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	items := []*client.BatchItem{
+		{CorrelationID: "1", OriginURL: "https://example.com/a"},
+		{CorrelationID: "2", OriginURL: "https://example.com/b"},
+	}
+	res, err := c.ShortenBatch(context.Background(), items, false)
+	if err != nil && !errors.Is(err, client.ErrPartialBatch) {
+		log.Fatal(err)
+	}
+	for _, item := range res {
+		fmt.Println(item.CorrelationID, item.Status, item.ShortURL, item.Error)
+	}
+}
+
+func ExampleClient_ShortenStream() {
+	c, err := client.New(client.DefaultConfig())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	items := slices.Values([]*client.BatchItem{
+		{CorrelationID: "1", OriginURL: "https://example.com/a"},
+		{CorrelationID: "2", OriginURL: "https://example.com/b"},
+	})
+	err = c.ShortenStream(context.Background(), items, func(item *client.BatchItem) error {
+		fmt.Println(item.CorrelationID, item.ShortURL)
+		return nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func ExampleClient_Links() {
+	c, err := client.New(client.DefaultConfig())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	opts := &client.ListOptions{Tag: "docs", Sort: client.SortCreatedAt, Desc: true}
+	for link, err := range c.Links(context.Background(), opts) {
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(link.ShortURL, link.OriginalURL)
+	}
+}
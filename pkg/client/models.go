@@ -0,0 +1,100 @@
+package client
+
+import (
+	"github.com/MatiXxD/url-shortener/internal/models"
+)
+
+// Api types, aliases let other modules use them
+type (
+	ShortenRequest  = models.ShortenURLReqBody
+	ShortenResponse = models.ShortenURLRespBody
+	// BatchItem is request and result of batch item, results have Status and Error set
+	BatchItem    = models.UrlDTO
+	Link         = models.Link
+	LinkPage     = models.LinkPage
+	URLChange    = models.URLChange
+	Stats        = models.Stats
+	DBNodeStats  = models.DBNodeStats
+	Domain       = models.Domain
+	Webhook      = models.Webhook
+	Delivery     = models.Delivery
+	DeliveryPage = models.DeliveryPage
+	// Event is payload of webhook delivery, see pkg/webhook for signature check
+	Event     = models.Event
+	EventLink = models.EventLink
+	Click     = models.Click
+	// AuditEntry is change of link, see Audit* actions
+	AuditEntry = models.AuditEntry
+	AuditActor = models.AuditActor
+	AuditState = models.AuditState
+	AuditPage  = models.AuditPage
+	// APIKey has Key only when it is created
+	APIKey           = models.APIKey
+	AddAPIKeyRequest = models.AddAPIKeyReqBody
+	// Workspace has its own links, zero settings mean service defaults
+	Workspace         = models.Workspace
+	WorkspaceSettings = models.WorkspaceSettings
+	WorkspaceRequest  = models.WorkspaceReqBody
+	WorkspaceMember   = models.WorkspaceMember
+	// QuotaUsage has ResetAt only for quotas reset every day
+	QuotaUsage = models.QuotaUsage
+)
+
+// Batch item statuses
+const (
+	BatchStatusCreated  = models.BatchStatusCreated
+	BatchStatusExisting = models.BatchStatusExisting
+	BatchStatusInvalid  = models.BatchStatusInvalid
+	BatchStatusFailed   = models.BatchStatusFailed
+)
+
+// Event types
+const (
+	EventLinkCreated = models.EventLinkCreated
+	EventLinkUpdated = models.EventLinkUpdated
+	EventLinkDeleted = models.EventLinkDeleted
+	EventLinkClicked = models.EventLinkClicked
+)
+
+// Delivery statuses
+const (
+	DeliveryPending   = models.DeliveryPending
+	DeliveryDelivered = models.DeliveryDelivered
+	DeliveryDead      = models.DeliveryDead
+)
+
+// API key scopes
+const (
+	ScopeLinksWrite = models.ScopeLinksWrite
+	ScopeLinksRead  = models.ScopeLinksRead
+	ScopeStatsRead  = models.ScopeStatsRead
+)
+
+// Audit actions
+const (
+	AuditCreate      = models.AuditCreate
+	AuditBatchCreate = models.AuditBatchCreate
+	AuditUpdate      = models.AuditUpdate
+	AuditRollback    = models.AuditRollback
+	AuditDelete      = models.AuditDelete
+)
+
+// Quota scopes and names
+const (
+	QuotaScopeUser      = models.QuotaScopeUser
+	QuotaScopeAPIKey    = models.QuotaScopeAPIKey
+	QuotaScopeWorkspace = models.QuotaScopeWorkspace
+	QuotaScopeIP        = models.QuotaScopeIP
+
+	QuotaLinksPerDay = models.QuotaLinksPerDay
+	QuotaActiveLinks = models.QuotaActiveLinks
+	QuotaBatchSize   = models.QuotaBatchSize
+)
+
+// Passthrough modes
+const (
+	PassthroughNone  = models.PassthroughNone
+	PassthroughQuery = models.PassthroughQuery
+	PassthroughPath  = models.PassthroughPath
+	PassthroughAll   = models.PassthroughAll
+)
@@ -0,0 +1,15 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// QuotaUsage returns quotas of caller, its api key and workspace, quotas off on server are left out
+func (c *Client) QuotaUsage(ctx context.Context) ([]*QuotaUsage, error) {
+	var usage []*QuotaUsage
+	if err := c.doJSON(ctx, &request{method: http.MethodGet, path: "/api/quota"}, &usage); err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
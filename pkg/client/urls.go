@@ -0,0 +1,448 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+)
+
+const (
+	passwordHeader     = "X-Link-Password"
+	contentTypeNDJSON  = "application/x-ndjson"
+	contentTypeJSON    = "application/json"
+	contentTypeText    = "text/plain"
+	maxStreamLineBytes = 1 << 20
+)
+
+var (
+	// ErrPartialBatch is returned with results when some batch items were not saved
+	ErrPartialBatch = errors.New("batch is saved partially")
+	// ErrBatchRejected is returned with results when atomic batch had invalid items and nothing was saved
+	ErrBatchRejected = errors.New("atomic batch is rejected")
+)
+
+// Sort fields of link listing
+const (
+	SortCreatedAt   = models.SortCreatedAt
+	SortOriginalURL = models.SortOriginalURL
+	SortShortURL    = models.SortShortURL
+)
+
+// Deleted links filter values
+const (
+	DeletedExclude = "false"
+	DeletedOnly    = "true"
+	DeletedAll     = "all"
+)
+
+// LinkOptions select link on not default short domain
+type LinkOptions struct {
+	ShortDomain string
+}
+
+func (o *LinkOptions) query() neturl.Values {
+	if o == nil || o.ShortDomain == "" {
+		return nil
+	}
+	return neturl.Values{"short_domain": {o.ShortDomain}}
+}
+
+type ListOptions struct {
+	Tag string
+	// Domain is host of original url
+	Domain      string
+	ShortDomain string
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	// Deleted is DeletedExclude by default
+	Deleted string
+	// Sort is SortCreatedAt by default, Desc is applied to any sort field
+	Sort   string
+	Desc   bool
+	Limit  int
+	Cursor string
+}
+
+func (o *ListOptions) query() neturl.Values {
+	q := neturl.Values{}
+	if o == nil {
+		return q
+	}
+
+	for name, v := range map[string]string{
+		"tag":          o.Tag,
+		"domain":       o.Domain,
+		"short_domain": o.ShortDomain,
+		"deleted":      o.Deleted,
+		"cursor":       o.Cursor,
+	} {
+		if v != "" {
+			q.Set(name, v)
+		}
+	}
+	if !o.CreatedFrom.IsZero() {
+		q.Set("created_from", o.CreatedFrom.Format(time.RFC3339))
+	}
+	if !o.CreatedTo.IsZero() {
+		q.Set("created_to", o.CreatedTo.Format(time.RFC3339))
+	}
+	if o.Sort != "" || o.Desc {
+		sort := o.Sort
+		if sort == "" {
+			sort = SortCreatedAt
+		}
+		if o.Desc {
+			sort = "-" + sort
+		}
+		q.Set("sort", sort)
+	}
+	if o.Limit > 0 {
+		q.Set("limit", strconv.Itoa(o.Limit))
+	}
+
+	return q
+}
+
+type ResolveOptions struct {
+	// Password of protected link
+	Password string
+}
+
+// Resolved is response of short link
+type Resolved struct {
+	StatusCode int
+	// Location is destination of redirect, empty when server rendered preview page
+	Location string
+}
+
+type QROptions struct {
+	// Format is png or svg
+	Format string
+	Size   int
+	// Level is error correction level: L, M, Q, H
+	Level  string
+	Margin int
+	// Foreground and Background are hex RRGGBB colors
+	Foreground string
+	Background string
+}
+
+func (o *QROptions) query() neturl.Values {
+	q := neturl.Values{}
+	if o == nil {
+		return q
+	}
+
+	for name, v := range map[string]string{
+		"format": o.Format, "level": o.Level, "fg": o.Foreground, "bg": o.Background,
+	} {
+		if v != "" {
+			q.Set(name, v)
+		}
+	}
+	if o.Size > 0 {
+		q.Set("size", strconv.Itoa(o.Size))
+	}
+	if o.Margin > 0 {
+		q.Set("margin", strconv.Itoa(o.Margin))
+	}
+
+	return q
+}
+
+// Shorten creates short link, existing short link is returned for already shortened url
+func (c *Client) Shorten(ctx context.Context, req *ShortenRequest) (*ShortenResponse, error) {
+	r, err := c.jsonRequest(http.MethodPost, "/api/shorten", req)
+	if err != nil {
+		return nil, err
+	}
+
+	var res ShortenResponse
+	if err := c.doJSON(ctx, r, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ShortenText creates short link with plain text api and returns short url
+func (c *Client) ShortenText(ctx context.Context, url string) (string, error) {
+	resp, err := c.do(ctx, &request{
+		method:      http.MethodPost,
+		path:        "/",
+		body:        []byte(url),
+		contentType: contentTypeText,
+		okCodes:     []int{http.StatusCreated},
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("cannot read response: %w", err)
+	}
+	return string(data), nil
+}
+
+// ShortenBatch shortens items in one request. Results are in order of items,
+// ErrPartialBatch and ErrBatchRejected are returned together with results.
+func (c *Client) ShortenBatch(ctx context.Context, items []*BatchItem, atomic bool) ([]*BatchItem, error) {
+	r, err := c.jsonRequest(http.MethodPost, "/api/shorten/batch", items)
+	if err != nil {
+		return nil, err
+	}
+	if atomic {
+		r.query = neturl.Values{"atomic": {"true"}}
+	}
+	// rejected atomic batch has results too
+	r.okCodes = []int{http.StatusOK, http.StatusMultiStatus, http.StatusBadRequest}
+
+	resp, err := c.do(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusBadRequest && !strings.Contains(resp.Header.Get("Content-Type"), contentTypeJSON) {
+		return nil, newError(resp)
+	}
+
+	var res []*BatchItem
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, fmt.Errorf("cannot decode response: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusMultiStatus:
+		return res, ErrPartialBatch
+	case http.StatusBadRequest:
+		return res, ErrBatchRejected
+	}
+	return res, nil
+}
+
+// ShortenStream sends items as ndjson and calls fn for every result as soon as server sends it.
+// Stream is not retried, items already passed to fn are saved when error is returned.
+func (c *Client) ShortenStream(ctx context.Context, items iter.Seq[*BatchItem], fn func(*BatchItem) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(c.writeStream(pw, items))
+	}()
+	defer pr.Close()
+
+	r := &request{method: http.MethodPost, path: "/api/shorten/batch", contentType: contentTypeNDJSON}
+	resp, err := c.send(ctx, r, pr, requestID(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return newError(resp)
+	}
+
+	sc := bufio.NewScanner(resp.Body)
+	sc.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxStreamLineBytes)
+	for sc.Scan() {
+		var item BatchItem
+		if err := json.Unmarshal(sc.Bytes(), &item); err != nil {
+			return fmt.Errorf("cannot decode result: %w", err)
+		}
+		// stream is ended by error line when server fails in the middle
+		if item.CorrelationID == "" && item.Error != "" {
+			return fmt.Errorf("%w: %s", ErrServer, item.Error)
+		}
+		if err := fn(&item); err != nil {
+			return err
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("cannot read results: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) writeStream(w io.Writer, items iter.Seq[*BatchItem]) error {
+	var (
+		enc    *json.Encoder
+		finish func() error
+	)
+	if c.gzip {
+		gw := newGzipWriter(w)
+		enc, finish = json.NewEncoder(gw), gw.Close
+	} else {
+		enc, finish = json.NewEncoder(w), func() error { return nil }
+	}
+
+	for item := range items {
+		if err := enc.Encode(item); err != nil {
+			return err
+		}
+	}
+	return finish()
+}
+
+// Resolve requests short link without following redirect, link is code or full short url
+func (c *Client) Resolve(ctx context.Context, link string, opts *ResolveOptions) (*Resolved, error) {
+	r := &request{
+		method: http.MethodGet,
+		path:   linkPath(link),
+		okCodes: []int{
+			http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+			http.StatusTemporaryRedirect, http.StatusPermanentRedirect, http.StatusOK,
+		},
+	}
+	if opts != nil && opts.Password != "" {
+		r.header = http.Header{passwordHeader: {opts.Password}}
+	}
+
+	resp, err := c.do(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return &Resolved{StatusCode: resp.StatusCode, Location: resp.Header.Get("Location")}, nil
+}
+
+// QRCode returns qr code image of short link, link is code or full short url
+func (c *Client) QRCode(ctx context.Context, link string, opts *QROptions) ([]byte, error) {
+	resp, err := c.do(ctx, &request{
+		method: http.MethodGet,
+		path:   linkPath(link) + "/qr",
+		query:  opts.query(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	img, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read qr code: %w", err)
+	}
+	return img, nil
+}
+
+// ListURLs returns page of own links, NextCursor of page is empty on the last one
+func (c *Client) ListURLs(ctx context.Context, opts *ListOptions) (*LinkPage, error) {
+	var page LinkPage
+	if err := c.doJSON(ctx, &request{method: http.MethodGet, path: "/api/urls", query: opts.query()}, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// Links iterates over all own links matching opts page by page
+func (c *Client) Links(ctx context.Context, opts *ListOptions) iter.Seq2[*Link, error] {
+	return func(yield func(*Link, error) bool) {
+		o := ListOptions{}
+		if opts != nil {
+			o = *opts
+		}
+
+		for {
+			page, err := c.ListURLs(ctx, &o)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, l := range page.Items {
+				if !yield(l, nil) {
+					return
+				}
+			}
+			if page.NextCursor == "" {
+				return
+			}
+			o.Cursor = page.NextCursor
+		}
+	}
+}
+
+// DeleteURL marks own link deleted
+func (c *Client) DeleteURL(ctx context.Context, code string, opts *LinkOptions) error {
+	return c.doJSON(ctx, &request{
+		method:  http.MethodDelete,
+		path:    "/api/urls/" + neturl.PathEscape(code),
+		query:   opts.query(),
+		okCodes: []int{http.StatusNoContent},
+	}, nil)
+}
+
+// UpdateURL changes destination of own link
+func (c *Client) UpdateURL(ctx context.Context, code, url string, opts *LinkOptions) (*Link, error) {
+	r, err := c.jsonRequest(http.MethodPatch, "/api/urls/"+neturl.PathEscape(code), &models.UpdateURLReqBody{URL: url})
+	if err != nil {
+		return nil, err
+	}
+	r.query = opts.query()
+
+	var link Link
+	if err := c.doJSON(ctx, r, &link); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// History returns destination changes of own link, newest first
+func (c *Client) History(ctx context.Context, code string, opts *LinkOptions) ([]*URLChange, error) {
+	var history []*URLChange
+	err := c.doJSON(ctx, &request{
+		method: http.MethodGet,
+		path:   "/api/urls/" + neturl.PathEscape(code) + "/history",
+		query:  opts.query(),
+	}, &history)
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// Rollback undoes change of own link destination, zero changeID means the latest change
+func (c *Client) Rollback(ctx context.Context, code string, changeID int, opts *LinkOptions) (*Link, error) {
+	r, err := c.jsonRequest(http.MethodPost, "/api/urls/"+neturl.PathEscape(code)+"/rollback", &models.RollbackReqBody{ChangeID: changeID})
+	if err != nil {
+		return nil, err
+	}
+	r.query = opts.query()
+
+	var link Link
+	if err := c.doJSON(ctx, r, &link); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// Stats returns service stats, server allows it only from trusted subnet
+func (c *Client) Stats(ctx context.Context) (*Stats, error) {
+	var stats Stats
+	if err := c.doJSON(ctx, &request{method: http.MethodGet, path: "/api/internal/stats"}, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// linkPath is path of code or full short url which is requested as is
+func linkPath(link string) string {
+	if strings.Contains(link, "://") {
+		return strings.TrimSuffix(link, "/")
+	}
+	return "/" + neturl.PathEscape(strings.Trim(link, "/"))
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+
+	"github.com/MatiXxD/url-shortener/internal/models"
+)
+
+// DeliveryOptions filter delivery log
+type DeliveryOptions struct {
+	WebhookID string
+	// Status is one of Delivery* statuses
+	Status string
+	// Event is one of Event* types
+	Event  string
+	Limit  int
+	Cursor string
+}
+
+func (o *DeliveryOptions) query() neturl.Values {
+	q := neturl.Values{}
+	if o == nil {
+		return q
+	}
+
+	for name, v := range map[string]string{
+		"webhook_id": o.WebhookID,
+		"status":     o.Status,
+		"event":      o.Event,
+		"cursor":     o.Cursor,
+	} {
+		if v != "" {
+			q.Set(name, v)
+		}
+	}
+	if o.Limit != 0 {
+		q.Set("limit", strconv.Itoa(o.Limit))
+	}
+	return q
+}
+
+// ListWebhooks returns webhooks without secrets, server allows it only with Config.AdminToken
+func (c *Client) ListWebhooks(ctx context.Context) ([]*Webhook, error) {
+	var webhooks []*Webhook
+	if err := c.doJSON(ctx, &request{method: http.MethodGet, path: "/api/webhooks"}, &webhooks); err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// AddWebhook subscribes url to events, empty secret is generated by server.
+// Secret is returned only here, so it must be kept by caller.
+func (c *Client) AddWebhook(ctx context.Context, url string, events []string, secret string) (*Webhook, error) {
+	r, err := c.jsonRequest(http.MethodPost, "/api/webhooks", &models.AddWebhookReqBody{URL: url, Events: events, Secret: secret})
+	if err != nil {
+		return nil, err
+	}
+	r.okCodes = []int{http.StatusCreated}
+
+	var webhook Webhook
+	if err := c.doJSON(ctx, r, &webhook); err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// DeleteWebhook removes webhook with its queued deliveries
+func (c *Client) DeleteWebhook(ctx context.Context, id string) error {
+	return c.doJSON(ctx, &request{
+		method:  http.MethodDelete,
+		path:    "/api/webhooks/" + neturl.PathEscape(id),
+		okCodes: []int{http.StatusNoContent},
+	}, nil)
+}
+
+// Deliveries returns page of delivery log, newest deliveries first
+func (c *Client) Deliveries(ctx context.Context, opts *DeliveryOptions) (*DeliveryPage, error) {
+	var page DeliveryPage
+	if err := c.doJSON(ctx, &request{method: http.MethodGet, path: "/api/webhooks/deliveries", query: opts.query()}, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// RetryDelivery queues delivery again with all attempts, it replays dead and delivered ones
+func (c *Client) RetryDelivery(ctx context.Context, id int64) (*Delivery, error) {
+	r := &request{method: http.MethodPost, path: "/api/webhooks/deliveries/" + strconv.FormatInt(id, 10) + "/retry"}
+
+	var d Delivery
+	if err := c.doJSON(ctx, r, &d); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	neturl "net/url"
+)
+
+// ListWorkspaces returns workspaces, server allows it only with Config.AdminToken
+func (c *Client) ListWorkspaces(ctx context.Context) ([]*Workspace, error) {
+	var workspaces []*Workspace
+	if err := c.doJSON(ctx, &request{method: http.MethodGet, path: "/api/workspaces"}, &workspaces); err != nil {
+		return nil, err
+	}
+	return workspaces, nil
+}
+
+func (c *Client) AddWorkspace(ctx context.Context, req *WorkspaceRequest) (*Workspace, error) {
+	r, err := c.jsonRequest(http.MethodPost, "/api/workspaces", req)
+	if err != nil {
+		return nil, err
+	}
+	r.okCodes = []int{http.StatusCreated}
+
+	var ws Workspace
+	if err := c.doJSON(ctx, r, &ws); err != nil {
+		return nil, err
+	}
+	return &ws, nil
+}
+
+// UpdateWorkspace replaces name and settings, they apply to links created afterwards
+func (c *Client) UpdateWorkspace(ctx context.Context, id string, req *WorkspaceRequest) (*Workspace, error) {
+	r, err := c.jsonRequest(http.MethodPut, "/api/workspaces/"+neturl.PathEscape(id), req)
+	if err != nil {
+		return nil, err
+	}
+
+	var ws Workspace
+	if err := c.doJSON(ctx, r, &ws); err != nil {
+		return nil, err
+	}
+	return &ws, nil
+}
+
+func (c *Client) ListMembers(ctx context.Context, workspaceID string) ([]*WorkspaceMember, error) {
+	var members []*WorkspaceMember
+	r := &request{method: http.MethodGet, path: "/api/workspaces/" + neturl.PathEscape(workspaceID) + "/members"}
+	if err := c.doJSON(ctx, r, &members); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// AddMember moves user to workspace, user is member of one workspace at most
+func (c *Client) AddMember(ctx context.Context, workspaceID, userID string) (*WorkspaceMember, error) {
+	var m WorkspaceMember
+	r := &request{method: http.MethodPut, path: memberPath(workspaceID, userID)}
+	if err := c.doJSON(ctx, r, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// RemoveMember returns user to default workspace
+func (c *Client) RemoveMember(ctx context.Context, workspaceID, userID string) error {
+	return c.doJSON(ctx, &request{
+		method:  http.MethodDelete,
+		path:    memberPath(workspaceID, userID),
+		okCodes: []int{http.StatusNoContent},
+	}, nil)
+}
+
+func memberPath(workspaceID, userID string) string {
+	return "/api/workspaces/" + neturl.PathEscape(workspaceID) + "/members/" + neturl.PathEscape(userID)
+}
@@ -3,6 +3,8 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -11,13 +13,89 @@ import (
 
 const (
 	connTimeout = 10 // connTimeout connection timeout in seconds
+
+	// DefaultCheckInterval is used when Config has no CheckInterval
+	DefaultCheckInterval = 5 * time.Second
 )
 
 type DB struct {
+	// Pool is primary, all writes go there
 	Pool *pgxpool.Pool
+
+	primary  *node
+	replicas []*node
+	next     atomic.Uint64
+	maxLag   time.Duration
+	stop     chan struct{}
+	done     sync.WaitGroup
+}
+
+// Config lists primary and replicas of one database
+type Config struct {
+	Primary  string
+	Replicas []string
+	// CheckInterval is how often replicas are pinged, DefaultCheckInterval if not positive
+	CheckInterval time.Duration
+	// MaxLag marks lagging replica unhealthy, zero disables lag check
+	MaxLag time.Duration
+}
+
+func (cfg Config) withDefaults() Config {
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = DefaultCheckInterval
+	}
+	return cfg
 }
 
 func New(connStr string) (*DB, error) {
+	return NewCluster(Config{Primary: connStr})
+}
+
+// NewCluster connects to primary and replicas, unreachable replica is only marked unhealthy
+func NewCluster(cfg Config) (*DB, error) {
+	cfg = cfg.withDefaults()
+
+	pool, err := connect(cfg.Primary)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), connTimeout*time.Second)
+	defer cancel()
+
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	db := &DB{
+		Pool:    pool,
+		primary: newNode("primary", pool),
+		maxLag:  cfg.MaxLag,
+		stop:    make(chan struct{}),
+	}
+	db.primary.healthy.Store(true)
+
+	for i, dsn := range cfg.Replicas {
+		pool, err := connect(dsn)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("replica %d: %w", i+1, err)
+		}
+		db.replicas = append(db.replicas, newNode(fmt.Sprintf("replica-%d", i+1), pool))
+	}
+
+	if len(db.replicas) > 0 {
+		db.checkReplicas(ctx)
+		db.done.Add(1)
+		go db.watchReplicas(cfg.CheckInterval)
+	}
+
+	return db, nil
+}
+
+// connect creates pool, connections are opened lazily
+func connect(connStr string) (*pgxpool.Pool, error) {
 	pgCfg, err := pgxpool.ParseConfig(connStr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create postgres config: %w", err)
@@ -34,14 +112,18 @@ func New(connStr string) (*DB, error) {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	if err := pool.Ping(ctx); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
-	}
-
-	return &DB{Pool: pool}, nil
+	return pool, nil
 }
 
 func (db *DB) Close() {
+	if db.stop != nil {
+		close(db.stop)
+		db.done.Wait()
+		db.stop = nil
+	}
+	for _, n := range db.replicas {
+		n.pool.Close()
+	}
 	if db.Pool != nil {
 		db.Pool.Close()
 	}
@@ -0,0 +1,158 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// replicaLag is zero when replica replayed everything it received,
+// otherwise idle primary would look like growing lag
+const replicaLag = `
+	SELECT CASE
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+	END
+`
+
+// queryCanceled is postgres error code for canceled statement
+const queryCanceled = "57014"
+
+type node struct {
+	name    string
+	pool    *pgxpool.Pool
+	healthy atomic.Bool
+	reads   atomic.Int64
+	// lag of last check in microseconds
+	lag atomic.Int64
+}
+
+func newNode(name string, pool *pgxpool.Pool) *node {
+	return &node{name: name, pool: pool}
+}
+
+// NodeStats are pool metrics of one database node
+type NodeStats struct {
+	Name          string
+	Healthy       bool
+	Reads         int64
+	Lag           time.Duration
+	TotalConns    int32
+	IdleConns     int32
+	AcquiredConns int32
+	MaxConns      int32
+	AcquireCount  int64
+	AcquireTime   time.Duration
+}
+
+// HasReplicas reports whether reads may be served not by primary
+func (db *DB) HasReplicas() bool {
+	return len(db.replicas) > 0
+}
+
+// Read runs fn on healthy replica in round-robin order or on primary if there is none.
+// Replica failed with connection error is marked unhealthy and fn is retried on primary.
+func (db *DB) Read(ctx context.Context, fn func(*pgxpool.Pool) error) error {
+	n := db.replica()
+	if n == nil {
+		db.primary.reads.Add(1)
+		return fn(db.Pool)
+	}
+
+	n.reads.Add(1)
+	err := fn(n.pool)
+	if err == nil || ctx.Err() != nil || !isNodeError(err) {
+		return err
+	}
+
+	n.healthy.Store(false)
+	db.primary.reads.Add(1)
+	return fn(db.Pool)
+}
+
+func (db *DB) replica() *node {
+	for range db.replicas {
+		n := db.replicas[db.next.Add(1)%uint64(len(db.replicas))]
+		if n.healthy.Load() {
+			return n
+		}
+	}
+	return nil
+}
+
+// Stats returns metrics of primary followed by replicas
+func (db *DB) Stats() []NodeStats {
+	nodes := append([]*node{db.primary}, db.replicas...)
+
+	res := make([]NodeStats, 0, len(nodes))
+	for _, n := range nodes {
+		st := n.pool.Stat()
+		res = append(res, NodeStats{
+			Name:          n.name,
+			Healthy:       n.healthy.Load(),
+			Reads:         n.reads.Load(),
+			Lag:           time.Duration(n.lag.Load()) * time.Microsecond,
+			TotalConns:    st.TotalConns(),
+			IdleConns:     st.IdleConns(),
+			AcquiredConns: st.AcquiredConns(),
+			MaxConns:      st.MaxConns(),
+			AcquireCount:  st.AcquireCount(),
+			AcquireTime:   st.AcquireDuration(),
+		})
+	}
+
+	return res
+}
+
+func (db *DB) watchReplicas(interval time.Duration) {
+	defer db.done.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-db.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			db.checkReplicas(ctx)
+			cancel()
+		}
+	}
+}
+
+// checkReplicas marks replica healthy if it answers and its lag is within limit
+func (db *DB) checkReplicas(ctx context.Context) {
+	for _, n := range db.replicas {
+		var seconds float64
+		if err := n.pool.QueryRow(ctx, replicaLag).Scan(&seconds); err != nil {
+			n.healthy.Store(false)
+			continue
+		}
+
+		lag := time.Duration(seconds * float64(time.Second))
+		n.lag.Store(lag.Microseconds())
+		n.healthy.Store(db.maxLag == 0 || lag <= db.maxLag)
+	}
+}
+
+// isNodeError tells connection failures from query errors which replica would repeat
+func isNodeError(err error) bool {
+	// connection exception and operator intervention like replica shutdown, but not statement timeout
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return strings.HasPrefix(pgErr.Code, "08") ||
+			strings.HasPrefix(pgErr.Code, "57") && pgErr.Code != queryCanceled
+	}
+
+	var connErr *pgconn.ConnectError
+	var netErr net.Error
+	return errors.As(err, &connErr) || errors.As(err, &netErr) || pgconn.SafeToRetry(err)
+}
@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDB_replica(t *testing.T) {
+	db := &DB{}
+	for i := 1; i <= 3; i++ {
+		n := newNode(fmt.Sprintf("replica-%d", i), nil)
+		n.healthy.Store(i != 2)
+		db.replicas = append(db.replicas, n)
+	}
+
+	var names []string
+	for i := 0; i < 4; i++ {
+		names = append(names, db.replica().name)
+	}
+	require.Equal(t, []string{"replica-3", "replica-1", "replica-3", "replica-1"}, names)
+
+	for _, n := range db.replicas {
+		n.healthy.Store(false)
+	}
+	require.Nil(t, db.replica())
+}
+
+func TestIsNodeError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "no rows", err: pgx.ErrNoRows, want: false},
+		{name: "unique violation", err: &pgconn.PgError{Code: "23505"}, want: false},
+		{name: "statement timeout", err: &pgconn.PgError{Code: queryCanceled}, want: false},
+		{name: "admin shutdown", err: fmt.Errorf("failed to get url: %w", &pgconn.PgError{Code: "57P01"}), want: true},
+		{name: "connection failure", err: &pgconn.PgError{Code: "08006"}, want: true},
+		{name: "other", err: errors.New("boom"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, isNodeError(tt.err))
+		})
+	}
+}
+
+func TestConfig_withDefaults(t *testing.T) {
+	require.Equal(t, DefaultCheckInterval, Config{}.withDefaults().CheckInterval)
+	require.Equal(t, DefaultCheckInterval, Config{CheckInterval: -time.Second}.withDefaults().CheckInterval)
+	require.Equal(t, time.Second, Config{CheckInterval: time.Second}.withDefaults().CheckInterval)
+}
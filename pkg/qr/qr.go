@@ -0,0 +1,218 @@
+package qr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+type Format string
+
+const (
+	FormatPNG Format = "png"
+	FormatSVG Format = "svg"
+)
+
+const (
+	MinSize = 64
+	MaxSize = 2048
+	// MaxMargin is quiet zone limit in modules, spec recommends 4
+	MaxMargin = 16
+)
+
+var (
+	ErrInvalidFormat = errors.New("unknown qr format")
+	ErrInvalidLevel  = errors.New("unknown error correction level")
+	ErrInvalidSize   = fmt.Errorf("size must be in range [%d, %d]", MinSize, MaxSize)
+	ErrInvalidMargin = fmt.Errorf("margin must be in range [0, %d]", MaxMargin)
+	ErrInvalidColor  = errors.New("color must be hex RRGGBB")
+)
+
+type Options struct {
+	Format     Format
+	Size       int    // image width and height in pixels
+	Level      string // error correction level: L, M, Q, H
+	Margin     int    // quiet zone in modules
+	Foreground color.RGBA
+	Background color.RGBA
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Format:     FormatPNG,
+		Size:       256,
+		Level:      "M",
+		Margin:     4,
+		Foreground: color.RGBA{A: 0xff},
+		Background: color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+	}
+}
+
+// Key is stable representation of options used for caching
+func (o Options) Key() string {
+	return fmt.Sprintf("%s:%d:%s:%d:%s:%s", o.Format, o.Size, o.Level, o.Margin, FormatColor(o.Foreground), FormatColor(o.Background))
+}
+
+func (o Options) ContentType() string {
+	if o.Format == FormatSVG {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+func (o Options) Validate() error {
+	var errs []error
+
+	if o.Format != FormatPNG && o.Format != FormatSVG {
+		errs = append(errs, ErrInvalidFormat)
+	}
+	if _, err := parseLevel(o.Level); err != nil {
+		errs = append(errs, err)
+	}
+	if o.Size < MinSize || o.Size > MaxSize {
+		errs = append(errs, ErrInvalidSize)
+	}
+	if o.Margin < 0 || o.Margin > MaxMargin {
+		errs = append(errs, ErrInvalidMargin)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Render encodes content as qr code image
+func Render(content string, opts Options) ([]byte, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	level, _ := parseLevel(opts.Level)
+	q, err := qrcode.New(content, level)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode qr code: %w", err)
+	}
+	// margin is drawn by us to make it configurable
+	q.DisableBorder = true
+
+	m := newMatrix(q.Bitmap(), opts.Margin)
+	if m.modules > opts.Size {
+		return nil, fmt.Errorf("%w: content needs at least %d pixels", ErrInvalidSize, m.modules)
+	}
+	if opts.Format == FormatSVG {
+		return renderSVG(m, opts), nil
+	}
+	return renderPNG(m, opts)
+}
+
+// ParseColor parses hex color with optional leading #
+func ParseColor(s string) (color.RGBA, error) {
+	s = strings.TrimPrefix(s, "#")
+
+	var r, g, b uint8
+	if len(s) != 6 {
+		return color.RGBA{}, ErrInvalidColor
+	}
+	if _, err := fmt.Sscanf(s, "%02x%02x%02x", &r, &g, &b); err != nil {
+		return color.RGBA{}, ErrInvalidColor
+	}
+
+	return color.RGBA{R: r, G: g, B: b, A: 0xff}, nil
+}
+
+func FormatColor(c color.RGBA) string {
+	return fmt.Sprintf("%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func parseLevel(s string) (qrcode.RecoveryLevel, error) {
+	switch strings.ToUpper(s) {
+	case "L":
+		return qrcode.Low, nil
+	case "M":
+		return qrcode.Medium, nil
+	case "Q":
+		return qrcode.High, nil
+	case "H":
+		return qrcode.Highest, nil
+	}
+	return 0, ErrInvalidLevel
+}
+
+// matrix is qr bitmap with margin
+type matrix struct {
+	bits   [][]bool
+	margin int
+	// modules is width of the whole symbol including margin
+	modules int
+}
+
+func newMatrix(bits [][]bool, margin int) *matrix {
+	return &matrix{
+		bits:    bits,
+		margin:  margin,
+		modules: len(bits) + 2*margin,
+	}
+}
+
+func (m *matrix) dark(x, y int) bool {
+	x, y = x-m.margin, y-m.margin
+	if y < 0 || y >= len(m.bits) || x < 0 || x >= len(m.bits[y]) {
+		return false
+	}
+	return m.bits[y][x]
+}
+
+func renderPNG(m *matrix, opts Options) ([]byte, error) {
+	img := image.NewPaletted(image.Rect(0, 0, opts.Size, opts.Size), color.Palette{opts.Background, opts.Foreground})
+
+	// whole modules only, leftover pixels go to padding around the symbol
+	scale := opts.Size / m.modules
+	offset := (opts.Size - scale*m.modules) / 2
+
+	for y := 0; y < opts.Size; y++ {
+		for x := 0; x < opts.Size; x++ {
+			mx, my := (x-offset)/scale, (y-offset)/scale
+			if x >= offset && y >= offset && m.dark(mx, my) {
+				img.SetColorIndex(x, y, 1)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode png: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func renderSVG(m *matrix, opts Options) []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		opts.Size, opts.Size, m.modules, m.modules)
+	fmt.Fprintf(&buf, `<rect width="100%%" height="100%%" fill="#%s"/>`, FormatColor(opts.Background))
+	fmt.Fprintf(&buf, `<path fill="#%s" d="`, FormatColor(opts.Foreground))
+
+	// one horizontal segment per run of dark modules keeps svg small
+	for y := 0; y < m.modules; y++ {
+		for x := 0; x < m.modules; x++ {
+			if !m.dark(x, y) {
+				continue
+			}
+			start := x
+			for x < m.modules && m.dark(x, y) {
+				x++
+			}
+			fmt.Fprintf(&buf, "M%d %dh%dv1h-%dz", start, y, x-start, x-start)
+		}
+	}
+
+	buf.WriteString(`"/></svg>`)
+
+	return buf.Bytes()
+}
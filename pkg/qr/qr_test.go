@@ -0,0 +1,94 @@
+package qr
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	content := "http://localhost:8080/AAAAAAAA"
+
+	t.Run("png", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.Size = 300
+		opts.Foreground = color.RGBA{R: 0x11, G: 0x22, B: 0x33, A: 0xff}
+
+		data, err := Render(content, opts)
+		require.NoError(t, err)
+
+		img, err := png.Decode(bytes.NewReader(data))
+		require.NoError(t, err)
+		require.Equal(t, 300, img.Bounds().Dx())
+		require.Equal(t, 300, img.Bounds().Dy())
+
+		// corner is quiet zone, finder pattern starts after margin
+		r, g, b, _ := img.At(0, 0).RGBA()
+		require.Equal(t, [3]uint32{0xffff, 0xffff, 0xffff}, [3]uint32{r, g, b})
+	})
+
+	t.Run("svg", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.Format = FormatSVG
+		opts.Margin = 0
+		opts.Background = color.RGBA{R: 0xff, A: 0xff}
+
+		data, err := Render(content, opts)
+		require.NoError(t, err)
+
+		svg := string(data)
+		require.True(t, strings.HasPrefix(svg, "<svg"))
+		require.Contains(t, svg, `fill="#ff0000"`)
+		// finder pattern at top left corner
+		require.Contains(t, svg, "M0 0h7v1h-7z")
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.Size = 1
+		opts.Level = "X"
+
+		_, err := Render(content, opts)
+		require.ErrorIs(t, err, ErrInvalidSize)
+		require.ErrorIs(t, err, ErrInvalidLevel)
+	})
+
+	t.Run("too small for content", func(t *testing.T) {
+		opts := DefaultOptions()
+		opts.Size = MinSize
+		opts.Level = "H"
+
+		_, err := Render(strings.Repeat("a", 500), opts)
+		require.ErrorIs(t, err, ErrInvalidSize)
+	})
+}
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    color.RGBA
+		wantErr bool
+	}{
+		{name: "plain", in: "ff8000", want: color.RGBA{R: 0xff, G: 0x80, A: 0xff}},
+		{name: "with hash", in: "#000000", want: color.RGBA{A: 0xff}},
+		{name: "short", in: "fff", wantErr: true},
+		{name: "not hex", in: "zzzzzz", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseColor(tt.in)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidColor)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
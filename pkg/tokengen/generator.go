@@ -0,0 +1,210 @@
+package tokengen
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+const (
+	DefaultAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	DefaultLength   = 10
+)
+
+// CodeGenerator makes short codes. Attempt grows when previous code was already taken,
+// deterministic strategies use it to get another code for the same url.
+type CodeGenerator interface {
+	Generate(ctx context.Context, original string, attempt int) (string, error)
+}
+
+// NextFunc returns next value of persistent counter
+type NextFunc func(ctx context.Context) (uint64, error)
+
+// Random makes codes of secure random symbols
+type Random struct {
+	alphabet string
+	length   int
+}
+
+func NewRandom(alphabet string, length int) *Random {
+	alphabet, length = withDefaults(alphabet, length)
+	return &Random{alphabet: alphabet, length: length}
+}
+
+func (g *Random) Generate(_ context.Context, _ string, _ int) (string, error) {
+	max := big.NewInt(int64(len(g.alphabet)))
+	code := make([]byte, g.length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to read random: %w", err)
+		}
+		code[i] = g.alphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// Sequence encodes counter values, length is minimal code length
+type Sequence struct {
+	next     NextFunc
+	alphabet string
+	length   int
+}
+
+func NewSequence(next NextFunc, alphabet string, length int) *Sequence {
+	alphabet, length = withDefaults(alphabet, length)
+	return &Sequence{next: next, alphabet: alphabet, length: length}
+}
+
+func (g *Sequence) Generate(ctx context.Context, _ string, _ int) (string, error) {
+	id, err := g.next(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get next id: %w", err)
+	}
+
+	code := encode(id, g.alphabet)
+	for len(code) < g.length {
+		code = g.alphabet[:1] + code
+	}
+	return code, nil
+}
+
+// Hash makes deterministic codes from salted sha256 of url
+type Hash struct {
+	alphabet string
+	length   int
+	salt     string
+}
+
+func NewHash(alphabet string, length int, salt string) *Hash {
+	alphabet, length = withDefaults(alphabet, length)
+	return &Hash{alphabet: alphabet, length: length, salt: salt}
+}
+
+func (g *Hash) Generate(_ context.Context, original string, attempt int) (string, error) {
+	data := g.salt + original
+	if attempt > 0 {
+		data += "#" + strconv.Itoa(attempt)
+	}
+	sum := sha256.Sum256([]byte(data))
+
+	n := new(big.Int).SetBytes(sum[:])
+	base := big.NewInt(int64(len(g.alphabet)))
+	mod := new(big.Int)
+
+	code := make([]byte, g.length)
+	for i := range code {
+		// 256 bits are enough for any sane length, rehash if they run out
+		if n.Sign() == 0 {
+			sum = sha256.Sum256(sum[:])
+			n.SetBytes(sum[:])
+		}
+		n.DivMod(n, base, mod)
+		code[i] = g.alphabet[mod.Int64()]
+	}
+	return string(code), nil
+}
+
+// guardDiv is part of alphabet reserved for guards, as in hashids
+const guardDiv = 12
+
+// Hashids obfuscates counter values with alphabet shuffled by salt, length is minimal code length.
+// Guards are never used for the number itself, so padding with them keeps codes unique.
+type Hashids struct {
+	next     NextFunc
+	alphabet string
+	guards   string
+	length   int
+	salt     string
+}
+
+func NewHashids(next NextFunc, alphabet string, length int, salt string) *Hashids {
+	alphabet, length = withDefaults(alphabet, length)
+	shuffled := consistentShuffle([]byte(alphabet), []byte(salt))
+	numGuards := (len(shuffled) + guardDiv - 1) / guardDiv
+
+	return &Hashids{
+		next:     next,
+		alphabet: string(shuffled[numGuards:]),
+		guards:   string(shuffled[:numGuards]),
+		length:   length,
+		salt:     salt,
+	}
+}
+
+func (g *Hashids) Generate(ctx context.Context, _ string, _ int) (string, error) {
+	id, err := g.next(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get next id: %w", err)
+	}
+	return g.Encode(id), nil
+}
+
+// Encode follows hashids algorithm for single number without separators,
+// code is padded with guards on both sides up to minimal length
+func (g *Hashids) Encode(id uint64) string {
+	alphabet := []byte(g.alphabet)
+	lottery := alphabet[id%100%uint64(len(alphabet))]
+
+	buffer := append([]byte{lottery}, g.salt...)
+	buffer = append(buffer, alphabet...)
+	alphabet = consistentShuffle(alphabet, buffer[:len(alphabet)])
+
+	code := []byte{lottery}
+	code = append(code, encode(id, string(alphabet))...)
+
+	for i := 0; len(code) < g.length; i++ {
+		guard := g.guards[(int(lottery)+i)%len(g.guards)]
+		if i%2 == 0 {
+			code = append([]byte{guard}, code...)
+		} else {
+			code = append(code, guard)
+		}
+	}
+
+	return string(code)
+}
+
+// encode writes number in positional system of alphabet
+func encode(n uint64, alphabet string) string {
+	base := uint64(len(alphabet))
+	var code []byte
+	for {
+		code = append([]byte{alphabet[n%base]}, code...)
+		n /= base
+		if n == 0 {
+			return string(code)
+		}
+	}
+}
+
+func consistentShuffle(alphabet, salt []byte) []byte {
+	res := append([]byte{}, alphabet...)
+	if len(salt) == 0 {
+		return res
+	}
+
+	for i, v, p := len(res)-1, 0, 0; i > 0; i-- {
+		v %= len(salt)
+		n := int(salt[v])
+		p += n
+		j := (n + v + p) % i
+		res[i], res[j] = res[j], res[i]
+		v++
+	}
+
+	return res
+}
+
+func withDefaults(alphabet string, length int) (string, int) {
+	if alphabet == "" {
+		alphabet = DefaultAlphabet
+	}
+	if length <= 0 {
+		length = DefaultLength
+	}
+	return alphabet, length
+}
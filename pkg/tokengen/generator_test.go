@@ -0,0 +1,133 @@
+package tokengen
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func counter() NextFunc {
+	var n uint64
+	return func(context.Context) (uint64, error) {
+		n++
+		return n, nil
+	}
+}
+
+func TestCodeGenerators(t *testing.T) {
+	const alphabet = "0123456789abcdef"
+
+	tests := []struct {
+		name     string
+		gen      CodeGenerator
+		alphabet string
+		length   int
+	}{
+		{
+			name:     "random",
+			gen:      NewRandom(alphabet, 8),
+			alphabet: alphabet,
+			length:   8,
+		},
+		{
+			name:     "random defaults",
+			gen:      NewRandom("", 0),
+			alphabet: DefaultAlphabet,
+			length:   DefaultLength,
+		},
+		{
+			name:     "sequence",
+			gen:      NewSequence(counter(), alphabet, 4),
+			alphabet: alphabet,
+			length:   4,
+		},
+		{
+			name:     "hash",
+			gen:      NewHash(alphabet, 12, "salt"),
+			alphabet: alphabet,
+			length:   12,
+		},
+		{
+			name:     "hashids",
+			gen:      NewHashids(counter(), alphabet, 6, "salt"),
+			alphabet: alphabet,
+			length:   6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen := make(map[string]bool)
+			for i := 0; i < 100; i++ {
+				code, err := tt.gen.Generate(context.Background(), fmt.Sprintf("https://example.com/%d", i), 0)
+				require.NoError(t, err)
+				require.Len(t, code, tt.length)
+				for _, r := range code {
+					require.Contains(t, tt.alphabet, string(r))
+				}
+				require.False(t, seen[code], "duplicate code %s", code)
+				seen[code] = true
+			}
+		})
+	}
+}
+
+func TestSequence_Generate(t *testing.T) {
+	gen := NewSequence(counter(), "0123456789", 3)
+
+	var codes []string
+	for i := 0; i < 12; i++ {
+		code, err := gen.Generate(context.Background(), "", 0)
+		require.NoError(t, err)
+		codes = append(codes, code)
+	}
+
+	require.Equal(t, "001", codes[0])
+	require.Equal(t, "012", codes[11])
+}
+
+func TestHash_Generate(t *testing.T) {
+	gen := NewHash(DefaultAlphabet, 7, "")
+
+	first, err := gen.Generate(context.Background(), "https://example.com", 0)
+	require.NoError(t, err)
+	again, err := gen.Generate(context.Background(), "https://example.com", 0)
+	require.NoError(t, err)
+	require.Equal(t, first, again)
+
+	retry, err := gen.Generate(context.Background(), "https://example.com", 1)
+	require.NoError(t, err)
+	require.NotEqual(t, first, retry)
+
+	salted, err := NewHash(DefaultAlphabet, 7, "pepper").Generate(context.Background(), "https://example.com", 0)
+	require.NoError(t, err)
+	require.NotEqual(t, first, salted)
+}
+
+func TestHashids_Encode(t *testing.T) {
+	gen := NewHashids(counter(), DefaultAlphabet, 8, "pepper")
+	other := NewHashids(counter(), DefaultAlphabet, 8, "salt")
+
+	require.Equal(t, gen.Encode(42), gen.Encode(42))
+	require.NotEqual(t, gen.Encode(42), gen.Encode(43))
+	require.NotEqual(t, gen.Encode(42), other.Encode(42))
+	require.Len(t, gen.Encode(1<<40), 8)
+}
+
+func TestHashids_unique(t *testing.T) {
+	// minimal alphabet and length, codes of small ids are padded
+	gen := NewHashids(counter(), "0123456789ABCDEF", 4, "salt")
+
+	seen := make(map[string]uint64)
+	for id := uint64(0); id < 100000; id++ {
+		code := gen.Encode(id)
+		prev, ok := seen[code]
+		require.False(t, ok, "ids %d and %d have same code %s", prev, id, code)
+		seen[code] = id
+	}
+
+	other := NewHashids(counter(), DefaultAlphabet, 4, "salt")
+	require.NotEqual(t, other.Encode(13), other.Encode(813))
+}
@@ -0,0 +1,129 @@
+package tokengen
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// Alphabet presets
+const (
+	AlphabetBase62 = "base62"
+	// AlphabetReadable has no 0, O, 1, I and l
+	AlphabetReadable = "readable"
+	// AlphabetReadableLower is readable alphabet for case-insensitive codes, it also has no o
+	AlphabetReadableLower = "readable_lower"
+)
+
+var alphabets = map[string]string{
+	AlphabetBase62:        DefaultAlphabet,
+	AlphabetReadable:      "23456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz",
+	AlphabetReadableLower: "23456789abcdefghijkmnpqrstuvwxyz",
+}
+
+// denylistAttempts limits regeneration of codes with denied words
+const denylistAttempts = 20
+
+var ErrNoAllowedCode = errors.New("every generated code contains denied word")
+
+// Alphabet returns symbols of preset, anything else is treated as symbols itself
+func Alphabet(name string) string {
+	if symbols, ok := alphabets[name]; ok {
+		return symbols
+	}
+	return name
+}
+
+// Denylist regenerates codes which contain any of denied words, case is ignored
+type Denylist struct {
+	gen   CodeGenerator
+	words []string
+}
+
+func NewDenylist(gen CodeGenerator, words []string) *Denylist {
+	lower := make([]string, 0, len(words))
+	for _, w := range words {
+		if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+			lower = append(lower, w)
+		}
+	}
+	return &Denylist{gen: gen, words: lower}
+}
+
+func (g *Denylist) Generate(ctx context.Context, original string, attempt int) (string, error) {
+	// every outer attempt gets own range of inner ones, so deterministic codes don't repeat
+	for i := 0; i < denylistAttempts; i++ {
+		code, err := g.gen.Generate(ctx, original, attempt*denylistAttempts+i)
+		if err != nil {
+			return "", err
+		}
+		if !g.Denied(code) {
+			return code, nil
+		}
+	}
+	return "", ErrNoAllowedCode
+}
+
+func (g *Denylist) Denied(code string) bool {
+	code = strings.ToLower(code)
+	for _, w := range g.words {
+		if strings.Contains(code, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// Checked appends check symbol to generated codes
+type Checked struct {
+	gen      CodeGenerator
+	alphabet string
+}
+
+func NewChecked(gen CodeGenerator, alphabet string) *Checked {
+	alphabet, _ = withDefaults(alphabet, 0)
+	return &Checked{gen: gen, alphabet: alphabet}
+}
+
+func (g *Checked) Generate(ctx context.Context, original string, attempt int) (string, error) {
+	code, err := g.gen.Generate(ctx, original, attempt)
+	if err != nil {
+		return "", err
+	}
+
+	check, ok := CheckSymbol(code, g.alphabet)
+	if !ok {
+		return "", errors.New("generated code has symbols out of alphabet")
+	}
+	return code + string(check), nil
+}
+
+// CheckSymbol computes Luhn mod N symbol of code, it catches any single typo
+// and most swaps of adjacent symbols. False means code has symbols out of alphabet.
+func CheckSymbol(code, alphabet string) (byte, bool) {
+	n := len(alphabet)
+	factor := 2
+	sum := 0
+
+	for i := len(code) - 1; i >= 0; i-- {
+		p := strings.IndexByte(alphabet, code[i])
+		if p < 0 {
+			return 0, false
+		}
+
+		addend := factor * p
+		sum += addend/n + addend%n
+		factor = 3 - factor
+	}
+
+	return alphabet[(n-sum%n)%n], true
+}
+
+// ValidCheck reports if last symbol of code is its check symbol
+func ValidCheck(code, alphabet string) bool {
+	if len(code) < 2 {
+		return false
+	}
+	check, ok := CheckSymbol(code[:len(code)-1], alphabet)
+	return ok && check == code[len(code)-1]
+}
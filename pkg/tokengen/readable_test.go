@@ -0,0 +1,63 @@
+package tokengen
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlphabet(t *testing.T) {
+	require.Equal(t, DefaultAlphabet, Alphabet(AlphabetBase62))
+	require.Equal(t, "0123456789abcdef", Alphabet("0123456789abcdef"))
+
+	for _, r := range "0O1Il" {
+		require.NotContains(t, Alphabet(AlphabetReadable), string(r))
+	}
+	require.Equal(t, strings.ToLower(Alphabet(AlphabetReadableLower)), Alphabet(AlphabetReadableLower))
+}
+
+func TestDenylist_Generate(t *testing.T) {
+	gen := NewDenylist(NewSequence(counter(), "0123456789abcdef", 1), []string{"2", " B "})
+
+	var codes []string
+	for i := 0; i < 10; i++ {
+		code, err := gen.Generate(context.Background(), "", 0)
+		require.NoError(t, err)
+		codes = append(codes, code)
+	}
+	require.Equal(t, []string{"1", "3", "4", "5", "6", "7", "8", "9", "a", "c"}, codes)
+
+	require.True(t, gen.Denied("xxbxx"))
+	require.False(t, gen.Denied("xxcxx"))
+
+	_, err := NewDenylist(NewRandom("ab", 4), []string{"a", "b"}).Generate(context.Background(), "", 0)
+	require.ErrorIs(t, err, ErrNoAllowedCode)
+}
+
+func TestChecked_Generate(t *testing.T) {
+	alphabet := Alphabet(AlphabetReadableLower)
+	gen := NewChecked(NewRandom(alphabet, 6), alphabet)
+
+	for i := 0; i < 50; i++ {
+		code, err := gen.Generate(context.Background(), "", 0)
+		require.NoError(t, err)
+		require.Len(t, code, 7)
+		require.True(t, ValidCheck(code, alphabet))
+
+		// any single typo must be caught
+		for pos := 0; pos < len(code); pos++ {
+			for _, r := range []byte(alphabet) {
+				if r == code[pos] {
+					continue
+				}
+				typo := code[:pos] + string(r) + code[pos+1:]
+				require.False(t, ValidCheck(typo, alphabet), "typo %s of %s", typo, code)
+			}
+		}
+	}
+
+	require.False(t, ValidCheck("a", alphabet))
+	require.False(t, ValidCheck("0000", alphabet))
+}
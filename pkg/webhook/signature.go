@@ -0,0 +1,84 @@
+// Package webhook signs webhook deliveries of url shortener and lets receivers verify them.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers of delivery request
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// DefaultTolerance is how old signature receivers should accept
+const DefaultTolerance = 5 * time.Minute
+
+var (
+	ErrNoSignature      = errors.New("webhook signature is missing")
+	ErrInvalidSignature = errors.New("webhook signature doesn't match")
+	ErrExpired          = errors.New("webhook signature is expired")
+)
+
+// Sign returns value of signature header: t=<unix time>,v1=<hex hmac-sha256 of "<unix time>.<body>">.
+// Time is signed too, so captured request can't be replayed later.
+func Sign(secret string, body []byte, t time.Time) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac(secret, ts, body))
+}
+
+// Verify checks signature header of body, signatures older than tolerance are rejected,
+// zero tolerance accepts any age
+func Verify(secret string, body []byte, header string, tolerance time.Duration) error {
+	var ts string
+	var sigs [][]byte
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			if sig, err := hex.DecodeString(v); err == nil {
+				sigs = append(sigs, sig)
+			}
+		}
+	}
+	if ts == "" || len(sigs) == 0 {
+		return ErrNoSignature
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	expected := mac(secret, ts, body)
+	valid := false
+	for _, sig := range sigs {
+		valid = valid || hmac.Equal(sig, expected)
+	}
+	if !valid {
+		return ErrInvalidSignature
+	}
+
+	if tolerance > 0 && time.Since(time.Unix(unix, 0)) > tolerance {
+		return ErrExpired
+	}
+
+	return nil
+}
+
+func mac(secret, ts string, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(ts))
+	h.Write([]byte("."))
+	h.Write(body)
+	return h.Sum(nil)
+}
@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"id":"1","type":"link.created"}`)
+	now := time.Now()
+	// receiver may accept signatures of old and new secret during rotation
+	_, rotated, _ := strings.Cut(Sign("secret", body, now), ",v1=")
+
+	tests := []struct {
+		name   string
+		secret string
+		body   []byte
+		header string
+		err    error
+	}{
+		{name: "valid", secret: "secret", body: body, header: Sign("secret", body, now)},
+		{name: "wrong secret", secret: "other", body: body, header: Sign("secret", body, now), err: ErrInvalidSignature},
+		{name: "changed body", secret: "secret", body: []byte(`{}`), header: Sign("secret", body, now), err: ErrInvalidSignature},
+		{name: "expired", secret: "secret", body: body, header: Sign("secret", body, now.Add(-time.Hour)), err: ErrExpired},
+		{name: "missing", secret: "secret", body: body, header: "", err: ErrNoSignature},
+		{name: "no time", secret: "secret", body: body, header: "v1=00ff", err: ErrNoSignature},
+		{
+			name:   "one of signatures matches",
+			secret: "secret",
+			body:   body,
+			header: Sign("old", body, now) + ",v1=" + rotated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.body, tt.header, DefaultTolerance)
+			if tt.err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	t.Run("zero tolerance accepts old signature", func(t *testing.T) {
+		header := Sign("secret", body, now.Add(-24*time.Hour))
+		require.NoError(t, Verify("secret", body, header, 0))
+	})
+}